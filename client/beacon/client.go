@@ -0,0 +1,243 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package beacon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blobCommitmentVersionKZG version byte of the blob versioned hashes
+const blobCommitmentVersionKZG = 0x01
+
+type genesisResponse struct {
+	Data struct {
+		GenesisTime string `json:"genesis_time"`
+	} `json:"data"`
+}
+
+type specResponse struct {
+	Data struct {
+		SecondsPerSlot string `json:"SECONDS_PER_SLOT"`
+	} `json:"data"`
+}
+
+// BlobSidecar blob sidecar of the beacon api, the proofs are left unverified
+type BlobSidecar struct {
+	Index         string `json:"index"`
+	Blob          string `json:"blob"`
+	KzgCommitment string `json:"kzg_commitment"`
+}
+
+type sidecarsResponse struct {
+	Data []*BlobSidecar `json:"data"`
+}
+
+// Client
+/*****************************************************
+ * beacon api client fetching the blob sidecars of the execution blocks,
+ * the execution block is mapped to its slot by the block timestamp
+ ****************************************************/
+type Client struct {
+	url            string
+	httpClient     *http.Client
+	genesisTime    uint64
+	secondsPerSlot uint64
+}
+
+// Dial creates the client & loads the genesis time & the slot duration of the beacon chain
+func Dial(rawurl string) (*Client, error) {
+	c := &Client{
+		url:        strings.TrimRight(rawurl, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	ctx := context.Background()
+	genesis := &genesisResponse{}
+	if err := c.get(ctx, "/eth/v1/beacon/genesis", genesis); err != nil {
+		return nil, fmt.Errorf("load beacon genesis err:%w", err)
+	}
+
+	spec := &specResponse{}
+	if err := c.get(ctx, "/eth/v1/config/spec", spec); err != nil {
+		return nil, fmt.Errorf("load beacon spec err:%w", err)
+	}
+
+	var err error
+	if c.genesisTime, err = strconv.ParseUint(genesis.Data.GenesisTime, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid genesis time[%s]", genesis.Data.GenesisTime)
+	}
+	if c.secondsPerSlot, err = strconv.ParseUint(spec.Data.SecondsPerSlot, 10, 64); err != nil || c.secondsPerSlot == 0 {
+		return nil, fmt.Errorf("invalid seconds per slot[%s]", spec.Data.SecondsPerSlot)
+	}
+	return c, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return xycommon.ErrNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("beacon api[%s] status[%d], body[%s]", path, resp.StatusCode, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// Slot returns the slot of the execution block timestamp
+func (c *Client) Slot(timestamp uint64) (uint64, error) {
+	if timestamp < c.genesisTime {
+		return 0, fmt.Errorf("timestamp[%d] before the beacon genesis[%d]", timestamp, c.genesisTime)
+	}
+	return (timestamp - c.genesisTime) / c.secondsPerSlot, nil
+}
+
+// BlobSidecars returns the blob sidecars of the slot, pruned or missed slots return ErrNotFound
+func (c *Client) BlobSidecars(ctx context.Context, slot uint64) ([]*BlobSidecar, error) {
+	ret := &sidecarsResponse{}
+	if err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), ret); err != nil {
+		return nil, err
+	}
+	return ret.Data, nil
+}
+
+// BlockBlobs returns the blobs of the execution block identified by the versioned hashes
+func (c *Client) BlockBlobs(ctx context.Context, number *big.Int, timestamp uint64) ([]*xycommon.RpcBlob, error) {
+	slot, err := c.Slot(timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	sidecars, err := c.BlobSidecars(ctx, slot)
+	if err != nil {
+		return nil, fmt.Errorf("block[%v] slot[%d] blob sidecars err:%w", number, slot, err)
+	}
+
+	blobs := make([]*xycommon.RpcBlob, 0, len(sidecars))
+	for _, sidecar := range sidecars {
+		hash, err := VersionedHash(sidecar.KzgCommitment)
+		if err != nil {
+			return nil, fmt.Errorf("block[%v] blob[%s] invalid commitment:%w", number, sidecar.Index, err)
+		}
+
+		blobs = append(blobs, &xycommon.RpcBlob{
+			VersionedHash: hash,
+			Data:          sidecar.Blob,
+		})
+	}
+	return blobs, nil
+}
+
+// VersionedHash returns the versioned hash of the kzg commitment, sha256 with the version as the first byte
+func VersionedHash(commitment string) (string, error) {
+	data, err := hexutil.Decode(commitment)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) != 48 {
+		return "", errors.New("commitment length must be 48 bytes")
+	}
+
+	hash := sha256.Sum256(data)
+	hash[0] = blobCommitmentVersionKZG
+	return hexutil.Encode(hash[:]), nil
+}
+
+// BlobsClient
+/*****************************************************
+ * IRPCClient wrapper serving the blobs of the beacon api besides the execution rpc,
+ * the optional apis are forwarded to the wrapped client
+ ****************************************************/
+type BlobsClient struct {
+	xycommon.IRPCClient
+	beacon *Client
+}
+
+func NewBlobsClient(client xycommon.IRPCClient, beacon *Client) *BlobsClient {
+	return &BlobsClient{
+		IRPCClient: client,
+		beacon:     beacon,
+	}
+}
+
+func (c *BlobsClient) BlockBlobs(ctx context.Context, number *big.Int, timestamp uint64) ([]*xycommon.RpcBlob, error) {
+	return c.beacon.BlockBlobs(ctx, number, timestamp)
+}
+
+func (c *BlobsClient) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	bc, ok := c.IRPCClient.(xycommon.IReceiptsClient)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+	return bc.BlockReceipts(ctx, number)
+}
+
+func (c *BlobsClient) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	bc, ok := c.IRPCClient.(xycommon.IReceiptsClient)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+	return bc.BatchTransactionReceipts(ctx, txHashes)
+}
+
+func (c *BlobsClient) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	tc, ok := c.IRPCClient.(xycommon.ITraceClient)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+	return tc.TraceBlockCalls(ctx, number)
+}
+
+func (c *BlobsClient) SubscribeNewHead(ctx context.Context, ch chan<- *xycommon.RpcHeader) (ethereum.Subscription, error) {
+	subscriber, ok := c.IRPCClient.(xycommon.IHeadSubscriber)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+	return subscriber.SubscribeNewHead(ctx, ch)
+}
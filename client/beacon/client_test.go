@@ -0,0 +1,74 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBlockBlobs(t *testing.T) {
+	commitment := hexutil.Encode(make([]byte, 48))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/genesis":
+			fmt.Fprint(w, `{"data":{"genesis_time":"1000","genesis_fork_version":"0x00000000"}}`)
+		case "/eth/v1/config/spec":
+			fmt.Fprint(w, `{"data":{"SECONDS_PER_SLOT":"12"}}`)
+		case "/eth/v1/beacon/blob_sidecars/10":
+			fmt.Fprintf(w, `{"data":[{"index":"0","blob":"0x00","kzg_commitment":"%s","kzg_proof":"0x00"}]}`, commitment)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := Dial(server.URL + "/")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	slot, err := c.Slot(1000 + 12*10 + 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), slot)
+
+	_, err = c.Slot(999)
+	assert.Error(t, err)
+
+	blobs, err := c.BlockBlobs(context.Background(), big.NewInt(1), 1000+12*10)
+	if assert.NoError(t, err) && assert.Len(t, blobs, 1) {
+		// sha256 of 48 zero bytes with the version byte
+		assert.Equal(t, "0x01b0761f87b081d5cf10757ccc89f12be355c70e2e29df288b65b30710dcbcd1", blobs[0].VersionedHash)
+		assert.Equal(t, "0x00", blobs[0].Data)
+	}
+
+	_, err = c.BlockBlobs(context.Background(), big.NewInt(2), 1000+12*11)
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package btc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// blockVerbosity getblock verbosity including the spent outputs of the inputs
+const blockVerbosity = 3
+
+// BClient
+/*****************************************************
+ * bitcoind compatible rpc client, the node is required to run
+ * with -txindex for the sender / receipt lookups of single txs
+ ****************************************************/
+type BClient struct {
+	rawClient *rpcclient.Client
+}
+
+// Dial creates the http post mode client, the credentials in the url take precedence
+func Dial(rawurl, user, pass string) (*BClient, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.User != nil {
+		user = u.User.Username()
+		pass, _ = u.User.Password()
+	}
+
+	client, err := rpcclient.New(&rpcclient.ConnConfig{
+		Host:         u.Host + u.Path,
+		User:         user,
+		Pass:         pass,
+		DisableTLS:   !strings.EqualFold(u.Scheme, "https"),
+		HTTPPostMode: true,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BClient{rawClient: client}, nil
+}
+
+// Close shutdown the underlying rpc client
+func (c *BClient) Close() {
+	c.rawClient.Shutdown()
+}
+
+func (c *BClient) BlockNumber(ctx context.Context) (uint64, error) {
+	count, err := c.rawClient.GetBlockCount()
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return uint64(count), nil
+}
+
+func (c *BClient) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	hash, err := c.rawClient.GetBlockHash(number.Int64())
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	params := []json.RawMessage{
+		json.RawMessage(fmt.Sprintf("%q", hash.String())),
+		json.RawMessage(fmt.Sprintf("%d", blockVerbosity)),
+	}
+	raw, err := c.rawClient.RawRequest("getblock", params)
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	block := &rpcBlock{}
+	if err = json.Unmarshal(raw, block); err != nil {
+		return nil, err
+	}
+	return convertBlock(block), nil
+}
+
+func (c *BClient) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	hash, err := c.rawClient.GetBlockHash(number.Int64())
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	header, err := c.rawClient.GetBlockHeaderVerbose(hash)
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	return &xycommon.RpcHeader{
+		ParentHash: header.PreviousHash,
+		Number:     big.NewInt(int64(header.Height)),
+		Time:       uint64(header.Time),
+		TxHash:     header.MerkleRoot,
+		Hash:       header.Hash,
+	}, nil
+}
+
+// TransactionSender returns the owner address of the output spent by the first input
+func (c *BClient) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	tx, err := c.rawTransaction(txHash)
+	if err != nil {
+		return "", err
+	}
+
+	if len(tx.Vin) < 1 || tx.Vin[0].IsCoinBase() {
+		return "", nil
+	}
+
+	prevTx, err := c.rawTransaction(tx.Vin[0].Txid)
+	if err != nil {
+		return "", err
+	}
+
+	n := tx.Vin[0].Vout
+	if int(n) >= len(prevTx.Vout) {
+		return "", fmt.Errorf("prev tx[%s] output[%d] not found", prevTx.Txid, n)
+	}
+	return scriptAddress(&prevTx.Vout[n].ScriptPubKey), nil
+}
+
+// TransactionReceipt builds the receipt of the confirmed tx, btc txs in blocks are always succeeded
+func (c *BClient) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	tx, err := c.rawTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.BlockHash == "" {
+		return nil, xycommon.ErrNotFound
+	}
+
+	return &xycommon.RpcReceipt{
+		Type:              big.NewInt(0),
+		Status:            big.NewInt(1),
+		CumulativeGasUsed: big.NewInt(0),
+		TxHash:            common.HexToHash(tx.Txid),
+		GasUsed:           big.NewInt(int64(tx.Vsize)),
+		EffectiveGasPrice: big.NewInt(0),
+		BlockHash:         common.HexToHash(tx.BlockHash),
+	}, nil
+}
+
+// FilterLogs btc has no contract logs
+func (c *BClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	return []xycommon.RpcLog{}, nil
+}
+
+func (c *BClient) rawTransaction(txHash string) (*btcjson.TxRawResult, error) {
+	hash, err := chainhash.NewHashFromStr(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.rawClient.GetRawTransactionVerbose(hash)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return tx, nil
+}
+
+func convertBlock(block *rpcBlock) *xycommon.RpcBlock {
+	cBlock := &xycommon.RpcBlock{
+		ParentHash:   block.PreviousHash,
+		Number:       big.NewInt(block.Height),
+		GasLimit:     big.NewInt(0),
+		GasUsed:      big.NewInt(0),
+		Time:         uint64(block.Time),
+		TxHash:       block.MerkleRoot,
+		Hash:         block.Hash,
+		Transactions: make([]*xycommon.RpcTransaction, 0, len(block.Tx)),
+	}
+
+	for idx, tx := range block.Tx {
+		cBlock.Transactions = append(cBlock.Transactions, convertTx(block, idx, tx))
+	}
+	return cBlock
+}
+
+// convertTx maps btc tx to the common tx:
+// from - owner of the output spent by the first input
+// to - owner of the first output, which receives the inscriptions revealed by the tx
+// gas - virtual size, gas price - fee rate in sat/vB
+func convertTx(block *rpcBlock, idx int, tx *rpcTx) *xycommon.RpcTransaction {
+	cTx := &xycommon.RpcTransaction{
+		BlockHash:   block.Hash,
+		BlockNumber: big.NewInt(block.Height),
+		TxIndex:     big.NewInt(int64(idx)),
+		Type:        big.NewInt(0),
+		Hash:        tx.Txid,
+		Value:       big.NewInt(0),
+		Gas:         big.NewInt(tx.Vsize),
+		GasPrice:    big.NewInt(0),
+		Vin:         make([]btcjson.Vin, 0, len(tx.Vin)),
+		Vout:        tx.Vout,
+	}
+
+	prevouts := make([]btcjson.Vout, 0, len(tx.Vin))
+	for _, vin := range tx.Vin {
+		cTx.Vin = append(cTx.Vin, btcjson.Vin{
+			Coinbase:  vin.Coinbase,
+			Txid:      vin.Txid,
+			Vout:      vin.Vout,
+			ScriptSig: vin.ScriptSig,
+			Sequence:  vin.Sequence,
+			Witness:   vin.Witness,
+		})
+
+		if vin.Prevout != nil {
+			prevouts = append(prevouts, btcjson.Vout{
+				Value:        vin.Prevout.Value,
+				N:            vin.Vout,
+				ScriptPubKey: vin.Prevout.ScriptPubKey,
+			})
+		}
+	}
+
+	// prevouts are only usable when known for all the inputs
+	if len(prevouts) == len(tx.Vin) {
+		cTx.Prevouts = prevouts
+	}
+
+	if len(tx.Vin) > 0 && tx.Vin[0].Prevout != nil {
+		cTx.From = scriptAddress(&tx.Vin[0].Prevout.ScriptPubKey)
+	}
+
+	if len(tx.Vout) > 0 {
+		cTx.To = scriptAddress(&tx.Vout[0].ScriptPubKey)
+	}
+
+	total := int64(0)
+	for _, vout := range tx.Vout {
+		if amount, err := btcutil.NewAmount(vout.Value); err == nil {
+			total += int64(amount)
+		}
+	}
+	cTx.Value = big.NewInt(total)
+
+	if fee, err := btcutil.NewAmount(tx.Fee); err == nil && tx.Vsize > 0 {
+		cTx.GasPrice = big.NewInt(int64(fee) / tx.Vsize)
+	}
+	return cTx
+}
+
+func scriptAddress(script *btcjson.ScriptPubKeyResult) string {
+	if script.Address != "" {
+		return script.Address
+	}
+
+	if len(script.Addresses) > 0 {
+		return script.Addresses[0]
+	}
+	return ""
+}
+
+func convertError(err error) error {
+	var rpcErr *btcjson.RPCError
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+
+	switch rpcErr.Code {
+	case btcjson.ErrRPCInvalidAddressOrKey, btcjson.ErrRPCInvalidParameter:
+		return xycommon.ErrNotFound
+	case btcjson.ErrRPCMethodNotFound.Code:
+		return fmt.Errorf("%w: %v", xycommon.ErrMethodNotSupported, err)
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package btc
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"testing"
+)
+
+// regtestBlock getblock verbosity 3 result of a regtest block with a coinbase & a reveal tx
+const regtestBlock = `{
+  "hash": "3a3a1f9c7c1d2d5e2b6f5a0e5d1c9b8a7f6e5d4c3b2a19080706050403020100",
+  "confirmations": 1,
+  "height": 102,
+  "version": 536870912,
+  "merkleroot": "8f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0",
+  "time": 1700000102,
+  "previousblockhash": "1b2c3d4e5f60718293a4b5c6d7e8f9000f1e2d3c4b5a69788796a5b4c3d2e1f0",
+  "tx": [
+    {
+      "txid": "c0ffee00c0ffee00c0ffee00c0ffee00c0ffee00c0ffee00c0ffee00c0ffee00",
+      "vsize": 100,
+      "vin": [{"coinbase": "016600", "txinwitness": ["0000000000000000000000000000000000000000000000000000000000000000"], "sequence": 4294967295}],
+      "vout": [{"value": 50.00000000, "n": 0, "scriptPubKey": {"asm": "", "hex": "0014aa", "type": "witness_v0_keyhash", "address": "bcrt1qminer"}}]
+    },
+    {
+      "txid": "7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a7e5a",
+      "vsize": 150,
+      "fee": 0.00001500,
+      "vin": [{
+        "txid": "5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d5e6d",
+        "vout": 0,
+        "scriptSig": {"asm": "", "hex": ""},
+        "txinwitness": ["03", "0063036f726400", "c0"],
+        "prevout": {"generated": false, "height": 101, "value": 0.00010000, "scriptPubKey": {"asm": "", "hex": "5120bb", "type": "witness_v1_taproot", "address": "bcrt1pcommit"}},
+        "sequence": 4294967293
+      }],
+      "vout": [
+        {"value": 0.00000546, "n": 0, "scriptPubKey": {"asm": "", "hex": "5120cc", "type": "witness_v1_taproot", "address": "bcrt1preceiver"}},
+        {"value": 0.00007954, "n": 1, "scriptPubKey": {"asm": "", "hex": "5120bb", "type": "witness_v1_taproot", "address": "bcrt1pchange"}}
+      ]
+    }
+  ]
+}`
+
+func TestConvertBlock(t *testing.T) {
+	raw := &rpcBlock{}
+	assert.NoError(t, json.Unmarshal([]byte(regtestBlock), raw))
+
+	block := convertBlock(raw)
+	assert.Equal(t, uint64(102), block.Number.Uint64())
+	assert.Equal(t, uint64(1700000102), block.Time)
+	assert.Equal(t, raw.Hash, block.Hash)
+	assert.Equal(t, raw.PreviousHash, block.ParentHash)
+	assert.Len(t, block.Transactions, 2)
+
+	coinbase := block.Transactions[0]
+	assert.Equal(t, "", coinbase.From)
+	assert.Equal(t, "bcrt1qminer", coinbase.To)
+	assert.True(t, coinbase.Vin[0].IsCoinBase())
+	assert.Empty(t, coinbase.Prevouts)
+
+	reveal := block.Transactions[1]
+	assert.Equal(t, raw.Tx[1].Txid, reveal.Hash)
+	assert.Equal(t, raw.Hash, reveal.BlockHash)
+	assert.Equal(t, uint64(1), reveal.TxIndex.Uint64())
+	assert.Equal(t, "bcrt1pcommit", reveal.From)
+	assert.Equal(t, "bcrt1preceiver", reveal.To)
+	assert.Equal(t, int64(8500), reveal.Value.Int64())
+	assert.Equal(t, int64(150), reveal.Gas.Int64())
+	assert.Equal(t, int64(10), reveal.GasPrice.Int64())
+	assert.Equal(t, []string{"03", "0063036f726400", "c0"}, reveal.Vin[0].Witness)
+	if assert.Len(t, reveal.Prevouts, 1) {
+		assert.Equal(t, 0.0001, reveal.Prevouts[0].Value)
+		assert.Equal(t, "bcrt1pcommit", reveal.Prevouts[0].ScriptPubKey.Address)
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	err := convertError(&btcjson.RPCError{Code: btcjson.ErrRPCInvalidParameter, Message: "Block height out of range"})
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+
+	err = convertError(&btcjson.RPCError{Code: btcjson.ErrRPCMethodNotFound.Code, Message: "Method not found"})
+	assert.ErrorIs(t, err, xycommon.ErrMethodNotSupported)
+
+	origin := errors.New("connection refused")
+	assert.Equal(t, origin, convertError(origin))
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package btc
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// rpcBlock getblock result with verbosity 3, transactions with the spent outputs
+type rpcBlock struct {
+	Hash         string   `json:"hash"`
+	Height       int64    `json:"height"`
+	Time         int64    `json:"time"`
+	MerkleRoot   string   `json:"merkleroot"`
+	PreviousHash string   `json:"previousblockhash"`
+	Tx           []*rpcTx `json:"tx"`
+}
+
+type rpcTx struct {
+	Txid  string         `json:"txid"`
+	Vsize int64          `json:"vsize"`
+	Vin   []*rpcVin      `json:"vin"`
+	Vout  []btcjson.Vout `json:"vout"`
+	Fee   float64        `json:"fee"`
+}
+
+type rpcVin struct {
+	Coinbase  string             `json:"coinbase"`
+	Txid      string             `json:"txid"`
+	Vout      uint32             `json:"vout"`
+	ScriptSig *btcjson.ScriptSig `json:"scriptSig"`
+	Sequence  uint32             `json:"sequence"`
+	Witness   []string           `json:"txinwitness"`
+	Prevout   *rpcPrevout        `json:"prevout"`
+}
+
+// rpcPrevout the output spent by the input, bitcoind 22+ only
+type rpcPrevout struct {
+	Height       int64                      `json:"height"`
+	Value        float64                    `json:"value"`
+	ScriptPubKey btcjson.ScriptPubKeyResult `json:"scriptPubKey"`
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package cosmos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("code[%d], message[%s], data[%s]", e.Code, e.Message, e.Data)
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type statusResult struct {
+	SyncInfo struct {
+		LatestBlockHeight string `json:"latest_block_height"`
+	} `json:"sync_info"`
+}
+
+type blockID struct {
+	Hash string `json:"hash"`
+}
+
+type rpcHeader struct {
+	Height      string    `json:"height"`
+	Time        time.Time `json:"time"`
+	LastBlockID blockID   `json:"last_block_id"`
+	DataHash    string    `json:"data_hash"`
+}
+
+type blockResult struct {
+	BlockID blockID `json:"block_id"`
+	Block   struct {
+		Header rpcHeader `json:"header"`
+		Data   struct {
+			Txs [][]byte `json:"txs"`
+		} `json:"data"`
+	} `json:"block"`
+}
+
+type blockchainResult struct {
+	BlockMetas []struct {
+		BlockID blockID   `json:"block_id"`
+		Header  rpcHeader `json:"header"`
+	} `json:"block_metas"`
+}
+
+type execResult struct {
+	Code    uint32 `json:"code"`
+	GasUsed string `json:"gas_used"`
+}
+
+type blockResultsResult struct {
+	TxsResults []*execResult `json:"txs_results"`
+}
+
+type txResult struct {
+	Hash     string     `json:"hash"`
+	Height   string     `json:"height"`
+	Index    uint32     `json:"index"`
+	TxResult execResult `json:"tx_result"`
+	Tx       []byte     `json:"tx"`
+}
+
+// CClient
+/*****************************************************
+ * tendermint / cometbft rpc client of the cosmos sdk chains,
+ * the inscriptions are carried by the memo of the bank sends
+ ****************************************************/
+type CClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// Dial creates the client of the rpc uri endpoints
+func Dial(rawurl string) (*CClient, error) {
+	if _, err := url.Parse(rawurl); err != nil {
+		return nil, err
+	}
+
+	return &CClient{
+		url:        strings.TrimRight(rawurl, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (c *CClient) call(ctx context.Context, method string, params url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	ret := &rpcResponse{}
+	if err = json.Unmarshal(body, ret); err != nil {
+		return fmt.Errorf("rpc[%s] status[%d] response decode err:%v", method, resp.StatusCode, err)
+	}
+
+	if ret.Error != nil {
+		return convertError(ret.Error)
+	}
+	return json.Unmarshal(ret.Result, result)
+}
+
+// convertError converts missing blocks & txs into ErrNotFound
+func convertError(err *rpcError) error {
+	msg := err.Message + " " + err.Data
+	if strings.Contains(msg, "not found") || strings.Contains(msg, "must be less than or equal to the current blockchain height") {
+		return xycommon.ErrNotFound
+	}
+	return err
+}
+
+func heightParams(number *big.Int) url.Values {
+	return url.Values{"height": []string{number.String()}}
+}
+
+func (c *CClient) BlockNumber(ctx context.Context) (uint64, error) {
+	ret := &statusResult{}
+	if err := c.call(ctx, "status", nil, ret); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(ret.SyncInfo.LatestBlockHeight, 10, 64)
+}
+
+func (c *CClient) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	ret := &blockResult{}
+	if err := c.call(ctx, "block", heightParams(number), ret); err != nil {
+		return nil, err
+	}
+	return convertBlock(ret), nil
+}
+
+func (c *CClient) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	params := url.Values{"minHeight": []string{number.String()}, "maxHeight": []string{number.String()}}
+	ret := &blockchainResult{}
+	if err := c.call(ctx, "blockchain", params, ret); err != nil {
+		return nil, err
+	}
+
+	if len(ret.BlockMetas) < 1 {
+		return nil, xycommon.ErrNotFound
+	}
+
+	meta := ret.BlockMetas[0]
+	height, _ := strconv.ParseInt(meta.Header.Height, 10, 64)
+	return &xycommon.RpcHeader{
+		ParentHash: meta.Header.LastBlockID.Hash,
+		Number:     big.NewInt(height),
+		Time:       uint64(meta.Header.Time.Unix()),
+		TxHash:     meta.Header.DataHash,
+		Hash:       meta.BlockID.Hash,
+	}, nil
+}
+
+// TransactionSender returns the sender of the bank send
+func (c *CClient) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	ret, err := c.tx(ctx, txHash)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := decodeTx(ret.Tx)
+	if err != nil {
+		return "", err
+	}
+	return tx.From, nil
+}
+
+func (c *CClient) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	ret, err := c.tx(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	height, _ := strconv.ParseInt(ret.Height, 10, 64)
+	receipt := convertReceipt(ret.Hash, &ret.TxResult)
+	receipt.BlockNumber = big.NewInt(height)
+	receipt.TransactionIndex = big.NewInt(int64(ret.Index))
+	return receipt, nil
+}
+
+func (c *CClient) tx(ctx context.Context, txHash string) (*txResult, error) {
+	params := url.Values{"hash": []string{"0x" + strings.TrimPrefix(strings.ToUpper(txHash), "0X")}}
+	ret := &txResult{}
+	if err := c.call(ctx, "tx", params, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// BlockReceipts returns the receipts of the block txs by the block results
+func (c *CClient) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	block, err := c.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &blockResultsResult{}
+	if err = c.call(ctx, "block_results", heightParams(number), ret); err != nil {
+		return nil, err
+	}
+
+	if len(ret.TxsResults) != len(block.Transactions) {
+		return nil, fmt.Errorf("block[%v] tx results size[%d] <> txs size[%d]", number, len(ret.TxsResults), len(block.Transactions))
+	}
+
+	receipts := make([]*xycommon.RpcReceipt, 0, len(block.Transactions))
+	for idx, tx := range block.Transactions {
+		receipt := convertReceipt(tx.Hash, ret.TxsResults[idx])
+		receipt.BlockNumber = block.Number
+		receipt.TransactionIndex = big.NewInt(int64(idx))
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// BatchTransactionReceipts batch requests are not supported by the uri endpoints
+func (c *CClient) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	return nil, xycommon.ErrMethodNotSupported
+}
+
+// FilterLogs cosmos has no evm logs
+func (c *CClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	return []xycommon.RpcLog{}, nil
+}
+
+func convertBlock(ret *blockResult) *xycommon.RpcBlock {
+	header := &ret.Block.Header
+	height, _ := strconv.ParseInt(header.Height, 10, 64)
+	block := &xycommon.RpcBlock{
+		ParentHash:   header.LastBlockID.Hash,
+		Number:       big.NewInt(height),
+		GasLimit:     big.NewInt(0),
+		GasUsed:      big.NewInt(0),
+		Time:         uint64(header.Time.Unix()),
+		TxHash:       header.DataHash,
+		Hash:         ret.BlockID.Hash,
+		Transactions: make([]*xycommon.RpcTransaction, 0, len(ret.Block.Data.Txs)),
+	}
+
+	for idx, raw := range ret.Block.Data.Txs {
+		block.Transactions = append(block.Transactions, convertTx(block, idx, raw))
+	}
+	return block
+}
+
+// convertTx maps cosmos tx to the common tx:
+// from / to - sender & receiver of the first msg if it is a bank send, empty otherwise
+// memo - memo of the tx body, gas - gas limit of the fee
+func convertTx(block *xycommon.RpcBlock, idx int, raw []byte) *xycommon.RpcTransaction {
+	hash := sha256.Sum256(raw)
+	cTx := &xycommon.RpcTransaction{
+		BlockHash:   block.Hash,
+		BlockNumber: block.Number,
+		TxIndex:     big.NewInt(int64(idx)),
+		Type:        big.NewInt(0),
+		Hash:        strings.ToUpper(hex.EncodeToString(hash[:])),
+		Value:       big.NewInt(0),
+		Gas:         big.NewInt(0),
+		GasPrice:    big.NewInt(0),
+	}
+
+	tx, err := decodeTx(raw)
+	if err != nil {
+		xylog.Logger.Debugf("decode tx[%s] err:%v & skip", cTx.Hash, err)
+		return cTx
+	}
+
+	cTx.From = tx.From
+	cTx.To = tx.To
+	cTx.Memo = tx.Memo
+	cTx.Gas = new(big.Int).SetUint64(tx.GasLimit)
+	return cTx
+}
+
+// convertReceipt failed txs are included in blocks with the non-zero code
+func convertReceipt(hash string, ret *execResult) *xycommon.RpcReceipt {
+	status := int64(1)
+	if ret.Code != 0 {
+		status = 0
+	}
+
+	gasUsed, _ := strconv.ParseInt(ret.GasUsed, 10, 64)
+	return &xycommon.RpcReceipt{
+		Type:              big.NewInt(0),
+		Status:            big.NewInt(status),
+		CumulativeGasUsed: big.NewInt(0),
+		TxHash:            common.HexToHash(hash),
+		GasUsed:           big.NewInt(gasUsed),
+		EffectiveGasPrice: big.NewInt(0),
+	}
+}
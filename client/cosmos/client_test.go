@@ -0,0 +1,156 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package cosmos
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testAddress(t *testing.T, b byte) string {
+	data, err := bech32.ConvertBits(make([]byte, 20), 8, 5, true)
+	assert.NoError(t, err)
+	data[len(data)-1] = b & 0x1f
+
+	address, err := bech32.Encode("celestia", data)
+	assert.NoError(t, err)
+	return address
+}
+
+// testTx builds the TxRaw of the bank send with the memo
+func testTx(msgType, from, to, memo string) []byte {
+	var msg, anyMsg, body, fee, authInfo, raw []byte
+	msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+	msg = protowire.AppendString(msg, from)
+	msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+	msg = protowire.AppendString(msg, to)
+
+	anyMsg = protowire.AppendTag(anyMsg, 1, protowire.BytesType)
+	anyMsg = protowire.AppendString(anyMsg, msgType)
+	anyMsg = protowire.AppendTag(anyMsg, 2, protowire.BytesType)
+	anyMsg = protowire.AppendBytes(anyMsg, msg)
+
+	body = protowire.AppendTag(body, 1, protowire.BytesType)
+	body = protowire.AppendBytes(body, anyMsg)
+	body = protowire.AppendTag(body, 2, protowire.BytesType)
+	body = protowire.AppendString(body, memo)
+
+	fee = protowire.AppendTag(fee, 2, protowire.VarintType)
+	fee = protowire.AppendVarint(fee, 80000)
+	authInfo = protowire.AppendTag(authInfo, 2, protowire.BytesType)
+	authInfo = protowire.AppendBytes(authInfo, fee)
+
+	raw = protowire.AppendTag(raw, 1, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, body)
+	raw = protowire.AppendTag(raw, 2, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, authInfo)
+	return raw
+}
+
+func TestDecodeTx(t *testing.T) {
+	from, to := testAddress(t, 1), testAddress(t, 2)
+	memo := `data:,{"op":"mint","amt":"10000","tick":"cias","p":"cia-20"}`
+
+	tx, err := decodeTx(testTx(msgSendTypeURL, from, to, memo))
+	if assert.NoError(t, err) {
+		assert.Equal(t, memo, tx.Memo)
+		assert.Equal(t, from, tx.From)
+		assert.Equal(t, to, tx.To)
+		assert.Equal(t, uint64(80000), tx.GasLimit)
+	}
+
+	// other msgs carry no addresses
+	tx, err = decodeTx(testTx("/cosmos.staking.v1beta1.MsgDelegate", from, to, memo))
+	if assert.NoError(t, err) {
+		assert.Equal(t, memo, tx.Memo)
+		assert.Empty(t, tx.From)
+	}
+
+	_, err = decodeTx(testTx(msgSendTypeURL, "0x00000000000000000000000000000000000000aa", to, memo))
+	assert.Error(t, err)
+
+	_, err = decodeTx([]byte{0xff})
+	assert.Error(t, err)
+}
+
+func TestBlockByNumber(t *testing.T) {
+	from, to := testAddress(t, 1), testAddress(t, 2)
+	txs := [][]byte{
+		testTx(msgSendTypeURL, from, from, `data:,{"op":"mint","amt":"10000","tick":"cias","p":"cia-20"}`),
+		testTx(msgSendTypeURL, from, to, ""),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/status?":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":-1,"result":{"sync_info":{"latest_block_height":"100"}}}`)
+		case "/block?height=100":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":-1,"result":{"block_id":{"hash":"B100"},"block":{"header":{"height":"100","time":"2024-01-01T00:00:00.5Z","last_block_id":{"hash":"B99"},"data_hash":"D100"},"data":{"txs":["%s","%s"]}}}}`,
+				base64.StdEncoding.EncodeToString(txs[0]), base64.StdEncoding.EncodeToString(txs[1]))
+		case "/block_results?height=100":
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":-1,"result":{"height":"100","txs_results":[{"code":0,"gas_used":"60000"},{"code":5,"gas_used":"40000"}]}}`)
+		default:
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":-1,"error":{"code":-32603,"message":"Internal error","data":"height 101 must be less than or equal to the current blockchain height 100"}}`)
+		}
+	}))
+	defer server.Close()
+
+	c, err := Dial(server.URL)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	num, err := c.BlockNumber(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), num)
+
+	block, err := c.BlockByNumber(ctx, big.NewInt(100))
+	if assert.NoError(t, err) && assert.Len(t, block.Transactions, 2) {
+		assert.Equal(t, "B100", block.Hash)
+		assert.Equal(t, "B99", block.ParentHash)
+		assert.Equal(t, uint64(1704067200), block.Time)
+		assert.Equal(t, from, block.Transactions[0].From)
+		assert.Equal(t, from, block.Transactions[0].To)
+		assert.Contains(t, block.Transactions[0].Memo, "cia-20")
+		assert.Len(t, block.Transactions[0].Hash, 64)
+		assert.Equal(t, to, block.Transactions[1].To)
+	}
+
+	receipts, err := c.BlockReceipts(ctx, big.NewInt(100))
+	if assert.NoError(t, err) && assert.Len(t, receipts, 2) {
+		assert.Equal(t, int64(1), receipts[0].Status.Int64())
+		assert.Equal(t, int64(0), receipts[1].Status.Int64())
+		assert.Equal(t, int64(60000), receipts[0].GasUsed.Int64())
+	}
+
+	_, err = c.BlockByNumber(ctx, big.NewInt(101))
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+}
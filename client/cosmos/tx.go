@@ -0,0 +1,186 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package cosmos
+
+import (
+	"errors"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"google.golang.org/protobuf/encoding/protowire"
+	"strings"
+)
+
+// msgSendTypeURL type url of the bank send msg, which carries the memo inscriptions
+const msgSendTypeURL = "/cosmos.bank.v1beta1.MsgSend"
+
+// decodedTx fields of the protobuf tx used by the indexer
+type decodedTx struct {
+	Memo     string
+	From     string // sender of the first msg if it is a bank send
+	To       string // receiver of the first msg if it is a bank send
+	GasLimit uint64
+}
+
+// rangeFields calls fn with each field of the protobuf message, the value is only set for the bytes fields
+func rangeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, i uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var (
+			v []byte
+			i uint64
+		)
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			i, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		fn(num, typ, v, i)
+		b = b[n:]
+	}
+	return nil
+}
+
+// decodeTx decodes the memo, the bank send addresses & the gas limit of the protobuf TxRaw,
+// legacy amino txs are not supported
+func decodeTx(raw []byte) (*decodedTx, error) {
+	var body, authInfo []byte
+	err := rangeFields(raw, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			body = v
+		case num == 2 && typ == protowire.BytesType:
+			authInfo = v
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if body == nil {
+		return nil, errors.New("tx body not found")
+	}
+
+	tx := &decodedTx{}
+	var firstMsg []byte
+	err = rangeFields(body, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		switch {
+		case num == 1 && typ == protowire.BytesType && firstMsg == nil:
+			firstMsg = v
+		case num == 2 && typ == protowire.BytesType:
+			tx.Memo = string(v)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if firstMsg != nil {
+		if tx.From, tx.To, err = decodeMsgSend(firstMsg); err != nil {
+			return nil, err
+		}
+	}
+
+	if authInfo != nil {
+		if tx.GasLimit, err = decodeGasLimit(authInfo); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// decodeMsgSend returns the addresses of the bank send msg packed in the Any, other msgs return empty addresses
+func decodeMsgSend(msg []byte) (from, to string, err error) {
+	var (
+		typeURL string
+		value   []byte
+	)
+	err = rangeFields(msg, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			typeURL = string(v)
+		case num == 2 && typ == protowire.BytesType:
+			value = v
+		}
+	})
+	if err != nil || typeURL != msgSendTypeURL {
+		return "", "", err
+	}
+
+	err = rangeFields(value, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			from = string(v)
+		case num == 2 && typ == protowire.BytesType:
+			to = string(v)
+		}
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	if !IsBech32Address(from) || !IsBech32Address(to) {
+		return "", "", errors.New("bank send address invalid")
+	}
+	return strings.ToLower(from), strings.ToLower(to), nil
+}
+
+// decodeGasLimit returns the gas limit of the fee in AuthInfo
+func decodeGasLimit(authInfo []byte) (gasLimit uint64, err error) {
+	var fee []byte
+	err = rangeFields(authInfo, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		if num == 2 && typ == protowire.BytesType {
+			fee = v
+		}
+	})
+	if err != nil || fee == nil {
+		return 0, err
+	}
+
+	err = rangeFields(fee, func(num protowire.Number, typ protowire.Type, _ []byte, i uint64) {
+		if num == 2 && typ == protowire.VarintType {
+			gasLimit = i
+		}
+	})
+	return gasLimit, err
+}
+
+// IsBech32Address reports whether the address is a valid bech32 account address
+func IsBech32Address(address string) bool {
+	hrp, data, err := bech32.Decode(address)
+	if err != nil || hrp == "" {
+		return false
+	}
+
+	decoded, err := bech32.ConvertBits(data, 5, 8, false)
+	return err == nil && len(decoded) > 0
+}
@@ -31,11 +31,26 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/xylog"
 	"math/big"
+	"regexp"
+	"strings"
 	"time"
 )
 
+const (
+	// maxBatchSize max requests in one json-rpc batch, most providers limit the batch size
+	maxBatchSize = 100
+
+	// methodNotFoundCode json-rpc error code of unknown method
+	methodNotFoundCode = -32601
+)
+
+// methodNotFoundMessage unknown method replies of the providers without the standard error code,
+// "method not found" or geth's "the method xxx does not exist/is not available"
+var methodNotFoundMessage = regexp.MustCompile(`^(method not found|the method [a-z0-9_]+ does not exist/is not available)$`)
+
 // RawClient defines typed wrappers for the Ethereum RPC API.
 type RawClient struct {
 	c *rpc.Client
@@ -99,6 +114,11 @@ func (ec *RawClient) CallContext(ctx context.Context, result interface{}, method
 			return rpc.ErrNoResult
 		}
 
+		// retrying never helps if the node does not support the method
+		if IsMethodNotFound(err) {
+			return err
+		}
+
 		select {
 		case <-time.After(time.Millisecond * 100):
 			//do nothing
@@ -200,7 +220,7 @@ func (ec *RawClient) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQ
 
 // SubscribeNewHead subscribes to notifications about the current blockchain head
 // on the given channel.
-func (ec *RawClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+func (ec *RawClient) SubscribeNewHead(ctx context.Context, ch chan<- *RpcHeader) (ethereum.Subscription, error) {
 	sub, err := ec.c.EthSubscribe(ctx, ch, "newHeads")
 	if err != nil {
 		// Defensively prefer returning nil interface explicitly on error-path, instead
@@ -245,6 +265,88 @@ func (ec *RawClient) TransactionReceipt(ctx context.Context, txHash common.Hash)
 	return r, err
 }
 
+// BlockReceipts returns the receipts of all transactions in the block.
+func (ec *RawClient) BlockReceipts(ctx context.Context, number *big.Int) ([]*RpcReceipt, error) {
+	var r []*RpcReceipt
+	err := ec.CallContext(ctx, &r, "eth_getBlockReceipts", toBlockNumArg(number))
+	if err == nil && r == nil {
+		return nil, ethereum.NotFound
+	}
+	return r, err
+}
+
+// TraceBlockCalls returns the call traces of all transactions in the block by the callTracer.
+func (ec *RawClient) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	var r []*xycommon.RpcTxTrace
+	err := ec.CallContext(ctx, &r, "debug_traceBlockByNumber", toBlockNumArg(number), map[string]interface{}{"tracer": "callTracer"})
+	if err == nil && r == nil {
+		return nil, ethereum.NotFound
+	}
+	return r, err
+}
+
+// BatchTransactionReceipts returns the receipts of transactions with batched json-rpc calls,
+// receipts are in the same order as the hashes.
+func (ec *RawClient) BatchTransactionReceipts(ctx context.Context, txHashes []common.Hash) ([]*RpcReceipt, error) {
+	receipts := make([]*RpcReceipt, 0, len(txHashes))
+	for start := 0; start < len(txHashes); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(txHashes) {
+			end = len(txHashes)
+		}
+
+		batch := make([]rpc.BatchElem, 0, end-start)
+		for _, txHash := range txHashes[start:end] {
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{txHash},
+				Result: new(RpcReceipt),
+			})
+		}
+
+		if err := ec.BatchCallContext(ctx, batch); err != nil {
+			return nil, err
+		}
+
+		for _, elem := range batch {
+			if elem.Error != nil {
+				return nil, elem.Error
+			}
+
+			r := elem.Result.(*RpcReceipt)
+			if r.TxHash == (common.Hash{}) {
+				return nil, ethereum.NotFound
+			}
+			receipts = append(receipts, r)
+		}
+	}
+	return receipts, nil
+}
+
+// BatchCallContext sends all given requests as a single batch with retry
+func (ec *RawClient) BatchCallContext(ctx context.Context, batch []rpc.BatchElem) (err error) {
+	retry := 10
+	for i := 0; i < retry; i++ {
+		timeCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+		t1 := time.Now()
+		err = ec.c.BatchCallContext(timeCtx, batch)
+		cancel()
+
+		xylog.Logger.Debugf("JSONRPC-BATCH-CALL, items:%d, cost[%v], retry[%d], err[%v]", len(batch), time.Since(t1), i, err)
+		if err == nil || IsMethodNotFound(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(time.Millisecond * 100):
+			//do nothing
+		case <-ctx.Done():
+			return errors.New("ctx done quit")
+		}
+	}
+	return err
+}
+
 func (ec *RawClient) TransactionByHash(ctx context.Context, hash common.Hash) (tx *RpcTransaction, isPending bool, err error) {
 	tx = &RpcTransaction{}
 	err = ec.CallContext(ctx, tx, "eth_getTransactionByHash", hash)
@@ -265,6 +367,17 @@ func (ec *RawClient) IsContract(ctx context.Context, address common.Address) (ok
 	return len(result) > 0, err
 }
 
+// IsMethodNotFound checks whether the node rejected the call as an unknown method
+func IsMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+		return true
+	}
+
+	// some providers reply unknown methods without standard error code
+	return methodNotFoundMessage.MatchString(strings.ToLower(strings.TrimSpace(err.Error())))
+}
+
 func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
 	arg := map[string]interface{}{
 		"address": q.Addresses,
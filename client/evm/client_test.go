@@ -0,0 +1,64 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// codeError json-rpc error reply of the node
+type codeError struct {
+	code int
+	msg  string
+}
+
+func (e *codeError) Error() string {
+	return e.msg
+}
+
+func (e *codeError) ErrorCode() int {
+	return e.code
+}
+
+func TestIsMethodNotFound(t *testing.T) {
+	cases := []struct {
+		err      error
+		notFound bool
+	}{
+		{&codeError{code: -32601, msg: "unsupported"}, true},
+		{fmt.Errorf("block receipts: %w", &codeError{code: -32601, msg: "unsupported"}), true},
+		{errors.New("Method not found"), true},
+		{errors.New("the method eth_getBlockReceipts does not exist/is not available"), true},
+		{&codeError{code: -32000, msg: "header not found"}, false},
+		{errors.New("transaction type not supported"), false},
+		{errors.New("batch size too large, not allowed"), false},
+		{errors.New("request failed: method not found for the api key"), false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.notFound, IsMethodNotFound(c.err), c.err.Error())
+	}
+}
@@ -72,6 +72,8 @@ type RpcTransaction struct {
 	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
 	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
 	MaxFeePerDataGas     *hexutil.Big   `json:"maxFeePerDataGas,omitempty"`
+	MaxFeePerBlobGas     *hexutil.Big   `json:"maxFeePerBlobGas,omitempty"`
+	BlobVersionedHashes  []common.Hash  `json:"blobVersionedHashes,omitempty"`
 }
 
 type RpcLog struct {
@@ -25,9 +25,11 @@ package evm
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"math/big"
@@ -96,6 +98,7 @@ func (ec *EClient) convertHeader(head *RpcHeader, err error) (*xycommon.RpcHeade
 		Number:     head.Number.ToInt(),
 		Time:       uint64(head.Time),
 		TxHash:     head.TxHash.String(),
+		Hash:       head.Hash.String(),
 	}
 	return header, nil
 }
@@ -137,6 +140,11 @@ func (ec *EClient) convertTransaction(tx *RpcTransaction) *xycommon.RpcTransacti
 	if tx.To != nil {
 		toAddr = tx.To.String()
 	}
+
+	var blobHashes []string
+	for _, hash := range tx.BlobVersionedHashes {
+		blobHashes = append(blobHashes, hash.String())
+	}
 	return &xycommon.RpcTransaction{
 		BlockHash:   tx.BlockHash.String(),
 		BlockNumber: tx.BlockNumber.ToInt(),
@@ -150,6 +158,7 @@ func (ec *EClient) convertTransaction(tx *RpcTransaction) *xycommon.RpcTransacti
 		Value:       tx.Value.ToInt(),
 		Gas:         big.NewInt(0).SetUint64(uint64(tx.Gas)),
 		GasPrice:    tx.GasPrice.ToInt(),
+		BlobHashes:  blobHashes,
 	}
 }
 
@@ -196,8 +205,33 @@ func (ec *EClient) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQue
 
 // SubscribeNewHead subscribes to notifications about the current blockchain head
 // on the given channel.
-func (ec *EClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header, ct chan<- *xycommon.RpcBlock) (ethereum.Subscription, error) {
-	return ec.rawClient.SubscribeNewHead(ctx, ch)
+func (ec *EClient) SubscribeNewHead(ctx context.Context, ch chan<- *xycommon.RpcHeader) (ethereum.Subscription, error) {
+	heads := make(chan *RpcHeader, 16)
+	sub, err := ec.rawClient.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case head := <-heads:
+				header, _ := ec.convertHeader(head, nil)
+				select {
+				case ch <- header:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
 }
 
 // TransactionSender returns the sender address of the given transaction. The transaction
@@ -265,3 +299,56 @@ func (ec *EClient) TransactionReceipt(ctx context.Context, txHashStr string) (*x
 	}
 	return ec.convertReceipt(r), nil
 }
+
+// BlockReceipts returns the receipts of all transactions in the block.
+func (ec *EClient) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	receipts, err := ec.rawClient.BlockReceipts(ctx, number)
+	if err != nil {
+		return nil, ec.convertError(err)
+	}
+
+	items := make([]*xycommon.RpcReceipt, 0, len(receipts))
+	for _, r := range receipts {
+		items = append(items, ec.convertReceipt(r))
+	}
+	return items, nil
+}
+
+// BatchTransactionReceipts returns the receipts of transactions in the same order as the hashes.
+func (ec *EClient) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	hashes := make([]common.Hash, 0, len(txHashes))
+	for _, txHash := range txHashes {
+		hashes = append(hashes, common.HexToHash(txHash))
+	}
+
+	receipts, err := ec.rawClient.BatchTransactionReceipts(ctx, hashes)
+	if err != nil {
+		return nil, ec.convertError(err)
+	}
+
+	items := make([]*xycommon.RpcReceipt, 0, len(receipts))
+	for _, r := range receipts {
+		items = append(items, ec.convertReceipt(r))
+	}
+	return items, nil
+}
+
+// TraceBlockCalls returns the call traces of all transactions in the block.
+func (ec *EClient) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	traces, err := ec.rawClient.TraceBlockCalls(ctx, number)
+	if err != nil {
+		return nil, ec.convertError(err)
+	}
+	return traces, nil
+}
+
+func (ec *EClient) convertError(err error) error {
+	if errors.Is(err, ethereum.NotFound) || errors.Is(err, rpc.ErrNoResult) {
+		return xycommon.ErrNotFound
+	}
+
+	if IsMethodNotFound(err) {
+		return fmt.Errorf("%w: %v", xycommon.ErrMethodNotSupported, err)
+	}
+	return err
+}
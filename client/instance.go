@@ -23,11 +23,77 @@
 package client
 
 import (
+	"errors"
+	"github.com/uxuycom/indexer/client/beacon"
+	"github.com/uxuycom/indexer/client/btc"
+	"github.com/uxuycom/indexer/client/cosmos"
 	"github.com/uxuycom/indexer/client/evm"
+	"github.com/uxuycom/indexer/client/multi"
+	"github.com/uxuycom/indexer/client/replay"
 	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/xylog"
+	"time"
 )
 
-func NewRPCClient(rpc string, proto model.ChainGroup) (xycommon.IRPCClient, error) {
+func NewRPCClient(rpc string, cfg *config.ChainConfig) (xycommon.IRPCClient, error) {
+	switch cfg.ChainGroup {
+	case model.BtcChainGroup:
+		return btc.Dial(rpc, cfg.UserName, cfg.PassWord)
+	case model.CosmosChainGroup:
+		return cosmos.Dial(rpc)
+	}
 	return evm.Dial(rpc)
 }
+
+// NewChainClient creates rpc client by chain config, several endpoints are wrapped with failover
+func NewChainClient(cfg *config.ChainConfig) (xycommon.IRPCClient, error) {
+	if cfg.ReplayDir != "" {
+		return replay.Open(cfg.ReplayDir)
+	}
+
+	c, err := newEndpointsClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.BeaconUrl != "" {
+		bc, err := beacon.Dial(cfg.BeaconUrl)
+		if err != nil {
+			return nil, err
+		}
+		c = beacon.NewBlobsClient(c, bc)
+	}
+
+	if cfg.RecordDir != "" {
+		return replay.NewRecorder(c, cfg.RecordDir)
+	}
+	return c, nil
+}
+
+func newEndpointsClient(cfg *config.ChainConfig) (xycommon.IRPCClient, error) {
+	endpoints := cfg.RpcEndpoints()
+	if len(endpoints) < 1 {
+		return nil, errors.New("no rpc endpoint configured")
+	}
+
+	if len(endpoints) == 1 {
+		return NewRPCClient(endpoints[0], cfg)
+	}
+
+	urls := make([]string, 0, len(endpoints))
+	clients := make([]xycommon.IRPCClient, 0, len(endpoints))
+	for _, rpc := range endpoints {
+		c, err := NewRPCClient(rpc, cfg)
+		if err != nil {
+			xylog.Logger.Errorf("dial rpc endpoint[%s] err:%v & skip", rpc, err)
+			continue
+		}
+		urls = append(urls, rpc)
+		clients = append(clients, c)
+	}
+
+	hedgeDelay := time.Duration(cfg.HedgeDelay) * time.Millisecond
+	return multi.NewClient(urls, clients, hedgeDelay, cfg.Quorum)
+}
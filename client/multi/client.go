@@ -0,0 +1,418 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// failurePenalty score penalty in milliseconds for each consecutive failure
+	failurePenalty = 1000
+
+	// failureCooldown failures older than cooldown are no longer penalized, so the endpoint gets retried
+	failureCooldown = 30 * time.Second
+
+	// defaultLatency initial latency score in milliseconds of an endpoint never called
+	defaultLatency = 100
+)
+
+var ErrNoEndpoint = errors.New("no rpc endpoint available")
+
+var ErrQuorumNotReached = errors.New("block hash quorum not reached")
+
+// Client
+/*****************************************************
+ * IRPCClient wrapping several endpoints of one chain,
+ * requests go to the healthiest endpoint first, fail over to the next one on error,
+ * and are hedged to the next one if the response is slower than the hedge delay
+ ****************************************************/
+type Client struct {
+	endpoints  []*endpoint
+	hedgeDelay time.Duration
+	quorum     int
+}
+
+type endpoint struct {
+	url         string
+	client      xycommon.IRPCClient
+	mu          sync.Mutex
+	latency     float64
+	failures    int
+	lastFailure time.Time
+}
+
+type callResult struct {
+	value interface{}
+	err   error
+	ep    *endpoint
+}
+
+type callFunc func(ctx context.Context, c xycommon.IRPCClient) (interface{}, error)
+
+// NewClient build client with endpoints, urls are used for logs & subscription detection only.
+// hedgeDelay zero disables hedged requests, quorum lower than two disables block hash cross checking
+func NewClient(urls []string, clients []xycommon.IRPCClient, hedgeDelay time.Duration, quorum int) (*Client, error) {
+	if len(clients) < 1 || len(urls) != len(clients) {
+		return nil, ErrNoEndpoint
+	}
+
+	if quorum > len(clients) {
+		return nil, fmt.Errorf("quorum[%d] is greater than endpoints[%d]", quorum, len(clients))
+	}
+
+	endpoints := make([]*endpoint, 0, len(clients))
+	for i, c := range clients {
+		endpoints = append(endpoints, &endpoint{
+			url:    urls[i],
+			client: c,
+		})
+	}
+
+	return &Client{
+		endpoints:  endpoints,
+		hedgeDelay: hedgeDelay,
+		quorum:     quorum,
+	}, nil
+}
+
+func (ep *endpoint) record(cost time.Duration, err error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	// not found or unsupported method is a valid answer of a healthy node
+	if err != nil && !errors.Is(err, xycommon.ErrNotFound) && !errors.Is(err, xycommon.ErrMethodNotSupported) {
+		ep.failures++
+		ep.lastFailure = time.Now()
+		return
+	}
+
+	ep.failures = 0
+	ms := float64(cost.Milliseconds())
+	if ep.latency <= 0 {
+		ep.latency = ms
+		return
+	}
+	ep.latency = ep.latency*0.8 + ms*0.2
+}
+
+// score lower is healthier
+func (ep *endpoint) score() float64 {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	score := ep.latency
+	if score <= 0 {
+		score = defaultLatency
+	}
+
+	if ep.failures > 0 && time.Since(ep.lastFailure) < failureCooldown {
+		failures := ep.failures
+		if failures > 10 {
+			failures = 10
+		}
+		score += float64(failures * failurePenalty)
+	}
+	return score
+}
+
+// ranked returns endpoints ordered by health score
+func (c *Client) ranked() []*endpoint {
+	type scored struct {
+		ep    *endpoint
+		score float64
+	}
+
+	items := make([]scored, 0, len(c.endpoints))
+	for _, ep := range c.endpoints {
+		items = append(items, scored{ep: ep, score: ep.score()})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].score < items[j].score
+	})
+
+	eps := make([]*endpoint, 0, len(items))
+	for _, item := range items {
+		eps = append(eps, item.ep)
+	}
+	return eps
+}
+
+// call runs fn on the healthiest endpoint, failing over & hedging to the next ones
+func (c *Client) call(ctx context.Context, method string, fn callFunc) (interface{}, *endpoint, error) {
+	eps := c.ranked()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *callResult, len(eps))
+	next, inflight := 0, 0
+	launch := func() {
+		ep := eps[next]
+		next++
+		inflight++
+		go func() {
+			st := time.Now()
+			v, err := fn(ctx, ep.client)
+
+			// calls cancelled by a faster endpoint say nothing about health
+			if err == nil || ctx.Err() == nil {
+				ep.record(time.Since(st), err)
+			}
+			results <- &callResult{value: v, err: err, ep: ep}
+		}()
+	}
+
+	var hedge <-chan time.Time
+	resetHedge := func() {
+		if c.hedgeDelay > 0 && next < len(eps) {
+			hedge = time.After(c.hedgeDelay)
+		}
+	}
+
+	launch()
+	resetHedge()
+
+	var lastErr error
+	for inflight > 0 {
+		select {
+		case r := <-results:
+			inflight--
+			if r.err == nil {
+				return r.value, r.ep, nil
+			}
+
+			lastErr = r.err
+			xylog.Logger.Debugf("rpc endpoint call failed, method:%s, endpoint:%s, err:%v", method, r.ep.url, r.err)
+			if next < len(eps) {
+				launch()
+				resetHedge()
+			}
+		case <-hedge:
+			xylog.Logger.Debugf("rpc endpoint call slow & hedged, method:%s", method)
+			hedge = nil
+			launch()
+			resetHedge()
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	return nil, nil, lastErr
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	v, _, err := c.call(ctx, "BlockNumber", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		return rc.BlockNumber(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(uint64), nil
+}
+
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	v, ep, err := c.call(ctx, "BlockByNumber", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		return rc.BlockByNumber(ctx, number)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	block := v.(*xycommon.RpcBlock)
+	if err = c.checkQuorum(ctx, ep, number, block.Hash); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// checkQuorum cross check the block hash with other endpoints
+func (c *Client) checkQuorum(ctx context.Context, source *endpoint, number *big.Int, hash string) error {
+	if c.quorum < 2 {
+		return nil
+	}
+
+	others := make([]*endpoint, 0, len(c.endpoints)-1)
+	for _, ep := range c.ranked() {
+		if ep != source {
+			others = append(others, ep)
+		}
+	}
+
+	type vote struct {
+		ep   *endpoint
+		hash string
+		err  error
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	votes := make(chan *vote, len(others))
+	for _, ep := range others {
+		go func(ep *endpoint) {
+			st := time.Now()
+			header, err := ep.client.HeaderByNumber(ctx, number)
+			if err == nil || ctx.Err() == nil {
+				ep.record(time.Since(st), err)
+			}
+
+			if err != nil {
+				votes <- &vote{ep: ep, err: err}
+				return
+			}
+			votes <- &vote{ep: ep, hash: header.Hash}
+		}(ep)
+	}
+
+	agreed := 1
+	for i := 0; i < len(others); i++ {
+		v := <-votes
+		if v.err != nil {
+			xylog.Logger.Debugf("block[%s] quorum vote failed, endpoint:%s, err:%v", number, v.ep.url, v.err)
+			continue
+		}
+
+		if !strings.EqualFold(v.hash, hash) {
+			xylog.Logger.Warnf("block[%s] hash mismatch, endpoint[%s]:%s <> endpoint[%s]:%s", number, source.url, hash, v.ep.url, v.hash)
+			continue
+		}
+
+		agreed++
+		if agreed >= c.quorum {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w, block[%s], agreed[%d], quorum[%d]", ErrQuorumNotReached, number, agreed, c.quorum)
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	v, _, err := c.call(ctx, "HeaderByNumber", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		return rc.HeaderByNumber(ctx, number)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*xycommon.RpcHeader), nil
+}
+
+func (c *Client) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	v, _, err := c.call(ctx, "TransactionSender", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		return rc.TransactionSender(ctx, txHash, blockHash, txIndex)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	v, _, err := c.call(ctx, "TransactionReceipt", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		return rc.TransactionReceipt(ctx, txHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*xycommon.RpcReceipt), nil
+}
+
+func (c *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	v, _, err := c.call(ctx, "FilterLogs", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		return rc.FilterLogs(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]xycommon.RpcLog), nil
+}
+
+func (c *Client) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	v, _, err := c.call(ctx, "BlockReceipts", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		bc, ok := rc.(xycommon.IReceiptsClient)
+		if !ok {
+			return nil, xycommon.ErrMethodNotSupported
+		}
+		return bc.BlockReceipts(ctx, number)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*xycommon.RpcReceipt), nil
+}
+
+func (c *Client) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	v, _, err := c.call(ctx, "BatchTransactionReceipts", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		bc, ok := rc.(xycommon.IReceiptsClient)
+		if !ok {
+			return nil, xycommon.ErrMethodNotSupported
+		}
+		return bc.BatchTransactionReceipts(ctx, txHashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*xycommon.RpcReceipt), nil
+}
+
+func (c *Client) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	v, _, err := c.call(ctx, "TraceBlockCalls", func(ctx context.Context, rc xycommon.IRPCClient) (interface{}, error) {
+		tc, ok := rc.(xycommon.ITraceClient)
+		if !ok {
+			return nil, xycommon.ErrMethodNotSupported
+		}
+		return tc.TraceBlockCalls(ctx, number)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*xycommon.RpcTxTrace), nil
+}
+
+// SubscribeNewHead subscribes new heads on the healthiest websocket endpoint
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *xycommon.RpcHeader) (ethereum.Subscription, error) {
+	var lastErr error = ErrNoEndpoint
+	for _, ep := range c.ranked() {
+		subscriber, ok := ep.client.(xycommon.IHeadSubscriber)
+		if !ok || !xycommon.IsWebsocketURL(ep.url) {
+			continue
+		}
+
+		sub, err := subscriber.SubscribeNewHead(ctx, ch)
+		if err != nil {
+			ep.record(0, err)
+			lastErr = err
+			continue
+		}
+		return sub, nil
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package multi
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+type fakeClient struct {
+	number uint64
+	hash   string
+	delay  time.Duration
+	err    error
+}
+
+func (f *fakeClient) wait(ctx context.Context) error {
+	select {
+	case <-time.After(f.delay):
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *fakeClient) BlockNumber(ctx context.Context) (uint64, error) {
+	if err := f.wait(ctx); err != nil {
+		return 0, err
+	}
+	return f.number, nil
+}
+
+func (f *fakeClient) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &xycommon.RpcBlock{Number: number, Hash: f.hash}, nil
+}
+
+func (f *fakeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	if err := f.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &xycommon.RpcHeader{Number: number, Hash: f.hash}, nil
+}
+
+func (f *fakeClient) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	return "", f.wait(ctx)
+}
+
+func (f *fakeClient) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	return nil, f.wait(ctx)
+}
+
+func (f *fakeClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	return nil, f.wait(ctx)
+}
+
+func newTestClient(t *testing.T, hedgeDelay time.Duration, quorum int, clients ...xycommon.IRPCClient) *Client {
+	urls := make([]string, 0, len(clients))
+	for range clients {
+		urls = append(urls, "http://127.0.0.1")
+	}
+
+	c, err := NewClient(urls, clients, hedgeDelay, quorum)
+	assert.NoError(t, err)
+	return c
+}
+
+func TestClientFailover(t *testing.T) {
+	bad := &fakeClient{err: errors.New("connection refused")}
+	good := &fakeClient{number: 100}
+	c := newTestClient(t, 0, 0, bad, good)
+
+	num, err := c.BlockNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), num)
+
+	// failed endpoint is ranked behind the healthy one
+	assert.Equal(t, good, c.ranked()[0].client)
+}
+
+func TestClientHedge(t *testing.T) {
+	slow := &fakeClient{number: 1, delay: time.Second}
+	fast := &fakeClient{number: 2}
+	c := newTestClient(t, 20*time.Millisecond, 0, slow, fast)
+
+	st := time.Now()
+	num, err := c.BlockNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), num)
+	assert.Less(t, time.Since(st), 500*time.Millisecond)
+}
+
+func TestClientQuorum(t *testing.T) {
+	c := newTestClient(t, 0, 2, &fakeClient{hash: "0xaa"}, &fakeClient{hash: "0xaa"}, &fakeClient{hash: "0xbb"})
+	block, err := c.BlockByNumber(context.Background(), big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "0xaa", block.Hash)
+
+	c = newTestClient(t, 0, 2, &fakeClient{hash: "0xaa"}, &fakeClient{hash: "0xbb"})
+	_, err = c.BlockByNumber(context.Background(), big.NewInt(1))
+	assert.ErrorIs(t, err, ErrQuorumNotReached)
+}
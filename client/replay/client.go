@@ -0,0 +1,298 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Client
+/*****************************************************
+ * IRPCClient replaying recorded files without any node,
+ * the chain ends at the highest recorded block
+ ****************************************************/
+type Client struct {
+	blocks   map[uint64]json.RawMessage
+	headers  map[uint64]*xycommon.RpcHeader
+	txs      map[string]*xycommon.RpcTransaction
+	receipts map[string]*xycommon.RpcReceipt
+	logs     map[uint64][]xycommon.RpcLog
+	logKeys  map[string]struct{}
+	traces   map[uint64][]*xycommon.RpcTxTrace
+	blobs    map[uint64][]*xycommon.RpcBlob
+	latest   uint64
+}
+
+// Open load all records in the dir
+func Open(dir string) (*Client, error) {
+	c := &Client{
+		blocks:   make(map[uint64]json.RawMessage, 1000),
+		headers:  make(map[uint64]*xycommon.RpcHeader, 1000),
+		txs:      make(map[string]*xycommon.RpcTransaction, 1000),
+		receipts: make(map[string]*xycommon.RpcReceipt, 1000),
+		logs:     make(map[uint64][]xycommon.RpcLog, 1000),
+		logKeys:  make(map[string]struct{}, 1000),
+		traces:   make(map[uint64][]*xycommon.RpcTxTrace, 1000),
+		blobs:    make(map[uint64][]*xycommon.RpcBlob, 1000),
+	}
+
+	if err := readRecords(dir, c.load); err != nil {
+		return nil, err
+	}
+
+	if len(c.blocks) < 1 {
+		return nil, fmt.Errorf("no block recorded in dir[%s]", dir)
+	}
+	xylog.Logger.Infof("replay records loaded, blocks[%d], receipts[%d], latest block[%d]", len(c.blocks), len(c.receipts), c.latest)
+	return c, nil
+}
+
+func (c *Client) load(r *Record) error {
+	switch r.Kind {
+	case RecordKindBlock:
+		block := &xycommon.RpcBlock{}
+		if err := json.Unmarshal(r.Data, block); err != nil {
+			return err
+		}
+
+		num := block.Number.Uint64()
+		c.blocks[num] = r.Data
+		c.headers[num] = &xycommon.RpcHeader{
+			ParentHash: block.ParentHash,
+			Number:     block.Number,
+			Time:       block.Time,
+			TxHash:     block.TxHash,
+			Hash:       block.Hash,
+		}
+		for _, tx := range block.Transactions {
+			c.txs[hashKey(tx.Hash)] = tx
+		}
+
+		if num > c.latest {
+			c.latest = num
+		}
+	case RecordKindReceipt:
+		receipt := &xycommon.RpcReceipt{}
+		if err := json.Unmarshal(r.Data, receipt); err != nil {
+			return err
+		}
+		c.receipts[hashKey(receipt.TxHash.String())] = receipt
+	case RecordKindLogs:
+		record := &LogsRecord{}
+		if err := json.Unmarshal(r.Data, record); err != nil {
+			return err
+		}
+
+		for _, log := range record.Logs {
+			key := fmt.Sprintf("%s_%s", log.TxHash.String(), log.Index.String())
+			if _, ok := c.logKeys[key]; ok {
+				continue
+			}
+			c.logKeys[key] = struct{}{}
+
+			num := log.BlockNumber.ToInt().Uint64()
+			c.logs[num] = append(c.logs[num], log)
+		}
+	case RecordKindTraces:
+		record := &TracesRecord{}
+		if err := json.Unmarshal(r.Data, record); err != nil {
+			return err
+		}
+		c.traces[record.Block.Uint64()] = record.Traces
+	case RecordKindBlobs:
+		record := &BlobsRecord{}
+		if err := json.Unmarshal(r.Data, record); err != nil {
+			return err
+		}
+		c.blobs[record.Block.Uint64()] = record.Blobs
+	default:
+		return fmt.Errorf("unknown record kind[%s]", r.Kind)
+	}
+	return nil
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.latest, nil
+}
+
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	raw, ok := c.blocks[number.Uint64()]
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+
+	// decode on every call, callers are free to modify the block
+	block := &xycommon.RpcBlock{}
+	if err := json.Unmarshal(raw, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	header, ok := c.headers[number.Uint64()]
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+
+	h := *header
+	return &h, nil
+}
+
+func (c *Client) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	tx, ok := c.txs[hashKey(txHash)]
+	if !ok {
+		return "", xycommon.ErrNotFound
+	}
+	return tx.From, nil
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	receipt, ok := c.receipts[hashKey(txHash)]
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+	return receipt, nil
+}
+
+// BlockReceipts returns the recorded receipts of the block txs
+func (c *Client) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	block, err := c.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]*xycommon.RpcReceipt, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		if receipt, ok := c.receipts[hashKey(tx.Hash)]; ok {
+			receipts = append(receipts, receipt)
+		}
+	}
+	return receipts, nil
+}
+
+func (c *Client) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	receipts := make([]*xycommon.RpcReceipt, 0, len(txHashes))
+	for _, txHash := range txHashes {
+		receipt, err := c.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// TraceBlockCalls returns the recorded call traces of the block
+func (c *Client) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	traces, ok := c.traces[number.Uint64()]
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+	return traces, nil
+}
+
+// BlockBlobs returns the recorded blobs of the block, blocks recorded without any blob source are not supported
+func (c *Client) BlockBlobs(ctx context.Context, number *big.Int, timestamp uint64) ([]*xycommon.RpcBlob, error) {
+	blobs, ok := c.blobs[number.Uint64()]
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+	return blobs, nil
+}
+
+func (c *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	if q.BlockHash != nil {
+		return nil, errors.New("filter logs by block hash is not supported")
+	}
+
+	from, to := uint64(0), c.latest
+	if q.FromBlock != nil {
+		from = q.FromBlock.Uint64()
+	}
+	if q.ToBlock != nil && q.ToBlock.Uint64() < to {
+		to = q.ToBlock.Uint64()
+	}
+
+	nums := make([]uint64, 0, len(c.logs))
+	for num := range c.logs {
+		if num >= from && num <= to {
+			nums = append(nums, num)
+		}
+	}
+	sort.Slice(nums, func(i, j int) bool {
+		return nums[i] < nums[j]
+	})
+
+	logs := make([]xycommon.RpcLog, 0)
+	for _, num := range nums {
+		for _, log := range c.logs[num] {
+			if matchLog(&log, q) {
+				logs = append(logs, log)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// hashKey normalizes tx hash, btc txids carry no 0x prefix
+func hashKey(hash string) string {
+	return strings.TrimPrefix(strings.ToLower(hash), "0x")
+}
+
+// matchLog checks log address & topics with the filter query criteria
+func matchLog(log *xycommon.RpcLog, q ethereum.FilterQuery) bool {
+	if len(q.Addresses) > 0 && !containsHash(q.Addresses, log.Address) {
+		return false
+	}
+
+	if len(q.Topics) > len(log.Topics) {
+		return false
+	}
+
+	for i, topics := range q.Topics {
+		if len(topics) > 0 && !containsHash(topics, log.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsHash[T common.Address | common.Hash](items []T, item T) bool {
+	for _, v := range items {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
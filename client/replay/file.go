@@ -0,0 +1,196 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	RecordKindBlock   = "block"
+	RecordKindReceipt = "receipt"
+	RecordKindLogs    = "logs"
+	RecordKindTraces  = "traces"
+	RecordKindBlobs   = "blobs"
+
+	// fileSuffix records files are gzip compressed json lines
+	fileSuffix = ".jsonl.gz"
+
+	// maxRecordsPerFile records file is rotated once exceeding
+	maxRecordsPerFile = 100000
+)
+
+// Record
+/*****************************************************
+ * one fetched rpc result, files are gzip compressed json lines of records
+ ****************************************************/
+type Record struct {
+	Kind string          `json:"k"`
+	Data json.RawMessage `json:"d"`
+}
+
+// fileWriter append records to rotated files
+type fileWriter struct {
+	mu      sync.Mutex
+	dir     string
+	file    *os.File
+	gz      *gzip.Writer
+	records int
+}
+
+func newFileWriter(dir string) (*fileWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &fileWriter{dir: dir}, nil
+}
+
+func (w *fileWriter) Write(kind string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(&Record{Kind: kind, Data: raw})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.gz == nil || w.records >= maxRecordsPerFile {
+		if err = w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err = w.gz.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	w.records++
+
+	// flush compressed data, records are readable even if the process crashed
+	return w.gz.Flush()
+}
+
+func (w *fileWriter) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+
+	name := filepath.Join(w.dir, fmt.Sprintf("%d%s", time.Now().UnixNano(), fileSuffix))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	w.file = f
+	w.gz = gzip.NewWriter(f)
+	w.records = 0
+	return nil
+}
+
+func (w *fileWriter) closeFile() error {
+	if w.gz == nil {
+		return nil
+	}
+
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+
+	err := w.file.Close()
+	w.gz, w.file = nil, nil
+	return err
+}
+
+func (w *fileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeFile()
+}
+
+// readRecords read all records files in the dir by the recording order
+func readRecords(dir string, fn func(r *Record) error) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+fileSuffix))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err = readRecordsFile(file, fn); err != nil {
+			return fmt.Errorf("read records file[%s] err:%v", file, err)
+		}
+	}
+	return nil
+}
+
+func readRecordsFile(file string, fn func(r *Record) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	reader := bufio.NewReader(gz)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			r := &Record{}
+			if err := json.Unmarshal(line, r); err != nil {
+				return err
+			}
+
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+
+		// files of an interrupted recording are not closed properly, keep the complete records
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
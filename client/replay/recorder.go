@@ -0,0 +1,205 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package replay
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+)
+
+// LogsRecord logs of one filter query
+type LogsRecord struct {
+	FromBlock *big.Int          `json:"from"`
+	ToBlock   *big.Int          `json:"to"`
+	Logs      []xycommon.RpcLog `json:"logs"`
+}
+
+// TracesRecord call traces of one block
+type TracesRecord struct {
+	Block  *big.Int               `json:"block"`
+	Traces []*xycommon.RpcTxTrace `json:"traces"`
+}
+
+// BlobsRecord blobs of one block
+type BlobsRecord struct {
+	Block *big.Int            `json:"block"`
+	Blobs []*xycommon.RpcBlob `json:"blobs"`
+}
+
+// Recorder
+/*****************************************************
+ * IRPCClient wrapper recording the fetched blocks, receipts, logs, traces & blobs into files,
+ * which are replayed by the replay Client
+ ****************************************************/
+type Recorder struct {
+	client xycommon.IRPCClient
+	writer *fileWriter
+}
+
+func NewRecorder(client xycommon.IRPCClient, dir string) (*Recorder, error) {
+	writer, err := newFileWriter(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		client: client,
+		writer: writer,
+	}, nil
+}
+
+func (r *Recorder) record(kind string, data interface{}) {
+	if err := r.writer.Write(kind, data); err != nil {
+		xylog.Logger.Errorf("record %s data err:%v", kind, err)
+	}
+}
+
+// Close flush & close the records file
+func (r *Recorder) Close() error {
+	return r.writer.Close()
+}
+
+func (r *Recorder) BlockNumber(ctx context.Context) (uint64, error) {
+	return r.client.BlockNumber(ctx)
+}
+
+func (r *Recorder) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	block, err := r.client.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	r.record(RecordKindBlock, block)
+	return block, nil
+}
+
+func (r *Recorder) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	return r.client.HeaderByNumber(ctx, number)
+}
+
+func (r *Recorder) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	return r.client.TransactionSender(ctx, txHash, blockHash, txIndex)
+}
+
+func (r *Recorder) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	receipt, err := r.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	r.record(RecordKindReceipt, receipt)
+	return receipt, nil
+}
+
+func (r *Recorder) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	logs, err := r.client.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	r.record(RecordKindLogs, &LogsRecord{
+		FromBlock: q.FromBlock,
+		ToBlock:   q.ToBlock,
+		Logs:      logs,
+	})
+	return logs, nil
+}
+
+func (r *Recorder) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	bc, ok := r.client.(xycommon.IReceiptsClient)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+
+	receipts, err := bc.BlockReceipts(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, receipt := range receipts {
+		r.record(RecordKindReceipt, receipt)
+	}
+	return receipts, nil
+}
+
+func (r *Recorder) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	bc, ok := r.client.(xycommon.IReceiptsClient)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+
+	receipts, err := bc.BatchTransactionReceipts(ctx, txHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, receipt := range receipts {
+		r.record(RecordKindReceipt, receipt)
+	}
+	return receipts, nil
+}
+
+func (r *Recorder) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	tc, ok := r.client.(xycommon.ITraceClient)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+
+	traces, err := tc.TraceBlockCalls(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	r.record(RecordKindTraces, &TracesRecord{
+		Block:  number,
+		Traces: traces,
+	})
+	return traces, nil
+}
+
+func (r *Recorder) BlockBlobs(ctx context.Context, number *big.Int, timestamp uint64) ([]*xycommon.RpcBlob, error) {
+	bc, ok := r.client.(xycommon.IBlobClient)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+
+	blobs, err := bc.BlockBlobs(ctx, number, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	r.record(RecordKindBlobs, &BlobsRecord{
+		Block: number,
+		Blobs: blobs,
+	})
+	return blobs, nil
+}
+
+func (r *Recorder) SubscribeNewHead(ctx context.Context, ch chan<- *xycommon.RpcHeader) (ethereum.Subscription, error) {
+	subscriber, ok := r.client.(xycommon.IHeadSubscriber)
+	if !ok {
+		return nil, xycommon.ErrMethodNotSupported
+	}
+	return subscriber.SubscribeNewHead(ctx, ch)
+}
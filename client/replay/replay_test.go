@@ -0,0 +1,304 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+// fakeNode serves fixed blocks with one tx each, the tx log, call trace & blob
+type fakeNode struct {
+	blocks map[uint64]*xycommon.RpcBlock
+}
+
+func newFakeNode(nums ...uint64) *fakeNode {
+	n := &fakeNode{blocks: make(map[uint64]*xycommon.RpcBlock)}
+	for _, num := range nums {
+		n.blocks[num] = &xycommon.RpcBlock{
+			ParentHash: common.BigToHash(big.NewInt(int64(num - 1))).String(),
+			Number:     big.NewInt(int64(num)),
+			Time:       1700000000 + num,
+			Hash:       common.BigToHash(big.NewInt(int64(num))).String(),
+			Transactions: []*xycommon.RpcTransaction{
+				{
+					BlockNumber: big.NewInt(int64(num)),
+					TxIndex:     big.NewInt(0),
+					Hash:        txHash(num),
+					From:        "0x00000000000000000000000000000000000000aa",
+					To:          "0x00000000000000000000000000000000000000bb",
+					Input:       "0x",
+				},
+			},
+		}
+	}
+	return n
+}
+
+func txHash(num uint64) string {
+	return common.BigToHash(big.NewInt(int64(1000 + num))).String()
+}
+
+func (n *fakeNode) BlockNumber(ctx context.Context) (uint64, error) {
+	latest := uint64(0)
+	for num := range n.blocks {
+		if num > latest {
+			latest = num
+		}
+	}
+	return latest, nil
+}
+
+func (n *fakeNode) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	block, ok := n.blocks[number.Uint64()]
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+	return block, nil
+}
+
+func (n *fakeNode) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	return nil, xycommon.ErrMethodNotSupported
+}
+
+func (n *fakeNode) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	return "", xycommon.ErrMethodNotSupported
+}
+
+func (n *fakeNode) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	for _, block := range n.blocks {
+		if block.Transactions[0].Hash == txHash {
+			return n.receipt(block), nil
+		}
+	}
+	return nil, xycommon.ErrNotFound
+}
+
+func (n *fakeNode) receipt(block *xycommon.RpcBlock) *xycommon.RpcReceipt {
+	return &xycommon.RpcReceipt{
+		Status:           big.NewInt(1),
+		TxHash:           common.HexToHash(block.Transactions[0].Hash),
+		GasUsed:          big.NewInt(21000),
+		BlockHash:        common.HexToHash(block.Hash),
+		BlockNumber:      block.Number,
+		TransactionIndex: big.NewInt(0),
+	}
+}
+
+func (n *fakeNode) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	block, err := n.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return []*xycommon.RpcReceipt{n.receipt(block)}, nil
+}
+
+func (n *fakeNode) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	receipts := make([]*xycommon.RpcReceipt, 0, len(txHashes))
+	for _, hash := range txHashes {
+		receipt, err := n.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+func (n *fakeNode) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	logs := make([]xycommon.RpcLog, 0)
+	for num := q.FromBlock.Uint64(); num <= q.ToBlock.Uint64(); num++ {
+		block, ok := n.blocks[num]
+		if !ok {
+			continue
+		}
+
+		logs = append(logs, xycommon.RpcLog{
+			Address:     common.HexToAddress("0x00000000000000000000000000000000000000cc"),
+			Topics:      []common.Hash{common.BigToHash(big.NewInt(int64(num % 2)))},
+			Data:        hexutil.Bytes{byte(num)},
+			BlockNumber: (*hexutil.Big)(block.Number),
+			TxHash:      common.HexToHash(block.Transactions[0].Hash),
+			TxIndex:     (*hexutil.Big)(big.NewInt(0)),
+			BlockHash:   common.HexToHash(block.Hash),
+			Index:       (*hexutil.Big)(big.NewInt(0)),
+		})
+	}
+	return logs, nil
+}
+
+func (n *fakeNode) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	block, err := n.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := block.Transactions[0]
+	return []*xycommon.RpcTxTrace{
+		{
+			TxHash: tx.Hash,
+			Result: &xycommon.RpcCallFrame{
+				Type:  "CALL",
+				From:  tx.From,
+				To:    tx.To,
+				Input: "0x",
+				Calls: []*xycommon.RpcCallFrame{{Type: "CALL", From: tx.To, To: tx.From, Input: "0x01"}},
+			},
+		},
+	}, nil
+}
+
+func (n *fakeNode) BlockBlobs(ctx context.Context, number *big.Int, timestamp uint64) ([]*xycommon.RpcBlob, error) {
+	return []*xycommon.RpcBlob{{VersionedHash: common.BigToHash(number).String(), Data: "0x0102"}}, nil
+}
+
+// assertJSONEqual compares the json encodings, zero big ints decode with another internal form
+func assertJSONEqual(t *testing.T, expected, actual interface{}) {
+	e, err := json.Marshal(expected)
+	assert.NoError(t, err)
+	a, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(e), string(a))
+}
+
+func TestRecorderReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	node := newFakeNode(100, 101, 102)
+
+	recorder, err := NewRecorder(node, dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for num := uint64(100); num <= 102; num++ {
+		_, err = recorder.BlockByNumber(ctx, big.NewInt(int64(num)))
+		assert.NoError(t, err)
+		_, err = recorder.TraceBlockCalls(ctx, big.NewInt(int64(num)))
+		assert.NoError(t, err)
+	}
+	_, err = recorder.BlockReceipts(ctx, big.NewInt(100))
+	assert.NoError(t, err)
+	_, err = recorder.TransactionReceipt(ctx, txHash(101))
+	assert.NoError(t, err)
+	_, err = recorder.BlockBlobs(ctx, big.NewInt(102), 0)
+	assert.NoError(t, err)
+
+	// overlapping log queries are recorded twice, the replay client serves each log once
+	_, err = recorder.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(100), ToBlock: big.NewInt(101)})
+	assert.NoError(t, err)
+	_, err = recorder.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(101), ToBlock: big.NewInt(102)})
+	assert.NoError(t, err)
+
+	// unknown block is not recorded
+	_, err = recorder.BlockByNumber(ctx, big.NewInt(103))
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+	assert.NoError(t, recorder.Close())
+
+	c, err := Open(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	latest, err := c.BlockNumber(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(102), latest)
+
+	block, err := c.BlockByNumber(ctx, big.NewInt(101))
+	if assert.NoError(t, err) {
+		assertJSONEqual(t, node.blocks[101], block)
+	}
+	_, err = c.BlockByNumber(ctx, big.NewInt(103))
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+
+	header, err := c.HeaderByNumber(ctx, big.NewInt(102))
+	if assert.NoError(t, err) {
+		assert.Equal(t, node.blocks[102].Hash, header.Hash)
+		assert.Equal(t, node.blocks[102].ParentHash, header.ParentHash)
+		assert.Equal(t, node.blocks[102].Time, header.Time)
+	}
+
+	sender, err := c.TransactionSender(ctx, txHash(100), "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x00000000000000000000000000000000000000aa", sender)
+
+	receipts, err := c.BlockReceipts(ctx, big.NewInt(100))
+	if assert.NoError(t, err) && assert.Len(t, receipts, 1) {
+		assertJSONEqual(t, node.receipt(node.blocks[100]), receipts[0])
+	}
+	receipt, err := c.TransactionReceipt(ctx, txHash(101))
+	if assert.NoError(t, err) {
+		assertJSONEqual(t, node.receipt(node.blocks[101]), receipt)
+	}
+	_, err = c.TransactionReceipt(ctx, txHash(102))
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+
+	traces, err := c.TraceBlockCalls(ctx, big.NewInt(101))
+	if assert.NoError(t, err) {
+		expected, _ := node.TraceBlockCalls(ctx, big.NewInt(101))
+		assertJSONEqual(t, expected, traces)
+	}
+	_, err = c.TraceBlockCalls(ctx, big.NewInt(103))
+	assert.ErrorIs(t, err, xycommon.ErrNotFound)
+
+	blobs, err := c.BlockBlobs(ctx, big.NewInt(102), 0)
+	if assert.NoError(t, err) {
+		expected, _ := node.BlockBlobs(ctx, big.NewInt(102), 0)
+		assertJSONEqual(t, expected, blobs)
+	}
+	_, err = c.BlockBlobs(ctx, big.NewInt(101), 0)
+	assert.ErrorIs(t, err, xycommon.ErrMethodNotSupported)
+
+	logs, err := c.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(100), ToBlock: big.NewInt(102)})
+	if assert.NoError(t, err) {
+		expected, _ := node.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(100), ToBlock: big.NewInt(102)})
+		assertJSONEqual(t, expected, logs)
+	}
+
+	logs, err = c.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: big.NewInt(100),
+		ToBlock:   big.NewInt(102),
+		Topics:    [][]common.Hash{{common.BigToHash(big.NewInt(1))}},
+	})
+	if assert.NoError(t, err) && assert.Len(t, logs, 1) {
+		assert.Equal(t, uint64(101), logs[0].BlockNumber.ToInt().Uint64())
+	}
+}
+
+func TestOpenEmptyDir(t *testing.T) {
+	_, err := Open(t.TempDir())
+	assert.Error(t, err)
+}
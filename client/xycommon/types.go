@@ -31,10 +31,25 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"math/big"
+	"net/url"
+	"strings"
 )
 
 var ErrNotFound = errors.New("not found")
 
+var ErrMethodNotSupported = errors.New("method not supported")
+
+// IsWebsocketURL reports whether the rpc url supports subscriptions
+func IsWebsocketURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "ws" || scheme == "wss"
+}
+
 type IRPCClient interface {
 	BlockNumber(ctx context.Context) (uint64, error)
 
@@ -49,11 +64,34 @@ type IRPCClient interface {
 	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]RpcLog, error)
 }
 
+// IHeadSubscriber is implemented by clients able to push new chain heads
+type IHeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *RpcHeader) (ethereum.Subscription, error)
+}
+
+// ITraceClient is implemented by clients able to trace the internal calls of the block txs
+type ITraceClient interface {
+	TraceBlockCalls(ctx context.Context, number *big.Int) ([]*RpcTxTrace, error)
+}
+
+// IBlobClient is implemented by clients able to fetch the blobs carried by the block txs
+type IBlobClient interface {
+	BlockBlobs(ctx context.Context, number *big.Int, timestamp uint64) ([]*RpcBlob, error)
+}
+
+// IReceiptsClient is implemented by clients able to fetch receipts in bulk
+type IReceiptsClient interface {
+	BlockReceipts(ctx context.Context, number *big.Int) ([]*RpcReceipt, error)
+
+	BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*RpcReceipt, error)
+}
+
 type RpcHeader struct {
 	ParentHash string   `json:"parentHash"       gencodec:"required"`
 	Number     *big.Int `json:"number"           gencodec:"required"`
 	Time       uint64   `json:"timestamp"`
 	TxHash     string   `json:"transactionsRoot" gencodec:"required"`
+	Hash       string   `json:"hash"`
 }
 
 type RpcBlock struct {
@@ -84,11 +122,56 @@ type RpcTransaction struct {
 	GasPrice    *big.Int       `json:"gasPrice"`
 	Vin         []btcjson.Vin  `json:"vin"`
 	Vout        []btcjson.Vout `json:"vout"`
+	Prevouts    []btcjson.Vout `json:"prevouts,omitempty"` // outputs spent by vin, same order as vin
+	Memo        string         `json:"memo,omitempty"`     // memo of the cosmos txs
 	Events      []RpcLog       `json:"events"`
+	Trace       *RpcCallFrame  `json:"trace,omitempty"` // root call frame, set in trace calls mode
+	BlobHashes  []string       `json:"blobVersionedHashes,omitempty"`
+	Blobs       []string       `json:"blobs,omitempty"` // blobs of the versioned hashes in order, set with the blob source configured
 	Receipt     []RpcReceipt   `json:"receipt"`
 	Status      int64          `json:"status"`
 }
 
+// DerivedHashSeparator separates the tx hash & the mark of the txs derived from the tx,
+// i.e. the call index of the internal calls or the blob mark of the blob payload
+const DerivedHashSeparator = "#"
+
+// BlobTxType tx type of the EIP-4844 txs carrying blobs
+const BlobTxType = 3
+
+// OriginHash returns the hash of the tx the derived tx belongs to
+func OriginHash(hash string) string {
+	origin, _, _ := strings.Cut(hash, DerivedHashSeparator)
+	return origin
+}
+
+// IsDerivedTx reports whether the tx is derived from an internal call or the blobs of the tx
+func IsDerivedTx(hash string) bool {
+	return strings.Contains(hash, DerivedHashSeparator)
+}
+
+// RpcCallFrame call frame of the callTracer
+type RpcCallFrame struct {
+	Type  string          `json:"type"`
+	From  string          `json:"from"`
+	To    string          `json:"to"`
+	Input string          `json:"input"`
+	Error string          `json:"error,omitempty"`
+	Calls []*RpcCallFrame `json:"calls,omitempty"`
+}
+
+// RpcTxTrace call trace of one block tx, older nodes omit the tx hash & return the traces in the tx order
+type RpcTxTrace struct {
+	TxHash string        `json:"txHash"`
+	Result *RpcCallFrame `json:"result"`
+}
+
+// RpcBlob blob of the type-3 txs, identified by the versioned hash of its kzg commitment
+type RpcBlob struct {
+	VersionedHash string `json:"versionedHash"`
+	Data          string `json:"data"`
+}
+
 type RpcLog struct {
 	// Consensus fields:
 	// address of the contract that generated the event
@@ -80,15 +80,35 @@ func main() {
 	if err != nil {
 		xylog.Logger.Fatalf("db init err:%v", err)
 	}
-	rpcClient, err := client.NewRPCClient(cfg.Chain.Rpc, cfg.Chain.ChainGroup)
+	rpcClient, err := client.NewChainClient(&cfg.Chain)
 	if err != nil {
 		xylog.Logger.Fatalf("initialize rpc client err:%v", err)
 	}
 
+	// the balances indexed before the available balance was kept load as fully escrowed otherwise
+	backfilled, err := dbClient.BackfillAvailableBalances(cfg.Chain.ChainName)
+	if err != nil {
+		xylog.Logger.Fatalf("backfill available balances err:%v", err)
+	}
+	if backfilled > 0 {
+		xylog.Logger.Infof("backfilled available balances, rows[%d]", backfilled)
+	}
+
 	dCache := dcache.NewManager(dbClient, cfg.Chain.ChainName)
 
 	// init protocols
-	protocol.InitProtocols(dCache)
+	if err = protocol.InitProtocols(&cfg, dCache); err != nil {
+		xylog.Logger.Fatalf("initialize protocols err:%v", err)
+	}
+
+	// the protocols depending on the indexed history are checked against the block the scan resumes from
+	startBlock, err := explorer.ResumeBlock(dbClient, &cfg)
+	if err != nil {
+		xylog.Logger.Fatalf("load history block index err:%v", err)
+	}
+	if err = protocol.VerifyStart(startBlock); err != nil {
+		xylog.Logger.Fatalf("protocols start checking err:%v", err)
+	}
 
 	// Listen for SIGINT and SIGTERM signals
 	quit := make(chan os.Signal, 1)
@@ -27,6 +27,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/jsonrpc"
+	"github.com/uxuycom/indexer/protocol"
 	"github.com/uxuycom/indexer/storage"
 	"github.com/uxuycom/indexer/xylog"
 	"log"
@@ -56,6 +57,11 @@ func main() {
 		log.Fatalf("initialize db client err:%v", err)
 		return
 	}
+	// tick rules of the served chains
+	if err = protocol.InitChainProtocols(cfg.Chains); err != nil {
+		log.Fatalf("initialize chain protocols err:%v", err)
+	}
+
 	//init server
 	server, err := jsonrpc.NewRPCServer(dbc, cfg.CacheStore)
 	if err != nil {
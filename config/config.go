@@ -35,14 +35,88 @@ type ScanConfig struct {
 	BlockBatchWorkers uint64 `json:"block_batch_workers"`
 	TxBatchWorkers    uint64 `json:"tx_batch_workers"`
 	DelayedBlockNum   uint64 `json:"delayed_block_num"`
+	ReorgDepth        uint64 `json:"reorg_depth"`  // max blocks rolled back on chain reorganization
+	ReceiptMode       string `json:"receipt_mode"` // receipts fetching mode: auto(default) / block / batch / single
+	TraceCalls        bool   `json:"trace_calls"`  // extracts inscriptions of the internal calls by debug_traceBlockByNumber, evm only
 }
 
 type ChainConfig struct {
 	ChainName  string           `json:"chain_name"`
 	Rpc        string           `json:"rpc"`
+	Rpcs       []string         `json:"rpcs"`        // multiple endpoints with failover, rpc is added as the first one if set
+	HedgeDelay uint64           `json:"hedge_delay"` // milliseconds to wait before hedging a slow request to another endpoint, 0 disables hedging
+	Quorum     int              `json:"quorum"`      // endpoints required to agree on a block hash, less than 2 disables checking
+	BeaconUrl  string           `json:"beacon_url"`  // beacon api endpoint serving the blobs of the type-3 txs, evm only
+	RecordDir  string           `json:"record_dir"`  // records fetched blocks, receipts & logs into the dir
+	ReplayDir  string           `json:"replay_dir"`  // replays the records in the dir instead of connecting rpc endpoints
 	UserName   string           `json:"username"`
 	PassWord   string           `json:"password"`
 	ChainGroup model.ChainGroup `json:"chain_group"`
+
+	ERC20Tokens   []*ERC20Token    `json:"erc20_tokens"`   // token contracts whose Transfer events are indexed, evm only
+	EventAdapters []*EventAdapter  `json:"event_adapters"` // contract events indexed as the tick transfers, evm only
+	ProtocolRules []*ProtocolRules `json:"protocol_rules"` // rule activation schedule of the protocols on the chain
+}
+
+// ProtocolRules rule changes of the protocol activated from the height, the unset fields keep the rules in force
+type ProtocolRules struct {
+	Protocol        string  `json:"protocol"`
+	Height          uint64  `json:"height"`
+	MaxPayloadSize  *int    `json:"max_payload_size"` // max characters of the inscription envelope, 0 unlimited
+	DefaultDecimals *int8   `json:"default_decimals"` // decimals of the ticks deployed without dec
+	MaxDecimals     *int8   `json:"max_decimals"`
+	StrictDecimals  *bool   `json:"strict_decimals"` // amounts with more fractional digits than the tick decimals are rejected
+	HashTransfer    *bool   `json:"hash_transfer"`   // ticks transferred by hash deployable, the tt field is ignored otherwise
+	TickRule        *string `json:"tick_rule"`       // legacy / brc-20 / unicode, the tick bytes changes apply on top of it
+	TickMinBytes    *int    `json:"tick_min_bytes"`
+	TickMaxBytes    *int    `json:"tick_max_bytes"`
+	StrictJSON      *bool   `json:"strict_json"`      // the inscriptions must match the json profile of the protocol
+	RFC2397         *bool   `json:"rfc2397_data_uri"` // the data uri schemes are case insensitive & the data percent decoded
+}
+
+// ERC20Token token contract indexed by the erc-20 protocol
+type ERC20Token struct {
+	Address     string `json:"address"`
+	Tick        string `json:"tick"`
+	Decimals    int8   `json:"decimals"`
+	DeployBlock uint64 `json:"deploy_block"` // block of the token contract creation, the scan shall start at or before it
+}
+
+// EventAdapter maps the events declared by the abi file into the tick transfers of the protocol,
+// only the events emitted by the allowlisted contracts are indexed
+type EventAdapter struct {
+	Name      string          `json:"name"`
+	Protocol  string          `json:"protocol"` // protocol of the transferred ticks
+	Abi       string          `json:"abi"`      // abi json file path
+	Contracts []string        `json:"contracts"`
+	Events    []*EventMapping `json:"events"`
+}
+
+// EventMapping names the event fields of the transfer, the tick is either fixed or read from the tick field
+type EventMapping struct {
+	Event     string `json:"event"`
+	Operate   string `json:"op"` // transfer(default) / exchange, the exchanges are recorded as the sales
+	Tick      string `json:"tick"`
+	TickField string `json:"tick_field"` // string tick or the bytes32 tick hash
+	From      string `json:"from"`       // sender field, defaults to the emitting contract
+	To        string `json:"to"`
+	Amount    string `json:"amount"`
+	Decimals  int32  `json:"decimals"` // decimals of the raw amount
+}
+
+// RpcEndpoints returns all configured rpc endpoints
+func (c *ChainConfig) RpcEndpoints() []string {
+	endpoints := make([]string, 0, len(c.Rpcs)+1)
+	if c.Rpc != "" {
+		endpoints = append(endpoints, c.Rpc)
+	}
+
+	for _, rpc := range c.Rpcs {
+		if rpc != "" && rpc != c.Rpc {
+			endpoints = append(endpoints, rpc)
+		}
+	}
+	return endpoints
 }
 
 type IndexFilter struct {
@@ -83,6 +157,7 @@ type JsonRcpConfig struct {
 	Database      DatabaseConfig `json:"database"`
 	Profile       *ProfileConfig `json:"profile"`
 	CacheStore    *CacheConfig   `json:"cache_store"`
+	Chains        []*ChainConfig `json:"chains"` // chains served, the requested ticks are normalized by their protocol_rules
 }
 
 type CacheConfig struct {
@@ -19,6 +19,12 @@ CREATE TABLE `inscriptions`
     `deploy_hash`    varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL, -- deployed tx hash
     `deploy_time`    timestamp                                                     NOT NULL, -- deployed time
     `transfer_type`  tinyint(1)                                                    NOT NULL, -- transfer type
+    `strict_decimals` tinyint(1)                                                   NOT NULL DEFAULT 0, -- amounts must fit the decimals
+    `address_mint_limit` DECIMAL(38, 18)                                           NOT NULL DEFAULT 0, -- total mint amount limit by per address, 0 unlimited
+    `mint_start_block`   bigint unsigned                                           NOT NULL DEFAULT 0, -- first mintable block, 0 unlimited
+    `mint_end_block`     bigint unsigned                                           NOT NULL DEFAULT 0, -- last mintable block, 0 unlimited
+    `premine`            DECIMAL(38, 18)                                           NOT NULL DEFAULT 0, -- amount minted to the deployer on deploy
+    `self_mint`          tinyint(1)                                                NOT NULL DEFAULT 0, -- mintable by the deployer only
     `created_at`     timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `updated_at`     timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     PRIMARY KEY (`id`),
@@ -153,11 +159,136 @@ CREATE TABLE `utxos`
     `amount`     DECIMAL(38, 18)                                               NOT NULL,
     `root_hash`  varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
     `tx_hash`    varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `spent_hash` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT 'spending tx hash',
     `status`     tinyint(1)                                                    NOT NULL COMMENT 'tx status',
     `created_at` timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `updated_at` timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     PRIMARY KEY (`id`),
-    KEY `idx_address` (`address`)
+    KEY `idx_address` (`address`),
+    KEY `idx_sn` (`sn`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- ethscriptions ------------------------------
+CREATE TABLE `ethscriptions`
+(
+    `id`              bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`           varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `ethscription_id` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL COMMENT 'creation tx hash',
+    `creator`         varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `initial_owner`   varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `current_owner`   varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `previous_owner`  varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `content_sha`     varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL COMMENT 'sha256 of the content uri',
+    `mime_type`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '',
+    `block_number`    bigint unsigned                                               NOT NULL,
+    `created_at`      timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_ethscription` (`chain`, `ethscription_id`),
+    KEY `idx_current_owner` (`current_owner`),
+    KEY `idx_content_sha` (`content_sha`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE `ethscription_transfers`
+(
+    `id`              bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`           varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `ethscription_id` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `tx_hash`         varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `from`            varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `to`              varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `block_number`    bigint unsigned                                               NOT NULL,
+    `log_index`       bigint                                                        NOT NULL DEFAULT -1 COMMENT '-1 for creation & calldata transfers',
+    `created_at`      timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_ethscription_id` (`ethscription_id`),
+    KEY `idx_block_number` (`block_number`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- runes ------------------------------
+CREATE TABLE `runes`
+(
+    `id`           bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `rune_id`      varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL COMMENT 'block:tx of the etching',
+    `tick`         varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin    NOT NULL,
+    `spaced_name`  varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `symbol`       varchar(8) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci   NOT NULL DEFAULT '',
+    `divisibility` tinyint                                                       NOT NULL DEFAULT 0,
+    `premine`      DECIMAL(40, 0)                                                NOT NULL DEFAULT 0,
+    `amount`       DECIMAL(40, 0)                                                NOT NULL DEFAULT 0,
+    `cap`          DECIMAL(40, 0)                                                NOT NULL DEFAULT 0,
+    `height_start` bigint unsigned                                                        DEFAULT NULL,
+    `height_end`   bigint unsigned                                                        DEFAULT NULL,
+    `offset_start` bigint unsigned                                                        DEFAULT NULL,
+    `offset_end`   bigint unsigned                                                        DEFAULT NULL,
+    `turbo`        tinyint(1)                                                    NOT NULL DEFAULT 0,
+    `cenotaph`     tinyint(1)                                                    NOT NULL DEFAULT 0,
+    `tx_hash`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `block_number` bigint unsigned                                               NOT NULL,
+    `created_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_rune_id` (`chain`, `rune_id`),
+    KEY `idx_tick` (`tick`),
+    KEY `idx_block_number` (`block_number`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE `list_orders`
+(
+    `id`           bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `protocol`     varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `tick`         varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin    NOT NULL,
+    `list_id`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL COMMENT 'hash of the list tx',
+    `seller`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `market`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `amount`       DECIMAL(38, 18)                                               NOT NULL,
+    `price`        DECIMAL(38, 18)                                               NOT NULL DEFAULT 0 COMMENT 'price in the native coin',
+    `status`       tinyint                                                       NOT NULL COMMENT '1-open 2-filled 3-canceled',
+    `buyer`        varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '',
+    `settle_hash`  varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT 'tx filling or canceling the order',
+    `block_number` bigint unsigned                                               NOT NULL,
+    `created_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_list_id` (`chain`, `list_id`),
+    KEY `idx_chain_protocol_tick_status` (`chain`, `protocol`, `tick`, `status`),
+    KEY `idx_seller` (`seller`),
+    KEY `idx_settle_hash` (`settle_hash`(12))
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE `trades`
+(
+    `id`           bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `protocol`     varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `tick`         varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin    NOT NULL,
+    `tx_hash`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `list_id`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `seller`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `buyer`        varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `market`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `amount`       DECIMAL(38, 18)                                               NOT NULL,
+    `price`        DECIMAL(38, 18)                                               NOT NULL COMMENT 'paid in the native coin for the amount',
+    `unit_price`   DECIMAL(38, 18)                                               NOT NULL,
+    `block_number` bigint unsigned                                               NOT NULL,
+    `block_time`   timestamp                                                     NOT NULL,
+    `created_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_chain_protocol_tick_time` (`chain`, `protocol`, `tick`, `block_time`),
+    KEY `idx_chain_block` (`chain`, `block_number`),
+    KEY `idx_seller` (`seller`),
+    KEY `idx_buyer` (`buyer`)
 ) ENGINE = InnoDB
   DEFAULT CHARSET = utf8mb4
   COLLATE = utf8mb4_general_ci;
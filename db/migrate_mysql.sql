@@ -0,0 +1,149 @@
+-- upgrades the databases created by an earlier db/init_mysql.sql, run it once before starting the upgraded indexer:
+-- mysql -uroot -p < db/migrate_mysql.sql
+Use
+    tap_indexer;
+
+-- inscription table ---------
+ALTER TABLE `inscriptions`
+    ADD COLUMN `strict_decimals`    tinyint(1)      NOT NULL DEFAULT 0 AFTER `transfer_type`,
+    ADD COLUMN `address_mint_limit` DECIMAL(38, 18) NOT NULL DEFAULT 0 AFTER `strict_decimals`,
+    ADD COLUMN `mint_start_block`   bigint unsigned NOT NULL DEFAULT 0 AFTER `address_mint_limit`,
+    ADD COLUMN `mint_end_block`     bigint unsigned NOT NULL DEFAULT 0 AFTER `mint_start_block`,
+    ADD COLUMN `premine`            DECIMAL(38, 18) NOT NULL DEFAULT 0 AFTER `mint_end_block`,
+    ADD COLUMN `self_mint`          tinyint(1)      NOT NULL DEFAULT 0 AFTER `premine`;
+
+-- utxo table ---------
+ALTER TABLE `utxos`
+    ADD COLUMN `spent_hash` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT 'spending tx hash' AFTER `tx_hash`,
+    ADD KEY `idx_sn` (`sn`);
+
+-- ethscriptions ------------------------------
+CREATE TABLE IF NOT EXISTS `ethscriptions`
+(
+    `id`              bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`           varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `ethscription_id` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL COMMENT 'creation tx hash',
+    `creator`         varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `initial_owner`   varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `current_owner`   varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `previous_owner`  varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `content_sha`     varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL COMMENT 'sha256 of the content uri',
+    `mime_type`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '',
+    `block_number`    bigint unsigned                                               NOT NULL,
+    `created_at`      timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`      timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_ethscription` (`chain`, `ethscription_id`),
+    KEY `idx_current_owner` (`current_owner`),
+    KEY `idx_content_sha` (`content_sha`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `ethscription_transfers`
+(
+    `id`              bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`           varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `ethscription_id` varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `tx_hash`         varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `from`            varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `to`              varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `block_number`    bigint unsigned                                               NOT NULL,
+    `log_index`       bigint                                                        NOT NULL DEFAULT -1 COMMENT '-1 for creation & calldata transfers',
+    `created_at`      timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_ethscription_id` (`ethscription_id`),
+    KEY `idx_block_number` (`block_number`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- runes ------------------------------
+CREATE TABLE IF NOT EXISTS `runes`
+(
+    `id`           bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `rune_id`      varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL COMMENT 'block:tx of the etching',
+    `tick`         varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin    NOT NULL,
+    `spaced_name`  varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `symbol`       varchar(8) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci   NOT NULL DEFAULT '',
+    `divisibility` tinyint                                                       NOT NULL DEFAULT 0,
+    `premine`      DECIMAL(40, 0)                                                NOT NULL DEFAULT 0,
+    `amount`       DECIMAL(40, 0)                                                NOT NULL DEFAULT 0,
+    `cap`          DECIMAL(40, 0)                                                NOT NULL DEFAULT 0,
+    `height_start` bigint unsigned                                                        DEFAULT NULL,
+    `height_end`   bigint unsigned                                                        DEFAULT NULL,
+    `offset_start` bigint unsigned                                                        DEFAULT NULL,
+    `offset_end`   bigint unsigned                                                        DEFAULT NULL,
+    `turbo`        tinyint(1)                                                    NOT NULL DEFAULT 0,
+    `cenotaph`     tinyint(1)                                                    NOT NULL DEFAULT 0,
+    `tx_hash`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `block_number` bigint unsigned                                               NOT NULL,
+    `created_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_rune_id` (`chain`, `rune_id`),
+    KEY `idx_tick` (`tick`),
+    KEY `idx_block_number` (`block_number`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `list_orders`
+(
+    `id`           bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `protocol`     varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `tick`         varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin    NOT NULL,
+    `list_id`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL COMMENT 'hash of the list tx',
+    `seller`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `market`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `amount`       DECIMAL(38, 18)                                               NOT NULL,
+    `price`        DECIMAL(38, 18)                                               NOT NULL DEFAULT 0 COMMENT 'price in the native coin',
+    `status`       tinyint                                                       NOT NULL COMMENT '1-open 2-filled 3-canceled',
+    `buyer`        varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '',
+    `settle_hash`  varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT 'tx filling or canceling the order',
+    `block_number` bigint unsigned                                               NOT NULL,
+    `created_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uqx_chain_list_id` (`chain`, `list_id`),
+    KEY `idx_chain_protocol_tick_status` (`chain`, `protocol`, `tick`, `status`),
+    KEY `idx_seller` (`seller`),
+    KEY `idx_settle_hash` (`settle_hash`(12))
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS `trades`
+(
+    `id`           bigint unsigned                                               NOT NULL AUTO_INCREMENT,
+    `chain`        varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `protocol`     varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci  NOT NULL,
+    `tick`         varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_bin    NOT NULL,
+    `tx_hash`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `list_id`      varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `seller`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `buyer`        varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `market`       varchar(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL,
+    `amount`       DECIMAL(38, 18)                                               NOT NULL,
+    `price`        DECIMAL(38, 18)                                               NOT NULL COMMENT 'paid in the native coin for the amount',
+    `unit_price`   DECIMAL(38, 18)                                               NOT NULL,
+    `block_number` bigint unsigned                                               NOT NULL,
+    `block_time`   timestamp                                                     NOT NULL,
+    `created_at`   timestamp                                                     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_chain_protocol_tick_time` (`chain`, `protocol`, `tick`, `block_time`),
+    KEY `idx_chain_block` (`chain`, `block_number`),
+    KEY `idx_seller` (`seller`),
+    KEY `idx_buyer` (`buyer`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci;
+
+-- balances table ---------
+-- the rows indexed before the available balance was kept have no available balance,
+-- nothing is escrowed before the upgrade so the whole balance is available
+UPDATE `balances`
+SET `available` = `balance`
+WHERE `available` = 0
+  AND `balance` > 0;
@@ -0,0 +1,74 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strings"
+	"sync"
+)
+
+// AddressMinted
+/*****************************************************
+ * Build cache for the amounts minted by the addresses
+ * Mainly used for the mint limit per address checking
+ ****************************************************/
+type AddressMinted struct {
+	items *sync.Map
+}
+
+func NewAddressMinted() *AddressMinted {
+	return &AddressMinted{
+		items: &sync.Map{},
+	}
+}
+
+/***************************************
+ * idx define protocol tick address unique id
+ ***************************************/
+func (d *AddressMinted) idx(protocol, tick, address string) string {
+	return fmt.Sprintf("%s_%s_%s", strings.ToLower(protocol), strings.ToLower(tick), strings.ToLower(address))
+}
+
+// Add
+/***************************************
+ * add the minted amount of the address
+ ***************************************/
+func (d *AddressMinted) Add(protocol, tick, address string, amount decimal.Decimal) decimal.Decimal {
+	minted := d.Get(protocol, tick, address).Add(amount)
+	d.items.Store(d.idx(protocol, tick, address), minted)
+	return minted
+}
+
+// Get
+/***************************************
+ * get the minted amount of the address
+ ***************************************/
+func (d *AddressMinted) Get(protocol, tick, address string) decimal.Decimal {
+	minted, ok := d.items.Load(d.idx(protocol, tick, address))
+	if !ok {
+		return decimal.Zero
+	}
+	return minted.(decimal.Decimal)
+}
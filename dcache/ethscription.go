@@ -0,0 +1,107 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"strings"
+	"sync"
+)
+
+// Ethscription
+/*****************************************************
+ * Build cache for all ethscriptions
+ * Mainly used for ownership & content uniqueness checking
+ ****************************************************/
+type Ethscription struct {
+	items  *sync.Map // ethscription id -> owners
+	hashes *sync.Map // content sha set
+}
+
+type EthscriptionItem struct {
+	Creator       string
+	Owner         string
+	PreviousOwner string
+}
+
+func NewEthscription() *Ethscription {
+	return &Ethscription{
+		items:  &sync.Map{},
+		hashes: &sync.Map{},
+	}
+}
+
+/***************************************
+ * idx define ethscription unique id
+ ***************************************/
+func (d *Ethscription) idx(id string) string {
+	return strings.ToLower(id)
+}
+
+// Create
+/***************************************
+ * Add new ethscription record with its content sha
+ ***************************************/
+func (d *Ethscription) Create(id, contentSha string, item *EthscriptionItem) {
+	d.items.Store(d.idx(id), item)
+	if contentSha != "" {
+		d.hashes.Store(strings.ToLower(contentSha), struct{}{})
+	}
+}
+
+// Get
+/***************************************
+ * get ethscription record by id
+ ***************************************/
+func (d *Ethscription) Get(id string) (bool, *EthscriptionItem) {
+	item, ok := d.items.Load(d.idx(id))
+	if !ok {
+		return false, nil
+	}
+	return true, item.(*EthscriptionItem)
+}
+
+// Transfer
+/***************************************
+ * move ethscription to the new owner
+ ***************************************/
+func (d *Ethscription) Transfer(id, to string) {
+	ok, item := d.Get(id)
+	if !ok {
+		return
+	}
+
+	d.items.Store(d.idx(id), &EthscriptionItem{
+		Creator:       item.Creator,
+		Owner:         to,
+		PreviousOwner: item.Owner,
+	})
+}
+
+// ContentExists
+/***************************************
+ * check whether the content was ethscribed before
+ ***************************************/
+func (d *Ethscription) ContentExists(contentSha string) bool {
+	_, ok := d.hashes.Load(strings.ToLower(contentSha))
+	return ok
+}
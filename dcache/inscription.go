@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/utils"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -47,6 +48,16 @@ type Tick struct {
 	LimitPerMint decimal.Decimal
 	TotalSupply  decimal.Decimal
 	Decimals     int8
+	DeployBy     string
+
+	StrictDecimals bool
+
+	// extended deploy parameters, zero values leave the minting unrestricted
+	AddressMintLimit decimal.Decimal
+	MintStartBlock   uint64
+	MintEndBlock     uint64
+	Premine          decimal.Decimal
+	SelfMint         bool
 }
 
 func NewInscription() *Inscription {
@@ -63,6 +74,23 @@ func (d *Inscription) idx(protocol, tick string) string {
 	return fmt.Sprintf("%s_%s", strings.ToLower(protocol), strings.ToLower(tick))
 }
 
+// Ticks
+/***************************************
+ * sorted ticks deployed on the protocol
+ ***************************************/
+func (d *Inscription) Ticks(protocol string) []string {
+	prefix := d.idx(protocol, "")
+	ticks := make([]string, 0)
+	d.ticks.Range(func(key, value any) bool {
+		if idx := key.(string); strings.HasPrefix(idx, prefix) {
+			ticks = append(ticks, strings.TrimPrefix(idx, prefix))
+		}
+		return true
+	})
+	sort.Strings(ticks)
+	return ticks
+}
+
 // Create
 /***************************************
  * init tick's metadata
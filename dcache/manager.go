@@ -36,7 +36,11 @@ type Manager struct {
 	chain            string
 	db               *storage.DBClient
 	Balance          *Balance
+	AddressMinted    *AddressMinted
 	UTXO             *UTXO
+	Ethscription     *Ethscription
+	Rune             *Rune
+	Order            *Order
 	Inscription      *Inscription
 	InscriptionStats *InscriptionStats
 }
@@ -54,10 +58,34 @@ func NewManager(db *storage.DBClient, chain string) *Manager {
 	e.initInscriptionCache(chain)
 	e.initInscriptionStatsCache(chain)
 	e.initBalanceCache(chain)
-	e.initUtxoCache()
+	e.initAddressMintedCache(chain)
+	e.initUtxoCache(chain)
+	e.initEthscriptionCache(chain)
+	e.initRuneCache(chain)
+	e.initOrderCache(chain)
 	return e
 }
 
+// Reload
+/***************************************
+ * rebuild all caches from database,
+ * used after indexed blocks were rolled back
+ ***************************************/
+func (h *Manager) Reload() {
+	if h.db == nil {
+		return
+	}
+
+	h.initInscriptionCache(h.chain)
+	h.initInscriptionStatsCache(h.chain)
+	h.initBalanceCache(h.chain)
+	h.initAddressMintedCache(h.chain)
+	h.initUtxoCache(h.chain)
+	h.initEthscriptionCache(h.chain)
+	h.initRuneCache(h.chain)
+	h.initOrderCache(h.chain)
+}
+
 func (h *Manager) initInscriptionCache(chain string) {
 	h.Inscription = NewInscription()
 
@@ -86,6 +114,15 @@ func (h *Manager) initInscriptionCache(chain string) {
 				LimitPerMint: v.LimitPerMint,
 				TotalSupply:  v.TotalSupply,
 				Decimals:     v.Decimals,
+				DeployBy:     v.DeployBy,
+
+				StrictDecimals: v.StrictDecimals,
+
+				AddressMintLimit: v.AddressMintLimit,
+				MintStartBlock:   v.MintStartBlock,
+				MintEndBlock:     v.MintEndBlock,
+				Premine:          v.Premine,
+				SelfMint:         v.SelfMint,
 			})
 
 			if v.SID > maxSid {
@@ -190,7 +227,23 @@ func (h *Manager) initBalanceCache(chain string) {
 	xylog.Logger.Infof("load balances data finished, cost ts:%v", time.Since(startTs))
 }
 
-func (h *Manager) initUtxoCache() {
+func (h *Manager) initAddressMintedCache(chain string) {
+	h.AddressMinted = NewAddressMinted()
+
+	startTs := time.Now()
+	xylog.Logger.Infof("load address minted data start...")
+	items, err := h.db.GetAddressMinted(chain)
+	if err != nil {
+		xylog.Logger.Fatalf("failed to initialize address minted cache data. err:%v", err)
+	}
+
+	for _, v := range items {
+		h.AddressMinted.Add(v.Protocol, v.Tick, v.Address, v.Minted)
+	}
+	xylog.Logger.Infof("load address minted data finished, items[%d], cost ts:%v", len(items), time.Since(startTs))
+}
+
+func (h *Manager) initUtxoCache(chain string) {
 	h.UTXO = NewUTXO()
 
 	startTs := time.Now()
@@ -199,7 +252,7 @@ func (h *Manager) initUtxoCache() {
 	limit := 1000
 	xylog.Logger.Infof("load utxos data start...")
 	for {
-		utxos, err := h.db.GetUTXOsByIdLimit(start, limit)
+		utxos, err := h.db.GetUTXOsByIdLimit(chain, start, limit)
 		if err != nil {
 			xylog.Logger.Fatalf("failed to initialize utxos cache data. err:%v", err)
 		}
@@ -219,3 +272,117 @@ func (h *Manager) initUtxoCache() {
 	}
 	xylog.Logger.Infof("load utxos data finished, cost ts:%v", time.Since(startTs))
 }
+
+func (h *Manager) initEthscriptionCache(chain string) {
+	h.Ethscription = NewEthscription()
+
+	startTs := time.Now()
+	idx := 0
+	start := uint64(0)
+	limit := 10000
+	xylog.Logger.Infof("load ethscriptions data start...")
+	for {
+		items, err := h.db.GetEthscriptionsByIdLimit(chain, start, limit)
+		if err != nil {
+			xylog.Logger.Fatalf("failed to initialize ethscriptions cache data. err:%v", err)
+		}
+		idx++
+		xylog.Logger.Infof("load ethscriptions ret, items[%d], idx:%d", len(items), idx)
+
+		if len(items) <= 0 {
+			break
+		}
+
+		for _, v := range items {
+			h.Ethscription.Create(v.EthscriptionID, v.ContentSha, &EthscriptionItem{
+				Creator:       v.Creator,
+				Owner:         v.CurrentOwner,
+				PreviousOwner: v.PreviousOwner,
+			})
+		}
+
+		//update id index
+		start = items[len(items)-1].ID
+	}
+	xylog.Logger.Infof("load ethscriptions data finished, cost ts:%v", time.Since(startTs))
+}
+
+func (h *Manager) initRuneCache(chain string) {
+	h.Rune = NewRune()
+
+	startTs := time.Now()
+	idx := 0
+	start := uint64(0)
+	limit := 10000
+	xylog.Logger.Infof("load runes data start...")
+	for {
+		items, err := h.db.GetRunesByIdLimit(chain, start, limit)
+		if err != nil {
+			xylog.Logger.Fatalf("failed to initialize runes cache data. err:%v", err)
+		}
+		idx++
+		xylog.Logger.Infof("load runes ret, items[%d], idx:%d", len(items), idx)
+
+		if len(items) <= 0 {
+			break
+		}
+
+		for _, v := range items {
+			h.Rune.Create(&RuneItem{
+				ID:           v.RuneID,
+				Tick:         v.Tick,
+				Divisibility: v.Divisibility,
+				Premine:      v.Premine,
+				Amount:       v.Amount,
+				Cap:          v.Cap,
+				HeightStart:  v.HeightStart,
+				HeightEnd:    v.HeightEnd,
+				OffsetStart:  v.OffsetStart,
+				OffsetEnd:    v.OffsetEnd,
+				Block:        v.BlockNumber,
+			})
+		}
+
+		//update id index
+		start = items[len(items)-1].ID
+	}
+	xylog.Logger.Infof("load runes data finished, cost ts:%v", time.Since(startTs))
+}
+
+func (h *Manager) initOrderCache(chain string) {
+	h.Order = NewOrder()
+
+	startTs := time.Now()
+	idx := 0
+	start := uint64(0)
+	limit := 10000
+	xylog.Logger.Infof("load open orders data start...")
+	for {
+		items, err := h.db.GetOpenListOrdersByIdLimit(chain, start, limit)
+		if err != nil {
+			xylog.Logger.Fatalf("failed to initialize orders cache data. err:%v", err)
+		}
+		idx++
+		xylog.Logger.Infof("load open orders ret, items[%d], idx:%d", len(items), idx)
+
+		if len(items) <= 0 {
+			break
+		}
+
+		for _, v := range items {
+			h.Order.Create(&OrderItem{
+				ListID:   v.ListID,
+				Protocol: v.Protocol,
+				Tick:     v.Tick,
+				Seller:   v.Seller,
+				Market:   v.Market,
+				Amount:   v.Amount,
+				Price:    v.Price,
+			})
+		}
+
+		//update id index
+		start = items[len(items)-1].ID
+	}
+	xylog.Logger.Infof("load open orders data finished, cost ts:%v", time.Since(startTs))
+}
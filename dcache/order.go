@@ -0,0 +1,89 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"github.com/shopspring/decimal"
+	"strings"
+	"sync"
+)
+
+// Order
+/*****************************************************
+ * Build cache for all open list orders
+ * Mainly used for settling the escrowed balances
+ ****************************************************/
+type Order struct {
+	items *sync.Map // list id -> open order
+}
+
+type OrderItem struct {
+	ListID   string
+	Protocol string
+	Tick     string
+	Seller   string
+	Market   string
+	Amount   decimal.Decimal
+	Price    decimal.Decimal
+}
+
+func NewOrder() *Order {
+	return &Order{
+		items: &sync.Map{},
+	}
+}
+
+/***************************************
+ * idx define order unique id
+ ***************************************/
+func (d *Order) idx(listID string) string {
+	return strings.ToLower(listID)
+}
+
+// Create
+/***************************************
+ * Add new open order
+ ***************************************/
+func (d *Order) Create(item *OrderItem) {
+	d.items.Store(d.idx(item.ListID), item)
+}
+
+// Get
+/***************************************
+ * get open order by list id
+ ***************************************/
+func (d *Order) Get(listID string) (bool, *OrderItem) {
+	item, ok := d.items.Load(d.idx(listID))
+	if !ok {
+		return false, nil
+	}
+	return true, item.(*OrderItem)
+}
+
+// Remove
+/***************************************
+ * remove the filled or canceled order
+ ***************************************/
+func (d *Order) Remove(listID string) {
+	d.items.Delete(d.idx(listID))
+}
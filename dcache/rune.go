@@ -0,0 +1,101 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package dcache
+
+import (
+	"github.com/shopspring/decimal"
+	"strings"
+	"sync"
+)
+
+// Rune
+/*****************************************************
+ * Build cache for all etched runes
+ * Mainly used for rune id & mint terms checking
+ ****************************************************/
+type Rune struct {
+	items *sync.Map // rune id -> rune
+	names *sync.Map // rune tick -> rune id
+}
+
+// RuneItem amounts are the raw integers, the tick balances are shifted by the divisibility
+type RuneItem struct {
+	ID           string
+	Tick         string
+	Divisibility int8
+	Premine      decimal.Decimal
+	Amount       decimal.Decimal
+	Cap          decimal.Decimal
+	HeightStart  *uint64
+	HeightEnd    *uint64
+	OffsetStart  *uint64
+	OffsetEnd    *uint64
+	Block        uint64
+}
+
+func NewRune() *Rune {
+	return &Rune{
+		items: &sync.Map{},
+		names: &sync.Map{},
+	}
+}
+
+/***************************************
+ * idx define rune unique id
+ ***************************************/
+func (d *Rune) idx(id string) string {
+	return strings.ToLower(id)
+}
+
+// Create
+/***************************************
+ * Add new etched rune
+ ***************************************/
+func (d *Rune) Create(item *RuneItem) {
+	d.items.Store(d.idx(item.ID), item)
+	d.names.Store(d.idx(item.Tick), item.ID)
+}
+
+// Get
+/***************************************
+ * get rune by rune id
+ ***************************************/
+func (d *Rune) Get(id string) (bool, *RuneItem) {
+	item, ok := d.items.Load(d.idx(id))
+	if !ok {
+		return false, nil
+	}
+	return true, item.(*RuneItem)
+}
+
+// GetByTick
+/***************************************
+ * get rune by the tick of the rune name
+ ***************************************/
+func (d *Rune) GetByTick(tick string) (bool, *RuneItem) {
+	id, ok := d.names.Load(d.idx(tick))
+	if !ok {
+		return false, nil
+	}
+	return d.Get(id.(string))
+}
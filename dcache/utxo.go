@@ -24,6 +24,7 @@ package dcache
 
 import (
 	"github.com/shopspring/decimal"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -35,6 +36,9 @@ import (
  ****************************************************/
 type UTXO struct {
 	hashes *sync.Map //record mint hash items
+
+	mu  sync.RWMutex
+	sns map[string]map[string]struct{} // sn -> root hashes, one utxo may carry balances of several ticks
 }
 
 type UTXOItem struct {
@@ -43,11 +47,13 @@ type UTXOItem struct {
 	Amount   decimal.Decimal
 	Owner    string
 	SN       string
+	RootHash string
 }
 
 func NewUTXO() *UTXO {
 	return &UTXO{
 		hashes: &sync.Map{},
+		sns:    make(map[string]map[string]struct{}),
 	}
 }
 
@@ -64,13 +70,26 @@ func (d *UTXO) idx(txHash string) string {
  ***************************************/
 func (d *UTXO) Add(protocol, tick, txHash, address string, amount decimal.Decimal, sn string) {
 	idx := d.idx(txHash)
+	if item, ok := d.hashes.Load(idx); ok {
+		d.unindex(item.(*UTXOItem).SN, idx)
+	}
+
 	d.hashes.Store(idx, &UTXOItem{
 		Protocol: protocol,
 		Tick:     tick,
 		Amount:   amount,
 		Owner:    address,
 		SN:       sn,
+		RootHash: txHash,
 	})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := d.idx(sn)
+	if _, ok := d.sns[key]; !ok {
+		d.sns[key] = make(map[string]struct{}, 1)
+	}
+	d.sns[key][idx] = struct{}{}
 }
 
 // Get
@@ -85,3 +104,50 @@ func (d *UTXO) Get(txHash string) (bool, *UTXOItem) {
 	}
 	return true, item.(*UTXOItem)
 }
+
+// Remove
+/***************************************
+ * remove spent utxo record by mint tx hash
+ ***************************************/
+func (d *UTXO) Remove(txHash string) {
+	idx := d.idx(txHash)
+	item, ok := d.hashes.LoadAndDelete(idx)
+	if !ok {
+		return
+	}
+	d.unindex(item.(*UTXOItem).SN, idx)
+}
+
+// GetBySN
+/***************************************
+ * get all utxo records of the sn
+ ***************************************/
+func (d *UTXO) GetBySN(sn string) []*UTXOItem {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	idxes := d.sns[d.idx(sn)]
+	items := make([]*UTXOItem, 0, len(idxes))
+	for idx := range idxes {
+		if item, ok := d.hashes.Load(idx); ok {
+			items = append(items, item.(*UTXOItem))
+		}
+	}
+
+	// keep the records in a stable order
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].RootHash < items[j].RootHash
+	})
+	return items
+}
+
+func (d *UTXO) unindex(sn, idx string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := d.idx(sn)
+	delete(d.sns[key], idx)
+	if len(d.sns[key]) < 1 {
+		delete(d.sns, key)
+	}
+}
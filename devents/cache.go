@@ -49,14 +49,48 @@ func (tc *TxResultHandler) UpdateCache(r *TxResult) {
 	if r.Transfer != nil {
 		tc.updateTransferCache(r)
 	}
+
+	if r.InscribeTransfer != nil {
+		tc.updateInscribeTransferCache(r)
+	}
+
+	if r.Ethscription != nil {
+		tc.updateEthscriptionCache(r)
+	}
+
+	if r.Etching != nil {
+		tc.updateEtchingCache(r)
+	}
+
+	if r.UTXOTransfer != nil {
+		tc.updateUTXOTransferCache(r)
+	}
+
+	if r.List != nil {
+		tc.updateListCache(r)
+	}
+
+	if r.Settle != nil {
+		tc.updateSettleCache(r)
+	}
 }
 
 func (tc *TxResultHandler) updateDeployCache(r *TxResult) {
 	//Add new tick
 	t := &dcache.Tick{
+		TransferType: r.Deploy.TransferType,
 		LimitPerMint: r.Deploy.MintLimit,
 		TotalSupply:  r.Deploy.MaxSupply,
 		Decimals:     r.Deploy.Decimal,
+		DeployBy:     r.Tx.From,
+
+		StrictDecimals: r.Deploy.StrictDecimals,
+
+		AddressMintLimit: r.Deploy.AddressMintLimit,
+		MintStartBlock:   r.Deploy.MintStartBlock,
+		MintEndBlock:     r.Deploy.MintEndBlock,
+		Premine:          r.Deploy.Premine,
+		SelfMint:         r.Deploy.SelfMint,
 	}
 	tc.cache.Inscription.Create(r.MD.Protocol, r.MD.Tick, t)
 
@@ -70,13 +104,24 @@ func (tc *TxResultHandler) updateDeployCache(r *TxResult) {
 func (tc *TxResultHandler) updateMintCache(r *TxResult) {
 	//Update mint stats
 	tc.cache.InscriptionStats.Mint(r.MD.Protocol, r.MD.Tick, r.Mint.Amount)
-	tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
+
+	//Premine is counted by the deploy tx & out of the mint limit per address
+	if !r.Mint.Premine {
+		tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
+		tc.cache.AddressMinted.Add(r.MD.Protocol, r.MD.Tick, r.Mint.Minter, r.Mint.Amount)
+	}
+
+	//Record minted utxo of hash transfer type tick
+	if r.Mint.SN != "" {
+		tc.cache.UTXO.Add(r.MD.Protocol, r.MD.Tick, r.Tx.Hash, r.Mint.Minter, r.Mint.Amount, r.Mint.SN)
+	}
 
 	//Update minter balances
 	ok, balance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, r.Mint.Minter)
 	if !ok {
 		tc.cache.Balance.Create(r.MD.Protocol, r.MD.Tick, r.Mint.Minter, &dcache.BalanceItem{
-			Overall: r.Mint.Amount,
+			Available: r.Mint.Amount,
+			Overall:   r.Mint.Amount,
 		})
 		tc.cache.InscriptionStats.Holders(r.MD.Protocol, r.MD.Tick, 1)
 
@@ -87,9 +132,9 @@ func (tc *TxResultHandler) updateMintCache(r *TxResult) {
 			tc.cache.InscriptionStats.Holders(r.MD.Protocol, r.MD.Tick, 1)
 		}
 
-		amount := balance.Overall.Add(r.Mint.Amount)
 		tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, r.Mint.Minter, &dcache.BalanceItem{
-			Overall: amount,
+			Available: balance.Available.Add(r.Mint.Amount),
+			Overall:   balance.Overall.Add(r.Mint.Amount),
 		})
 	}
 }
@@ -110,18 +155,34 @@ func (tc *TxResultHandler) updateTransferCache(r *TxResult) {
 	if senderAmount.LessThanOrEqual(decimal.Zero) {
 		holders--
 	}
+
+	// transferable balance was moved out of the available one when inscribed
+	senderAvailable := senderBalance.Available
+	if !r.Transfer.Transferable {
+		senderAvailable = senderAvailable.Sub(sendTotalAmount)
+	}
 	tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, r.Transfer.Sender, &dcache.BalanceItem{
-		Overall: senderAmount,
+		Available: senderAvailable,
+		Overall:   senderAmount,
 	})
 
+	if r.Transfer.RootHash != "" {
+		tc.cache.UTXO.Remove(r.Transfer.RootHash)
+	}
+
 	for _, item := range r.Transfer.Receives {
+		//Move utxo ownership to the receiver
+		if item.SN != "" {
+			tc.cache.UTXO.Add(r.MD.Protocol, r.MD.Tick, r.Transfer.RootHash, item.Address, item.Amount, item.SN)
+		}
+
 		ok, receiveBalance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, item.Address)
 		if !ok {
 			holders++
 
-			receiveAmount := item.Amount
 			tc.cache.Balance.Create(r.MD.Protocol, r.MD.Tick, item.Address, &dcache.BalanceItem{
-				Overall: receiveAmount,
+				Available: item.Amount,
+				Overall:   item.Amount,
 			})
 
 			//mark minter init
@@ -131,11 +192,115 @@ func (tc *TxResultHandler) updateTransferCache(r *TxResult) {
 				holders++
 			}
 
-			receiveAmount := receiveBalance.Overall.Add(item.Amount)
 			tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, item.Address, &dcache.BalanceItem{
-				Overall: receiveAmount,
+				Available: receiveBalance.Available.Add(item.Amount),
+				Overall:   receiveBalance.Overall.Add(item.Amount),
+			})
+		}
+	}
+
+	if holders == 0 {
+		return
+	}
+	tc.cache.InscriptionStats.Holders(r.MD.Protocol, r.MD.Tick, holders)
+}
+
+func (tc *TxResultHandler) updateInscribeTransferCache(r *TxResult) {
+	//Update transfer stats
+	tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
+
+	//Move inscribed amount from available to transferable balance
+	it := r.InscribeTransfer
+	_, balance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, it.Address)
+	tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, it.Address, &dcache.BalanceItem{
+		Available: balance.Available.Sub(it.Amount),
+		Overall:   balance.Overall,
+	})
+
+	//Record pending transfer inscription
+	tc.cache.UTXO.Add(r.MD.Protocol, r.MD.Tick, r.Tx.Hash, it.Address, it.Amount, it.SN)
+}
+
+func (tc *TxResultHandler) updateEthscriptionCache(r *TxResult) {
+	e := r.Ethscription
+	if e.Create {
+		tc.cache.Ethscription.Create(e.ID, e.ContentSha, &dcache.EthscriptionItem{
+			Creator:       e.From,
+			Owner:         e.To,
+			PreviousOwner: e.From,
+		})
+		return
+	}
+	tc.cache.Ethscription.Transfer(e.ID, e.To)
+}
+
+func (tc *TxResultHandler) updateEtchingCache(r *TxResult) {
+	e := r.Etching
+	tc.cache.Rune.Create(&dcache.RuneItem{
+		ID:           e.RuneID,
+		Tick:         r.MD.Tick,
+		Divisibility: e.Divisibility,
+		Premine:      e.Premine,
+		Amount:       e.Amount,
+		Cap:          e.Cap,
+		HeightStart:  e.HeightStart,
+		HeightEnd:    e.HeightEnd,
+		OffsetStart:  e.OffsetStart,
+		OffsetEnd:    e.OffsetEnd,
+		Block:        r.Block.Number.Uint64(),
+	})
+}
+
+func (tc *TxResultHandler) updateUTXOTransferCache(r *TxResult) {
+	ut := r.UTXOTransfer
+
+	//Update transfer stats, the deploy tx is counted by the new tick stats
+	if r.Deploy == nil {
+		tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
+	}
+
+	if ut.Minted.GreaterThan(decimal.Zero) {
+		tc.cache.InscriptionStats.Mint(r.MD.Protocol, r.MD.Tick, ut.Minted)
+	}
+
+	//Move balances from the spent utxos to the created ones
+	for _, item := range ut.Spends {
+		tc.cache.UTXO.Remove(item.RootHash)
+	}
+
+	for _, item := range ut.Creates {
+		tc.cache.UTXO.Add(r.MD.Protocol, r.MD.Tick, item.RootHash, item.Address, item.Amount, item.SN)
+	}
+
+	holders := int64(0)
+	for _, item := range ut.Changes {
+		ok, balance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, item.Address)
+		if !ok {
+			if item.Amount.GreaterThan(decimal.Zero) {
+				holders++
+			}
+
+			tc.cache.Balance.Create(r.MD.Protocol, r.MD.Tick, item.Address, &dcache.BalanceItem{
+				Available: item.Amount,
+				Overall:   item.Amount,
 			})
+
+			//mark receiver init
+			item.Init = true
+			continue
+		}
+
+		overall := balance.Overall.Add(item.Amount)
+		if balance.Overall.LessThanOrEqual(decimal.Zero) && overall.GreaterThan(decimal.Zero) {
+			holders++
+		} else if balance.Overall.GreaterThan(decimal.Zero) && overall.LessThanOrEqual(decimal.Zero) {
+			holders--
 		}
+
+		tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, item.Address, &dcache.BalanceItem{
+			Available: balance.Available.Add(item.Amount),
+			Overall:   overall,
+		})
 	}
 
 	if holders == 0 {
@@ -143,3 +308,45 @@ func (tc *TxResultHandler) updateTransferCache(r *TxResult) {
 	}
 	tc.cache.InscriptionStats.Holders(r.MD.Protocol, r.MD.Tick, holders)
 }
+
+func (tc *TxResultHandler) updateListCache(r *TxResult) {
+	//Update list stats
+	tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
+
+	//Escrow listed amount out of the available balance
+	l := r.List
+	_, balance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, l.Seller)
+	tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, l.Seller, &dcache.BalanceItem{
+		Available: balance.Available.Sub(l.Amount),
+		Overall:   balance.Overall,
+	})
+
+	tc.cache.Order.Create(&dcache.OrderItem{
+		ListID:   l.ListID,
+		Protocol: r.MD.Protocol,
+		Tick:     r.MD.Tick,
+		Seller:   l.Seller,
+		Market:   l.Market,
+		Amount:   l.Amount,
+		Price:    l.Price,
+	})
+}
+
+func (tc *TxResultHandler) updateSettleCache(r *TxResult) {
+	st := r.Settle
+	tc.cache.Order.Remove(st.ListID)
+
+	// the fill settles the escrow by the transfer to the buyer
+	if st.Buyer != "" {
+		return
+	}
+
+	//Update delist stats & return the escrow to the seller
+	tc.cache.InscriptionStats.TxCnt(r.MD.Protocol, r.MD.Tick, 1)
+
+	_, balance := tc.cache.Balance.Get(r.MD.Protocol, r.MD.Tick, st.Seller)
+	tc.cache.Balance.Update(r.MD.Protocol, r.MD.Tick, st.Seller, &dcache.BalanceItem{
+		Available: balance.Available.Add(st.Amount),
+		Overall:   balance.Overall,
+	})
+}
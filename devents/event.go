@@ -24,6 +24,7 @@ package devents
 
 import (
 	"context"
+	"errors"
 	"github.com/uxuycom/indexer/storage"
 	"github.com/uxuycom/indexer/xylog"
 	"gorm.io/gorm"
@@ -37,6 +38,7 @@ type Event struct {
 	BlockTime uint64
 	BlockHash string
 	Items     []*DBModelEvent
+	Revert    *Revert
 }
 
 type DEvent struct {
@@ -133,13 +135,41 @@ func (h *DEvent) Sink(db *storage.DBClient) bool {
 	// Add random sleep to avoid db lock contention
 	<-time.After(time.Millisecond * time.Duration(rand.Intn(10)))
 
-	dm := BuildDBUpdateModel(events)
-	chain := dm.BlockStatus.Chain
-
 	// fetch db lock
 	h.getDBLockTillSuccess(db)
 	defer h.releaseDBLock(db)
 
+	// revert events must be applied in order, split the events by them
+	batch := make([]*Event, 0, len(events))
+	for _, event := range events {
+		if event.Revert == nil {
+			batch = append(batch, event)
+			continue
+		}
+
+		if !h.sinkEvents(db, batch) {
+			event.Revert.done <- errors.New("flush db error before reverting")
+			return false
+		}
+		batch = batch[:0]
+
+		err := h.sinkRevert(db, event)
+		event.Revert.done <- err
+		if err != nil {
+			return false
+		}
+	}
+	return h.sinkEvents(db, batch)
+}
+
+func (h *DEvent) sinkEvents(db *storage.DBClient, events []*Event) bool {
+	if len(events) < 1 {
+		return true
+	}
+
+	dm := BuildDBUpdateModel(events)
+	chain := dm.BlockStatus.Chain
+
 	startTs := time.Now()
 	err := db.SqlDB.Transaction(func(tx *gorm.DB) error {
 		// insert inscriptions
@@ -244,6 +274,72 @@ func (h *DEvent) Sink(db *storage.DBClient) bool {
 			}
 		}
 
+		// add pending utxos before spending, utxos may be created & spent in the same batch
+		if items := dm.UTXOs[DBActionCreate]; len(items) > 0 {
+			if err := db.BatchAddUTXOs(tx, items); err != nil {
+				xylog.Logger.Errorf("failed insert utxos records. err=%s", err)
+				return err
+			}
+		}
+
+		if items := dm.UTXOs[DBActionUpdate]; len(items) > 0 {
+			if err := db.BatchSpendUTXOs(tx, chain, items); err != nil {
+				xylog.Logger.Errorf("failed spend utxos records. err=%s", err)
+				return err
+			}
+		}
+
+		// add created ethscriptions before moving owners, they may be created & transferred in the same batch
+		if items := dm.Ethscriptions[DBActionCreate]; len(items) > 0 {
+			if err := db.BatchAddEthscriptions(tx, items); err != nil {
+				xylog.Logger.Errorf("failed insert ethscriptions records. err=%s", err)
+				return err
+			}
+		}
+
+		if items := dm.Ethscriptions[DBActionUpdate]; len(items) > 0 {
+			if err := db.BatchUpdateEthscriptionOwners(tx, chain, items); err != nil {
+				xylog.Logger.Errorf("failed update ethscriptions owners. err=%s", err)
+				return err
+			}
+		}
+
+		if len(dm.EthscriptionTransfers) > 0 {
+			if err := db.BatchAddEthscriptionTransfers(tx, dm.EthscriptionTransfers); err != nil {
+				xylog.Logger.Errorf("failed insert ethscription transfers records. err=%s", err)
+				return err
+			}
+		}
+
+		if len(dm.Runes) > 0 {
+			if err := db.BatchAddRunes(tx, dm.Runes); err != nil {
+				xylog.Logger.Errorf("failed insert runes records. err=%s", err)
+				return err
+			}
+		}
+
+		// add listed orders before settling, orders may be listed & settled in the same batch
+		if items := dm.Orders[DBActionCreate]; len(items) > 0 {
+			if err := db.BatchAddListOrders(tx, items); err != nil {
+				xylog.Logger.Errorf("failed insert list orders records. err=%s", err)
+				return err
+			}
+		}
+
+		if items := dm.Orders[DBActionUpdate]; len(items) > 0 {
+			if err := db.BatchSettleListOrders(tx, chain, items); err != nil {
+				xylog.Logger.Errorf("failed settle list orders records. err=%s", err)
+				return err
+			}
+		}
+
+		if len(dm.Trades) > 0 {
+			if err := db.BatchAddTrades(tx, dm.Trades); err != nil {
+				xylog.Logger.Errorf("failed insert trades records. err=%s", err)
+				return err
+			}
+		}
+
 		// record block status
 		if err := db.SaveLastBlock(tx, dm.BlockStatus); err != nil {
 			xylog.Logger.Errorf("failed to save block information. err=%s", err)
@@ -43,16 +43,34 @@ type DBModelEvent struct {
 	Balances         map[DBAction][]*model.Balances
 	AddressTxs       []*model.AddressTxs
 	BalanceTxs       []*model.BalanceTxn
+	UTXOs            map[DBAction][]*model.UTXO
+
+	Ethscriptions         map[DBAction][]*model.Ethscription
+	EthscriptionTransfers []*model.EthscriptionTransfer
+
+	Rune   *model.Rune
+	Orders map[DBAction][]*model.ListOrder
+	Trade  *model.Trade
 }
 
 func (tc *TxResultHandler) BuildModel(r *TxResult) *DBModelEvent {
 	dm := &DBModelEvent{}
 
+	// ethscriptions are not tick based, only the ownership records are built
+	if r.Ethscription != nil {
+		dm.Ethscriptions, dm.EthscriptionTransfers = tc.BuildEthscription(r)
+		return dm
+	}
+
 	dm.Tx = tc.BuildTx(r)
 	dm.Inscriptions = tc.BuildInscription(r)
 	dm.InscriptionStats = tc.BuildInscriptionStat(r)
 	dm.BalanceTxs, dm.Balances = tc.BuildBalance(r)
 	dm.AddressTxs = tc.BuildAddressTxs(r)
+	dm.UTXOs = tc.BuildUTXOs(r)
+	dm.Rune = tc.BuildRune(r)
+	dm.Orders = tc.BuildOrders(r)
+	dm.Trade = tc.BuildTrade(r)
 	return dm
 }
 
@@ -75,6 +93,15 @@ func (tc *TxResultHandler) BuildInscription(e *TxResult) map[DBAction]*model.Ins
 		DeployHash:   e.Tx.Hash,
 		DeployTime:   time.Unix(int64(e.Block.Time), 0),
 		Decimals:     e.Deploy.Decimal,
+		TransferType: e.Deploy.TransferType,
+
+		StrictDecimals: e.Deploy.StrictDecimals,
+
+		AddressMintLimit: e.Deploy.AddressMintLimit,
+		MintStartBlock:   e.Deploy.MintStartBlock,
+		MintEndBlock:     e.Deploy.MintEndBlock,
+		Premine:          e.Deploy.Premine,
+		SelfMint:         e.Deploy.SelfMint,
 	}
 	return ret
 }
@@ -93,15 +120,22 @@ func (tc *TxResultHandler) BuildInscriptionStat(e *TxResult) map[DBAction]*model
 	}
 
 	// update mint stats
+	minted := decimal.Zero
 	if e.Mint != nil {
+		minted = e.Mint.Amount
+	} else if e.UTXOTransfer != nil {
+		minted = e.UTXOTransfer.Minted
+	}
+
+	if minted.GreaterThan(decimal.Zero) {
 		// first mint block record
-		if d.Minted.Equal(e.Mint.Amount) {
+		if d.Minted.Equal(minted) {
 			data.MintFirstBlock = e.Block.Number.Uint64()
 		}
 
-		// final mint block record
+		// final mint block record, uncapped ticks e.g. erc-20 tokens never complete
 		_, inscription := tc.cache.Inscription.Get(e.MD.Protocol, e.MD.Tick)
-		if inscription.TotalSupply.LessThanOrEqual(d.Minted) {
+		if inscription.TotalSupply.GreaterThan(decimal.Zero) && inscription.TotalSupply.LessThanOrEqual(d.Minted) {
 			data.MintLastBlock = e.Block.Number.Uint64()
 
 			ts := time.Unix(int64(e.Block.Time), 0)
@@ -127,7 +161,9 @@ type AddressTxEvent struct {
 
 func (tc *TxResultHandler) BuildAddressTxEvents(e *TxResult) []*AddressTxEvent {
 	items := make([]*AddressTxEvent, 0, 10)
-	if e.Deploy != nil {
+
+	// the premine mint records the deployer
+	if e.Deploy != nil && e.Mint == nil {
 		items = append(items, &AddressTxEvent{
 			Address: e.Tx.From,
 			Amount:  decimal.Zero,
@@ -136,11 +172,18 @@ func (tc *TxResultHandler) BuildAddressTxEvents(e *TxResult) []*AddressTxEvent {
 
 	if e.Mint != nil {
 		items = append(items, &AddressTxEvent{
-			Address: e.Tx.To,
+			Address: e.Mint.Minter,
 			Amount:  e.Mint.Amount,
 		})
 	}
 
+	if e.InscribeTransfer != nil {
+		items = append(items, &AddressTxEvent{
+			Address: e.InscribeTransfer.Address,
+			Amount:  e.InscribeTransfer.Amount,
+		})
+	}
+
 	if e.Transfer != nil {
 		sendTotalAmount := decimal.Zero
 		for _, item := range e.Transfer.Receives {
@@ -159,6 +202,30 @@ func (tc *TxResultHandler) BuildAddressTxEvents(e *TxResult) []*AddressTxEvent {
 			})
 		}
 	}
+
+	if e.UTXOTransfer != nil {
+		for _, item := range e.UTXOTransfer.Changes {
+			items = append(items, &AddressTxEvent{
+				Address: item.Address,
+				Amount:  item.Amount.Abs(),
+			})
+		}
+	}
+
+	if e.List != nil {
+		items = append(items, &AddressTxEvent{
+			Address: e.List.Seller,
+			Amount:  e.List.Amount,
+		})
+	}
+
+	// the fill is recorded by the settling transfer
+	if e.Settle != nil && e.Settle.Buyer == "" {
+		items = append(items, &AddressTxEvent{
+			Address: e.Settle.Seller,
+			Amount:  e.Settle.Amount,
+		})
+	}
 	return items
 }
 
@@ -226,6 +293,18 @@ func (tc *TxResultHandler) BuildBalanceTxEvents(e *TxResult) []BalanceTxEvent {
 		})
 	}
 
+	if e.InscribeTransfer != nil {
+		_, balance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.InscribeTransfer.Address)
+		items = append(items, BalanceTxEvent{
+			Action:           DBActionUpdate,
+			SID:              balance.SID,
+			Address:          e.InscribeTransfer.Address,
+			Amount:           e.InscribeTransfer.Amount.Neg(),
+			AvailableBalance: balance.Available,
+			OverallBalance:   balance.Overall,
+		})
+	}
+
 	if e.Transfer != nil {
 		sendTotalAmount := decimal.Zero
 		for _, item := range e.Transfer.Receives {
@@ -258,6 +337,48 @@ func (tc *TxResultHandler) BuildBalanceTxEvents(e *TxResult) []BalanceTxEvent {
 			})
 		}
 	}
+
+	if e.UTXOTransfer != nil {
+		for _, item := range e.UTXOTransfer.Changes {
+			_, balance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, item.Address)
+			action := DBActionUpdate
+			if item.Init {
+				action = DBActionCreate
+			}
+			items = append(items, BalanceTxEvent{
+				Action:           action,
+				SID:              balance.SID,
+				Address:          item.Address,
+				Amount:           item.Amount,
+				AvailableBalance: balance.Available,
+				OverallBalance:   balance.Overall,
+			})
+		}
+	}
+
+	if e.List != nil {
+		_, balance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.List.Seller)
+		items = append(items, BalanceTxEvent{
+			Action:           DBActionUpdate,
+			SID:              balance.SID,
+			Address:          e.List.Seller,
+			Amount:           e.List.Amount.Neg(),
+			AvailableBalance: balance.Available,
+			OverallBalance:   balance.Overall,
+		})
+	}
+
+	if e.Settle != nil && e.Settle.Buyer == "" {
+		_, balance := tc.cache.Balance.Get(e.MD.Protocol, e.MD.Tick, e.Settle.Seller)
+		items = append(items, BalanceTxEvent{
+			Action:           DBActionUpdate,
+			SID:              balance.SID,
+			Address:          e.Settle.Seller,
+			Amount:           e.Settle.Amount,
+			AvailableBalance: balance.Available,
+			OverallBalance:   balance.Overall,
+		})
+	}
 	return items
 }
 
@@ -295,6 +416,213 @@ func (tc *TxResultHandler) BuildBalance(e *TxResult) (txns []*model.BalanceTxn,
 	return txns, balances
 }
 
+func (tc *TxResultHandler) BuildUTXOs(e *TxResult) map[DBAction][]*model.UTXO {
+	utxos := make(map[DBAction][]*model.UTXO, 2)
+	if e.Mint != nil && e.Mint.SN != "" {
+		utxos[DBActionCreate] = append(utxos[DBActionCreate], tc.buildUTXO(e, e.Mint.SN, e.Tx.Hash, e.Mint.Minter, e.Mint.Amount))
+	}
+
+	if e.InscribeTransfer != nil {
+		it := e.InscribeTransfer
+		utxos[DBActionCreate] = append(utxos[DBActionCreate], tc.buildUTXO(e, it.SN, e.Tx.Hash, it.Address, it.Amount))
+	}
+
+	if e.Transfer != nil && e.Transfer.RootHash != "" {
+		utxos[DBActionUpdate] = append(utxos[DBActionUpdate], &model.UTXO{
+			Sn:        e.Transfer.SN,
+			Chain:     e.MD.Chain,
+			RootHash:  e.Transfer.RootHash,
+			SpentHash: e.Tx.Hash,
+			Status:    model.UTXOStatusSpent,
+		})
+
+		for _, item := range e.Transfer.Receives {
+			if item.SN == "" {
+				continue
+			}
+			utxos[DBActionCreate] = append(utxos[DBActionCreate], tc.buildUTXO(e, item.SN, e.Transfer.RootHash, item.Address, item.Amount))
+		}
+	}
+
+	if e.UTXOTransfer != nil {
+		for _, item := range e.UTXOTransfer.Spends {
+			utxos[DBActionUpdate] = append(utxos[DBActionUpdate], &model.UTXO{
+				Sn:        item.SN,
+				Chain:     e.MD.Chain,
+				RootHash:  item.RootHash,
+				SpentHash: e.Tx.Hash,
+				Status:    model.UTXOStatusSpent,
+			})
+		}
+
+		for _, item := range e.UTXOTransfer.Creates {
+			utxos[DBActionCreate] = append(utxos[DBActionCreate], tc.buildUTXO(e, item.SN, item.RootHash, item.Address, item.Amount))
+		}
+	}
+	return utxos
+}
+
+func (tc *TxResultHandler) buildUTXO(e *TxResult, sn, rootHash, address string, amount decimal.Decimal) *model.UTXO {
+	return &model.UTXO{
+		Sn:        sn,
+		Chain:     e.MD.Chain,
+		Protocol:  e.MD.Protocol,
+		Address:   address,
+		Tick:      e.MD.Tick,
+		Amount:    amount,
+		RootHash:  rootHash,
+		TxHash:    e.Tx.Hash,
+		Status:    model.UTXOStatusUnspent,
+		CreatedAt: time.Unix(int64(e.Block.Time), 0),
+		UpdatedAt: time.Unix(int64(e.Block.Time), 0),
+	}
+}
+
+func (tc *TxResultHandler) BuildRune(e *TxResult) *model.Rune {
+	if e.Etching == nil {
+		return nil
+	}
+
+	et := e.Etching
+	return &model.Rune{
+		Chain:        e.MD.Chain,
+		RuneID:       et.RuneID,
+		Tick:         e.MD.Tick,
+		SpacedName:   et.SpacedName,
+		Symbol:       et.Symbol,
+		Divisibility: et.Divisibility,
+		Premine:      et.Premine,
+		Amount:       et.Amount,
+		Cap:          et.Cap,
+		HeightStart:  et.HeightStart,
+		HeightEnd:    et.HeightEnd,
+		OffsetStart:  et.OffsetStart,
+		OffsetEnd:    et.OffsetEnd,
+		Turbo:        et.Turbo,
+		Cenotaph:     et.Cenotaph,
+		TxHash:       e.Tx.Hash,
+		BlockNumber:  e.Block.Number.Uint64(),
+		CreatedAt:    time.Unix(int64(e.Block.Time), 0),
+	}
+}
+
+func (tc *TxResultHandler) BuildOrders(e *TxResult) map[DBAction][]*model.ListOrder {
+	orders := make(map[DBAction][]*model.ListOrder, 1)
+	ts := time.Unix(int64(e.Block.Time), 0)
+	if e.List != nil {
+		orders[DBActionCreate] = append(orders[DBActionCreate], &model.ListOrder{
+			Chain:       e.MD.Chain,
+			Protocol:    e.MD.Protocol,
+			Tick:        e.MD.Tick,
+			ListID:      e.List.ListID,
+			Seller:      e.List.Seller,
+			Market:      e.List.Market,
+			Amount:      e.List.Amount,
+			Price:       e.List.Price,
+			Status:      model.OrderStatusOpen,
+			BlockNumber: e.Block.Number.Uint64(),
+			CreatedAt:   ts,
+			UpdatedAt:   ts,
+		})
+	}
+
+	if e.Settle != nil {
+		status := int8(model.OrderStatusFilled)
+		if e.Settle.Buyer == "" {
+			status = model.OrderStatusCanceled
+		}
+		orders[DBActionUpdate] = append(orders[DBActionUpdate], &model.ListOrder{
+			Chain:      e.MD.Chain,
+			ListID:     e.Settle.ListID,
+			Status:     status,
+			Buyer:      e.Settle.Buyer,
+			Price:      e.Settle.Price,
+			SettleHash: e.Tx.Hash,
+			UpdatedAt:  ts,
+		})
+	}
+	return orders
+}
+
+func (tc *TxResultHandler) BuildTrade(e *TxResult) *model.Trade {
+	if e.Trade == nil {
+		return nil
+	}
+
+	td := e.Trade
+	unitPrice := decimal.Zero
+	if td.Amount.GreaterThan(decimal.Zero) {
+		unitPrice = td.Price.DivRound(td.Amount, 18)
+	}
+
+	ts := time.Unix(int64(e.Block.Time), 0)
+	return &model.Trade{
+		Chain:       e.MD.Chain,
+		Protocol:    e.MD.Protocol,
+		Tick:        e.MD.Tick,
+		TxHash:      e.Tx.Hash,
+		ListID:      td.ListID,
+		Seller:      td.Seller,
+		Buyer:       td.Buyer,
+		Market:      td.Market,
+		Amount:      td.Amount,
+		Price:       td.Price,
+		UnitPrice:   unitPrice,
+		BlockNumber: e.Block.Number.Uint64(),
+		BlockTime:   ts,
+		CreatedAt:   ts,
+	}
+}
+
+func (tc *TxResultHandler) BuildEthscription(e *TxResult) (map[DBAction][]*model.Ethscription, []*model.EthscriptionTransfer) {
+	es := e.Ethscription
+	ts := time.Unix(int64(e.Block.Time), 0)
+	transfers := []*model.EthscriptionTransfer{
+		{
+			Chain:          e.MD.Chain,
+			EthscriptionID: es.ID,
+			TxHash:         e.Tx.Hash,
+			From:           es.From,
+			To:             es.To,
+			BlockNumber:    e.Block.Number.Uint64(),
+			LogIndex:       es.LogIndex,
+			CreatedAt:      ts,
+		},
+	}
+
+	if es.Create {
+		return map[DBAction][]*model.Ethscription{
+			DBActionCreate: {
+				{
+					Chain:          e.MD.Chain,
+					EthscriptionID: es.ID,
+					Creator:        es.From,
+					InitialOwner:   es.To,
+					CurrentOwner:   es.To,
+					PreviousOwner:  es.From,
+					ContentSha:     es.ContentSha,
+					MimeType:       es.MimeType,
+					BlockNumber:    e.Block.Number.Uint64(),
+					CreatedAt:      ts,
+					UpdatedAt:      ts,
+				},
+			},
+		}, transfers
+	}
+
+	return map[DBAction][]*model.Ethscription{
+		DBActionUpdate: {
+			{
+				Chain:          e.MD.Chain,
+				EthscriptionID: es.ID,
+				CurrentOwner:   es.To,
+				PreviousOwner:  es.From,
+				UpdatedAt:      ts,
+			},
+		},
+	}, transfers
+}
+
 func (tc *TxResultHandler) BuildTx(e *TxResult) *model.Transaction {
 	return &model.Transaction{
 		Chain:           e.MD.Chain,
@@ -319,7 +647,15 @@ type DBModelsFattened struct {
 	Txs              []*model.Transaction
 	AddressTxs       []*model.AddressTxs
 	BalanceTxs       []*model.BalanceTxn
+	UTXOs            map[DBAction][]*model.UTXO
 	BlockStatus      *model.BlockStatus
+
+	Ethscriptions         map[DBAction][]*model.Ethscription
+	EthscriptionTransfers []*model.EthscriptionTransfer
+
+	Runes  []*model.Rune
+	Orders map[DBAction][]*model.ListOrder
+	Trades []*model.Trade
 }
 
 type DBModels struct {
@@ -329,6 +665,14 @@ type DBModels struct {
 	Txs              map[string]*model.Transaction
 	AddressTxs       []*model.AddressTxs
 	BalanceTxs       []*model.BalanceTxn
+	UTXOs            map[DBAction][]*model.UTXO
+
+	Ethscriptions         map[DBAction][]*model.Ethscription
+	EthscriptionTransfers []*model.EthscriptionTransfer
+
+	Runes  []*model.Rune
+	Orders map[DBAction][]*model.ListOrder
+	Trades []*model.Trade
 }
 
 func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
@@ -348,6 +692,21 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 		Txs:        make(map[string]*model.Transaction, len(blocksEvents)*2),
 		AddressTxs: make([]*model.AddressTxs, 0, len(blocksEvents)*2),
 		BalanceTxs: make([]*model.BalanceTxn, 0, len(blocksEvents)*2),
+		UTXOs: map[DBAction][]*model.UTXO{
+			DBActionCreate: make([]*model.UTXO, 0, len(blocksEvents)),
+			DBActionUpdate: make([]*model.UTXO, 0, len(blocksEvents)),
+		},
+		Ethscriptions: map[DBAction][]*model.Ethscription{
+			DBActionCreate: make([]*model.Ethscription, 0, len(blocksEvents)),
+			DBActionUpdate: make([]*model.Ethscription, 0, len(blocksEvents)),
+		},
+		EthscriptionTransfers: make([]*model.EthscriptionTransfer, 0, len(blocksEvents)),
+		Runes:                 make([]*model.Rune, 0),
+		Orders: map[DBAction][]*model.ListOrder{
+			DBActionCreate: make([]*model.ListOrder, 0),
+			DBActionUpdate: make([]*model.ListOrder, 0),
+		},
+		Trades: make([]*model.Trade, 0),
 	}
 	for _, blockEvent := range blocksEvents {
 		for _, event := range blockEvent.Items {
@@ -376,11 +735,13 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 				dm.InscriptionStats[action][item.SID] = item
 			}
 
-			txIdx := event.Tx.TxHash
-			if _, ok := dm.Txs[txIdx]; ok {
-				xylog.Logger.Debugf("tx[%s] exist & force update", txIdx)
+			if event.Tx != nil {
+				txIdx := event.Tx.TxHash
+				if _, ok := dm.Txs[txIdx]; ok {
+					xylog.Logger.Debugf("tx[%s] exist & force update", txIdx)
+				}
+				dm.Txs[txIdx] = event.Tx
 			}
-			dm.Txs[txIdx] = event.Tx
 
 			if len(event.AddressTxs) > 0 {
 				dm.AddressTxs = append(dm.AddressTxs, event.AddressTxs...)
@@ -390,6 +751,30 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 				dm.BalanceTxs = append(dm.BalanceTxs, event.BalanceTxs...)
 			}
 
+			// utxos keep the tx order, one utxo may be created & spent in the same batch
+			for action, items := range event.UTXOs {
+				dm.UTXOs[action] = append(dm.UTXOs[action], items...)
+			}
+
+			// ownership changes keep the tx & log order
+			for action, items := range event.Ethscriptions {
+				dm.Ethscriptions[action] = append(dm.Ethscriptions[action], items...)
+			}
+			dm.EthscriptionTransfers = append(dm.EthscriptionTransfers, event.EthscriptionTransfers...)
+
+			if event.Rune != nil {
+				dm.Runes = append(dm.Runes, event.Rune)
+			}
+
+			// orders may be listed & settled in the same batch
+			for action, items := range event.Orders {
+				dm.Orders[action] = append(dm.Orders[action], items...)
+			}
+
+			if event.Trade != nil {
+				dm.Trades = append(dm.Trades, event.Trade)
+			}
+
 			for action, items := range event.Balances {
 				for _, item := range items {
 					if _, ok := dm.Balances[action][item.SID]; ok {
@@ -425,7 +810,15 @@ func BuildDBUpdateModel(blocksEvents []*Event) (dmf *DBModelsFattened) {
 		Txs:         make([]*model.Transaction, 0, len(dm.Txs)),
 		AddressTxs:  dm.AddressTxs,
 		BalanceTxs:  dm.BalanceTxs,
+		UTXOs:       dm.UTXOs,
 		BlockStatus: bs,
+
+		Ethscriptions:         dm.Ethscriptions,
+		EthscriptionTransfers: dm.EthscriptionTransfers,
+
+		Runes:  dm.Runes,
+		Orders: dm.Orders,
+		Trades: dm.Trades,
 	}
 
 	// flatten tx
@@ -0,0 +1,94 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package devents
+
+import (
+	"context"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/storage"
+	"github.com/uxuycom/indexer/xylog"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Revert rolls back all indexed data above the fork block
+type Revert struct {
+	ForkBlock uint64
+	ForkHash  string
+	ForkTime  uint64
+	done      chan error
+}
+
+// RevertDB queues a revert event behind all pending events & waits until it is flushed
+func (h *DEvent) RevertDB(ctx context.Context, chain string, forkBlock uint64, forkHash string, forkTime uint64) error {
+	revert := &Revert{
+		ForkBlock: forkBlock,
+		ForkHash:  forkHash,
+		ForkTime:  forkTime,
+		done:      make(chan error, 1),
+	}
+
+	h.events <- &Event{
+		Chain:     chain,
+		BlockNum:  forkBlock,
+		BlockTime: forkTime,
+		BlockHash: forkHash,
+		Revert:    revert,
+	}
+
+	select {
+	case err := <-revert.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *DEvent) sinkRevert(db *storage.DBClient, event *Event) error {
+	startTs := time.Now()
+	err := db.SqlDB.Transaction(func(tx *gorm.DB) error {
+		if err := db.RevertBlocks(tx, event.Chain, event.Revert.ForkBlock); err != nil {
+			xylog.Logger.Errorf("failed to revert blocks. err=%s", err)
+			return err
+		}
+
+		bs := &model.BlockStatus{
+			Chain:       event.Chain,
+			BlockHash:   event.Revert.ForkHash,
+			BlockNumber: event.Revert.ForkBlock,
+			BlockTime:   time.Unix(int64(event.Revert.ForkTime), 0),
+		}
+		if err := db.SaveLastBlock(tx, bs); err != nil {
+			xylog.Logger.Errorf("failed to save block information. err=%s", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		xylog.Logger.Errorf("revert db error. fork block[%d], err=%s, cost:%v", event.Revert.ForkBlock, err, time.Since(startTs))
+		return err
+	}
+	xylog.Logger.Infof("revert db success, fork block[%d], cost:%v", event.Revert.ForkBlock, time.Since(startTs))
+	return nil
+}
@@ -34,6 +34,7 @@ const (
 	OperateList     string = "list"
 	OperateDelist   string = "delist"
 	OperateExchange string = "exchange"
+	OperateCreate   string = "create"
 )
 
 type MetaData struct {
@@ -42,6 +43,11 @@ type MetaData struct {
 	Operate  string `json:"op"`
 	Tick     string `json:"tick"`
 	Data     string
+	Size     int `json:"-"` // bytes of the inscription envelope as inscribed, before decoding
+
+	// Legacy the metadata decoded by the legacy data uri rules if they decode the uri differently, empty if
+	// they reject it, the data is left empty if RFC 2397 rejects it. The protocol rules pick one by the tx height
+	Legacy *MetaData `json:"-"`
 }
 
 func (original *MetaData) Copy() *MetaData {
@@ -51,38 +57,146 @@ func (original *MetaData) Copy() *MetaData {
 		Operate:  original.Operate,
 		Tick:     original.Tick,
 		Data:     original.Data,
+		Size:     original.Size,
 	}
 }
 
 type Deploy struct {
-	Name      string
-	MaxSupply decimal.Decimal
-	MintLimit decimal.Decimal
-	Decimal   int8
+	Name         string
+	MaxSupply    decimal.Decimal
+	MintLimit    decimal.Decimal
+	Decimal      int8
+	TransferType int8
+
+	// amounts must fit the decimals, fixed by the rules in force at the deploy height
+	StrictDecimals bool
+
+	// extended deploy parameters, zero values leave the minting unrestricted
+	AddressMintLimit decimal.Decimal
+	MintStartBlock   uint64
+	MintEndBlock     uint64
+	Premine          decimal.Decimal
+	SelfMint         bool
 }
 
 type Mint struct {
-	Minter string
-	Amount decimal.Decimal
-	Init   bool
+	Minter  string
+	Amount  decimal.Decimal
+	Init    bool
+	SN      string // utxo created by the mint of the hash transfer type tick
+	Premine bool   // minted to the deployer on deploy, not counted by the mint limit per address
 }
 
 type Receive struct {
 	Address string
 	Amount  decimal.Decimal
 	Init    bool
+	SN      string // utxo created for the receiver of the hash transfer
 }
 
 type Transfer struct {
 	Sender   string
 	Receives []*Receive
+
+	RootHash     string // root hash of the utxo spent by the transfer, empty for the balance transfer
+	SN           string // sn of the utxo spent by the transfer
+	Transferable bool   // transfer settles the transferable balance reserved by the inscribed utxo
+}
+
+// InscribeTransfer reserves the available balance of the address into a pending transfer utxo
+type InscribeTransfer struct {
+	Address string
+	Amount  decimal.Decimal
+	SN      string
+}
+
+// List escrows the available balance of the seller into the open order of the market
+type List struct {
+	ListID string
+	Seller string
+	Market string
+	Amount decimal.Decimal
+	Price  decimal.Decimal
+}
+
+// Settle closes the open order, the escrowed balance is sent to the buyer by the fill
+// with the settling transfer or returned to the seller by the delist
+type Settle struct {
+	ListID string
+	Seller string
+	Buyer  string // empty for the delist
+	Amount decimal.Decimal
+	Price  decimal.Decimal
+}
+
+// Trade records the fill of the market order, the price is paid in the native coin for the whole amount
+type Trade struct {
+	ListID string
+	Seller string
+	Buyer  string
+	Market string
+	Amount decimal.Decimal
+	Price  decimal.Decimal
+}
+
+// Ethscription moves the ethscription to the new owner, the creation moves it from the creator to the initial owner
+type Ethscription struct {
+	ID         string
+	From       string
+	To         string
+	Create     bool
+	ContentSha string // sha256 of the content uri, set for the creation only
+	MimeType   string
+	LogIndex   int64 // index of the esip event log, -1 for the creation & calldata transfers
+}
+
+// UTXOBalance amount of the tick bound to one utxo
+type UTXOBalance struct {
+	RootHash string // unique key of the balance, one utxo may carry several ticks
+	SN       string // outpoint of the utxo
+	Address  string
+	Amount   decimal.Decimal
+}
+
+// UTXOTransfer moves the balances bound to the spent utxos into the created ones,
+// the minted amount is created with them & the unallocated rest is burned
+type UTXOTransfer struct {
+	Spends  []*UTXOBalance
+	Creates []*UTXOBalance
+	Minted  decimal.Decimal
+	Burned  decimal.Decimal
+	Changes []*Receive // net balance changes of the addresses, may be negative
+}
+
+// Etching registers the rune etched by the tx, amounts are the raw integers
+type Etching struct {
+	RuneID       string
+	SpacedName   string
+	Symbol       string
+	Divisibility int8
+	Premine      decimal.Decimal
+	Amount       decimal.Decimal
+	Cap          decimal.Decimal
+	HeightStart  *uint64
+	HeightEnd    *uint64
+	OffsetStart  *uint64
+	OffsetEnd    *uint64
+	Turbo        bool
+	Cenotaph     bool
 }
 
 type TxResult struct {
-	MD       *MetaData
-	Block    *xycommon.RpcBlock
-	Tx       *xycommon.RpcTransaction
-	Mint     *Mint
-	Deploy   *Deploy
-	Transfer *Transfer
+	MD               *MetaData
+	Block            *xycommon.RpcBlock
+	Tx               *xycommon.RpcTransaction
+	Mint             *Mint
+	Deploy           *Deploy
+	Transfer         *Transfer
+	InscribeTransfer *InscribeTransfer
+	Ethscription     *Ethscription
+	Etching          *Etching
+	UTXOTransfer     *UTXOTransfer
+	List             *List
+	Settle           *Settle
+	Trade            *Trade
 }
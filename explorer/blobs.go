@@ -0,0 +1,75 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"strings"
+)
+
+// attachBlobs attaches the blobs to the block type-3 txs, skipped without any blob source configured
+func (e *Explorer) attachBlobs(ctx context.Context, block *xycommon.RpcBlock) error {
+	blobTxs := make([]*xycommon.RpcTransaction, 0)
+	for _, tx := range block.Transactions {
+		if len(tx.BlobHashes) > 0 {
+			blobTxs = append(blobTxs, tx)
+		}
+	}
+
+	if len(blobTxs) < 1 {
+		return nil
+	}
+
+	bc, ok := e.node.(xycommon.IBlobClient)
+	if !ok {
+		return nil
+	}
+
+	blobs, err := bc.BlockBlobs(ctx, block.Number, block.Time)
+	if errors.Is(err, xycommon.ErrMethodNotSupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]string, len(blobs))
+	for _, blob := range blobs {
+		data[strings.ToLower(blob.VersionedHash)] = blob.Data
+	}
+
+	for _, tx := range blobTxs {
+		tx.Blobs = make([]string, 0, len(tx.BlobHashes))
+		for _, hash := range tx.BlobHashes {
+			blob, ok := data[strings.ToLower(hash)]
+			if !ok {
+				return fmt.Errorf("blob[%s] of tx[%s] not found", hash, tx.Hash)
+			}
+			tx.Blobs = append(tx.Blobs, blob)
+		}
+	}
+	return nil
+}
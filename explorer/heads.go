@@ -0,0 +1,135 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"errors"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"time"
+)
+
+var (
+	// newHeadTimeout treat the subscription as dropped if no head arrives in time
+	newHeadTimeout = time.Minute
+
+	// pollingFallbackDuration polling duration before re-subscribing
+	pollingFallbackDuration = 10 * time.Second
+)
+
+var errNewHeadTimeout = errors.New("no new head received within timeout")
+
+// subscriptionEnabled push mode is enabled if any endpoint is a websocket one
+func (e *Explorer) subscriptionEnabled() bool {
+	for _, rpc := range e.config.Chain.RpcEndpoints() {
+		if xycommon.IsWebsocketURL(rpc) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchNewHeads keeps the new heads subscription alive,
+// falling back to polling whenever the subscription drops
+func (e *Explorer) watchNewHeads(subscriber xycommon.IHeadSubscriber) {
+	xylog.Logger.Infof("block discovery by new heads subscription. chain:%s", e.config.Chain.ChainName)
+	for {
+		err := e.subscribeNewHeads(subscriber)
+		select {
+		case <-e.ctx.Done():
+			return
+		default:
+		}
+
+		xylog.Logger.Errorf("new heads subscription dropped & fall back to polling. chain:%s err=%v", e.config.Chain.ChainName, err)
+		e.pollLatestBlockNumber(pollingFallbackDuration)
+	}
+}
+
+func (e *Explorer) subscribeNewHeads(subscriber xycommon.IHeadSubscriber) error {
+	heads := make(chan *xycommon.RpcHeader, 64)
+	sub, err := subscriber.SubscribeNewHead(e.ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	// heads missed during the gap are backfilled by scanning up to the latest block
+	num, err := e.node.BlockNumber(e.ctx)
+	if err != nil {
+		return err
+	}
+	e.updateLatestBlockNum(num)
+
+	timer := time.NewTimer(newHeadTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case head := <-heads:
+			if head == nil || head.Number == nil {
+				continue
+			}
+			e.updateLatestBlockNum(head.Number.Uint64())
+			xylog.Logger.Debugf("new head received, number[%d], hash[%s]", head.Number.Uint64(), head.Hash)
+
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(newHeadTimeout)
+		case err = <-sub.Err():
+			return err
+		case <-timer.C:
+			return errNewHeadTimeout
+		case <-e.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// updateLatestBlockNum raise the latest block number & wake up the scanner
+func (e *Explorer) updateLatestBlockNum(num uint64) {
+	for {
+		latest := e.latestBlockNum.Load()
+		if num <= latest {
+			return
+		}
+
+		if e.latestBlockNum.CompareAndSwap(latest, num) {
+			break
+		}
+	}
+
+	select {
+	case e.headNotify <- struct{}{}:
+	default:
+	}
+}
+
+// waitNewHead wait for a new head, or a second at most
+func (e *Explorer) waitNewHead() {
+	select {
+	case <-e.headNotify:
+	case <-time.After(time.Second):
+	case <-e.ctx.Done():
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol"
+	"github.com/uxuycom/indexer/storage"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const headsRpc = "ws://127.0.0.1:8546"
+
+// headsSub is the new heads subscription handed out by the headsNode
+type headsSub struct {
+	heads        chan<- *xycommon.RpcHeader
+	err          chan error
+	unsubscribed atomic.Bool
+}
+
+func (s *headsSub) Unsubscribe() {
+	s.unsubscribed.Store(true)
+}
+
+func (s *headsSub) Err() <-chan error {
+	return s.err
+}
+
+// headsNode pushes new heads through subscriptions, the first failures subscriptions are refused
+type headsNode struct {
+	*chainNode
+	tip        atomic.Uint64
+	failures   atomic.Int32
+	subscribed atomic.Int32
+	subs       chan *headsSub
+}
+
+func newHeadsNode(blocks []*xycommon.RpcBlock, tip uint64) *headsNode {
+	n := &headsNode{
+		chainNode: newChainNode(blocks),
+		subs:      make(chan *headsSub, 16),
+	}
+	n.tip.Store(tip)
+	return n
+}
+
+func (n *headsNode) BlockNumber(ctx context.Context) (uint64, error) {
+	return n.tip.Load(), nil
+}
+
+func (n *headsNode) SubscribeNewHead(ctx context.Context, ch chan<- *xycommon.RpcHeader) (ethereum.Subscription, error) {
+	n.subscribed.Add(1)
+	if n.failures.Add(-1) >= 0 {
+		return nil, errors.New("subscription refused")
+	}
+
+	sub := &headsSub{heads: ch, err: make(chan error, 1)}
+	n.subs <- sub
+	return sub, nil
+}
+
+// nextSub waits for the next subscription of the node
+func (n *headsNode) nextSub(t *testing.T) *headsSub {
+	select {
+	case sub := <-n.subs:
+		return sub
+	case <-time.After(replayTimeout):
+		t.Fatalf("subscription timeout")
+		return nil
+	}
+}
+
+func newHead(num uint64) *xycommon.RpcHeader {
+	return &xycommon.RpcHeader{Number: new(big.Int).SetUint64(num)}
+}
+
+func newHeadsExplorer(t *testing.T, node xycommon.IRPCClient) *Explorer {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return &Explorer{
+		ctx:        ctx,
+		cancel:     cancel,
+		node:       node,
+		config:     &config.Config{Chain: config.ChainConfig{ChainName: replayChain, Rpc: headsRpc}},
+		headNotify: make(chan struct{}, 1),
+	}
+}
+
+// waitLatest waits until the latest block number of the explorer reaches num
+func waitLatest(t *testing.T, e *Explorer, num uint64) {
+	deadline := time.Now().Add(replayTimeout)
+	for e.latestBlockNum.Load() != num {
+		if time.Now().After(deadline) {
+			t.Fatalf("latest block number[%d] not reached, got[%d]", num, e.latestBlockNum.Load())
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+}
+
+func assertNotified(t *testing.T, e *Explorer) {
+	select {
+	case <-e.headNotify:
+	default:
+		t.Errorf("scanner not notified")
+	}
+}
+
+func TestSubscriptionEnabled(t *testing.T) {
+	cases := []struct {
+		name  string
+		chain config.ChainConfig
+		want  bool
+	}{
+		{"http", config.ChainConfig{Rpc: "http://127.0.0.1:8545"}, false},
+		{"ws", config.ChainConfig{Rpc: headsRpc}, true},
+		{"wss fallback", config.ChainConfig{Rpc: "https://127.0.0.1:8545", Rpcs: []string{"wss://127.0.0.1:8546"}}, true},
+		{"none", config.ChainConfig{}, false},
+	}
+
+	for _, c := range cases {
+		e := &Explorer{config: &config.Config{Chain: c.chain}}
+		assert.Equal(t, c.want, e.subscriptionEnabled(), c.name)
+	}
+}
+
+func TestSubscribeNewHeads(t *testing.T) {
+	node := newHeadsNode(nil, 5)
+	e := newHeadsExplorer(t, node)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.subscribeNewHeads(node)
+	}()
+	sub := node.nextSub(t)
+
+	// the heads skipped before subscribing are backfilled from the latest block number
+	waitLatest(t, e, 5)
+	assertNotified(t, e)
+
+	sub.heads <- newHead(8)
+	waitLatest(t, e, 8)
+	assertNotified(t, e)
+
+	// stale & empty heads never lower the latest block number
+	sub.heads <- newHead(6)
+	sub.heads <- nil
+	sub.heads <- &xycommon.RpcHeader{}
+	sub.heads <- newHead(9)
+	waitLatest(t, e, 9)
+
+	sub.err <- errors.New("connection reset")
+	select {
+	case err := <-done:
+		assert.EqualError(t, err, "connection reset")
+	case <-time.After(replayTimeout):
+		t.Fatalf("subscription not dropped")
+	}
+	assert.True(t, sub.unsubscribed.Load())
+}
+
+func TestSubscribeNewHeadsTimeout(t *testing.T) {
+	timeout := newHeadTimeout
+	newHeadTimeout = 100 * time.Millisecond
+	defer func() {
+		newHeadTimeout = timeout
+	}()
+
+	node := newHeadsNode(nil, 1)
+	e := newHeadsExplorer(t, node)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.subscribeNewHeads(node)
+	}()
+	sub := node.nextSub(t)
+
+	// heads keep the subscription alive
+	for num := uint64(2); num <= 4; num++ {
+		<-time.After(50 * time.Millisecond)
+		sub.heads <- newHead(num)
+	}
+	waitLatest(t, e, 4)
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, errNewHeadTimeout)
+	case <-time.After(replayTimeout):
+		t.Fatalf("silent subscription not dropped")
+	}
+	assert.True(t, sub.unsubscribed.Load())
+}
+
+func TestSubscribeNewHeadsStopped(t *testing.T) {
+	node := newHeadsNode(nil, 1)
+	e := newHeadsExplorer(t, node)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.subscribeNewHeads(node)
+	}()
+	sub := node.nextSub(t)
+
+	e.cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(replayTimeout):
+		t.Fatalf("subscription not stopped")
+	}
+	assert.True(t, sub.unsubscribed.Load())
+}
+
+func TestWatchNewHeadsFallback(t *testing.T) {
+	duration := pollingFallbackDuration
+	pollingFallbackDuration = 500 * time.Millisecond
+	defer func() {
+		pollingFallbackDuration = duration
+	}()
+
+	node := newHeadsNode(nil, 3)
+	node.failures.Store(1)
+	e := newHeadsExplorer(t, node)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.watchNewHeads(node)
+	}()
+
+	// the refused subscription falls back to polling before re-subscribing
+	waitLatest(t, e, 3)
+	assert.Equal(t, int32(1), node.subscribed.Load())
+
+	sub := node.nextSub(t)
+	assert.Equal(t, int32(2), node.subscribed.Load())
+	sub.heads <- newHead(4)
+	waitLatest(t, e, 4)
+
+	// the dropped subscription falls back to polling as well
+	node.tip.Store(10)
+	sub.err <- errors.New("connection reset")
+	waitLatest(t, e, 10)
+	assert.Equal(t, int32(2), node.subscribed.Load())
+	assert.True(t, sub.unsubscribed.Load())
+
+	sub = node.nextSub(t)
+	assert.Equal(t, int32(3), node.subscribed.Load())
+	sub.heads <- newHead(11)
+	waitLatest(t, e, 11)
+
+	e.cancel()
+	select {
+	case <-done:
+	case <-time.After(replayTimeout):
+		t.Fatalf("watching not stopped")
+	}
+}
+
+func TestScanSkippedHeads(t *testing.T) {
+	blocks := evmBlocks([][]string{{"deploy"}, {"mint"}, {"mint"}, {"mint"}, {"transfer"}, {"mint"}})
+	dir := t.TempDir()
+	chain := config.ChainConfig{ChainName: replayChain, Rpc: headsRpc}
+	cfg := &config.Config{
+		Scan: config.ScanConfig{
+			StartBlock:        1,
+			BlockBatchWorkers: 1,
+			TxBatchWorkers:    1,
+		},
+		Chain:   chain,
+		Filters: &config.IndexFilter{},
+		Database: config.DatabaseConfig{
+			Type: storage.DatabaseTypeSqlite3,
+			Dsn:  filepath.Join(dir, "indexer.db"),
+		},
+	}
+
+	db, err := storage.NewDbClient(&cfg.Database)
+	assert.NoError(t, err)
+
+	dCache := dcache.NewManager(db, chain.ChainName)
+	assert.NoError(t, protocol.InitProtocols(cfg, dCache))
+
+	eventCtx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	node := newHeadsNode(blocks, 1)
+	quit := make(chan os.Signal, 1)
+	exp := NewExplorer(node, db, cfg, dCache, devents.NewDEvents(eventCtx, db), quit)
+	go exp.Scan()
+	go exp.Index()
+	go exp.FlushDB()
+	t.Cleanup(exp.Stop)
+
+	// the heights between the pushed heads are scanned all the same
+	sub := node.nextSub(t)
+	sub.heads <- newHead(3)
+	sub.heads <- newHead(6)
+	waitIndexed(t, db, blocks[5].Hash)
+
+	for _, block := range blocks {
+		assertTxIndexed(t, db, block.Transactions[0].Hash, true)
+	}
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "360", replayAddrB: "40"})
+}
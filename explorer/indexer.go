@@ -25,63 +25,36 @@ package explorer
 import (
 	"errors"
 	"fmt"
-	"github.com/alitto/pond"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
 	"github.com/uxuycom/indexer/protocol"
-	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xyerrors"
 	"github.com/uxuycom/indexer/xylog"
 	"math/big"
 	"strings"
-	"sync"
 	"time"
 )
 
-func (e *Explorer) validReceiptTxs(items []*xycommon.RpcTransaction) ([]*xycommon.RpcTransaction, *xyerrors.InsError) {
+func (e *Explorer) validReceiptTxs(block *xycommon.RpcBlock, items []*xycommon.RpcTransaction) ([]*xycommon.RpcTransaction, *xyerrors.InsError) {
 	startTs := time.Now()
 	defer func() {
 		xylog.Logger.Infof("handle txs, fetch receipt data cost[%v], items[%d]", time.Since(startTs), len(items))
 	}()
 
-	txHashList := make(map[string]struct{}, len(items))
-	for _, item := range items {
-		txHashList[item.Hash] = struct{}{}
-	}
-
-	workers := int(e.config.Scan.TxBatchWorkers)
-	pool := pond.New(workers, 0, pond.MinWorkers(workers))
-
-	receiptsMap := &sync.Map{}
-	for txHash := range txHashList {
-		hash := txHash
-		pool.Submit(func() {
-			r, err := e.node.TransactionReceipt(e.ctx, hash)
-			if err != nil {
-				xylog.Logger.Errorf("get tx receipt err:%v, tx:%s", err, hash)
-				return
-			}
-
-			if r == nil {
-				xylog.Logger.Errorf("get tx receipt nil, tx:%s", hash)
-				return
-			}
-			receiptsMap.Store(hash, r)
-		})
+	if len(items) < 1 {
+		return items, nil
 	}
 
-	// Stop the pool and wait for all submitted tasks to complete
-	pool.StopAndWait()
+	receiptsMap := e.fetchReceipts(block, items)
 
 	results := make([]*xycommon.RpcTransaction, 0, len(items))
 	for _, item := range items {
-		rv, ok := receiptsMap.Load(item.Hash)
+		r, ok := receiptsMap[item.Hash]
 		if !ok {
 			return nil, xyerrors.NewInsError(-100, fmt.Sprintf("get tx[%s] receipt nil", item.Hash))
 		}
 
-		r := rv.(*xycommon.RpcReceipt)
-
 		// tx status check
 		if r.Status.Int64() != 1 {
 			xylog.Logger.Warnf("tx[%s] status <> 1 & filtered", item.Hash)
@@ -102,30 +75,60 @@ func (e *Explorer) validReceiptTxs(items []*xycommon.RpcTransaction) ([]*xycommo
 
 func (e *Explorer) tryFilterTxs(txs []*xycommon.RpcTransaction) []*xycommon.RpcTransaction {
 	validTxs := make([]*xycommon.RpcTransaction, 0, len(txs))
+	inscribed := make(map[string]struct{}, len(txs))
 	for _, tx := range txs {
-		pt, md := protocol.GetProtocol(e.config, tx)
-		if pt == nil {
+		matches := protocol.GetProtocols(e.config, tx)
+		if len(matches) < 1 {
+			// inscriptions revealed earlier in the block are not indexed yet
+			if spendsInscribed(tx, inscribed) {
+				validTxs = append(validTxs, tx)
+			}
 			continue
 		}
 
-		// Add protocol whitelist
-		if !e.protocolEnabled(md.Protocol) {
+		if !e.anyMatchEnabled(tx, matches) {
 			continue
 		}
 
-		// Add protocol whitelist
-		if !e.tickEnabled(md.Tick) {
+		if len(tx.Vin) > 0 {
+			inscribed[strings.ToLower(tx.Hash)] = struct{}{}
+		}
+		validTxs = append(validTxs, tx)
+	}
+	return validTxs
+}
+
+// anyMatchEnabled checks whether any protocol matched by the tx passes the filters
+func (e *Explorer) anyMatchEnabled(tx *xycommon.RpcTransaction, matches []*protocol.Matched) bool {
+	for _, m := range matches {
+		// Add protocol & tick whitelist
+		if !e.mdEnabled(m.MD) {
 			continue
 		}
 
 		// Add mint completed filter
-		if e.filterMintCompleted(md) {
+		if e.filterMintCompleted(m.MD) {
 			xylog.Logger.Infof("tx hit mint completed strategy & ignore. tx[%s]", tx.Hash)
 			continue
 		}
-		validTxs = append(validTxs, tx)
+		return true
 	}
-	return validTxs
+	return false
+}
+
+// spendsInscribed checks whether the utxo tx spends the outputs of the txs inscribed earlier in the same block,
+// the utxos indexed before the block are matched by the protocols
+func spendsInscribed(tx *xycommon.RpcTransaction, inscribed map[string]struct{}) bool {
+	for _, vin := range tx.Vin {
+		if vin.IsCoinBase() {
+			continue
+		}
+
+		if _, ok := inscribed[strings.ToLower(vin.Txid)]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func (e *Explorer) filterMintCompleted(md *devents.MetaData) bool {
@@ -161,40 +164,39 @@ func (e *Explorer) handleTxs(block *xycommon.RpcBlock, txs []*xycommon.RpcTransa
 
 	blockTxResults := make([]*devents.DBModelEvent, 0, len(txs))
 	for _, tx := range txs {
-		pt, md := protocol.GetProtocol(e.config, tx)
-		if pt == nil {
-			continue
-		}
+		// shared protocols index the tx after its primary protocol
+		for _, m := range protocol.GetProtocols(e.config, tx) {
+			pt, md := m.Protocol, m.MD
 
-		// Add protocol whitelist
-		if !e.protocolEnabled(md.Protocol) {
-			continue
-		}
+			// Add protocol & tick whitelist
+			if !e.mdEnabled(md) {
+				continue
+			}
 
-		// Add protocol whitelist
-		if !e.tickEnabled(md.Tick) {
-			continue
-		}
+			txResults, err := pt.Parse(block, tx, md)
+			if err != nil && errors.Is(err, xyerrors.ErrInternal) {
+				return err
+			}
+			if err != nil {
+				xylog.Logger.Infof("tx data parsed failed. md[%v], tx[%s], err[%v]", md, tx.Hash, err)
+				continue
+			}
+			xylog.Logger.Infof("tx data parsed success. md[%v], tx[%s]", md, tx.Hash)
 
-		txResults, err := pt.Parse(block, tx, md)
-		if err != nil && errors.Is(err, xyerrors.ErrInternal) {
-			return err
-		}
-		if err != nil {
-			xylog.Logger.Infof("tx data parsed failed. md[%v], tx[%s], err[%v]", md, tx.Hash, err)
-			continue
-		}
-		xylog.Logger.Infof("tx data parsed success. md[%v], tx[%s]", md, tx.Hash)
+			if len(txResults) < 1 {
+				xylog.Logger.Warnf("tx data parsed result nil. md[%v], tx[%s]", md, tx.Hash)
+				continue
+			}
 
-		if len(txResults) < 1 {
-			xylog.Logger.Warnf("tx data parsed result nil. md[%v], tx[%s]", md, tx.Hash)
-			continue
-		}
+			// update cache
+			for _, txResult := range txResults {
+				if !e.mdEnabled(txResult.MD) {
+					continue
+				}
 
-		// update cache
-		for _, txResult := range txResults {
-			e.txResultHandler.UpdateCache(txResult)
-			blockTxResults = append(blockTxResults, e.txResultHandler.BuildModel(txResult))
+				e.txResultHandler.UpdateCache(txResult)
+				blockTxResults = append(blockTxResults, e.txResultHandler.BuildModel(txResult))
+			}
 		}
 	}
 	e.writeDBAsync(block, blockTxResults)
@@ -207,12 +209,25 @@ func (e *Explorer) extractTxsFromBlock(block *xycommon.RpcBlock) []*xycommon.Rpc
 	}
 
 	txs := make([]*xycommon.RpcTransaction, 0, len(block.Transactions))
+	inscribed := make(map[string]struct{})
 	for _, tx := range block.Transactions {
 		// fast check & filter invalid txs
-		if !e.fastChecking(tx) {
-			continue
+		if e.fastChecking(tx) || spendsInscribed(tx, inscribed) {
+			if len(tx.Vin) > 0 {
+				inscribed[strings.ToLower(tx.Hash)] = struct{}{}
+			}
+
+			// contract emitted creations are indexed as the contract inscribing
+			txs = append(txs, protocol.ResolveEventCreation(tx))
+		}
+
+		// inscriptions of the internal calls follow the tx in call order
+		txs = append(txs, e.tracedTxs(tx)...)
+
+		// the blob inscription follows the last one
+		if blobTx := protocol.ResolveBlobInscription(tx); blobTx != nil {
+			txs = append(txs, blobTx)
 		}
-		txs = append(txs, tx)
 	}
 	return txs
 }
@@ -238,10 +253,15 @@ func (e *Explorer) Index() {
 	}()
 	xylog.Logger.Infof("start indexing...")
 
+	e.loadIndexedBlock()
 	for {
 		select {
 		case block := <-e.blocks:
+			if !e.acceptBlock(block) {
+				continue
+			}
 			e.handleBlock(block)
+			e.markIndexed(block)
 		case <-e.ctx.Done():
 			return
 		}
@@ -269,7 +289,7 @@ func (e *Explorer) handleBlock(block *xycommon.RpcBlock) {
 		txs = e.tryFilterTxs(txs)
 
 		// Add receipt data & filter invalid status
-		txs, err := e.validReceiptTxs(txs)
+		txs, err := e.validReceiptTxs(block, txs)
 		if err != nil {
 			xylog.Logger.Errorf("fetch receipt data internal err:%v & retry later[%d]", err, retry)
 			retry++
@@ -315,19 +335,17 @@ func (e *Explorer) fastChecking(tx *xycommon.RpcTransaction) bool {
 		return true
 	}
 
-	// input dmt format checking
-	trxContent := tx.Input
-
-	// 0x prefix checking
-	if !strings.HasPrefix(trxContent, "0x") {
-		return false
-	}
+	// the group envelopes & the protocol specific formats
+	return protocol.MatchTx(tx)
+}
 
-	// data prefix checking
-	if strings.HasPrefix(trxContent, common.DataPrefix) {
+// mdEnabled checks the metadata against the protocol & tick whitelists, the adapters index the transfers
+// of their target protocols, which are checked by the parsed results
+func (e *Explorer) mdEnabled(md *devents.MetaData) bool {
+	if md.Protocol == types.AdapterProtocol {
 		return true
 	}
-	return false
+	return e.protocolEnabled(md.Protocol) && e.tickEnabled(md.Tick)
 }
 
 func (e *Explorer) protocolEnabled(protocol string) bool {
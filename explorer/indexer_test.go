@@ -0,0 +1,44 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/devents"
+	"testing"
+)
+
+func TestMdEnabled(t *testing.T) {
+	filters := &config.IndexFilter{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"whitelist":{"protocols":["asc-20"],"ticks":["duck"]}}`), filters))
+	e := &Explorer{config: &config.Config{Filters: filters}}
+
+	assert.True(t, e.mdEnabled(&devents.MetaData{Protocol: "ASC-20", Tick: "duck"}))
+	assert.False(t, e.mdEnabled(&devents.MetaData{Protocol: "brc-20", Tick: "duck"}))
+	assert.False(t, e.mdEnabled(&devents.MetaData{Protocol: "asc-20", Tick: "ordi"}))
+
+	// the adapters are checked by the target protocols of their results
+	assert.True(t, e.mdEnabled(&devents.MetaData{Protocol: "abi-adapter", Tick: "ordi"}))
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"errors"
+	"github.com/alitto/pond"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"strings"
+	"sync"
+)
+
+const (
+	ReceiptModeAuto   = "auto"
+	ReceiptModeBlock  = "block"
+	ReceiptModeBatch  = "batch"
+	ReceiptModeSingle = "single"
+)
+
+// receiptModes fallback order of the receipts fetching modes
+var receiptModes = []string{ReceiptModeBlock, ReceiptModeBatch, ReceiptModeSingle}
+
+// fetchReceipts fetch receipts of the txs, keyed by tx hash,
+// the first mode supported by the node is chosen & remembered in auto mode
+func (e *Explorer) fetchReceipts(block *xycommon.RpcBlock, items []*xycommon.RpcTransaction) map[string]*xycommon.RpcReceipt {
+	bc, ok := e.node.(xycommon.IReceiptsClient)
+	if !ok {
+		return e.fetchReceiptsOneByOne(items)
+	}
+
+	for {
+		mode := e.currentReceiptMode()
+		var (
+			receipts []*xycommon.RpcReceipt
+			err      error
+		)
+		switch mode {
+		case ReceiptModeBlock:
+			receipts, err = bc.BlockReceipts(e.ctx, block.Number)
+		case ReceiptModeBatch:
+			hashes := make([]string, 0, len(items))
+			for hash := range originHashes(items) {
+				hashes = append(hashes, hash)
+			}
+			receipts, err = bc.BatchTransactionReceipts(e.ctx, hashes)
+		default:
+			return e.fetchReceiptsOneByOne(items)
+		}
+
+		if err == nil {
+			receiptsMap := make(map[string]*xycommon.RpcReceipt, len(receipts))
+			for _, r := range receipts {
+				if r != nil {
+					receiptsMap[receiptKey(r.TxHash.String())] = r
+				}
+			}
+			return e.normalizeReceiptKeys(items, receiptsMap)
+		}
+
+		if errors.Is(err, xycommon.ErrMethodNotSupported) && e.downgradeReceiptMode(mode) {
+			xylog.Logger.Warnf("receipts mode[%s] not supported by node & fall back to mode[%s]", mode, e.currentReceiptMode())
+			continue
+		}
+
+		// missing receipts are reported & retried by the caller
+		xylog.Logger.Errorf("fetch receipts by mode[%s] err:%v, block[%d]", mode, err, block.Number.Uint64())
+		return nil
+	}
+}
+
+func (e *Explorer) currentReceiptMode() string {
+	if e.receiptMode != "" {
+		return e.receiptMode
+	}
+
+	mode := strings.ToLower(e.config.Scan.ReceiptMode)
+	if mode == "" || mode == ReceiptModeAuto {
+		mode = receiptModes[0]
+	}
+	e.receiptMode = mode
+	return mode
+}
+
+// downgradeReceiptMode switch to the next mode, only allowed in auto mode
+func (e *Explorer) downgradeReceiptMode(mode string) bool {
+	cfgMode := strings.ToLower(e.config.Scan.ReceiptMode)
+	if cfgMode != "" && cfgMode != ReceiptModeAuto {
+		return false
+	}
+
+	for i, m := range receiptModes {
+		if m == mode && i+1 < len(receiptModes) {
+			e.receiptMode = receiptModes[i+1]
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeReceiptKeys key receipts by the tx hash format of the block txs
+func (e *Explorer) normalizeReceiptKeys(items []*xycommon.RpcTransaction, receipts map[string]*xycommon.RpcReceipt) map[string]*xycommon.RpcReceipt {
+	ret := make(map[string]*xycommon.RpcReceipt, len(items))
+	for _, item := range items {
+		if r, ok := receipts[receiptKey(item.Hash)]; ok {
+			ret[item.Hash] = r
+		}
+	}
+	return ret
+}
+
+// receiptKey normalizes tx hash, btc txids carry no 0x prefix, internal calls share the receipt of their tx
+func receiptKey(hash string) string {
+	return strings.TrimPrefix(strings.ToLower(xycommon.OriginHash(hash)), "0x")
+}
+
+// originHashes returns the distinct hashes of the txs owning the receipts
+func originHashes(items []*xycommon.RpcTransaction) map[string]struct{} {
+	hashes := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		hashes[xycommon.OriginHash(item.Hash)] = struct{}{}
+	}
+	return hashes
+}
+
+func (e *Explorer) fetchReceiptsOneByOne(items []*xycommon.RpcTransaction) map[string]*xycommon.RpcReceipt {
+	txHashList := originHashes(items)
+
+	workers := int(e.config.Scan.TxBatchWorkers)
+	if workers <= 0 {
+		workers = 1
+	}
+	pool := pond.New(workers, 0, pond.MinWorkers(workers))
+
+	receiptsMap := &sync.Map{}
+	for txHash := range txHashList {
+		hash := txHash
+		pool.Submit(func() {
+			r, err := e.node.TransactionReceipt(e.ctx, hash)
+			if err != nil {
+				xylog.Logger.Errorf("get tx receipt err:%v, tx:%s", err, hash)
+				return
+			}
+
+			if r == nil {
+				xylog.Logger.Errorf("get tx receipt nil, tx:%s", hash)
+				return
+			}
+			receiptsMap.Store(hash, r)
+		})
+	}
+
+	// Stop the pool and wait for all submitted tasks to complete
+	pool.StopAndWait()
+
+	ret := make(map[string]*xycommon.RpcReceipt, len(txHashList))
+	receiptsMap.Range(func(k, v interface{}) bool {
+		ret[receiptKey(k.(string))] = v.(*xycommon.RpcReceipt)
+		return true
+	})
+	return e.normalizeReceiptKeys(items, ret)
+}
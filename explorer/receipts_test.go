@@ -0,0 +1,175 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"math/big"
+	"testing"
+)
+
+// receiptsNode serves the bulk receipts methods, the unsupported ones fail as the client reports them
+type receiptsNode struct {
+	*chainNode
+	unsupported map[string]bool
+	failed      error
+	calls       map[string]int
+}
+
+func newReceiptsNode(blocks []*xycommon.RpcBlock, unsupported ...string) *receiptsNode {
+	n := &receiptsNode{
+		chainNode:   newChainNode(blocks),
+		unsupported: make(map[string]bool),
+		calls:       make(map[string]int),
+	}
+	for _, mode := range unsupported {
+		n.unsupported[mode] = true
+	}
+	return n
+}
+
+func (n *receiptsNode) call(mode string) error {
+	n.calls[mode]++
+	if n.unsupported[mode] {
+		return fmt.Errorf("%w: the method does not exist/is not available", xycommon.ErrMethodNotSupported)
+	}
+	return n.failed
+}
+
+func (n *receiptsNode) BlockReceipts(ctx context.Context, number *big.Int) ([]*xycommon.RpcReceipt, error) {
+	if err := n.call(ReceiptModeBlock); err != nil {
+		return nil, err
+	}
+
+	block, err := n.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]*xycommon.RpcReceipt, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		receipts = append(receipts, n.receipts[tx.Hash])
+	}
+	return receipts, nil
+}
+
+func (n *receiptsNode) BatchTransactionReceipts(ctx context.Context, txHashes []string) ([]*xycommon.RpcReceipt, error) {
+	if err := n.call(ReceiptModeBatch); err != nil {
+		return nil, err
+	}
+
+	receipts := make([]*xycommon.RpcReceipt, 0, len(txHashes))
+	for _, hash := range txHashes {
+		receipts = append(receipts, n.receipts[hash])
+	}
+	return receipts, nil
+}
+
+func (n *receiptsNode) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	n.calls[ReceiptModeSingle]++
+	return n.chainNode.TransactionReceipt(ctx, txHash)
+}
+
+func newReceiptsExplorer(node xycommon.IRPCClient, mode string) *Explorer {
+	return &Explorer{
+		ctx:    context.Background(),
+		node:   node,
+		config: &config.Config{Scan: config.ScanConfig{ReceiptMode: mode, TxBatchWorkers: 1}},
+	}
+}
+
+func TestFetchReceiptsModes(t *testing.T) {
+	cases := []struct {
+		name        string
+		mode        string
+		unsupported []string
+		chosen      string
+		calls       map[string]int
+	}{
+		{"auto by block", ReceiptModeAuto, nil, ReceiptModeBlock, map[string]int{ReceiptModeBlock: 2}},
+		{"auto fallback to batch", "", []string{ReceiptModeBlock}, ReceiptModeBatch, map[string]int{ReceiptModeBlock: 1, ReceiptModeBatch: 2}},
+		{"auto fallback to single", ReceiptModeAuto, []string{ReceiptModeBlock, ReceiptModeBatch}, ReceiptModeSingle, map[string]int{ReceiptModeBlock: 1, ReceiptModeBatch: 1, ReceiptModeSingle: 4}},
+		{"configured batch", ReceiptModeBatch, nil, ReceiptModeBatch, map[string]int{ReceiptModeBatch: 2}},
+		{"configured single", ReceiptModeSingle, nil, ReceiptModeSingle, map[string]int{ReceiptModeSingle: 4}},
+	}
+
+	for _, c := range cases {
+		blocks := evmBlocks([][]string{{"deploy", "mint"}, {"mint", "transfer"}})
+		node := newReceiptsNode(blocks, c.unsupported...)
+		e := newReceiptsExplorer(node, c.mode)
+
+		// the chosen mode is remembered for the next blocks
+		for _, block := range blocks {
+			receipts := e.fetchReceipts(block, block.Transactions)
+			if assert.Len(t, receipts, 2, c.name) {
+				for _, tx := range block.Transactions {
+					assert.Equal(t, tx.Hash, receipts[tx.Hash].TxHash.Hex(), c.name)
+				}
+			}
+		}
+		assert.Equal(t, c.chosen, e.receiptMode, c.name)
+		assert.Equal(t, c.calls, node.calls, c.name)
+	}
+}
+
+func TestFetchReceiptsConfiguredModeUnsupported(t *testing.T) {
+	blocks := evmBlocks([][]string{{"deploy", "mint"}})
+	node := newReceiptsNode(blocks, ReceiptModeBlock)
+	e := newReceiptsExplorer(node, ReceiptModeBlock)
+
+	// the configured mode is never downgraded, missing receipts are retried by the caller
+	assert.Nil(t, e.fetchReceipts(blocks[0], blocks[0].Transactions))
+	assert.Equal(t, ReceiptModeBlock, e.receiptMode)
+	assert.Equal(t, map[string]int{ReceiptModeBlock: 1}, node.calls)
+}
+
+func TestFetchReceiptsFailed(t *testing.T) {
+	blocks := evmBlocks([][]string{{"deploy", "mint"}})
+	node := newReceiptsNode(blocks)
+	node.failed = errors.New("header not found")
+	e := newReceiptsExplorer(node, ReceiptModeAuto)
+
+	// other errors never switch the mode
+	assert.Nil(t, e.fetchReceipts(blocks[0], blocks[0].Transactions))
+	assert.Equal(t, ReceiptModeBlock, e.receiptMode)
+	assert.Equal(t, map[string]int{ReceiptModeBlock: 1}, node.calls)
+}
+
+func TestFetchReceiptsDerivedTxs(t *testing.T) {
+	blocks := evmBlocks([][]string{{"deploy", "mint"}})
+	e := newReceiptsExplorer(newChainNode(blocks), ReceiptModeAuto)
+
+	// internal calls share the receipt of their tx, nodes without the bulk methods are fetched one by one
+	tx := blocks[0].Transactions[1]
+	derived := *tx
+	derived.Hash = tx.Hash + xycommon.DerivedHashSeparator + "2"
+	receipts := e.fetchReceipts(blocks[0], []*xycommon.RpcTransaction{tx, &derived})
+	if assert.Len(t, receipts, 2) {
+		assert.Same(t, receipts[tx.Hash], receipts[derived.Hash])
+	}
+}
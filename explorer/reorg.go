@@ -0,0 +1,177 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultReorgDepth = 64
+
+func (e *Explorer) reorgDepth() uint64 {
+	if e.config.Scan.ReorgDepth > 0 {
+		return e.config.Scan.ReorgDepth
+	}
+	return defaultReorgDepth
+}
+
+// loadIndexedBlock load the last flushed block as the parent of the next indexed block
+func (e *Explorer) loadIndexedBlock() {
+	block, err := e.db.FindLastBlock(e.config.Chain.ChainName)
+	if err != nil || block == nil {
+		xylog.Logger.Infof("no indexed block found, chain:%s", e.config.Chain.ChainName)
+		return
+	}
+
+	num, err := strconv.ParseUint(block.BlockNumber, 10, 64)
+	if err != nil || num <= 0 {
+		return
+	}
+
+	e.indexedBlockNum = num
+	e.indexedBlockHash = block.BlockHash
+	e.recentBlocks[num] = block.BlockHash
+}
+
+// acceptBlock checks the block links to the last indexed block,
+// the indexed state is rolled back to the common ancestor once a chain reorganization is detected
+func (e *Explorer) acceptBlock(block *xycommon.RpcBlock) bool {
+	if block == nil || block.Number == nil {
+		return false
+	}
+
+	num := block.Number.Uint64()
+	if e.indexedBlockNum > 0 && num != e.indexedBlockNum+1 {
+		xylog.Logger.Infof("block[%d] is not next to the indexed block[%d] & skip", num, e.indexedBlockNum)
+		return false
+	}
+
+	if e.indexedBlockHash == "" || strings.EqualFold(block.ParentHash, e.indexedBlockHash) {
+		return true
+	}
+
+	xylog.Logger.Warnf("chain reorganization detected. block[%d], parent hash[%s] <> indexed hash[%s]", num, block.ParentHash, e.indexedBlockHash)
+	e.rollback(num)
+	return false
+}
+
+// markIndexed record block hash for the common ancestor lookup
+func (e *Explorer) markIndexed(block *xycommon.RpcBlock) {
+	num := block.Number.Uint64()
+	e.indexedBlockNum = num
+	e.indexedBlockHash = block.Hash
+	e.recentBlocks[num] = block.Hash
+
+	depth := e.reorgDepth()
+	if num > depth {
+		delete(e.recentBlocks, num-depth-1)
+	}
+}
+
+// rollback revert the indexed data & caches to the common ancestor of the block, then re-index from there
+func (e *Explorer) rollback(blockNum uint64) {
+	for retry := 0; ; retry++ {
+		select {
+		case <-e.ctx.Done():
+			return
+		default:
+		}
+
+		fork, err := e.findForkBlock(blockNum)
+		if err != nil {
+			xylog.Logger.Errorf("find fork block err:%v, block[%d] & retry later[%d]", err, blockNum, retry)
+			<-time.After(time.Second)
+			continue
+		}
+
+		forkNum := fork.Number.Uint64()
+		xylog.Logger.Warnf("rollback indexed blocks[%d-%d], fork hash[%s]", forkNum+1, e.indexedBlockNum, fork.Hash)
+
+		err = e.dEvent.RevertDB(e.ctx, e.config.Chain.ChainName, forkNum, fork.Hash, fork.Time)
+		if err != nil {
+			xylog.Logger.Errorf("revert db err:%v, fork block[%d] & retry later[%d]", err, forkNum, retry)
+			<-time.After(time.Second)
+			continue
+		}
+
+		// rebuild caches from the reverted db
+		e.dCache.Reload()
+
+		for num := range e.recentBlocks {
+			if num > forkNum {
+				delete(e.recentBlocks, num)
+			}
+		}
+		e.indexedBlockNum = forkNum
+		e.indexedBlockHash = fork.Hash
+		e.recentBlocks[forkNum] = fork.Hash
+
+		// re-scan canonical blocks from the fork point
+		e.currentBlockNum.Store(forkNum + 1)
+		return
+	}
+}
+
+// findForkBlock walk back the indexed blocks to find the latest one still on the canonical chain,
+// the whole reorg depth is rolled back if no common ancestor is tracked
+func (e *Explorer) findForkBlock(blockNum uint64) (*xycommon.RpcHeader, error) {
+	depth := e.reorgDepth()
+	for num := blockNum - 1; num > 0 && blockNum-num <= depth; num-- {
+		hash, ok := e.recentBlocks[num]
+		if !ok {
+			continue
+		}
+
+		header, err := e.node.HeaderByNumber(e.ctx, new(big.Int).SetUint64(num))
+		if err != nil {
+			return nil, fmt.Errorf("get header[%d] err:%v", num, err)
+		}
+
+		if strings.EqualFold(header.Hash, hash) {
+			return header, nil
+		}
+	}
+
+	forkNum := uint64(0)
+	if blockNum > depth+1 {
+		forkNum = blockNum - depth - 1
+	}
+
+	// never roll back beyond the configured start block
+	if e.config.Scan.StartBlock > 0 && forkNum < e.config.Scan.StartBlock-1 {
+		forkNum = e.config.Scan.StartBlock - 1
+	}
+
+	xylog.Logger.Warnf("no common ancestor found within depth[%d], rollback to block[%d]", depth, forkNum)
+	header, err := e.node.HeaderByNumber(e.ctx, new(big.Int).SetUint64(forkNum))
+	if err != nil {
+		return nil, fmt.Errorf("get header[%d] err:%v", forkNum, err)
+	}
+	return header, nil
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol"
+	"github.com/uxuycom/indexer/storage"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// forkNode serves the blocks of the active chain, switched to the fork while indexing
+type forkNode struct {
+	mu   sync.RWMutex
+	node *chainNode
+}
+
+func (n *forkNode) active() *chainNode {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.node
+}
+
+func (n *forkNode) switchTo(node *chainNode) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.node = node
+}
+
+func (n *forkNode) BlockNumber(ctx context.Context) (uint64, error) {
+	return n.active().BlockNumber(ctx)
+}
+
+func (n *forkNode) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	return n.active().BlockByNumber(ctx, number)
+}
+
+func (n *forkNode) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	return n.active().HeaderByNumber(ctx, number)
+}
+
+func (n *forkNode) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	return n.active().TransactionSender(ctx, txHash, blockHash, txIndex)
+}
+
+func (n *forkNode) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	return n.active().TransactionReceipt(ctx, txHash)
+}
+
+func (n *forkNode) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	return n.active().FilterLogs(ctx, q)
+}
+
+// forkBlocks keeps the canonical blocks below the fork block & re-hashes the forked ones from there,
+// so the forked blocks & txs never share a hash with the canonical ones
+func forkBlocks(canonical, forked []*xycommon.RpcBlock, from uint64) []*xycommon.RpcBlock {
+	rehash := func(hash string) string {
+		return "0xf" + hash[3:]
+	}
+
+	blocks := make([]*xycommon.RpcBlock, 0, len(forked))
+	for _, block := range forked {
+		num := block.Number.Uint64()
+		if num < from {
+			blocks = append(blocks, canonical[num-1])
+			continue
+		}
+
+		block.ParentHash = blocks[len(blocks)-1].Hash
+		block.Hash = rehash(block.Hash)
+		for _, tx := range block.Transactions {
+			tx.Hash = rehash(tx.Hash)
+			tx.BlockHash = block.Hash
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// reorgIndex indexes the canonical chain, then switches the node to the fork & waits for the indexer
+// to roll back & index the forked blocks up to the last one
+func reorgIndex(t *testing.T, canonical, forked []*xycommon.RpcBlock, reorgDepth uint64) (*storage.DBClient, *dcache.Manager) {
+	dir := t.TempDir()
+	chain := config.ChainConfig{ChainName: replayChain}
+	cfg := &config.Config{
+		Scan: config.ScanConfig{
+			StartBlock:        1,
+			BlockBatchWorkers: 1,
+			TxBatchWorkers:    1,
+			ReorgDepth:        reorgDepth,
+		},
+		Chain:   chain,
+		Filters: &config.IndexFilter{},
+		Database: config.DatabaseConfig{
+			Type: storage.DatabaseTypeSqlite3,
+			Dsn:  filepath.Join(dir, "indexer.db"),
+		},
+	}
+
+	db, err := storage.NewDbClient(&cfg.Database)
+	assert.NoError(t, err)
+
+	dCache := dcache.NewManager(db, chain.ChainName)
+	assert.NoError(t, protocol.InitProtocols(cfg, dCache))
+
+	eventCtx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	node := &forkNode{node: newChainNode(canonical)}
+	quit := make(chan os.Signal, 1)
+	exp := NewExplorer(node, db, cfg, dCache, devents.NewDEvents(eventCtx, db), quit)
+	go exp.Scan()
+	go exp.Index()
+	go exp.FlushDB()
+	t.Cleanup(exp.Stop)
+
+	waitIndexed(t, db, canonical[len(canonical)-1].Hash)
+	node.switchTo(newChainNode(forked))
+	waitIndexed(t, db, forked[len(forked)-1].Hash)
+	return db, dCache
+}
+
+// waitIndexed waits until the block of the hash is the last flushed block
+func waitIndexed(t *testing.T, db *storage.DBClient, hash string) {
+	deadline := time.Now().Add(replayTimeout)
+	for {
+		// no block is found before the first flush
+		block, err := db.FindLastBlock(replayChain)
+		if err == nil && block != nil && strings.EqualFold(block.BlockHash, hash) {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("indexing timeout, block[%s] not indexed", hash)
+		}
+		<-time.After(100 * time.Millisecond)
+	}
+}
+
+func assertTxIndexed(t *testing.T, db *storage.DBClient, hash string, indexed bool) {
+	tx, err := db.FindTransaction(replayChain, hash)
+	assert.NoError(t, err)
+	if indexed {
+		assert.NotNil(t, tx, "tx[%s] not indexed", hash)
+	} else {
+		assert.Nil(t, tx, "tx[%s] not reverted", hash)
+	}
+}
+
+func TestReorgRollback(t *testing.T) {
+	canonical := evmBlocks([][]string{
+		{"deploy", "deployhash"},
+		{"mint", "minthash"},
+		{"transfer", "transferhash:2.1", "mint"},
+		{"minthash"},
+	})
+	forkOps := [][]string{
+		{"deploy", "deployhash"},
+		{"mint", "minthash"},
+		{"mint"},
+		{"minthash"},
+		{"mint"},
+	}
+	forked := forkBlocks(canonical, evmBlocks(forkOps), 3)
+
+	// the forked chain indexed from scratch
+	expected := replayIndex(t, newChainNode(evmBlocks(forkOps)), config.ChainConfig{ChainName: replayChain})
+
+	db, dCache := reorgIndex(t, canonical, forked, 0)
+
+	// balances are rolled back to the fork block, then the forked blocks are applied
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "300"})
+	assertBalances(t, db, replayChain, "brc-20", "hash", map[string]string{replayAddrA: "200"})
+	for _, tick := range []string{"test", "hash"} {
+		balance, err := db.FindUserBalanceByTick(replayChain, "brc-20", tick, replayAddrB)
+		assert.NoError(t, err)
+		assert.Nil(t, balance, "balance of the reverted transfer to B")
+	}
+
+	// caches are reloaded from the reverted db
+	ok, item := dCache.Balance.Get("brc-20", "test", replayAddrA)
+	if assert.True(t, ok) {
+		assert.Equal(t, "300", item.Overall.String())
+	}
+	ok, _ = dCache.Balance.Get("brc-20", "test", replayAddrB)
+	assert.False(t, ok)
+
+	// stats match the forked chain indexed from scratch
+	for _, tick := range []string{"test", "hash"} {
+		stats, err := db.FindInscriptionsStatsByTick(replayChain, "brc-20", tick)
+		assert.NoError(t, err)
+		want, err := expected.FindInscriptionsStatsByTick(replayChain, "brc-20", tick)
+		assert.NoError(t, err)
+		if assert.NotNil(t, stats) && assert.NotNil(t, want) {
+			assert.Equal(t, want.Minted.String(), stats.Minted.String(), tick)
+			assert.Equal(t, want.Holders, stats.Holders, tick)
+			assert.Equal(t, want.TxCnt, stats.TxCnt, tick)
+			assert.Equal(t, want.MintLastBlock, stats.MintLastBlock, tick)
+		}
+	}
+
+	// txs of the reverted blocks are removed & the forked ones indexed
+	for _, tx := range canonical[2].Transactions {
+		assertTxIndexed(t, db, tx.Hash, false)
+	}
+	assertTxIndexed(t, db, canonical[3].Transactions[0].Hash, false)
+	for _, block := range forked[2:] {
+		assertTxIndexed(t, db, block.Transactions[0].Hash, true)
+	}
+	assertTxIndexed(t, db, forked[1].Transactions[0].Hash, true)
+
+	// the utxo spent by the reverted transfer is unspent again, the ones created by the reverted blocks are removed
+	utxos, err := db.GetUtxosByAddress(replayAddrA, replayChain, "brc-20", "hash")
+	assert.NoError(t, err)
+	if assert.Len(t, utxos, 2) {
+		assert.Equal(t, forked[3].Transactions[0].Hash, utxos[0].RootHash)
+		assert.Equal(t, forked[1].Transactions[1].Hash, utxos[1].RootHash)
+		assert.Equal(t, int8(model.UTXOStatusUnspent), utxos[1].Status)
+	}
+	utxos, err = db.GetUtxosByAddress(replayAddrB, replayChain, "brc-20", "hash")
+	assert.NoError(t, err)
+	assert.Len(t, utxos, 0)
+}
+
+func TestReorgBeyondDepth(t *testing.T) {
+	canonical := evmBlocks([][]string{{"deploy"}, {"mint"}, {"mint"}, {"mint"}})
+	forked := forkBlocks(canonical, evmBlocks([][]string{{"deploy"}, {"mint"}, {"mint"}, {"transfer"}, {"mint"}}), 2)
+
+	// the common ancestor is out of the search depth, the indexer rolls back the whole depth
+	// & re-indexes the forked blocks from there instead of stalling
+	db, _ := reorgIndex(t, canonical, forked, 1)
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "260", replayAddrB: "40"})
+
+	assertTxIndexed(t, db, canonical[3].Transactions[0].Hash, false)
+	assertTxIndexed(t, db, forked[3].Transactions[0].Hash, true)
+	assertTxIndexed(t, db, forked[4].Transactions[0].Hash, true)
+
+	// blocks below the search depth are kept
+	assertTxIndexed(t, db, canonical[2].Transactions[0].Hash, true)
+}
@@ -0,0 +1,1393 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/replay"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol"
+	"github.com/uxuycom/indexer/protocol/avax/asc20"
+	"github.com/uxuycom/indexer/protocol/btc/runes"
+	"github.com/uxuycom/indexer/protocol/eth/ethscriptions"
+	"github.com/uxuycom/indexer/protocol/evm/erc20"
+	"github.com/uxuycom/indexer/storage"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	replayChain   = "replay"
+	replayAddrA   = "0x00000000000000000000000000000000000000aa"
+	replayAddrB   = "0x00000000000000000000000000000000000000bb"
+	replayAddrC   = "0x00000000000000000000000000000000000000cc"
+	replayAddrD   = "0x00000000000000000000000000000000000000dd"
+	replayTimeout = 30 * time.Second
+)
+
+var (
+	abiString, _  = abi.NewType("string", "", nil)
+	abiUint256, _ = abi.NewType("uint256", "", nil)
+)
+
+const replaySoldABI = `[{"type":"event","name":"Sold","anonymous":false,"inputs":[
+	{"name":"seller","type":"address","indexed":true},
+	{"name":"buyer","type":"address","indexed":true},
+	{"name":"tick","type":"string","indexed":false},
+	{"name":"amount","type":"uint256","indexed":false}
+]}]`
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+// chainNode serves fixed blocks, receipts & logs in memory
+type chainNode struct {
+	blocks   map[uint64]*xycommon.RpcBlock
+	receipts map[string]*xycommon.RpcReceipt
+	txs      map[string]*xycommon.RpcTransaction
+	logs     []xycommon.RpcLog
+	traces   map[uint64][]*xycommon.RpcTxTrace
+	blobs    map[uint64][]*xycommon.RpcBlob
+}
+
+// newChainNode serves the tx events through FilterLogs, the tx traces through TraceBlockCalls & the tx blobs
+// through BlockBlobs only, like a real node
+func newChainNode(blocks []*xycommon.RpcBlock) *chainNode {
+	n := &chainNode{
+		blocks:   make(map[uint64]*xycommon.RpcBlock),
+		receipts: make(map[string]*xycommon.RpcReceipt),
+		txs:      make(map[string]*xycommon.RpcTransaction),
+		traces:   make(map[uint64][]*xycommon.RpcTxTrace),
+		blobs:    make(map[uint64][]*xycommon.RpcBlob),
+	}
+
+	for _, block := range blocks {
+		n.blocks[block.Number.Uint64()] = block
+		for _, tx := range block.Transactions {
+			if tx.Trace != nil {
+				n.traces[block.Number.Uint64()] = append(n.traces[block.Number.Uint64()], &xycommon.RpcTxTrace{TxHash: tx.Hash, Result: tx.Trace})
+				tx.Trace = nil
+			}
+
+			for i, blob := range tx.Blobs {
+				n.blobs[block.Number.Uint64()] = append(n.blobs[block.Number.Uint64()], &xycommon.RpcBlob{VersionedHash: tx.BlobHashes[i], Data: blob})
+			}
+			tx.Blobs = nil
+
+			for i := range tx.Events {
+				event := tx.Events[i]
+				event.TxHash = common.HexToHash(tx.Hash)
+				event.BlockHash = common.HexToHash(block.Hash)
+				event.BlockNumber = (*hexutil.Big)(block.Number)
+				event.TxIndex = (*hexutil.Big)(tx.TxIndex)
+				event.Index = (*hexutil.Big)(big.NewInt(int64(len(n.logs))))
+				n.logs = append(n.logs, event)
+			}
+			tx.Events = nil
+
+			n.txs[tx.Hash] = tx
+			n.receipts[tx.Hash] = &xycommon.RpcReceipt{
+				Type:              big.NewInt(0),
+				Status:            big.NewInt(1),
+				CumulativeGasUsed: big.NewInt(21000),
+				TxHash:            common.HexToHash(tx.Hash),
+				GasUsed:           big.NewInt(21000),
+				EffectiveGasPrice: big.NewInt(1),
+				BlockHash:         common.HexToHash(block.Hash),
+				BlockNumber:       block.Number,
+				TransactionIndex:  tx.TxIndex,
+			}
+		}
+	}
+	return n
+}
+
+// buildBlocks builds chained blocks, tx builder is called with the block number & the tx index
+func buildBlocks(txs [][]string, hashPrefix string, builder func(num uint64, idx int, op string) *xycommon.RpcTransaction) []*xycommon.RpcBlock {
+	blocks := make([]*xycommon.RpcBlock, 0, len(txs))
+	parentHash := hashPrefix + common.Hash{}.Hex()[2:]
+	for i, ops := range txs {
+		num := uint64(i + 1)
+		block := &xycommon.RpcBlock{
+			ParentHash: parentHash,
+			Number:     new(big.Int).SetUint64(num),
+			GasLimit:   big.NewInt(30000000),
+			GasUsed:    big.NewInt(21000),
+			Time:       1700000000 + num,
+			Hash:       hashPrefix + common.BigToHash(big.NewInt(int64(1000 + num))).Hex()[2:],
+		}
+
+		for j, op := range ops {
+			tx := builder(num, j, op)
+			tx.BlockHash = block.Hash
+			tx.BlockNumber = new(big.Int).SetUint64(num)
+			tx.TxIndex = big.NewInt(int64(j))
+			tx.Hash = hashPrefix + common.BigToHash(big.NewInt(int64(num*100 + uint64(j)))).Hex()[2:]
+			tx.Type = big.NewInt(0)
+			tx.Value = big.NewInt(0)
+			tx.Gas = big.NewInt(21000)
+			tx.GasPrice = big.NewInt(1)
+			block.Transactions = append(block.Transactions, tx)
+		}
+		blocks = append(blocks, block)
+		parentHash = block.Hash
+	}
+	return blocks
+}
+
+// evmBlocks builds blocks of "data:" calldata inscriptions, ops are "<op>", "transferhash:<block>.<idx>"
+// transferring the utxo minted by the tx, "emit:<op>" inscribed by the contract event or "wallet:<op>" inscribed
+// by the internal calls of the smart wallet or "blob:<op>" inscribed by the blob, transfers are sent from A to B.
+// "token:<from><to><value>,..." emits the Transfer events of the token contract C, "0" is the zero address,
+// "sold:<contract><buyer><amount>" emits the sale of the tick test sold by A
+func evmBlocks(txs [][]string) []*xycommon.RpcBlock {
+	data := map[string]string{
+		"deploy":       `{"p":"brc-20","op":"deploy","tick":"test","max":"1000","lim":"100"}`,
+		"mint":         `{"p":"brc-20","op":"mint","tick":"test","amt":"100"}`,
+		"transfer":     `{"p":"brc-20","op":"transfer","tick":"test","amt":"40"}`,
+		"deployhash":   `{"p":"brc-20","op":"deploy","tick":"hash","max":"1000","lim":"100","tt":"hash"}`,
+		"minthash":     `{"p":"brc-20","op":"mint","tick":"hash","amt":"100"}`,
+		"transferamt":  `{"p":"brc-20","op":"transfer","tick":"hash","amt":"40"}`,
+		"transferhash": `{"p":"brc-20","op":"transfer","tick":"hash","hash":"%s"}`,
+		"deploydec":    `{"p":"brc-20","op":"deploy","tick":"deci","max":"1000","lim":"10","dec":"2"}`,
+		"mintdec":      `{"p":"brc-20","op":"mint","tick":"deci","amt":"1.25"}`,
+		"mintdec3":     `{"p":"brc-20","op":"mint","tick":"deci","amt":"1.255"}`,
+	}
+
+	return buildBlocks(txs, "0x", func(num uint64, idx int, op string) *xycommon.RpcTransaction {
+		parts := strings.Split(op, ":")
+		content := data[parts[0]]
+		if len(parts) > 1 {
+			var block, pos int64
+			_, _ = fmt.Sscanf(parts[1], "%d.%d", &block, &pos)
+			content = fmt.Sprintf(content, common.BigToHash(big.NewInt(block*100+pos)).Hex())
+		}
+
+		// bundler A calls the entry point C executing the user operation of the smart wallet D,
+		// inscribed by the second & the fifth internal calls, the third one is reverted
+		if parts[0] == "wallet" {
+			input := "0x" + hex.EncodeToString([]byte("data:,"+data[parts[1]]))
+			call := func(typ, errMsg string) *xycommon.RpcCallFrame {
+				return &xycommon.RpcCallFrame{Type: typ, From: replayAddrD, To: replayAddrD, Input: input, Error: errMsg}
+			}
+			return &xycommon.RpcTransaction{
+				From:  replayAddrA,
+				To:    replayAddrC,
+				Input: "0x1fad948c",
+				Trace: &xycommon.RpcCallFrame{
+					Type: "CALL", From: replayAddrA, To: replayAddrC, Input: "0x1fad948c",
+					Calls: []*xycommon.RpcCallFrame{
+						{
+							Type: "CALL", From: replayAddrC, To: replayAddrD, Input: "0xb61d27f6",
+							Calls: []*xycommon.RpcCallFrame{call("CALL", ""), call("CALL", "execution reverted"), call("STATICCALL", "")},
+						},
+						call("CALL", ""),
+					},
+				},
+			}
+		}
+
+		// A inscribes by the gzip compressed calldata (ESIP-7) or the base64 encoded data uri
+		if parts[0] == "gzip" || parts[0] == "base64" {
+			uri := []byte("data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(data[parts[1]])))
+			if parts[0] == "gzip" {
+				var buf bytes.Buffer
+				w := gzip.NewWriter(&buf)
+				_, _ = w.Write([]byte("data:," + data[parts[1]]))
+				_ = w.Close()
+				uri = buf.Bytes()
+			}
+			return &xycommon.RpcTransaction{From: replayAddrA, To: replayAddrA, Input: "0x" + hex.EncodeToString(uri)}
+		}
+
+		// A inscribes by the blob of the type-3 tx sent to itself
+		if parts[0] == "blob" {
+			return &xycommon.RpcTransaction{
+				Type:       big.NewInt(xycommon.BlobTxType),
+				From:       replayAddrA,
+				To:         replayAddrA,
+				Input:      "0x",
+				BlobHashes: []string{common.BigToHash(big.NewInt(int64(num*100) + int64(idx))).Hex()},
+				Blobs:      protocol.EncodeBlobs([]byte("data:," + data[parts[1]])),
+			}
+		}
+
+		// token contract C emits the Transfer events called by A
+		if parts[0] == "token" {
+			addrs := map[byte]string{'0': common.Address{}.Hex(), 'a': replayAddrA, 'b': replayAddrB, 'c': replayAddrC, 'd': replayAddrD}
+			events := make([]xycommon.RpcLog, 0, 2)
+			for _, item := range strings.Split(parts[1], ",") {
+				value, _ := new(big.Int).SetString(item[2:], 10)
+				events = append(events, xycommon.RpcLog{
+					Address: common.HexToAddress(replayAddrC),
+					Topics: []common.Hash{
+						common.HexToHash(erc20.EventTopicTransfer),
+						common.HexToHash(addrs[item[0]]),
+						common.HexToHash(addrs[item[1]]),
+					},
+					Data: common.BigToHash(value).Bytes(),
+				})
+			}
+			return &xycommon.RpcTransaction{From: replayAddrA, To: replayAddrC, Input: "0xa9059cbb", Events: events}
+		}
+
+		// the market contract emits the sale called by A
+		if parts[0] == "sold" {
+			addrs := map[byte]string{'b': replayAddrB, 'c': replayAddrC, 'd': replayAddrD}
+			amount, _ := new(big.Int).SetString(parts[1][2:], 10)
+			data, _ := abi.Arguments{{Type: abiString}, {Type: abiUint256}}.Pack("test", amount)
+			return &xycommon.RpcTransaction{
+				From:  replayAddrA,
+				To:    addrs[parts[1][0]],
+				Input: "0x",
+				Events: []xycommon.RpcLog{{
+					Address: common.HexToAddress(addrs[parts[1][0]]),
+					Topics: []common.Hash{
+						crypto.Keccak256Hash([]byte("Sold(address,address,string,uint256)")),
+						common.HexToHash(replayAddrA),
+						common.HexToHash(addrs[parts[1][1]]),
+					},
+					Data: data,
+				}},
+			}
+		}
+
+		// contract C emits the creation of the content owned by B
+		if parts[0] == "emit" {
+			data, _ := abi.Arguments{{Type: abiString}}.Pack("data:," + data[parts[1]])
+			return &xycommon.RpcTransaction{
+				From:  replayAddrA,
+				To:    replayAddrC,
+				Input: "0x",
+				Events: []xycommon.RpcLog{{
+					Address: common.HexToAddress(replayAddrC),
+					Topics:  []common.Hash{common.HexToHash(protocol.EventTopicHashCreate), common.HexToHash(replayAddrB)},
+					Data:    data,
+				}},
+			}
+		}
+
+		to := replayAddrA
+		if strings.HasPrefix(op, "transfer") {
+			to = replayAddrB
+		}
+		return &xycommon.RpcTransaction{
+			From:  replayAddrA,
+			To:    to,
+			Input: "0x" + hex.EncodeToString([]byte("data:,"+content)),
+		}
+	})
+}
+
+// replayOrder binds the asc-20 market order tuple
+type replayOrder struct {
+	Seller         common.Address
+	Creator        common.Address
+	ListId         [32]byte
+	Ticker         string
+	Amount         *big.Int
+	Price          *big.Int
+	Nonce          *big.Int
+	ListingTime    uint64
+	ExpirationTime uint64
+	CreatorFeeRate uint16
+	Salt           uint32
+	ExtraParams    []byte
+	V              uint8
+	R              [32]byte
+	S              [32]byte
+}
+
+// marketBlocks builds asc-20 blocks of A listing to the market C, ops are inscriptions sent by A,
+// or "buy:<block>.<idx>:<price>" filling the listing of the tx by D & "cancel:<block>.<idx>" canceling it by A
+func marketBlocks(txs [][]string) []*xycommon.RpcBlock {
+	data := map[string]string{
+		"deploy":   `{"p":"asc-20","op":"deploy","tick":"dino","max":"1000","lim":"100"}`,
+		"mint":     `{"p":"asc-20","op":"mint","tick":"dino","amt":"100"}`,
+		"list":     `{"p":"asc-20","op":"list","tick":"dino","amt":"30","price":"1.5"}`,
+		"transfer": `{"p":"asc-20","op":"transfer","tick":"dino","amt":"50"}`,
+	}
+
+	return buildBlocks(txs, "0x", func(num uint64, idx int, op string) *xycommon.RpcTransaction {
+		parts := strings.Split(op, ":")
+		if len(parts) == 1 {
+			to := replayAddrA
+			switch op {
+			case "list":
+				to = replayAddrC
+			case "transfer":
+				to = replayAddrB
+			}
+			return &xycommon.RpcTransaction{From: replayAddrA, To: to, Input: "0x" + hex.EncodeToString([]byte("data:,"+data[op]))}
+		}
+
+		var block, pos, price int64
+		_, _ = fmt.Sscanf(parts[1], "%d.%d", &block, &pos)
+		if len(parts) > 2 {
+			_, _ = fmt.Sscanf(parts[2], "%d", &price)
+		}
+		listID := common.BigToHash(big.NewInt(block*100 + pos))
+		order := replayOrder{
+			Seller: common.HexToAddress(replayAddrA),
+			ListId: listID,
+			Ticker: "dino",
+			Amount: big.NewInt(30),
+			Price:  new(big.Int).Mul(big.NewInt(price), big.NewInt(1e18)),
+			Nonce:  big.NewInt(1),
+		}
+
+		// the fake ops are sent to the foreign contract B emitting the market events
+		market := replayAddrC
+		if strings.HasPrefix(parts[0], "fake") {
+			market = replayAddrB
+		}
+
+		from, method, args := replayAddrD, asc20.ParsedABI.Methods["executeOrder"], []interface{}{order, common.HexToAddress(replayAddrD)}
+		if strings.HasSuffix(parts[0], "cancel") {
+			from, method, args = replayAddrA, asc20.ParsedABI.Methods["cancelOrder"], []interface{}{order}
+		}
+		input, _ := method.Inputs.Pack(args...)
+		return &xycommon.RpcTransaction{
+			From:  from,
+			To:    market,
+			Input: "0x" + hex.EncodeToString(append(method.ID, input...)),
+			Events: []xycommon.RpcLog{{
+				Address: common.HexToAddress(market),
+				Topics:  []common.Hash{common.HexToHash(asc20.EventTopicHashExchange), common.HexToHash(market), common.HexToHash(from)},
+				Data:    listID.Bytes(),
+			}},
+		}
+	})
+}
+
+func (n *chainNode) BlockNumber(ctx context.Context) (uint64, error) {
+	return uint64(len(n.blocks)), nil
+}
+
+func (n *chainNode) BlockByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcBlock, error) {
+	block, ok := n.blocks[number.Uint64()]
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+	return block, nil
+}
+
+func (n *chainNode) HeaderByNumber(ctx context.Context, number *big.Int) (*xycommon.RpcHeader, error) {
+	block, err := n.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return &xycommon.RpcHeader{ParentHash: block.ParentHash, Number: block.Number, Time: block.Time, Hash: block.Hash}, nil
+}
+
+func (n *chainNode) TransactionSender(ctx context.Context, txHash, blockHash string, txIndex uint) (string, error) {
+	tx, ok := n.txs[txHash]
+	if !ok {
+		return "", xycommon.ErrNotFound
+	}
+	return tx.From, nil
+}
+
+func (n *chainNode) TransactionReceipt(ctx context.Context, txHash string) (*xycommon.RpcReceipt, error) {
+	receipt, ok := n.receipts[txHash]
+	if !ok {
+		return nil, xycommon.ErrNotFound
+	}
+	return receipt, nil
+}
+
+func (n *chainNode) TraceBlockCalls(ctx context.Context, number *big.Int) ([]*xycommon.RpcTxTrace, error) {
+	if _, ok := n.blocks[number.Uint64()]; !ok {
+		return nil, xycommon.ErrNotFound
+	}
+	return append([]*xycommon.RpcTxTrace{}, n.traces[number.Uint64()]...), nil
+}
+
+func (n *chainNode) BlockBlobs(ctx context.Context, number *big.Int, timestamp uint64) ([]*xycommon.RpcBlob, error) {
+	if _, ok := n.blocks[number.Uint64()]; !ok {
+		return nil, xycommon.ErrNotFound
+	}
+	return append([]*xycommon.RpcBlob{}, n.blobs[number.Uint64()]...), nil
+}
+
+func (n *chainNode) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]xycommon.RpcLog, error) {
+	logs := make([]xycommon.RpcLog, 0, len(n.logs))
+	for _, log := range n.logs {
+		num := log.BlockNumber.ToInt()
+		if num.Cmp(q.FromBlock) >= 0 && num.Cmp(q.ToBlock) <= 0 {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// replayIndex records the node blocks, then indexes the replayed records into a fresh sqlite db
+func replayIndex(t *testing.T, node *chainNode, chain config.ChainConfig, topics ...string) *storage.DBClient {
+	dir := t.TempDir()
+	recordDir := filepath.Join(dir, "records")
+
+	// record the chain data through the recorder
+	recorder, err := replay.NewRecorder(node, recordDir)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	blocks := uint64(len(node.blocks))
+	for num := uint64(1); num <= blocks; num++ {
+		block, err := recorder.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+		assert.NoError(t, err)
+		for _, tx := range block.Transactions {
+			_, err = recorder.TransactionReceipt(ctx, tx.Hash)
+			assert.NoError(t, err)
+		}
+
+		if len(node.traces) > 0 {
+			_, err = recorder.TraceBlockCalls(ctx, block.Number)
+			assert.NoError(t, err)
+		}
+
+		if len(node.blobs) > 0 {
+			_, err = recorder.BlockBlobs(ctx, block.Number, block.Time)
+			assert.NoError(t, err)
+		}
+	}
+
+	_, err = recorder.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(1), ToBlock: new(big.Int).SetUint64(blocks)})
+	assert.NoError(t, err)
+	assert.NoError(t, recorder.Close())
+
+	// replay the records into a fresh sqlite db
+	replayClient, err := replay.Open(recordDir)
+	assert.NoError(t, err)
+
+	latest, err := replayClient.BlockNumber(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks, latest)
+
+	cfg := &config.Config{
+		Scan: config.ScanConfig{
+			StartBlock:        1,
+			BlockBatchWorkers: 1,
+			TxBatchWorkers:    1,
+			TraceCalls:        len(node.traces) > 0,
+		},
+		Chain:   chain,
+		Filters: &config.IndexFilter{EventTopics: topics},
+		Database: config.DatabaseConfig{
+			Type: storage.DatabaseTypeSqlite3,
+			Dsn:  filepath.Join(dir, "indexer.db"),
+		},
+	}
+
+	db, err := storage.NewDbClient(&cfg.Database)
+	assert.NoError(t, err)
+
+	dCache := dcache.NewManager(db, chain.ChainName)
+	assert.NoError(t, protocol.InitProtocols(cfg, dCache))
+
+	eventCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	quit := make(chan os.Signal, 1)
+	dEvent := devents.NewDEvents(eventCtx, db)
+	exp := NewExplorer(replayClient, db, cfg, dCache, dEvent, quit)
+	go exp.Scan()
+	go exp.Index()
+	go exp.FlushDB()
+	defer exp.Stop()
+
+	deadline := time.Now().Add(replayTimeout)
+	for {
+		num, err := db.QueryLastBlock(chain.ChainName)
+		assert.NoError(t, err)
+		if num.Uint64() >= blocks {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("replay indexing timeout, last block[%d]", num.Uint64())
+		}
+		<-time.After(100 * time.Millisecond)
+	}
+	return db
+}
+
+func assertBalances(t *testing.T, db *storage.DBClient, chain, protocol, tick string, balances map[string]string) {
+	for addr, expected := range balances {
+		balance, err := db.FindUserBalanceByTick(chain, protocol, tick, addr)
+		assert.NoError(t, err)
+		if assert.NotNil(t, balance, fmt.Sprintf("address[%s] balance not found", addr)) {
+			assert.Equal(t, expected, balance.Balance.String())
+		}
+	}
+}
+
+func TestReplayIndexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{{"deploy"}, {"mint"}, {"transfer"}}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain})
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "60", replayAddrB: "40"})
+}
+
+func TestReplayHashTransferIndexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{
+		{"deployhash"},
+		{"minthash", "minthash"},
+		// the balance transfer of the hash tick is rejected
+		{"transferhash:2.0", "transferamt"},
+		// the spent utxo is not owned by the sender anymore
+		{"transferhash:2.0", "minthash"},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain})
+	assertBalances(t, db, replayChain, "brc-20", "hash", map[string]string{replayAddrA: "200", replayAddrB: "100"})
+
+	mintHash := common.BigToHash(big.NewInt(200)).Hex()
+	transferHash := common.BigToHash(big.NewInt(300)).Hex()
+
+	utxos, err := db.GetUtxosByAddress(replayAddrA, replayChain, "brc-20", "hash")
+	assert.NoError(t, err)
+	if assert.Len(t, utxos, 2) {
+		assert.Equal(t, common.BigToHash(big.NewInt(401)).Hex(), utxos[0].RootHash)
+		assert.Equal(t, common.BigToHash(big.NewInt(201)).Hex(), utxos[1].RootHash)
+	}
+
+	utxos, err = db.GetUtxosByAddress(replayAddrB, replayChain, "brc-20", "hash")
+	assert.NoError(t, err)
+	if assert.Len(t, utxos, 1) {
+		assert.Equal(t, mintHash, utxos[0].RootHash)
+		assert.Equal(t, transferHash, utxos[0].Sn)
+		assert.Equal(t, "100", utxos[0].Amount.String())
+	}
+
+	spent := &model.UTXO{}
+	assert.NoError(t, db.SqlDB.Where("sn = ?", mintHash).First(spent).Error)
+	assert.Equal(t, int8(model.UTXOStatusSpent), spent.Status)
+	assert.Equal(t, transferHash, spent.SpentHash)
+
+	ins, err := db.FindInscriptionByTick(replayChain, "brc-20", "hash")
+	assert.NoError(t, err)
+	if assert.NotNil(t, ins) {
+		assert.Equal(t, int8(model.TransferTypeHash), ins.TransferType)
+	}
+}
+
+func TestReplayEventCreationIndexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{
+		{"deploy"},
+		{"emit:mint", "mint"},
+		// contract C holds no balance to transfer
+		{"emit:transfer", "emit:mint"},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain})
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "100", replayAddrB: "200"})
+
+	tx, err := db.FindTransaction(replayChain, common.BigToHash(big.NewInt(200)).Hex())
+	assert.NoError(t, err)
+	if assert.NotNil(t, tx) {
+		assert.Equal(t, replayAddrC, tx.From)
+		assert.Equal(t, replayAddrB, tx.To)
+	}
+
+	balance, err := db.FindUserBalanceByTick(replayChain, "brc-20", "test", replayAddrC)
+	assert.NoError(t, err)
+	assert.Nil(t, balance)
+}
+
+func TestReplayERC20Indexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{
+		{"token:0a10000"},
+		// B sends the tokens received in the same tx, the second tx exceeds the balance of B
+		{"token:ab2500,bc1000", "token:bd9999"},
+		// C burns, the brc-20 deploy is indexed alongside
+		{"token:c0500", "deploy"},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{
+		ChainName:   replayChain,
+		ERC20Tokens: []*config.ERC20Token{{Address: strings.ToUpper(replayAddrC), Tick: "USDX", Decimals: 2, DeployBlock: 1}},
+	})
+
+	zero := strings.ToLower(common.Address{}.Hex())
+	assertBalances(t, db, replayChain, "erc-20", "usdx", map[string]string{
+		replayAddrA: "75", replayAddrB: "15", replayAddrC: "5", zero: "5",
+	})
+
+	balance, err := db.FindUserBalanceByTick(replayChain, "erc-20", "usdx", replayAddrD)
+	assert.NoError(t, err)
+	assert.Nil(t, balance)
+
+	inscription, err := db.FindInscriptionByTick(replayChain, "erc-20", "usdx")
+	assert.NoError(t, err)
+	if assert.NotNil(t, inscription) {
+		assert.Equal(t, int8(2), inscription.Decimals)
+		assert.Equal(t, int8(model.TransferTypeBalance), inscription.TransferType)
+		assert.True(t, inscription.TotalSupply.IsZero())
+	}
+
+	stats, err := db.FindInscriptionsStatsByTick(replayChain, "erc-20", "usdx")
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, "100", stats.Minted.String())
+		assert.Equal(t, uint64(4), stats.Holders)
+		assert.Nil(t, stats.MintCompletedTime)
+	}
+
+	var mints int64
+	assert.NoError(t, db.SqlDB.Model(&model.AddressTxs{}).
+		Where("chain = ? AND protocol = ? AND address = ? AND event = ? AND amount = ?", replayChain, "erc-20", replayAddrA, model.TransactionEventMint, 100).
+		Count(&mints).Error)
+	assert.Equal(t, int64(1), mints)
+
+	brc20, err := db.FindInscriptionByTick(replayChain, "brc-20", "test")
+	assert.NoError(t, err)
+	assert.NotNil(t, brc20)
+}
+
+func TestReplayEventAdapterIndexing(t *testing.T) {
+	abiFile := filepath.Join(t.TempDir(), "market.json")
+	assert.NoError(t, os.WriteFile(abiFile, []byte(replaySoldABI), 0644))
+
+	node := newChainNode(evmBlocks([][]string{
+		{"deploy"},
+		{"mint"},
+		// contract D is not on the allowlist
+		{"sold:cd30", "sold:dd30"},
+		// the first sale exceeds the balance of A
+		{"sold:cb1000", "sold:cb20"},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{
+		ChainName: replayChain,
+		EventAdapters: []*config.EventAdapter{{
+			Name:      "market",
+			Protocol:  "brc-20",
+			Abi:       abiFile,
+			Contracts: []string{replayAddrC},
+			Events: []*config.EventMapping{
+				{Event: "Sold", Operate: devents.OperateExchange, TickField: "tick", From: "seller", To: "buyer", Amount: "amount"},
+			},
+		}},
+	})
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "50", replayAddrB: "20", replayAddrD: "30"})
+
+	var exchanges int64
+	assert.NoError(t, db.SqlDB.Model(&model.AddressTxs{}).
+		Where("chain = ? AND protocol = ? AND address = ? AND event = ?", replayChain, "brc-20", replayAddrD, model.TransactionEventExchange).
+		Count(&exchanges).Error)
+	assert.Equal(t, int64(1), exchanges)
+}
+
+func TestReplayListOrdersIndexing(t *testing.T) {
+	node := newChainNode(marketBlocks([][]string{
+		{"deploy"},
+		{"mint"},
+		{"list", "list"},
+		// the transfer exceeds the balance of A out of the escrow
+		{"transfer", "buy:3.0:3", "cancel:3.1"},
+		{"list", "list"},
+		{"buy:5.0:6"},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain}, asc20.EventTopicHashExchange)
+	assertBalances(t, db, replayChain, "asc-20", "dino", map[string]string{replayAddrA: "40", replayAddrD: "60"})
+
+	balance, err := db.FindUserBalanceByTick(replayChain, "asc-20", "dino", replayAddrA)
+	assert.NoError(t, err)
+	if assert.NotNil(t, balance) {
+		assert.Equal(t, "10", balance.Available.String())
+	}
+
+	listID := func(block, idx int64) string {
+		return common.BigToHash(big.NewInt(block*100 + idx)).Hex()
+	}
+	orders := func() map[string]*model.ListOrder {
+		items := make([]*model.ListOrder, 0)
+		assert.NoError(t, db.SqlDB.Where("chain = ?", replayChain).Find(&items).Error)
+		mapped := make(map[string]*model.ListOrder, len(items))
+		for _, item := range items {
+			mapped[item.ListID] = item
+		}
+		return mapped
+	}
+
+	items := orders()
+	assert.Len(t, items, 4)
+	if item := items[listID(3, 0)]; assert.NotNil(t, item) {
+		assert.Equal(t, model.OrderStatusFilled, item.Status)
+		assert.Equal(t, replayAddrD, strings.ToLower(item.Buyer))
+		assert.Equal(t, "3", item.Price.String())
+		assert.Equal(t, listID(4, 1), item.SettleHash)
+	}
+	if item := items[listID(3, 1)]; assert.NotNil(t, item) {
+		assert.Equal(t, model.OrderStatusCanceled, item.Status)
+		assert.Equal(t, "", item.Buyer)
+	}
+
+	open, total, err := db.GetOpenListOrders(replayChain, "asc-20", "dino", replayAddrA, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, open, 1) {
+		assert.Equal(t, listID(5, 1), open[0].ListID)
+		assert.Equal(t, replayAddrC, open[0].Market)
+		assert.Equal(t, "30", open[0].Amount.String())
+		assert.Equal(t, "1.5", open[0].Price.String())
+	}
+
+	floor, err := db.GetFloorPrice(replayChain, "asc-20", "dino")
+	assert.NoError(t, err)
+	if assert.NotNil(t, floor) {
+		assert.Equal(t, "0.05", floor.String())
+	}
+
+	// the fills are traded at the unit prices of 0.1 & 0.2
+	trades, total, err := db.GetTrades(replayChain, "asc-20", "dino", replayAddrD, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, listID(5, 0), trades[0].ListID)
+		assert.Equal(t, listID(6, 0), trades[0].TxHash)
+		assert.Equal(t, replayAddrA, trades[0].Seller)
+		assert.Equal(t, replayAddrC, trades[0].Market)
+		assert.Equal(t, "6", trades[0].Price.String())
+		assert.Equal(t, "0.2", trades[0].UnitPrice.String())
+		assert.Equal(t, "0.1", trades[1].UnitPrice.String())
+	}
+
+	candles, err := db.GetTickCandles(replayChain, "asc-20", "dino", 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, candles, 2) {
+		assert.Equal(t, int64(1700000004), candles[0].Time)
+		assert.Equal(t, "0.1", candles[0].Close.String())
+		assert.Equal(t, "0.2", candles[1].Open.String())
+	}
+
+	candles, err = db.GetTickCandles(replayChain, "asc-20", "dino", 3600, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, candles, 1) {
+		c := candles[0]
+		assert.Equal(t, int64(1700000004/3600*3600), c.Time)
+		assert.Equal(t, []string{"0.1", "0.2", "0.1", "0.2"}, []string{c.Open.String(), c.High.String(), c.Low.String(), c.Close.String()})
+		assert.Equal(t, "60", c.Volume.String())
+		assert.Equal(t, "9", c.Value.String())
+		assert.Equal(t, 2, c.Trades)
+	}
+
+	// the settled orders are reopened, the later listings & the trades are dropped
+	assert.NoError(t, db.RevertBlocks(db.SqlDB, replayChain, 3))
+	items = orders()
+	assert.Len(t, items, 2)
+	for _, item := range items {
+		assert.Equal(t, model.OrderStatusOpen, item.Status)
+		assert.Equal(t, "", item.SettleHash)
+	}
+
+	_, total, err = db.GetTrades(replayChain, "asc-20", "dino", "", 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestReplayForeignMarketIndexing(t *testing.T) {
+	node := newChainNode(marketBlocks([][]string{
+		{"deploy"},
+		{"mint"},
+		{"list"},
+		// the foreign contract B neither fills nor delists the order escrowed by the market C
+		{"fakebuy:3.0:3", "fakecancel:3.0"},
+		{"mint"},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain}, asc20.EventTopicHashExchange)
+
+	balance, err := db.FindUserBalanceByTick(replayChain, "asc-20", "dino", replayAddrA)
+	assert.NoError(t, err)
+	if assert.NotNil(t, balance) {
+		assert.Equal(t, "200", balance.Balance.String())
+		assert.Equal(t, "170", balance.Available.String())
+	}
+
+	balance, err = db.FindUserBalanceByTick(replayChain, "asc-20", "dino", replayAddrD)
+	assert.NoError(t, err)
+	assert.Nil(t, balance)
+
+	open, total, err := db.GetOpenListOrders(replayChain, "asc-20", "dino", replayAddrA, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, open, 1) {
+		assert.Equal(t, common.BigToHash(big.NewInt(300)).Hex(), open[0].ListID)
+		assert.Equal(t, replayAddrC, open[0].Market)
+	}
+}
+
+func TestReplayTraceCallsIndexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{{"deploy"}, {"wallet:mint"}}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain})
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrD: "200"})
+
+	for _, idx := range []int{2, 5} {
+		hash := fmt.Sprintf("%s%s%d", common.BigToHash(big.NewInt(200)).Hex(), xycommon.DerivedHashSeparator, idx)
+		tx, err := db.FindTransaction(replayChain, hash)
+		assert.NoError(t, err)
+		if assert.NotNil(t, tx, hash) {
+			assert.Equal(t, replayAddrD, tx.From)
+			assert.Equal(t, devents.OperateMint, tx.Op)
+		}
+	}
+
+	var txs int64
+	assert.NoError(t, db.SqlDB.Model(&model.Transaction{}).Where("chain = ?", replayChain).Count(&txs).Error)
+	assert.Equal(t, int64(3), txs)
+}
+
+func TestReplayRuleScheduleIndexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{
+		{"deploydec"},
+		{"mintdec3"},
+		{"mintdec3", "mintdec", "deploy"},
+		{"deployhash", "mintdec"},
+	}))
+
+	var (
+		strict   = true
+		decimals = int8(18)
+		maxBytes = 3
+	)
+	db := replayIndex(t, node, config.ChainConfig{
+		ChainName: replayChain,
+		ProtocolRules: []*config.ProtocolRules{
+			{Protocol: "brc-20", Height: 3, StrictDecimals: &strict, DefaultDecimals: &decimals},
+			{Protocol: "brc-20", Height: 4, TickMaxBytes: &maxBytes},
+			{Protocol: "bsc-20", StrictDecimals: &strict},
+		},
+	})
+
+	// the tick deployed before the height 3 keeps accepting the amounts exceeding its decimals
+	assertBalances(t, db, replayChain, "brc-20", "deci", map[string]string{replayAddrA: "5.01"})
+
+	for tick, expected := range map[string]struct {
+		decimals int8
+		strict   bool
+	}{"deci": {2, false}, "test": {18, true}} {
+		ins, err := db.FindInscriptionByTick(replayChain, "brc-20", tick)
+		assert.NoError(t, err)
+		if assert.NotNil(t, ins, tick) {
+			assert.Equal(t, expected.decimals, ins.Decimals, tick)
+			assert.Equal(t, expected.strict, ins.StrictDecimals, tick)
+		}
+	}
+
+	// 4 bytes ticks are not deployable from the height 4
+	ins, err := db.FindInscriptionByTick(replayChain, "brc-20", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, ins)
+}
+
+func TestReplayDataURIIndexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{{"gzip:deploy"}, {"base64:mint", "gzip:mint", "mint"}}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain})
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "300"})
+}
+
+func TestReplayBlobIndexing(t *testing.T) {
+	node := newChainNode(evmBlocks([][]string{{"deploy"}, {"blob:mint", "mint"}}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: replayChain})
+	assertBalances(t, db, replayChain, "brc-20", "test", map[string]string{replayAddrA: "200"})
+
+	hash := common.BigToHash(big.NewInt(200)).Hex() + xycommon.DerivedHashSeparator + "blob"
+	tx, err := db.FindTransaction(replayChain, hash)
+	assert.NoError(t, err)
+	if assert.NotNil(t, tx, hash) {
+		assert.Equal(t, replayAddrA, tx.From)
+		assert.Equal(t, devents.OperateMint, tx.Op)
+	}
+}
+
+// ethBlocks builds ethscriptions blocks, ops are "create:<content>:<to>" & "create6:<content>:<to>" created by A,
+// "send:<block>.<idx>:<from>:<to>" calldata transfers, "esip1:<block>.<idx>:<contract>:<to>" &
+// "esip2:<block>.<idx>:<contract>:<previous>:<to>" event transfers, addresses are "a" / "b" / "c"
+func ethBlocks(txs [][]string) []*xycommon.RpcBlock {
+	addrs := map[string]string{"a": replayAddrA, "b": replayAddrB, "c": replayAddrC}
+	return buildBlocks(txs, "0x", func(num uint64, idx int, op string) *xycommon.RpcTransaction {
+		parts := strings.Split(op, ":")
+		id := common.Hash{}
+		if len(parts) > 1 {
+			var block, pos int64
+			_, _ = fmt.Sscanf(parts[1], "%d.%d", &block, &pos)
+			id = common.BigToHash(big.NewInt(block*100 + pos))
+		}
+
+		tx := &xycommon.RpcTransaction{From: replayAddrA, Input: "0x"}
+		switch parts[0] {
+		case "create":
+			tx.To = addrs[parts[2]]
+			tx.Input = "0x" + hex.EncodeToString([]byte("data:,"+parts[1]))
+		case "create6":
+			tx.To = addrs[parts[2]]
+			tx.Input = "0x" + hex.EncodeToString([]byte("data:;rule=esip6,"+parts[1]))
+		case "send":
+			tx.From, tx.To = addrs[parts[2]], addrs[parts[3]]
+			tx.Input = id.Hex()
+		case "esip1":
+			tx.To = addrs[parts[2]]
+			tx.Events = []xycommon.RpcLog{{
+				Address: common.HexToAddress(tx.To),
+				Topics:  []common.Hash{common.HexToHash(ethscriptions.EventTopicHashTransfer), common.HexToHash(addrs[parts[3]]), id},
+			}}
+		case "esip2":
+			tx.To = addrs[parts[2]]
+			tx.Events = []xycommon.RpcLog{{
+				Address: common.HexToAddress(tx.To),
+				Topics: []common.Hash{
+					common.HexToHash(ethscriptions.EventTopicHashTransferForPreviousOwner),
+					common.HexToHash(addrs[parts[3]]),
+					common.HexToHash(addrs[parts[4]]),
+					id,
+				},
+			}}
+		}
+		return tx
+	})
+}
+
+func TestReplayEthscriptionsIndexing(t *testing.T) {
+	node := newChainNode(ethBlocks([][]string{
+		{"create:x:c", "create:y:b"},
+		// duplicated content is rejected unless esip-6 applied, A does not own 1.1
+		{"create:x:b", "create6:x:b", "send:1.1:a:b"},
+		{"send:1.1:b:a", "esip1:1.0:c:b"},
+		// previous owner of 2.1 is A, the one of 1.0 is C
+		{"esip2:2.1:b:c:a", "esip2:1.0:b:c:a"},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: model.ChainETH})
+
+	owners := func() map[string][2]string {
+		items := make([]*model.Ethscription, 0)
+		assert.NoError(t, db.SqlDB.Where("chain = ?", model.ChainETH).Order("id asc").Find(&items).Error)
+
+		ret := make(map[string][2]string, len(items))
+		for _, item := range items {
+			assert.Equal(t, replayAddrA, item.Creator)
+			assert.Equal(t, "text/plain", item.MimeType)
+			ret[item.EthscriptionID] = [2]string{item.CurrentOwner, item.PreviousOwner}
+		}
+		return ret
+	}
+
+	id := func(num int64) string {
+		return common.BigToHash(big.NewInt(num)).Hex()
+	}
+	assert.Equal(t, map[string][2]string{
+		id(100): {replayAddrA, replayAddrB},
+		id(101): {replayAddrA, replayAddrB},
+		id(201): {replayAddrB, replayAddrA},
+	}, owners())
+
+	var transfers int64
+	assert.NoError(t, db.SqlDB.Model(&model.EthscriptionTransfer{}).Where("chain = ?", model.ChainETH).Count(&transfers).Error)
+	assert.Equal(t, int64(6), transfers)
+
+	// owners are restored by the remaining transfers
+	assert.NoError(t, db.RevertBlocks(db.SqlDB, model.ChainETH, 2))
+	assert.Equal(t, map[string][2]string{
+		id(100): {replayAddrC, replayAddrA},
+		id(101): {replayAddrB, replayAddrA},
+		id(201): {replayAddrB, replayAddrA},
+	}, owners())
+
+	assert.NoError(t, db.RevertBlocks(db.SqlDB, model.ChainETH, 1))
+	assert.Len(t, owners(), 2)
+}
+
+// btcBlocks builds regtest like blocks, ops are "<op>:<receiver>" revealing brc-20 inscriptions to the receiver,
+// or "send:<block>.<idx>:<receiver>" sending the inscription revealed by the tx, "fee" receiver loses it to the fee
+func btcBlocks(txs [][]string) []*xycommon.RpcBlock {
+	data := map[string]string{
+		"deploy":      `{"p":"brc-20","op":"deploy","tick":"ordi","max":"1000","lim":"100"}`,
+		"mint":        `{"p":"brc-20","op":"mint","tick":"ordi","amt":"100"}`,
+		"mint50":      `{"p":"brc-20","op":"mint","tick":"ordi","amt":"50"}`,
+		"transfer10":  `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"10"}`,
+		"transfer20":  `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"20"}`,
+		"transfer30":  `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"30"}`,
+		"transfer500": `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"500"}`,
+	}
+
+	witness := func(op string) []string {
+		script, _ := txscript.NewScriptBuilder().
+			AddData(bytes.Repeat([]byte{0x02}, 32)).AddOp(txscript.OP_CHECKSIG).
+			AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData([]byte("ord")).
+			AddOp(txscript.OP_1).AddData([]byte("text/plain;charset=utf-8")).
+			AddOp(txscript.OP_0).AddData([]byte(data[op])).
+			AddOp(txscript.OP_ENDIF).Script()
+		return []string{
+			hex.EncodeToString(bytes.Repeat([]byte{0x03}, 64)),
+			hex.EncodeToString(script),
+			hex.EncodeToString(append([]byte{0xc0}, bytes.Repeat([]byte{0x04}, 32)...)),
+		}
+	}
+
+	return buildBlocks(txs, "", func(num uint64, idx int, op string) *xycommon.RpcTransaction {
+		parts := strings.Split(op, ":")
+		receiver := parts[len(parts)-1]
+		tx := &xycommon.RpcTransaction{
+			From: receiver,
+			To:   receiver,
+			Vout: []btcjson.Vout{{Value: 0.00000546, ScriptPubKey: btcjson.ScriptPubKeyResult{Address: receiver}}},
+		}
+
+		switch parts[0] {
+		case "coinbase":
+			tx.From = ""
+			tx.Vin = []btcjson.Vin{{Coinbase: "0100"}}
+			return tx
+		case "send":
+			var block, pos uint64
+			_, _ = fmt.Sscanf(parts[1], "%d.%d", &block, &pos)
+			tx.Vin = []btcjson.Vin{
+				{Txid: common.BigToHash(big.NewInt(int64(num*100 + uint64(idx) + 5000))).Hex()[2:], Vout: 1},
+				{Txid: common.BigToHash(big.NewInt(int64(block*100 + pos))).Hex()[2:], Vout: 0},
+			}
+			tx.Prevouts = []btcjson.Vout{{Value: 0.0001}, {Value: 0.00000546}}
+			tx.Vout[0].Value = 0.0001 + 0.00000546
+			if receiver == "fee" {
+				tx.To = ""
+				tx.Vout[0].Value = 0.0001
+				tx.Vout[0].ScriptPubKey.Address = ""
+			}
+			return tx
+		case "multi":
+			// the funding input reveals the optional inscription, all sats go to the receiver
+			tx.Vin = []btcjson.Vin{{Txid: common.BigToHash(big.NewInt(int64(num*100 + uint64(idx) + 5000))).Hex()[2:], Vout: 1}}
+			tx.Prevouts = []btcjson.Vout{{Value: 0.0001}}
+			if parts[2] != "" {
+				tx.Vin[0].Witness = witness(parts[2])
+			}
+			for _, sent := range strings.Split(parts[1], ",") {
+				var block, pos int64
+				_, _ = fmt.Sscanf(sent, "%d.%d", &block, &pos)
+				tx.Vin = append(tx.Vin, btcjson.Vin{Txid: common.BigToHash(big.NewInt(block*100 + pos)).Hex()[2:], Vout: 0})
+				tx.Prevouts = append(tx.Prevouts, btcjson.Vout{Value: 0.00000546})
+			}
+			tx.Vout[0].Value = 0.0001 + float64(len(tx.Vin)-1)*0.00000546
+			return tx
+		}
+
+		tx.Vin = []btcjson.Vin{{
+			Txid:    common.BigToHash(big.NewInt(int64(num*100 + uint64(idx) + 5000))).Hex()[2:],
+			Witness: witness(parts[0]),
+		}}
+		return tx
+	})
+}
+
+func TestReplayBTCIndexing(t *testing.T) {
+	const (
+		addrA = "bcrt1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqp3mvzv"
+		addrB = "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080"
+	)
+
+	node := newChainNode(btcBlocks([][]string{
+		{"coinbase:" + addrA, "deploy:" + addrA},
+		{"coinbase:" + addrA, "mint:" + addrA},
+		{"coinbase:" + addrA, "mint50:" + addrB},
+		// inscribe transfers, the one over the available balance is invalid
+		{"coinbase:" + addrA, "transfer30:" + addrA, "transfer500:" + addrA, "transfer30:" + addrA},
+		// settle to B, the invalid inscription is ignored
+		{"coinbase:" + addrA, "send:4.1:" + addrB, "send:4.2:" + addrB},
+		// double spent inscription is rejected, the one lost to the fee returns to A
+		{"coinbase:" + addrA, "send:4.1:" + addrB, "send:4.3:fee"},
+		// inscribed & sent in the same block, the last one stays pending
+		{"coinbase:" + addrA, "transfer10:" + addrB, "send:7.1:" + addrA, "transfer20:" + addrA},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: model.ChainBTC, ChainGroup: model.BtcChainGroup})
+	assertBalances(t, db, model.ChainBTC, "brc-20", "ordi", map[string]string{addrA: "80", addrB: "70"})
+
+	for addr, expected := range map[string]string{addrA: "60", addrB: "70"} {
+		balance, err := db.FindUserBalanceByTick(model.ChainBTC, "brc-20", "ordi", addr)
+		assert.NoError(t, err)
+		if assert.NotNil(t, balance) {
+			assert.Equal(t, expected, balance.Available.String())
+		}
+	}
+
+	ins, err := db.FindInscriptionByTick(model.ChainBTC, "brc-20", "ordi")
+	assert.NoError(t, err)
+	if assert.NotNil(t, ins) {
+		assert.Equal(t, addrA, ins.DeployBy)
+	}
+
+	utxos := make([]*model.UTXO, 0)
+	assert.NoError(t, db.SqlDB.Order("id asc").Find(&utxos).Error)
+	if assert.Len(t, utxos, 4) {
+		txHash := func(num, idx int64) string {
+			return common.BigToHash(big.NewInt(num*100 + idx)).Hex()[2:]
+		}
+		assert.Equal(t, txHash(4, 1)+"i0", utxos[0].Sn)
+		assert.Equal(t, txHash(5, 1), utxos[0].SpentHash)
+		assert.Equal(t, txHash(6, 2), utxos[1].SpentHash)
+		assert.Equal(t, txHash(7, 2), utxos[2].SpentHash)
+		for _, utxo := range utxos[:3] {
+			assert.Equal(t, int8(model.UTXOStatusSpent), utxo.Status)
+		}
+		assert.Equal(t, int8(model.UTXOStatusUnspent), utxos[3].Status)
+		assert.Equal(t, addrA, utxos[3].Address)
+		assert.Equal(t, "20", utxos[3].Amount.String())
+	}
+}
+
+// runesBlocks builds regtest like blocks of the runestones, ops are "etch:<receiver>" etching TEST•RUNE
+// with the premine, "mint:<receiver>" minting it, "send:<block>.<idx>.<vout>:<sender>:<receiver>:<amount>"
+// sending the amount by the edict & the change to the sender, or "move:<block>.<idx>.<vout>:<receiver>"
+// moving all runes of the utxo without runestone
+func runesBlocks(txs [][]string) []*xycommon.RpcBlock {
+	outpoint := func(s string) btcjson.Vin {
+		var block, pos, vout uint64
+		_, _ = fmt.Sscanf(s, "%d.%d.%d", &block, &pos, &vout)
+		return btcjson.Vin{Txid: common.BigToHash(big.NewInt(int64(block*100 + pos))).Hex()[2:], Vout: uint32(vout)}
+	}
+	output := func(addr string) btcjson.Vout {
+		return btcjson.Vout{Value: 0.00000546, ScriptPubKey: btcjson.ScriptPubKeyResult{Address: addr}}
+	}
+	runestone := func(stone *runes.Runestone) btcjson.Vout {
+		return btcjson.Vout{ScriptPubKey: btcjson.ScriptPubKeyResult{Hex: hex.EncodeToString(stone.Encipher())}}
+	}
+
+	return buildBlocks(txs, "", func(num uint64, idx int, op string) *xycommon.RpcTransaction {
+		parts := strings.Split(op, ":")
+		tx := &xycommon.RpcTransaction{
+			Vin: []btcjson.Vin{{Txid: common.BigToHash(big.NewInt(int64(num*100 + uint64(idx) + 5000))).Hex()[2:], Vout: 1}},
+		}
+
+		switch parts[0] {
+		case "coinbase":
+			tx.Vin = []btcjson.Vin{{Coinbase: "0100"}}
+			tx.Vout = []btcjson.Vout{output(parts[1])}
+		case "etch":
+			name, _ := runes.ParseName("TESTRUNE")
+			tx.From = parts[1]
+			tx.Vout = []btcjson.Vout{runestone(&runes.Runestone{
+				Etching: &runes.Etching{
+					Divisibility: 2,
+					Premine:      big.NewInt(1000),
+					Rune:         name,
+					Spacers:      1 << 3,
+					Terms:        &runes.Terms{Amount: big.NewInt(500), Cap: big.NewInt(2)},
+				},
+			}), output(parts[1])}
+		case "mint":
+			tx.From = parts[1]
+			tx.Vout = []btcjson.Vout{runestone(&runes.Runestone{Mint: &runes.RuneID{Block: 1, Tx: 1}}), output(parts[1])}
+		case "send":
+			amount, _ := new(big.Int).SetString(parts[4], 10)
+			pointer := uint32(2)
+			tx.From = parts[2]
+			tx.Vin = append(tx.Vin, outpoint(parts[1]))
+			tx.Vout = []btcjson.Vout{runestone(&runes.Runestone{
+				Edicts:  []*runes.Edict{{ID: runes.RuneID{Block: 1, Tx: 1}, Amount: amount, Output: 1}},
+				Pointer: &pointer,
+			}), output(parts[3]), output(parts[2])}
+		case "move":
+			tx.Vin = append(tx.Vin, outpoint(parts[1]))
+			tx.Vout = []btcjson.Vout{output(parts[2])}
+		}
+		return tx
+	})
+}
+
+func TestReplayBTCMultiSendIndexing(t *testing.T) {
+	const (
+		addrA = "bcrt1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqp3mvzv"
+		addrB = "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080"
+	)
+
+	node := newChainNode(btcBlocks([][]string{
+		{"coinbase:" + addrA, "deploy:" + addrA},
+		{"coinbase:" + addrA, "mint:" + addrA},
+		{"coinbase:" + addrA, "transfer30:" + addrA, "transfer20:" + addrA},
+		// both inscriptions return to A, the transfer inscription revealed by the same tx is inscribed too
+		{"coinbase:" + addrA, "multi:3.1,3.2:transfer10:" + addrA},
+		// the spends without reveal settle all inscriptions to B
+		{"coinbase:" + addrA, "transfer10:" + addrA, "transfer20:" + addrA},
+		{"coinbase:" + addrA, "multi:4.1,5.1,5.2::" + addrB},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: model.ChainBTC, ChainGroup: model.BtcChainGroup})
+	assertBalances(t, db, model.ChainBTC, "brc-20", "ordi", map[string]string{addrA: "60", addrB: "40"})
+
+	for addr, expected := range map[string]string{addrA: "60", addrB: "40"} {
+		balance, err := db.FindUserBalanceByTick(model.ChainBTC, "brc-20", "ordi", addr)
+		assert.NoError(t, err)
+		if assert.NotNil(t, balance) {
+			assert.Equal(t, expected, balance.Available.String())
+		}
+	}
+
+	utxos := make([]*model.UTXO, 0)
+	assert.NoError(t, db.SqlDB.Order("id asc").Find(&utxos).Error)
+	assert.Len(t, utxos, 5)
+	for _, utxo := range utxos {
+		assert.Equal(t, int8(model.UTXOStatusSpent), utxo.Status, utxo.Sn)
+	}
+}
+
+func TestReplayRunesIndexing(t *testing.T) {
+	const (
+		addrA = "bcrt1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqp3mvzv"
+		addrB = "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080"
+		addrC = "bcrt1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qzf4jry"
+		addrD = "bcrt1q6rhpng9evdsfnn833a4f4vej0asu6dk5srld6x"
+	)
+
+	node := newChainNode(runesBlocks([][]string{
+		{"coinbase:" + addrA, "etch:" + addrA},
+		// the third mint is over the cap
+		{"coinbase:" + addrA, "mint:" + addrB, "mint:" + addrB, "mint:" + addrB},
+		{"coinbase:" + addrA, "send:1.1.1:" + addrA + ":" + addrC + ":300"},
+		// moved twice in the same block without runestone
+		{"coinbase:" + addrA, "move:3.1.1:" + addrD, "move:4.1.0:" + addrB},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: model.ChainBTC, ChainGroup: model.BtcChainGroup})
+	assertBalances(t, db, model.ChainBTC, "runes", "testrune", map[string]string{addrA: "7", addrB: "13", addrC: "0", addrD: "0"})
+
+	ins, err := db.FindInscriptionByTick(model.ChainBTC, "runes", "testrune")
+	assert.NoError(t, err)
+	if assert.NotNil(t, ins) {
+		assert.Equal(t, "TEST•RUNE", ins.Name)
+		assert.Equal(t, int8(2), ins.Decimals)
+		assert.Equal(t, "20", ins.TotalSupply.String())
+		assert.Equal(t, addrA, ins.DeployBy)
+	}
+
+	stats, err := db.FindInscriptionsStatsByTick(model.ChainBTC, "runes", "testrune")
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, "20", stats.Minted.String())
+		assert.Equal(t, uint64(2), stats.Holders)
+	}
+
+	items := make([]*model.Rune, 0)
+	assert.NoError(t, db.SqlDB.Find(&items).Error)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "1:1", items[0].RuneID)
+		assert.Equal(t, "1000", items[0].Premine.String())
+	}
+
+	// balances are bound to the unspent outputs
+	unspent := make([]*model.UTXO, 0)
+	assert.NoError(t, db.SqlDB.Where("status = ?", model.UTXOStatusUnspent).Order("id asc").Find(&unspent).Error)
+	amounts := make(map[string]string, len(unspent))
+	for _, utxo := range unspent {
+		amounts[utxo.Sn] = utxo.Address + "=" + utxo.Amount.String()
+	}
+	sn := func(num, idx int64, vout uint32) string {
+		return runes.Outpoint(common.BigToHash(big.NewInt(num*100 + idx)).Hex()[2:], vout)
+	}
+	assert.Equal(t, map[string]string{
+		sn(2, 1, 1): addrB + "=5",
+		sn(2, 2, 1): addrB + "=5",
+		sn(3, 1, 2): addrA + "=7",
+		sn(4, 2, 0): addrB + "=3",
+	}, amounts)
+
+	balances, _, err := db.GetAddressInscriptions(10, 0, addrB, model.ChainBTC, "runes", "", storage.OrderByModeDesc)
+	assert.NoError(t, err)
+	if assert.Len(t, balances, 1) {
+		assert.Equal(t, "TEST•RUNE", balances[0].Name)
+		assert.Equal(t, int8(2), balances[0].Decimals)
+	}
+
+	// the etching is reverted with the tick
+	assert.NoError(t, db.RevertBlocks(db.SqlDB, model.ChainBTC, 0))
+	items = make([]*model.Rune, 0)
+	assert.NoError(t, db.SqlDB.Find(&items).Error)
+	assert.Len(t, items, 0)
+
+	ins, err = db.FindInscriptionByTick(model.ChainBTC, "runes", "testrune")
+	assert.NoError(t, err)
+	assert.Nil(t, ins)
+}
+
+// cosmosBlocks builds blocks of bank sends with memo inscriptions, ops are "<op>:<sender>:<receiver>",
+// "base64:" prefixed ops carry the base64 encoded memo, "plain:" prefixed ones carry no inscription
+func cosmosBlocks(txs [][]string) []*xycommon.RpcBlock {
+	data := map[string]string{
+		"deploy":   `{"p":"cia-20","op":"deploy","tick":"cias","max":"1000","lim":"100"}`,
+		"mint":     `{"p":"cia-20","op":"mint","tick":"cias","amt":"100"}`,
+		"transfer": `{"p":"cia-20","op":"transfer","tick":"cias","amt":"40"}`,
+	}
+
+	return buildBlocks(txs, "", func(num uint64, idx int, op string) *xycommon.RpcTransaction {
+		parts := strings.Split(op, ":")
+		tx := &xycommon.RpcTransaction{
+			Hash: strings.ToUpper(common.BigToHash(big.NewInt(int64(num*100 + uint64(idx)))).Hex()[2:]),
+			From: parts[len(parts)-2],
+			To:   parts[len(parts)-1],
+		}
+
+		switch parts[0] {
+		case "base64":
+			tx.Memo = base64.StdEncoding.EncodeToString([]byte("data:," + data[parts[1]]))
+		case "plain":
+			tx.Memo = data[parts[1]]
+		default:
+			tx.Memo = "data:," + data[parts[0]]
+		}
+		return tx
+	})
+}
+
+func TestReplayCosmosIndexing(t *testing.T) {
+	const (
+		addrA = "celestia142424242424242424242424242424242v52yp3"
+		addrB = "celestia1hwamhwamhwamhwamhwamhwamhwamhwam72ae5r"
+	)
+
+	node := newChainNode(cosmosBlocks([][]string{
+		{"deploy:" + addrA + ":" + addrA},
+		{"mint:" + addrA + ":" + addrA, "base64:mint:" + addrB + ":" + addrB, "plain:mint:" + addrB + ":" + addrB},
+		{"transfer:" + addrA + ":" + addrB},
+	}))
+	db := replayIndex(t, node, config.ChainConfig{ChainName: model.ChainCelestia, ChainGroup: model.CosmosChainGroup})
+	assertBalances(t, db, model.ChainCelestia, "cia-20", "cias", map[string]string{addrA: "60", addrB: "140"})
+
+	ins, err := db.FindInscriptionByTick(model.ChainCelestia, "cia-20", "cias")
+	assert.NoError(t, err)
+	if assert.NotNil(t, ins) {
+		assert.Equal(t, addrA, ins.DeployBy)
+	}
+}
@@ -32,17 +32,33 @@ import (
 	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol"
 	"github.com/uxuycom/indexer/storage"
 	"github.com/uxuycom/indexer/xylog"
 	"golang.org/x/sync/errgroup"
 	"math/big"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 )
 
+// ResumeBlock returns the block the scan starts from, the one after the last indexed block,
+// the configured start block before any block indexed
+func ResumeBlock(db *storage.DBClient, cfg *config.Config) (uint64, error) {
+	blockNum, err := db.QueryLastBlock(cfg.Chain.ChainName)
+	if err != nil {
+		return 0, err
+	}
+
+	if blockNum.Uint64() > 0 {
+		return blockNum.Uint64() + 1, nil
+	}
+	return cfg.Scan.StartBlock, nil
+}
+
 type Explorer struct {
 	config          *config.Config
 	node            xycommon.IRPCClient
@@ -56,6 +72,13 @@ type Explorer struct {
 	dEvent          *devents.DEvent
 	latestBlockNum  atomic.Uint64
 	currentBlockNum atomic.Uint64
+	headNotify      chan struct{}
+
+	// indexed chain state, only accessed by the index routine
+	indexedBlockNum  uint64
+	indexedBlockHash string
+	recentBlocks     map[uint64]string
+	receiptMode      string
 }
 
 func NewExplorer(rpcClient xycommon.IRPCClient, dbc *storage.DBClient, cfg *config.Config, dCache *dcache.Manager, dEvent *devents.DEvent, quit chan os.Signal) *Explorer {
@@ -72,7 +95,9 @@ func NewExplorer(rpcClient xycommon.IRPCClient, dbc *storage.DBClient, cfg *conf
 		config:          cfg,
 		dCache:          dCache,
 		blocks:          make(chan *xycommon.RpcBlock, 100),
+		headNotify:      make(chan struct{}, 1),
 		txResultHandler: txResultHandler,
+		recentBlocks:    make(map[uint64]string, defaultReorgDepth),
 
 		dEvent: dEvent,
 	}
@@ -91,16 +116,11 @@ func (e *Explorer) Scan() {
 	xylog.Logger.Infof("start scanning...")
 
 	// Prioritize using data retrieved from the database
-	blockNum, err := e.db.QueryLastBlock(e.config.Chain.ChainName)
+	startBlock, err := ResumeBlock(e.db, e.config)
 	if err != nil {
 		xylog.Logger.Fatalf("load hisotry block index err:%v", err)
 	}
 
-	startBlock := e.config.Scan.StartBlock
-	if blockNum.Uint64() > 0 {
-		startBlock = blockNum.Uint64() + 1
-	}
-
 	// update latest block number
 	go e.updateBlockLatestNumberTiming()
 
@@ -117,14 +137,14 @@ func (e *Explorer) Scan() {
 		latestBlockNum := e.latestBlockNum.Load()
 		if latestBlockNum < 1 {
 			xylog.Logger.Infof("latest block number is zero. chain:%s", e.config.Chain.ChainName)
-			<-time.After(time.Second)
+			e.waitNewHead()
 			continue
 		}
 
 		// wait more blocks for safety
 		if startBlock > (latestBlockNum - e.config.Scan.DelayedBlockNum) {
 			xylog.Logger.Infof("current block number[%d] is too close to the latest block number[%d]. chain:%s", startBlock, latestBlockNum, e.config.Chain.ChainName)
-			<-time.After(time.Second)
+			e.waitNewHead()
 			continue
 		}
 
@@ -143,8 +163,8 @@ func (e *Explorer) Scan() {
 			continue
 		}
 
-		// update current block number
-		e.currentBlockNum.Store(endBlock + 1)
+		// update current block number, skipped if the indexer rolled it back meanwhile
+		e.currentBlockNum.CompareAndSwap(startBlock, endBlock+1)
 	}
 }
 
@@ -153,8 +173,23 @@ func (e *Explorer) updateBlockLatestNumberTiming() {
 		e.cancel()
 	}()
 
+	// push mode, driven by new heads subscription
+	if subscriber, ok := e.node.(xycommon.IHeadSubscriber); ok && e.subscriptionEnabled() {
+		e.watchNewHeads(subscriber)
+		return
+	}
+	e.pollLatestBlockNumber(0)
+}
+
+// pollLatestBlockNumber refresh the latest block number every second, polling forever if duration is zero
+func (e *Explorer) pollLatestBlockNumber(duration time.Duration) {
 	_ = e.syncLatestBlockNumber()
 
+	var deadline <-chan time.Time
+	if duration > 0 {
+		deadline = time.After(duration)
+	}
+
 	t := time.NewTicker(1 * time.Second)
 	defer t.Stop()
 	for {
@@ -163,6 +198,8 @@ func (e *Explorer) updateBlockLatestNumberTiming() {
 			if err := e.syncLatestBlockNumber(); err != nil {
 				xylog.Logger.Errorf("failed to obtain the current block height. chain:%s err=%s", e.config.Chain.ChainName, err)
 			}
+		case <-deadline:
+			return
 		case <-e.ctx.Done():
 			return
 		}
@@ -184,21 +221,22 @@ func (e *Explorer) syncLatestBlockNumber() error {
 		return errors.New("block number is zero")
 	}
 
-	e.latestBlockNum.Store(num)
+	e.updateLatestBlockNum(num)
 	xylog.Logger.Info("latestBlockNum:", num)
 	return nil
 }
 
 func (e *Explorer) scanLogs(startBlock, endBlock uint64, result chan map[string][]xycommon.RpcLog) {
-	if e.config.Filters == nil || len(e.config.Filters.EventTopics) <= 0 {
+	eventTopics := e.eventTopics()
+	if len(eventTopics) <= 0 {
 		result <- nil
 		return
 	}
 
 	// filter Logs
 	topics := [][]common.Hash{{}}
-	topics[0] = make([]common.Hash, 0, len(e.config.Filters.EventTopics))
-	for _, ts := range e.config.Filters.EventTopics {
+	topics[0] = make([]common.Hash, 0, len(eventTopics))
+	for _, ts := range eventTopics {
 		topics[0] = append(topics[0], common.HexToHash(ts))
 	}
 
@@ -231,6 +269,29 @@ DoFilter:
 	result <- groupLogs
 }
 
+// eventTopics merges the configured filter topics with the ones of the enabled protocols
+func (e *Explorer) eventTopics() []string {
+	topics := make([]string, 0)
+	if e.config.Filters != nil {
+		topics = append(topics, e.config.Filters.EventTopics...)
+	}
+
+	for _, topic := range protocol.EventTopics() {
+		exist := false
+		for _, v := range topics {
+			if strings.EqualFold(v, topic) {
+				exist = true
+				break
+			}
+		}
+
+		if !exist {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
 func (e *Explorer) batchScan(startBlock, endBlock uint64) error {
 	startTs := time.Now()
 	defer func() {
@@ -253,6 +314,16 @@ func (e *Explorer) batchScan(startBlock, endBlock uint64) error {
 				xylog.Logger.Errorf("scan call rpc BlockByNumber[%d], err=%s", blockNum, err)
 				return err
 			}
+
+			if err = e.attachTraces(ctx, block); err != nil {
+				xylog.Logger.Errorf("scan call rpc TraceBlockCalls[%d], err=%s", blockNum, err)
+				return err
+			}
+
+			if err = e.attachBlobs(ctx, block); err != nil {
+				xylog.Logger.Errorf("scan call rpc BlockBlobs[%d], err=%s", blockNum, err)
+				return err
+			}
 			blockMap.Store(blockNum, block)
 			return nil
 		})
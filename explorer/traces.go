@@ -0,0 +1,101 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/protocol"
+	"strings"
+)
+
+// attachTraces attaches the call traces to the block txs in trace calls mode
+func (e *Explorer) attachTraces(ctx context.Context, block *xycommon.RpcBlock) error {
+	if !e.config.Scan.TraceCalls || len(block.Transactions) < 1 {
+		return nil
+	}
+
+	tc, ok := e.node.(xycommon.ITraceClient)
+	if !ok {
+		return fmt.Errorf("trace calls mode: %w", xycommon.ErrMethodNotSupported)
+	}
+
+	traces, err := tc.TraceBlockCalls(ctx, block.Number)
+	if err != nil {
+		return err
+	}
+
+	frames := make(map[string]*xycommon.RpcCallFrame, len(traces))
+	for i, trace := range traces {
+		hash := trace.TxHash
+		if hash == "" && i < len(block.Transactions) {
+			hash = block.Transactions[i].Hash
+		}
+		frames[receiptKey(hash)] = trace.Result
+	}
+
+	for _, tx := range block.Transactions {
+		tx.Trace = frames[receiptKey(tx.Hash)]
+	}
+	return nil
+}
+
+// tracedTxs extracts the "data:" calldata of the succeeded internal calls as separate txs,
+// sent by the calling contract (e.g. the smart wallet of the user operation) & ordered by the call order.
+// the call index is appended to the tx hash, the top level call is indexed by the tx itself
+func (e *Explorer) tracedTxs(tx *xycommon.RpcTransaction) []*xycommon.RpcTransaction {
+	if tx.Trace == nil || tx.Trace.Error != "" {
+		return nil
+	}
+
+	txs := make([]*xycommon.RpcTransaction, 0)
+	idx := 0
+	var walk func(frames []*xycommon.RpcCallFrame)
+	walk = func(frames []*xycommon.RpcCallFrame) {
+		for _, frame := range frames {
+			idx++
+
+			// calls of the reverted frame are reverted too
+			if frame == nil || frame.Error != "" {
+				continue
+			}
+
+			if strings.EqualFold(frame.Type, "CALL") && frame.To != "" {
+				item := *tx
+				item.Hash = fmt.Sprintf("%s%s%d", tx.Hash, xycommon.DerivedHashSeparator, idx)
+				item.From = strings.ToLower(frame.From)
+				item.To = strings.ToLower(frame.To)
+				item.Input = strings.ToLower(frame.Input)
+				item.Events = nil
+				item.Trace = nil
+				if protocol.MatchTx(&item) {
+					txs = append(txs, &item)
+				}
+			}
+			walk(frame.Calls)
+		}
+	}
+	walk(tx.Trace.Calls)
+	return txs
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package explorer
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/protocol"
+	"testing"
+)
+
+func TestTracedTxs(t *testing.T) {
+	assert.NoError(t, protocol.InitProtocols(&config.Config{Chain: config.ChainConfig{ChainName: replayChain}}, nil))
+
+	blocks := evmBlocks([][]string{{"deploy", "wallet:mint"}})
+	node := newChainNode(blocks)
+	e := &Explorer{
+		ctx:    context.Background(),
+		node:   node,
+		config: &config.Config{Scan: config.ScanConfig{TraceCalls: true}},
+	}
+	assert.NoError(t, e.attachTraces(context.Background(), blocks[0]))
+
+	deploy, wallet := blocks[0].Transactions[0], blocks[0].Transactions[1]
+	assert.Nil(t, deploy.Trace)
+	assert.Nil(t, e.tracedTxs(deploy))
+
+	// the reverted call, the static call & the calls without inscription are not indexed
+	txs := e.tracedTxs(wallet)
+	if !assert.Len(t, txs, 2) {
+		return
+	}
+	for i, idx := range []int{2, 5} {
+		assert.Equal(t, fmt.Sprintf("%s%s%d", wallet.Hash, xycommon.DerivedHashSeparator, idx), txs[i].Hash)
+		assert.Equal(t, replayAddrD, txs[i].From)
+		assert.Equal(t, replayAddrD, txs[i].To)
+		assert.Nil(t, txs[i].Trace)
+	}
+
+	// the calls of the reverted tx are reverted too
+	wallet.Trace.Error = "execution reverted"
+	assert.Nil(t, e.tracedTxs(wallet))
+
+	// nodes without the trace method fail the trace calls mode
+	e.node = struct{ xycommon.IRPCClient }{node}
+	assert.ErrorIs(t, e.attachTraces(context.Background(), blocks[0]), xycommon.ErrMethodNotSupported)
+}
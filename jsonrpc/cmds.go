@@ -69,6 +69,12 @@ type InscriptionInfo struct {
 	Holders      uint64 `json:"holders"`
 	TxCnt        uint64 `json:"tx_cnt"`
 	Progress     string `json:"progress"`
+
+	AddressMintLimit string `json:"address_mint_limit"`
+	MintStartBlock   uint64 `json:"mint_start_block"`
+	MintEndBlock     uint64 `json:"mint_end_block"`
+	Premine          string `json:"premine"`
+	SelfMint         bool   `json:"self_mint"`
 }
 
 // FindInscriptionTickCmd defines the inscription JSON-RPC command.
@@ -101,7 +107,9 @@ type AddressTransaction struct {
 	From      string `json:"from"`
 	To        string `json:"to"`
 	TxHash    string `json:"tx_hash"`
-	Amount    string `json:"amount"`
+	Amount    string `json:"amount"`               // display amount in the tick decimals
+	AmountRaw string `json:"amount_raw,omitempty"` // amount in the integer units, empty for the ticks without strict decimals
+	Decimals  int8   `json:"decimals"`
 	Event     int8   `json:"event"`
 	Operate   string `json:"operate"`
 	Status    int8   `json:"status"`
@@ -136,6 +144,108 @@ type IndsGetBalanceByAddressCmd struct {
 	Sort     int
 }
 
+type IndsGetUtxosByAddressCmd struct {
+	Address  string
+	Chain    string
+	Protocol string
+	Tick     string
+}
+
+// IndsGetListOrdersCmd the open list orders of the tick, the seller is optional
+type IndsGetListOrdersCmd struct {
+	Limit    int
+	Offset   int
+	Chain    string
+	Protocol string
+	Tick     string
+	Seller   string
+}
+
+type ListOrderInfo struct {
+	Chain     string `json:"chain"`
+	Protocol  string `json:"protocol"`
+	Tick      string `json:"tick"`
+	ListID    string `json:"list_id"`
+	Seller    string `json:"seller"`
+	Market    string `json:"market"`
+	Amount    string `json:"amount"`
+	AmountRaw string `json:"amount_raw,omitempty"`
+	Price     string `json:"price"`
+	CreatedAt uint32 `json:"created_at"`
+}
+
+type FindListOrdersResponse struct {
+	Orders []*ListOrderInfo `json:"orders"`
+	Total  int64            `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+// IndsGetTradesCmd the trades of the tick, latest first, the address of the seller or the buyer is optional
+type IndsGetTradesCmd struct {
+	Limit    int
+	Offset   int
+	Chain    string
+	Protocol string
+	Tick     string
+	Address  string
+}
+
+type TradeInfo struct {
+	Chain       string `json:"chain"`
+	Protocol    string `json:"protocol"`
+	Tick        string `json:"tick"`
+	TxHash      string `json:"tx_hash"`
+	ListID      string `json:"list_id"`
+	Seller      string `json:"seller"`
+	Buyer       string `json:"buyer"`
+	Market      string `json:"market"`
+	Amount      string `json:"amount"`
+	AmountRaw   string `json:"amount_raw,omitempty"`
+	Price       string `json:"price"`
+	UnitPrice   string `json:"unit_price"`
+	BlockNumber uint64 `json:"block_number"`
+	BlockTime   uint32 `json:"block_time"`
+}
+
+type FindTradesResponse struct {
+	Trades []*TradeInfo `json:"trades"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+// IndsGetTickMarketStatsCmd the floor price & the candles of the tick, the interval is in seconds,
+// the interval & the limit of the candles are defaulted if not positive
+type IndsGetTickMarketStatsCmd struct {
+	Chain    string
+	Protocol string
+	Tick     string
+	Interval int64
+	Limit    int
+}
+
+type CandleInfo struct {
+	Time   uint32 `json:"time"`
+	Open   string `json:"open"`
+	High   string `json:"high"`
+	Low    string `json:"low"`
+	Close  string `json:"close"`
+	Volume string `json:"volume"`
+	Value  string `json:"value"`
+	Trades int    `json:"trades"`
+}
+
+type TickMarketStatsResponse struct {
+	Chain      string        `json:"chain"`
+	Protocol   string        `json:"protocol"`
+	Tick       string        `json:"tick"`
+	FloorPrice string        `json:"floor_price"` // lowest unit price of the open orders, empty if not listed
+	LastPrice  string        `json:"last_price"`  // unit price of the latest trade, empty if not traded
+	Interval   int64         `json:"interval"`
+	Candles    []*CandleInfo `json:"candles"`
+}
+
 type FindUserBalanceCmd struct {
 	Address  string
 	Chain    string
@@ -148,9 +258,12 @@ type BalanceInfo struct {
 	Protocol     string `json:"protocol"`
 	Tick         string `json:"tick"`
 	Address      string `json:"address"`
-	Balance      string `json:"balance"`
+	Balance      string `json:"balance"`               // display balance in the tick decimals
+	BalanceRaw   string `json:"balance_raw,omitempty"` // balance in the integer units, empty for the ticks without strict decimals
 	DeployHash   string `json:"deploy_hash"`
 	TransferType int8   `json:"transfer_type"`
+	Name         string `json:"name,omitempty"` // tick name, the spaced name of the runes
+	Decimals     int8   `json:"decimals"`
 }
 
 type TickHolder struct {
@@ -160,22 +273,35 @@ type TickHolder struct {
 	DeployHash  string `json:"deploy_hash"`
 	Address     string `json:"address"`
 	Balance     string `json:"balance"`
+	BalanceRaw  string `json:"balance_raw,omitempty"`
 	TotalSupply string `json:"total_supply"`
+	Decimals    int8   `json:"decimals"`
 }
 
 type BalanceBrief struct {
 	Tick         string       `json:"tick"`
 	Balance      string       `json:"balance"`
+	BalanceRaw   string       `json:"balance_raw,omitempty"`
 	TransferType int8         `json:"transfer_type"`
 	Utxos        []*UTXOBrief `json:"utxos,omitempty"`
 	DeployHash   string       `json:"deploy_hash"`
 	Available    string       `json:"available"`
+	AvailableRaw string       `json:"available_raw,omitempty"`
+	Decimals     int8         `json:"decimals"`
 }
 
 type UTXOBrief struct {
-	Tick     string `json:"tick"`
-	Amount   string `json:"amount"`
-	RootHash string `json:"root_hash"`
+	Tick      string `json:"tick"`
+	Amount    string `json:"amount"`
+	AmountRaw string `json:"amount_raw,omitempty"`
+	RootHash  string `json:"root_hash"`
+	Sn        string `json:"sn,omitempty"`
+	TxHash    string `json:"tx_hash,omitempty"`
+}
+
+type FindUserUtxosResponse struct {
+	Utxos []*UTXOBrief `json:"utxos"`
+	Total int          `json:"total"`
 }
 
 type FindUserBalancesResponse struct {
@@ -261,6 +387,8 @@ type TransactionInfo struct {
 	From       string `json:"from"`
 	To         string `json:"to"`
 	Amount     string `json:"amount"`
+	AmountRaw  string `json:"amount_raw,omitempty"`
+	Decimals   int8   `json:"decimals"`
 	Op         string `json:"op"`
 }
 
@@ -293,4 +421,8 @@ func init() {
 	MustRegisterCmd("inds_getLastBlockNumberIndexed", (*LastBlockNumberCmd)(nil), flags)
 	MustRegisterCmd("inds_getTickByCallData", (*TxOperateCmd)(nil), flags)
 	MustRegisterCmd("inds_getTransactionByHash", (*GetTxByHashCmd)(nil), flags)
+	MustRegisterCmd("inds_getUtxosByAddress", (*IndsGetUtxosByAddressCmd)(nil), flags)
+	MustRegisterCmd("inds_getListOrders", (*IndsGetListOrdersCmd)(nil), flags)
+	MustRegisterCmd("inds_getTrades", (*IndsGetTradesCmd)(nil), flags)
+	MustRegisterCmd("inds_getTickMarketStats", (*IndsGetTickMarketStatsCmd)(nil), flags)
 }
@@ -4,13 +4,72 @@ import (
 	"errors"
 	"fmt"
 	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol"
 	"strings"
 )
 
+const (
+	// defaultCandleInterval & defaultCandleLimit serve the hourly candles of the last day
+	defaultCandleInterval int64 = 3600
+	defaultCandleLimit          = 24
+	maxCandleLimit              = 1000
+)
+
+// tickDecimals is the decimals of a tick and whether its amounts are checked against them
+type tickDecimals struct {
+	decimals int8
+	strict   bool
+}
+
+// amounts returns the display amount in the tick decimals & the amount in the integer units.
+// Ticks indexed without strict decimals may hold amounts finer than their decimals, these amounts
+// are displayed as indexed and their raw amounts are left empty instead of being truncated.
+func (d tickDecimals) amounts(amount decimal.Decimal) (string, string, error) {
+	units := amount.Shift(int32(d.decimals))
+	if !units.IsInteger() {
+		if d.strict {
+			return "", "", fmt.Errorf("amount[%s] exceeds the tick decimals[%d]", amount, d.decimals)
+		}
+		return amount.String(), "", nil
+	}
+
+	if !d.strict {
+		return amount.StringFixed(int32(d.decimals)), "", nil
+	}
+	return amount.StringFixed(int32(d.decimals)), units.StringFixed(0), nil
+}
+
+// normalizeTick returns the tick identity of the requested tick by the tick rule of the chain protocol in force now
+func normalizeTick(chain, protocolName, tick string) string {
+	return protocol.NormalizeTick(chain, protocolName, protocol.LatestHeight, tick)
+}
+
+// findTickDecimals returns the decimals of the tick, 0 if the tick is not found.
+// The decimals are cached once found, the ticks not deployed yet are looked up again
+func findTickDecimals(s *RpcServer, chain, protocol, tick string) (tickDecimals, error) {
+	v, _ := s.ticks.LoadOrStore(strings.ToLower(chain), dcache.NewInscription())
+	ticks := v.(*dcache.Inscription)
+	if ok, t := ticks.Get(protocol, tick); ok {
+		return tickDecimals{decimals: t.Decimals, strict: t.StrictDecimals}, nil
+	}
+
+	inscription, err := s.dbc.FindInscriptionByTick(chain, protocol, tick)
+	if err != nil {
+		return tickDecimals{}, err
+	}
+	if inscription == nil {
+		return tickDecimals{}, nil
+	}
+
+	ticks.Create(protocol, tick, &dcache.Tick{SID: inscription.SID, Decimals: inscription.Decimals, StrictDecimals: inscription.StrictDecimals})
+	return tickDecimals{decimals: inscription.Decimals, strict: inscription.StrictDecimals}, nil
+}
+
 func findAddressBalances(s *RpcServer, limit, offset int, address, chain, protocol, tick string, sort int) (interface{}, error) {
 	protocol = strings.ToLower(protocol)
-	tick = strings.ToLower(tick)
+	tick = normalizeTick(chain, protocol, tick)
 	cacheKey := fmt.Sprintf("addr_balances_%d_%d_%s_%s_%s_%s_%d", limit, offset, address, chain, protocol, tick, sort)
 	if ins, ok := s.cacheStore.Get(cacheKey); ok {
 		if allIns, ok := ins.(*FindUserBalancesResponse); ok {
@@ -25,14 +84,22 @@ func findAddressBalances(s *RpcServer, limit, offset int, address, chain, protoc
 
 	list := make([]*BalanceInfo, 0, len(balances))
 	for _, b := range balances {
+		amount, raw, err := tickDecimals{decimals: b.Decimals, strict: b.StrictDecimals}.amounts(b.Balance)
+		if err != nil {
+			return ErrRPCInternal, err
+		}
+
 		balance := &BalanceInfo{
 			Chain:        b.Chain,
 			Protocol:     b.Protocol,
 			Tick:         b.Tick,
 			Address:      b.Address,
-			Balance:      b.Balance.String(),
+			Balance:      amount,
+			BalanceRaw:   raw,
 			DeployHash:   b.DeployHash,
 			TransferType: b.TransferType,
+			Name:         b.Name,
+			Decimals:     b.Decimals,
 		}
 		list = append(list, balance)
 	}
@@ -47,9 +114,229 @@ func findAddressBalances(s *RpcServer, limit, offset int, address, chain, protoc
 	return resp, nil
 }
 
+// findAddressUtxos returns the unspent utxos of the hash transfer type tick owned by the address
+func findAddressUtxos(s *RpcServer, address, chain, protocol, tick string) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = normalizeTick(chain, protocol, tick)
+	cacheKey := fmt.Sprintf("addr_utxos_%s_%s_%s_%s", address, chain, protocol, tick)
+	if ins, ok := s.cacheStore.Get(cacheKey); ok {
+		if utxos, ok := ins.(*FindUserUtxosResponse); ok {
+			return utxos, nil
+		}
+	}
+
+	result, err := s.dbc.GetUtxosByAddress(address, chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	decimals, err := findTickDecimals(s, chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	utxos := make([]*UTXOBrief, 0, len(result))
+	for _, u := range result {
+		amount, raw, err := decimals.amounts(u.Amount)
+		if err != nil {
+			return ErrRPCInternal, err
+		}
+
+		utxos = append(utxos, &UTXOBrief{
+			Tick:      u.Tick,
+			Amount:    amount,
+			AmountRaw: raw,
+			RootHash:  u.RootHash,
+			Sn:        u.Sn,
+			TxHash:    u.TxHash,
+		})
+	}
+
+	resp := &FindUserUtxosResponse{
+		Utxos: utxos,
+		Total: len(utxos),
+	}
+	s.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+// findListOrders returns the open list orders of the tick, the seller is optional
+func findListOrders(s *RpcServer, limit, offset int, chain, protocol, tick, seller string) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = normalizeTick(chain, protocol, tick)
+	seller = strings.ToLower(seller)
+	cacheKey := fmt.Sprintf("list_orders_%d_%d_%s_%s_%s_%s", limit, offset, chain, protocol, tick, seller)
+	if orders, ok := s.cacheStore.Get(cacheKey); ok {
+		if resp, ok := orders.(*FindListOrdersResponse); ok {
+			return resp, nil
+		}
+	}
+
+	result, total, err := s.dbc.GetOpenListOrders(chain, protocol, tick, seller, limit, offset)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	decimals, err := findTickDecimals(s, chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	orders := make([]*ListOrderInfo, 0, len(result))
+	for _, o := range result {
+		amount, raw, err := decimals.amounts(o.Amount)
+		if err != nil {
+			return ErrRPCInternal, err
+		}
+
+		orders = append(orders, &ListOrderInfo{
+			Chain:     o.Chain,
+			Protocol:  o.Protocol,
+			Tick:      o.Tick,
+			ListID:    o.ListID,
+			Seller:    o.Seller,
+			Market:    o.Market,
+			Amount:    amount,
+			AmountRaw: raw,
+			Price:     o.Price.String(),
+			CreatedAt: uint32(o.CreatedAt.Unix()),
+		})
+	}
+
+	resp := &FindListOrdersResponse{
+		Orders: orders,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	s.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func findTrades(s *RpcServer, limit, offset int, chain, protocol, tick, address string) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = normalizeTick(chain, protocol, tick)
+	address = strings.ToLower(address)
+	cacheKey := fmt.Sprintf("trades_%d_%d_%s_%s_%s_%s", limit, offset, chain, protocol, tick, address)
+	if trades, ok := s.cacheStore.Get(cacheKey); ok {
+		if resp, ok := trades.(*FindTradesResponse); ok {
+			return resp, nil
+		}
+	}
+
+	result, total, err := s.dbc.GetTrades(chain, protocol, tick, address, limit, offset)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	decimals, err := findTickDecimals(s, chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	trades := make([]*TradeInfo, 0, len(result))
+	for _, t := range result {
+		amount, raw, err := decimals.amounts(t.Amount)
+		if err != nil {
+			return ErrRPCInternal, err
+		}
+
+		trades = append(trades, &TradeInfo{
+			Chain:       t.Chain,
+			Protocol:    t.Protocol,
+			Tick:        t.Tick,
+			TxHash:      t.TxHash,
+			ListID:      t.ListID,
+			Seller:      t.Seller,
+			Buyer:       t.Buyer,
+			Market:      t.Market,
+			Amount:      amount,
+			AmountRaw:   raw,
+			Price:       t.Price.String(),
+			UnitPrice:   t.UnitPrice.String(),
+			BlockNumber: t.BlockNumber,
+			BlockTime:   uint32(t.BlockTime.Unix()),
+		})
+	}
+
+	resp := &FindTradesResponse{
+		Trades: trades,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	s.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
+func findTickMarketStats(s *RpcServer, chain, protocol, tick string, interval int64, limit int) (interface{}, error) {
+	protocol = strings.ToLower(protocol)
+	tick = normalizeTick(chain, protocol, tick)
+	if interval <= 0 {
+		interval = defaultCandleInterval
+	}
+	if limit <= 0 {
+		limit = defaultCandleLimit
+	}
+	if limit > maxCandleLimit {
+		limit = maxCandleLimit
+	}
+
+	cacheKey := fmt.Sprintf("tick_market_stats_%s_%s_%s_%d_%d", chain, protocol, tick, interval, limit)
+	if stats, ok := s.cacheStore.Get(cacheKey); ok {
+		if resp, ok := stats.(*TickMarketStatsResponse); ok {
+			return resp, nil
+		}
+	}
+
+	resp := &TickMarketStatsResponse{
+		Chain:    chain,
+		Protocol: protocol,
+		Tick:     tick,
+		Interval: interval,
+	}
+
+	floor, err := s.dbc.GetFloorPrice(chain, protocol, tick)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+	if floor != nil {
+		resp.FloorPrice = floor.String()
+	}
+
+	last, _, err := s.dbc.GetTrades(chain, protocol, tick, "", 1, 0)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+	if len(last) > 0 {
+		resp.LastPrice = last[0].UnitPrice.String()
+	}
+
+	candles, err := s.dbc.GetTickCandles(chain, protocol, tick, interval, limit)
+	if err != nil {
+		return ErrRPCInternal, err
+	}
+
+	resp.Candles = make([]*CandleInfo, 0, len(candles))
+	for _, c := range candles {
+		resp.Candles = append(resp.Candles, &CandleInfo{
+			Time:   uint32(c.Time),
+			Open:   c.Open.String(),
+			High:   c.High.String(),
+			Low:    c.Low.String(),
+			Close:  c.Close.String(),
+			Volume: c.Volume.String(),
+			Value:  c.Value.String(),
+			Trades: c.Trades,
+		})
+	}
+	s.cacheStore.Set(cacheKey, resp)
+	return resp, nil
+}
+
 func findInsciptions(s *RpcServer, limit, offset int, chain, protocol, tick, deployBy string, sort, sortMode int) (interface{}, error) {
 	protocol = strings.ToLower(protocol)
-	tick = strings.ToLower(tick)
+	tick = normalizeTick(chain, protocol, tick)
 	cacheKey := fmt.Sprintf("all_ins_%d_%d_%s_%s_%s_%s_%d_%d", limit, offset, chain, protocol, tick, deployBy, sort, sortMode)
 	if ins, ok := s.cacheStore.Get(cacheKey); ok {
 		if allIns, ok := ins.(*FindAllInscriptionsResponse); ok {
@@ -110,7 +397,7 @@ func findInsciptions(s *RpcServer, limit, offset int, chain, protocol, tick, dep
 
 func findInsciption(s *RpcServer, chain, protocol, tick, deployHash string) (interface{}, error) {
 	protocol = strings.ToLower(protocol)
-	tick = strings.ToLower(tick)
+	tick = normalizeTick(chain, protocol, tick)
 
 	cacheKey := fmt.Sprintf("tick_%s_%s_%s_%s", chain, protocol, tick, deployHash)
 	if ins, ok := s.cacheStore.Get(cacheKey); ok {
@@ -145,6 +432,12 @@ func findInsciption(s *RpcServer, chain, protocol, tick, deployHash string) (int
 		DeployTime:   uint32(inscription.DeployTime.Unix()),
 		CreatedAt:    uint32(inscription.CreatedAt.Unix()),
 		UpdatedAt:    uint32(inscription.UpdatedAt.Unix()),
+
+		AddressMintLimit: inscription.AddressMintLimit.String(),
+		MintStartBlock:   inscription.MintStartBlock,
+		MintEndBlock:     inscription.MintEndBlock,
+		Premine:          inscription.Premine.String(),
+		SelfMint:         inscription.SelfMint,
 	}
 
 	s.cacheStore.Set(cacheKey, resp)
@@ -153,7 +446,7 @@ func findInsciption(s *RpcServer, chain, protocol, tick, deployHash string) (int
 
 func findTickHolders(s *RpcServer, limit int, offset int, chain, protocol, tick string, sortMode int) (interface{}, error) {
 	protocol = strings.ToLower(protocol)
-	tick = strings.ToLower(tick)
+	tick = normalizeTick(chain, protocol, tick)
 	cacheKey := fmt.Sprintf("all_ins_%d_%d_%s_%s_%s_%d", limit, offset, chain, protocol, tick, sortMode)
 	if ins, ok := s.cacheStore.Get(cacheKey); ok {
 		if allIns, ok := ins.(*FindTickHoldersResponse); ok {
@@ -176,16 +469,24 @@ func findTickHolders(s *RpcServer, limit int, offset int, chain, protocol, tick
 		return ErrRPCInternal, err
 	}
 
+	decimals := tickDecimals{decimals: inscription.Decimals, strict: inscription.StrictDecimals}
 	list := make([]*TickHolder, 0, len(holders))
 	for _, holder := range holders {
+		amount, raw, err := decimals.amounts(holder.Balance)
+		if err != nil {
+			return ErrRPCInternal, err
+		}
+
 		balance := &TickHolder{
 			Chain:       holder.Chain,
 			Protocol:    holder.Protocol,
 			Tick:        holder.Tick,
 			DeployHash:  inscription.DeployHash,
 			Address:     holder.Address,
-			Balance:     holder.Balance.String(),
+			Balance:     amount,
+			BalanceRaw:  raw,
 			TotalSupply: inscription.TotalSupply.String(),
+			Decimals:    inscription.Decimals,
 		}
 		list = append(list, balance)
 	}
@@ -0,0 +1,88 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package jsonrpc
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/storage"
+	"path/filepath"
+	"testing"
+)
+
+func TestTickDecimalsAmounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		decimals tickDecimals
+		amount   string
+		display  string
+		raw      string
+		err      bool
+	}{
+		{"strict", tickDecimals{decimals: 8, strict: true}, "1.5", "1.50000000", "150000000", false},
+		{"strict integer", tickDecimals{decimals: 2, strict: true}, "100", "100.00", "10000", false},
+		{"strict exceeds decimals", tickDecimals{decimals: 2, strict: true}, "0.001", "", "", true},
+		{"no decimals", tickDecimals{strict: true}, "21000000", "21000000", "21000000", false},
+		{"legacy", tickDecimals{decimals: 2}, "1.5", "1.50", "", false},
+		{"legacy finer", tickDecimals{decimals: 2}, "0.001", "0.001", "", false},
+	}
+	for _, tt := range tests {
+		display, raw, err := tt.decimals.amounts(decimal.RequireFromString(tt.amount))
+		assert.Equal(t, tt.err, err != nil, tt.name)
+		assert.Equal(t, tt.display, display, tt.name)
+		assert.Equal(t, tt.raw, raw, tt.name)
+	}
+}
+
+func TestFindTickDecimals(t *testing.T) {
+	db, err := storage.NewDbClient(&config.DatabaseConfig{
+		Type: storage.DatabaseTypeSqlite3,
+		Dsn:  filepath.Join(t.TempDir(), "indexer.db"),
+	})
+	assert.NoError(t, err)
+
+	inscription := &model.Inscriptions{SID: 1, Chain: "avalanche", Protocol: "asc-20", Tick: "duck", Decimals: 8, StrictDecimals: true}
+	assert.NoError(t, db.SqlDB.Create(inscription).Error)
+
+	s := &RpcServer{dbc: db}
+	decimals, err := findTickDecimals(s, "avalanche", "asc-20", "duck")
+	assert.NoError(t, err)
+	assert.Equal(t, tickDecimals{decimals: 8, strict: true}, decimals)
+
+	// the decimals are served from the cache once found
+	assert.NoError(t, db.SqlDB.Delete(inscription).Error)
+	decimals, err = findTickDecimals(s, "Avalanche", "asc-20", "duck")
+	assert.NoError(t, err)
+	assert.Equal(t, tickDecimals{decimals: 8, strict: true}, decimals)
+
+	// the ticks not deployed are looked up again
+	decimals, err = findTickDecimals(s, "avalanche", "asc-20", "none")
+	assert.NoError(t, err)
+	assert.Equal(t, tickDecimals{}, decimals)
+	ticks, _ := s.ticks.Load("avalanche")
+	ok, _ := ticks.(*dcache.Inscription).Get("asc-20", "none")
+	assert.False(t, ok)
+}
@@ -14,6 +14,10 @@ var rpcHandlersBeforeInitV2 = map[string]commandHandler{
 	"inds_getTickByCallData":         handleGetTxOperate,
 	"inds_getTransactionByHash":      handleGetTxByHash,
 	"inds_getTick":                   indsGetTick,
+	"inds_getUtxosByAddress":         indsGetUtxosByAddress,
+	"inds_getListOrders":             indsGetListOrders,
+	"inds_getTrades":                 indsGetTrades,
+	"inds_getTickMarketStats":        indsGetTickMarketStats,
 	//"address.Balance": handleFindAddressBalance,
 }
 
@@ -56,3 +60,43 @@ func indsGetHoldersByTick(s *RpcServer, cmd interface{}, closeChan <-chan struct
 
 	return findTickHolders(s, req.Limit, req.Offset, req.Chain, req.Protocol, req.Tick, req.SortMode)
 }
+
+func indsGetUtxosByAddress(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*IndsGetUtxosByAddressCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("find user utxos cmd params:%v", req)
+
+	return findAddressUtxos(s, req.Address, req.Chain, req.Protocol, req.Tick)
+}
+
+func indsGetListOrders(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*IndsGetListOrdersCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("find list orders cmd params:%v", req)
+
+	return findListOrders(s, req.Limit, req.Offset, req.Chain, req.Protocol, req.Tick, req.Seller)
+}
+
+func indsGetTrades(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*IndsGetTradesCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("find trades cmd params:%v", req)
+
+	return findTrades(s, req.Limit, req.Offset, req.Chain, req.Protocol, req.Tick, req.Address)
+}
+
+func indsGetTickMarketStats(s *RpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	req, ok := cmd.(*IndsGetTickMarketStatsCmd)
+	if !ok {
+		return ErrRPCInvalidParams, errors.New("invalid params")
+	}
+	xylog.Logger.Infof("find tick market stats cmd params:%v", req)
+
+	return findTickMarketStats(s, req.Chain, req.Protocol, req.Tick, req.Interval, req.Limit)
+}
@@ -64,7 +64,7 @@ func handleFindInscriptionTick(s *RpcServer, cmd interface{}, closeChan <-chan s
 	xylog.Logger.Infof("find inscriptions tick cmd params:%v", req)
 
 	req.Protocol = strings.ToLower(req.Protocol)
-	req.Tick = strings.ToLower(req.Tick)
+	req.Tick = normalizeTick(req.Chain, req.Protocol, req.Tick)
 
 	reqByte, err := json.Marshal(req)
 	if err != nil {
@@ -113,7 +113,7 @@ func handleFindAddressTransactions(s *RpcServer, cmd interface{}, closeChan <-ch
 	xylog.Logger.Infof("find user transactions cmd params:%v", req)
 
 	req.Protocol = strings.ToLower(req.Protocol)
-	req.Tick = strings.ToLower(req.Tick)
+	req.Tick = normalizeTick(req.Chain, req.Protocol, req.Tick)
 
 	reqByte, err := json.Marshal(req)
 	if err != nil {
@@ -152,8 +152,22 @@ func handleFindAddressTransactions(s *RpcServer, cmd interface{}, closeChan <-ch
 		}
 	}
 
+	decimals := make(map[string]tickDecimals)
 	list := make([]*AddressTransaction, 0, len(transactions))
 	for _, t := range transactions {
+		tickKey := fmt.Sprintf("%s_%s_%s", t.Chain, t.Protocol, t.Tick)
+		if _, ok := decimals[tickKey]; !ok {
+			decimals[tickKey], err = findTickDecimals(s, t.Chain, t.Protocol, t.Tick)
+			if err != nil {
+				return ErrRPCInternal, err
+			}
+		}
+
+		amount, raw, err := decimals[tickKey].amounts(t.Amount)
+		if err != nil {
+			return ErrRPCInternal, err
+		}
+
 		key := fmt.Sprintf("%s_%s", t.Chain, t.TxHash)
 		from := ""
 		to := ""
@@ -168,7 +182,9 @@ func handleFindAddressTransactions(s *RpcServer, cmd interface{}, closeChan <-ch
 			Address:   t.Address,
 			From:      from,
 			To:        to,
-			Amount:    t.Amount.String(),
+			Amount:    amount,
+			AmountRaw: raw,
+			Decimals:  decimals[tickKey].decimals,
 			Tick:      t.Tick,
 			Protocol:  t.Protocol,
 			Operate:   t.Operate,
@@ -213,7 +229,7 @@ func handleFindAddressBalance(s *RpcServer, cmd interface{}, closeChan <-chan st
 	}
 
 	req.Protocol = strings.ToLower(req.Protocol)
-	req.Tick = strings.ToLower(req.Tick)
+	req.Tick = normalizeTick(req.Chain, req.Protocol, req.Tick)
 	cacheKey := fmt.Sprintf("addr_balance_%s", string(reqByte))
 	if ins, ok := s.cacheStore.Get(cacheKey); ok {
 		if allIns, ok := ins.(*BalanceBrief); ok {
@@ -232,7 +248,9 @@ func handleFindAddressBalance(s *RpcServer, cmd interface{}, closeChan <-chan st
 		Tick:         inscription.Tick,
 		TransferType: inscription.TransferType,
 		DeployHash:   inscription.DeployHash,
+		Decimals:     inscription.Decimals,
 	}
+	insDecimals := tickDecimals{decimals: inscription.Decimals, strict: inscription.StrictDecimals}
 
 	// balance
 	balance, err := s.dbc.FindUserBalanceByTick(req.Chain, req.Protocol, req.Tick, req.Address)
@@ -242,8 +260,12 @@ func handleFindAddressBalance(s *RpcServer, cmd interface{}, closeChan <-chan st
 	if balance == nil {
 		return nil, errors.New("Record not found")
 	}
-	resp.Balance = balance.Balance.String()
-	resp.Available = balance.Available.String()
+	if resp.Balance, resp.BalanceRaw, err = insDecimals.amounts(balance.Balance); err != nil {
+		return ErrRPCInternal, err
+	}
+	if resp.Available, resp.AvailableRaw, err = insDecimals.amounts(balance.Available); err != nil {
+		return ErrRPCInternal, err
+	}
 
 	switch inscription.TransferType {
 	case model.TransferTypeHash:
@@ -254,10 +276,16 @@ func handleFindAddressBalance(s *RpcServer, cmd interface{}, closeChan <-chan st
 		}
 		utxos := make([]*UTXOBrief, 0, len(result))
 		for _, u := range result {
+			amount, raw, err := insDecimals.amounts(u.Amount)
+			if err != nil {
+				return ErrRPCInternal, err
+			}
+
 			utxos = append(utxos, &UTXOBrief{
-				Tick:     u.Tick,
-				Amount:   u.Amount.String(),
-				RootHash: u.RootHash,
+				Tick:      u.Tick,
+				Amount:    amount,
+				AmountRaw: raw,
+				RootHash:  u.RootHash,
 			})
 		}
 		resp.Utxos = utxos
@@ -329,7 +357,7 @@ func handleGetTxOperate(s *RpcServer, cmd interface{}, closeChan <-chan struct{}
 	}
 	var deployHash string
 	if operate.Protocol != "" && operate.Tick != "" {
-		inscription, err := s.dbc.FindInscriptionByTick(strings.ToLower(req.Chain), strings.ToLower(string(operate.Protocol)), strings.ToLower(operate.Tick))
+		inscription, err := s.dbc.FindInscriptionByTick(strings.ToLower(req.Chain), strings.ToLower(string(operate.Protocol)), operate.Tick)
 		if err != nil {
 			xylog.Logger.Errorf("the query for the inscription failed. chain:%s protocol:%s tick:%s err=%s", req.Chain, string(operate.Protocol), operate.Tick, err)
 		}
@@ -408,7 +436,11 @@ func handleGetTxByHash(s *RpcServer, cmd interface{}, closeChan <-chan struct{})
 	if addressTx == nil {
 		return nil, errors.New("Record not found")
 	}
-	transInfo.Amount = addressTx.Amount.String()
+	insDecimals := tickDecimals{decimals: inscription.Decimals, strict: inscription.StrictDecimals}
+	if transInfo.Amount, transInfo.AmountRaw, err = insDecimals.amounts(addressTx.Amount); err != nil {
+		return ErrRPCInternal, err
+	}
+	transInfo.Decimals = inscription.Decimals
 
 	resp.IsInscription = true
 	resp.Transaction = transInfo
@@ -111,6 +111,7 @@ type RpcServer struct {
 	dbc                    *storage.DBClient
 	cacheConfig            *config.CacheConfig
 	cacheStore             *cache_store.CacheStore
+	ticks                  sync.Map // chain -> *dcache.Inscription, the decimals of the ticks are immutable once deployed
 }
 
 // httpStatusLine returns a response Status-Line (RFC 2616 Section 6.1)
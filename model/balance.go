@@ -60,7 +60,8 @@ type UTXO struct {
 	Amount    decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(38,18)"` // amount
 	RootHash  string          `json:"root_hash" gorm:"column:root_hash"`
 	TxHash    string          `json:"tx_hash" gorm:"column:tx_hash"`
-	Status    int8            `json:"status" gorm:"column:status"` // tx status
+	SpentHash string          `json:"spent_hash" gorm:"column:spent_hash"` // tx hash spending the utxo
+	Status    int8            `json:"status" gorm:"column:status"`         // tx status
 	CreatedAt time.Time       `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt time.Time       `json:"updated_at" gorm:"column:updated_at"`
 }
@@ -70,11 +71,14 @@ func (UTXO) TableName() string {
 }
 
 type BalanceInscription struct {
-	Chain        string          `json:"chain"`
-	Protocol     string          `json:"protocol"`
-	Tick         string          `json:"tick"`
-	Address      string          `json:"address"`
-	Balance      decimal.Decimal `json:"balance"`
-	DeployHash   string          `json:"deploy_hash"`
-	TransferType int8            `json:"transfer_type"`
+	Chain          string          `json:"chain"`
+	Protocol       string          `json:"protocol"`
+	Tick           string          `json:"tick"`
+	Address        string          `json:"address"`
+	Balance        decimal.Decimal `json:"balance"`
+	DeployHash     string          `json:"deploy_hash"`
+	TransferType   int8            `json:"transfer_type"`
+	Name           string          `json:"name"`
+	Decimals       int8            `json:"decimals"`
+	StrictDecimals bool            `json:"strict_decimals"`
 }
@@ -25,11 +25,16 @@ package model
 type ChainGroup string
 
 const (
-	EvmChainGroup ChainGroup = "evm"
-	BtcChainGroup ChainGroup = "btc"
+	EvmChainGroup    ChainGroup = "evm"
+	BtcChainGroup    ChainGroup = "btc"
+	CosmosChainGroup ChainGroup = "cosmos"
 )
 
 const (
 	ChainBTC  string = "btc"
 	ChainAVAX string = "avalanche"
+	ChainETH  string = "eth"
+
+	ChainCosmosHub string = "cosmoshub"
+	ChainCelestia  string = "celestia"
 )
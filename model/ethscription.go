@@ -0,0 +1,62 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package model
+
+import "time"
+
+// Ethscription current state of one ethscription, the id is the creation tx hash
+type Ethscription struct {
+	ID             uint64    `gorm:"primaryKey" json:"id"`
+	Chain          string    `json:"chain" gorm:"column:chain"`
+	EthscriptionID string    `json:"ethscription_id" gorm:"column:ethscription_id"`
+	Creator        string    `json:"creator" gorm:"column:creator"`
+	InitialOwner   string    `json:"initial_owner" gorm:"column:initial_owner"`
+	CurrentOwner   string    `json:"current_owner" gorm:"column:current_owner"`
+	PreviousOwner  string    `json:"previous_owner" gorm:"column:previous_owner"`
+	ContentSha     string    `json:"content_sha" gorm:"column:content_sha"` // sha256 of the content uri
+	MimeType       string    `json:"mime_type" gorm:"column:mime_type"`
+	BlockNumber    uint64    `json:"block_number" gorm:"column:block_number"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (Ethscription) TableName() string {
+	return "ethscriptions"
+}
+
+// EthscriptionTransfer ownership history, the creation is recorded as the transfer from the creator to the initial owner
+type EthscriptionTransfer struct {
+	ID             uint64    `gorm:"primaryKey" json:"id"`
+	Chain          string    `json:"chain" gorm:"column:chain"`
+	EthscriptionID string    `json:"ethscription_id" gorm:"column:ethscription_id"`
+	TxHash         string    `json:"tx_hash" gorm:"column:tx_hash"`
+	From           string    `json:"from" gorm:"column:from"`
+	To             string    `json:"to" gorm:"column:to"`
+	BlockNumber    uint64    `json:"block_number" gorm:"column:block_number"`
+	LogIndex       int64     `json:"log_index" gorm:"column:log_index"` // -1 for the creation & calldata transfers
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+func (EthscriptionTransfer) TableName() string {
+	return "ethscription_transfers"
+}
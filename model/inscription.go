@@ -55,6 +55,16 @@ type Inscriptions struct {
 	CreatedAt    time.Time       `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt    time.Time       `json:"updated_at" gorm:"column:updated_at"`
 	Decimals     int8            `json:"decimals" gorm:"column:decimals"`
+
+	// amounts of the tick must fit the decimals, false for the ticks deployed before the strict decimals rules
+	StrictDecimals bool `json:"strict_decimals" gorm:"column:strict_decimals"`
+
+	// extended deploy parameters, zero values leave the minting unrestricted
+	AddressMintLimit decimal.Decimal `gorm:"column:address_mint_limit;type:decimal(38,18)" json:"address_mint_limit"` // total mint limit per address
+	MintStartBlock   uint64          `json:"mint_start_block" gorm:"column:mint_start_block"`
+	MintEndBlock     uint64          `json:"mint_end_block" gorm:"column:mint_end_block"`
+	Premine          decimal.Decimal `gorm:"column:premine;type:decimal(38,18)" json:"premine"` // minted to the deployer on deploy
+	SelfMint         bool            `json:"self_mint" gorm:"column:self_mint"`                 // mintable by the deployer only
 }
 
 func (Inscriptions) TableName() string {
@@ -98,10 +108,19 @@ type InscriptionOverView struct {
 	CreatedAt    time.Time       `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt    time.Time       `json:"updated_at" gorm:"column:updated_at"`
 	Decimals     int8            `json:"decimals" gorm:"column:decimals"`
-	Holders      uint64          `json:"holders" gorm:"column:holders"`
-	Minted       decimal.Decimal `gorm:"column:minted;type:decimal(38,18)" json:"minted"`
-	TxCnt        uint64          `gorm:"column:tx_cnt" json:"tx_cnt"`
-	Progress     decimal.Decimal `gorm:"column:progress;type:decimal(36,18)" json:"progress"` // mint进度
+
+	StrictDecimals bool `json:"strict_decimals" gorm:"column:strict_decimals"`
+
+	AddressMintLimit decimal.Decimal `gorm:"column:address_mint_limit;type:decimal(38,18)" json:"address_mint_limit"`
+	MintStartBlock   uint64          `json:"mint_start_block" gorm:"column:mint_start_block"`
+	MintEndBlock     uint64          `json:"mint_end_block" gorm:"column:mint_end_block"`
+	Premine          decimal.Decimal `gorm:"column:premine;type:decimal(38,18)" json:"premine"`
+	SelfMint         bool            `json:"self_mint" gorm:"column:self_mint"`
+
+	Holders  uint64          `json:"holders" gorm:"column:holders"`
+	Minted   decimal.Decimal `gorm:"column:minted;type:decimal(38,18)" json:"minted"`
+	TxCnt    uint64          `gorm:"column:tx_cnt" json:"tx_cnt"`
+	Progress decimal.Decimal `gorm:"column:progress;type:decimal(36,18)" json:"progress"` // mint进度
 }
 
 type InscriptionBrief struct {
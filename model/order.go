@@ -0,0 +1,58 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	OrderStatusOpen     int8 = 1
+	OrderStatusFilled   int8 = 2
+	OrderStatusCanceled int8 = 3
+)
+
+// ListOrder marketplace listing of the tick, the amount is escrowed from the available balance of the seller
+// until the order is filled to the buyer or canceled, the price is in the native coin
+type ListOrder struct {
+	ID          uint64          `gorm:"primaryKey" json:"id"`
+	Chain       string          `json:"chain" gorm:"column:chain"`
+	Protocol    string          `json:"protocol" gorm:"column:protocol"`
+	Tick        string          `json:"tick" gorm:"column:tick"`
+	ListID      string          `json:"list_id" gorm:"column:list_id"` // hash of the list tx
+	Seller      string          `json:"seller" gorm:"column:seller"`
+	Market      string          `json:"market" gorm:"column:market"`
+	Amount      decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(38,18)"`
+	Price       decimal.Decimal `json:"price" gorm:"column:price;type:decimal(38,18)"`
+	Status      int8            `json:"status" gorm:"column:status"`
+	Buyer       string          `json:"buyer" gorm:"column:buyer"`
+	SettleHash  string          `json:"settle_hash" gorm:"column:settle_hash"` // tx filling or canceling the order
+	BlockNumber uint64          `json:"block_number" gorm:"column:block_number"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (ListOrder) TableName() string {
+	return "list_orders"
+}
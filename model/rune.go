@@ -0,0 +1,55 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Rune etching of the bitcoin runes, amounts are the raw integers & the unset terms are null
+type Rune struct {
+	ID           uint64          `gorm:"primaryKey" json:"id"`
+	Chain        string          `json:"chain" gorm:"column:chain"`
+	RuneID       string          `json:"rune_id" gorm:"column:rune_id"` // block:tx of the etching
+	Tick         string          `json:"tick" gorm:"column:tick"`
+	SpacedName   string          `json:"spaced_name" gorm:"column:spaced_name"`
+	Symbol       string          `json:"symbol" gorm:"column:symbol"`
+	Divisibility int8            `json:"divisibility" gorm:"column:divisibility"`
+	Premine      decimal.Decimal `json:"premine" gorm:"column:premine;type:decimal(40,0)"`
+	Amount       decimal.Decimal `json:"amount" gorm:"column:amount;type:decimal(40,0)"`
+	Cap          decimal.Decimal `json:"cap" gorm:"column:cap;type:decimal(40,0)"`
+	HeightStart  *uint64         `json:"height_start" gorm:"column:height_start"`
+	HeightEnd    *uint64         `json:"height_end" gorm:"column:height_end"`
+	OffsetStart  *uint64         `json:"offset_start" gorm:"column:offset_start"`
+	OffsetEnd    *uint64         `json:"offset_end" gorm:"column:offset_end"`
+	Turbo        bool            `json:"turbo" gorm:"column:turbo"`
+	Cenotaph     bool            `json:"cenotaph" gorm:"column:cenotaph"` // etched by a cenotaph, nothing minted
+	TxHash       string          `json:"tx_hash" gorm:"column:tx_hash"`
+	BlockNumber  uint64          `json:"block_number" gorm:"column:block_number"`
+	CreatedAt    time.Time       `json:"created_at" gorm:"column:created_at"`
+}
+
+func (Rune) TableName() string {
+	return "runes"
+}
@@ -28,7 +28,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	protocommon "github.com/uxuycom/indexer/protocol/common"
 	"github.com/uxuycom/indexer/utils"
 	"github.com/uxuycom/indexer/xyerrors"
 	"github.com/uxuycom/indexer/xylog"
@@ -36,6 +38,9 @@ import (
 	"strings"
 )
 
+// nativeDecimals of the avax order prices in wei
+const nativeDecimals = 18
+
 const (
 	// EventTopicHashExchange avascriptions_protocol_TransferASC20TokenForListing (index_topic_1 address from, index_topic_2 address to, bytes32 id)
 	EventTopicHashExchange = "0xe2750d6418e3719830794d3db788aa72febcd657bcd18ed8f1facdbf61a69a9a"
@@ -51,6 +56,25 @@ type Exchange struct {
 	From    string
 	To      string
 	Amount  decimal.Decimal
+	ListID  string          // list tx hash of the order, empty for the direct transfers
+	Price   decimal.Decimal // order price in the native coin
+	Seller  string          // seller of the order, the market contract is the sender
+}
+
+// trade returns the trade of the order fill, the direct transfers & the delists are not traded
+func (e *Exchange) trade() *devents.Trade {
+	if e.ListID == "" || e.Operate != devents.OperateExchange {
+		return nil
+	}
+
+	return &devents.Trade{
+		ListID: e.ListID,
+		Seller: strings.ToLower(e.Seller),
+		Buyer:  strings.ToLower(e.To),
+		Market: strings.ToLower(e.From),
+		Amount: e.Amount,
+		Price:  e.Price,
+	}
 }
 
 // ASC20Order is an auto generated low-level Go binding around an user-defined struct.
@@ -75,7 +99,19 @@ func (p *Protocol) Exchange(block *xycommon.RpcBlock, tx *xycommon.RpcTransactio
 	for _, exchange := range exchanges {
 		md := omd.Copy()
 		md.Operate = exchange.Operate
-		md.Tick = strings.ToLower(strings.TrimSpace(exchange.Tick))
+		md.Tick = p.NormalizeTick(protocommon.TxHeight(tx), exchange.Tick)
+
+		// tracked orders settle the escrow of the seller, the others are moved out of the market balance
+		if ok, order := p.cache.Order.Get(exchange.ListID); ok && exchange.ListID != "" {
+			item, err1 := p.settle(block, tx, md, exchange, order)
+			if err1 != nil {
+				xylog.Logger.Infof("order settle verified failed, err:%v, data:%v", err1, exchange)
+				continue
+			}
+			items = append(items, item)
+			continue
+		}
+
 		if err1 := p.verifyExchange(md, exchange); err1 != nil {
 			xylog.Logger.Infof("exchange verified failed, err:%v, data:%v", err1, exchange)
 			continue
@@ -85,6 +121,7 @@ func (p *Protocol) Exchange(block *xycommon.RpcBlock, tx *xycommon.RpcTransactio
 			MD:    md,
 			Block: block,
 			Tx:    tx,
+			Trade: exchange.trade(),
 			Transfer: &devents.Transfer{
 				Sender: exchange.From,
 				Receives: []*devents.Receive{
@@ -110,12 +147,20 @@ func (p *Protocol) parseOrderByExchange(e xycommon.RpcLog, orders map[string]*AS
 		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("order amount value empty, ticker[%v]", order.Ticker))
 	}
 
+	price := decimal.Zero
+	if order.Price != nil {
+		price = decimal.NewFromBigInt(order.Price, -nativeDecimals)
+	}
+
 	return &Exchange{
 		Operate: order.Operate,
 		Tick:    order.Ticker,
 		From:    e.Address.String(),
 		To:      common.BytesToAddress(e.Topics[2].Bytes()).String(),
 		Amount:  decimal.NewFromBigInt(order.Amount, 0),
+		ListID:  strings.ToLower(e.Data.String()),
+		Price:   price,
+		Seller:  order.Seller.String(),
 	}, nil
 }
 
@@ -270,6 +315,52 @@ func (p *Protocol) extractValidOrdersByExchange(tx *xycommon.RpcTransaction) []*
 	return items
 }
 
+// settle closes the open order, the delist returns the escrow to the seller & the fill transfers it to the buyer
+func (p *Protocol) settle(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData, e *Exchange, order *dcache.OrderItem) (*devents.TxResult, *xyerrors.InsError) {
+	// only the market holding the escrow settles the order
+	if !strings.EqualFold(e.From, order.Market) {
+		return nil, xyerrors.NewInsError(-18, fmt.Sprintf("exchange market[%s] <> order market[%s], list[%s]", e.From, order.Market, e.ListID))
+	}
+
+	if order.Protocol != md.Protocol || order.Tick != md.Tick {
+		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("order tick[%s-%s] <> exchange tick[%s-%s], list[%s]", order.Protocol, order.Tick, md.Protocol, md.Tick, e.ListID))
+	}
+
+	if !order.Amount.Equal(e.Amount) {
+		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("order amount[%v] <> exchange amount[%v], list[%s]", order.Amount, e.Amount, e.ListID))
+	}
+
+	result := &devents.TxResult{
+		MD:    md,
+		Block: block,
+		Tx:    tx,
+		Settle: &devents.Settle{
+			ListID: order.ListID,
+			Seller: order.Seller,
+			Amount: order.Amount,
+			Price:  e.Price,
+		},
+	}
+
+	if e.Operate == devents.OperateDelist {
+		return result, nil
+	}
+
+	result.Settle.Buyer = e.To
+	result.Trade = e.trade()
+	result.Transfer = &devents.Transfer{
+		Sender: order.Seller,
+		Receives: []*devents.Receive{
+			{
+				Address: e.To,
+				Amount:  order.Amount,
+			},
+		},
+		Transferable: true,
+	}
+	return result, nil
+}
+
 func (p *Protocol) verifyExchange(md *devents.MetaData, e *Exchange) *xyerrors.InsError {
 	var (
 		protocol = md.Protocol
@@ -31,6 +31,8 @@ import (
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	protocommon "github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xylog"
 	"math/big"
 	"testing"
@@ -85,7 +87,7 @@ func TestExtractInputOrders_cancelOrdersVerify(t *testing.T) {
 	}
 
 	cache := dcache.NewManager(nil, "avax")
-	protocol := NewProtocol(cache)
+	protocol := NewProtocol(cache, protocommon.BaseSchedule(protocommon.DefaultRules, types.ASC20Protocol))
 
 	results := protocol.extractInputOrders("", "0x7b2c304d00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000006000000000000000000000000000000000000000000000000000000000000002a000000000000000000000000000000000000000000000000000000000000004e00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000050cf0e5438354c45bcaf1689916a6ae39a2198059045bb79275c718d4fce7a5d00000000000000000000000000000000000000000000000000000000000001e0000000000000000000000000000000000000000000000000000000037e11d600000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000022000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000046176617800000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000dcf1bc942bb158a669e6ce4bf8714c06aaaf19abbd96c08f5e759f9ca696fda800000000000000000000000000000000000000000000000000000000000001e000000000000000000000000000000000000000000000000000000003b9aca00000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004617661760000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000084b6f0bd44aba8c87e416c91e0874a6b1d4a4b9eb23a7aec6a93860e3e19ded500000000000000000000000000000000000000000000000000000000000001e00000000000000000000000000000000000000000000000000000000430e234000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000220000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000478787979000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000")
 
@@ -130,7 +132,7 @@ func TestExtractInputOrders_batchMatchOrdersVerify(t *testing.T) {
 	}
 
 	cache := dcache.NewManager(nil, "avax")
-	protocol := NewProtocol(cache)
+	protocol := NewProtocol(cache, protocommon.BaseSchedule(protocommon.DefaultRules, types.ASC20Protocol))
 
 	results := protocol.extractInputOrders("", "0x24608215000000000000000000000000000000000000000000000000000000000000004000000000000000000000000024e24277e2ff8828d5d2e278764ca258c22bd4970000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000006000000000000000000000000000000000000000000000000000000000000002a000000000000000000000000000000000000000000000000000000000000004e00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000050cf0e5438354c45bcaf1689916a6ae39a2198059045bb79275c718d4fce7a5d00000000000000000000000000000000000000000000000000000000000001e0000000000000000000000000000000000000000000000000000000037e11d600000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000022000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000046176617800000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000dcf1bc942bb158a669e6ce4bf8714c06aaaf19abbd96c08f5e759f9ca696fda800000000000000000000000000000000000000000000000000000000000001e000000000000000000000000000000000000000000000000000000003b9aca00000000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004617661760000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000084b6f0bd44aba8c87e416c91e0874a6b1d4a4b9eb23a7aec6a93860e3e19ded500000000000000000000000000000000000000000000000000000000000001e00000000000000000000000000000000000000000000000000000000430e234000000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000220000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000478787979000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000")
 
@@ -287,7 +289,7 @@ func TestExtractValidOrdersByExchange(t *testing.T) {
 	}
 
 	cache := dcache.NewManager(nil, "avax")
-	protocol := NewProtocol(cache)
+	protocol := NewProtocol(cache, protocommon.BaseSchedule(protocommon.DefaultRules, types.ASC20Protocol))
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
 			result := protocol.extractValidOrdersByExchange(test.Tx)
@@ -342,7 +344,7 @@ func TestExtractValidOrdersByTransfer(t *testing.T) {
 
 	cache := dcache.NewManager(nil, "avax")
 	cache.Inscription = dcache.NewInscription()
-	protocol := NewProtocol(cache)
+	protocol := NewProtocol(cache, protocommon.BaseSchedule(protocommon.DefaultRules, types.ASC20Protocol))
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
 			for _, tick := range test.Tickers {
@@ -362,3 +364,58 @@ func TestExtractValidOrdersByTransfer(t *testing.T) {
 		})
 	}
 }
+
+func TestSettle(t *testing.T) {
+	const (
+		market  = "0x00000000000000000000000000000000000000Cc"
+		foreign = "0x00000000000000000000000000000000000000bb"
+		seller  = "0x00000000000000000000000000000000000000aa"
+		buyer   = "0x00000000000000000000000000000000000000dd"
+		listID  = "0x000000000000000000000000000000000000000000000000000000000000012c"
+	)
+
+	cache := dcache.NewManager(nil, "avax")
+	protocol := NewProtocol(cache, protocommon.BaseSchedule(protocommon.DefaultRules, types.ASC20Protocol))
+	order := &dcache.OrderItem{
+		ListID:   listID,
+		Protocol: types.ASC20Protocol,
+		Tick:     "dino",
+		Seller:   seller,
+		Market:   "0x00000000000000000000000000000000000000cc",
+		Amount:   decimal.NewFromInt(30),
+	}
+	md := &devents.MetaData{Protocol: types.ASC20Protocol, Tick: "dino"}
+	exchange := func(operate, from, tick string, amount int64) *Exchange {
+		return &Exchange{Operate: operate, Tick: tick, From: from, To: buyer, Amount: decimal.NewFromInt(amount), ListID: listID, Price: decimal.NewFromInt(3)}
+	}
+
+	// only the market holding the escrow fills or delists the order
+	_, err := protocol.settle(nil, nil, md, exchange(devents.OperateExchange, foreign, "dino", 30), order)
+	assert.NotNil(t, err)
+	_, err = protocol.settle(nil, nil, md, exchange(devents.OperateDelist, foreign, "dino", 30), order)
+	assert.NotNil(t, err)
+
+	_, err = protocol.settle(nil, nil, &devents.MetaData{Protocol: types.ASC20Protocol, Tick: "avav"}, exchange(devents.OperateExchange, market, "avav", 30), order)
+	assert.NotNil(t, err)
+	_, err = protocol.settle(nil, nil, md, exchange(devents.OperateExchange, market, "dino", 20), order)
+	assert.NotNil(t, err)
+
+	result, err := protocol.settle(nil, nil, md, exchange(devents.OperateDelist, market, "dino", 30), order)
+	if assert.Nil(t, err) {
+		assert.Equal(t, listID, result.Settle.ListID)
+		assert.Equal(t, seller, result.Settle.Seller)
+		assert.Empty(t, result.Settle.Buyer)
+		assert.Nil(t, result.Transfer)
+	}
+
+	result, err = protocol.settle(nil, nil, md, exchange(devents.OperateExchange, market, "dino", 30), order)
+	if assert.Nil(t, err) {
+		assert.Equal(t, buyer, result.Settle.Buyer)
+		assert.NotNil(t, result.Trade)
+		if assert.NotNil(t, result.Transfer) {
+			assert.Equal(t, seller, result.Transfer.Sender)
+			assert.True(t, result.Transfer.Transferable)
+			assert.Equal(t, "30", result.Transfer.Receives[0].Amount.String())
+		}
+	}
+}
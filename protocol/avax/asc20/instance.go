@@ -25,9 +25,12 @@ package asc20
 import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
 	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/xyerrors"
 	"github.com/uxuycom/indexer/xylog"
@@ -43,9 +46,17 @@ type Protocol struct {
 
 var ParsedABI abi.ABI
 
-func NewProtocol(cache *dcache.Manager) *Protocol {
+// jsonProfile brc-20 shapes with the list orders
+var jsonProfile = common.HashTransferJSONProfile.Extend(map[string]map[string]common.FieldRule{
+	devents.OperateList: {
+		"amt":   {Kind: common.FieldNumber, Required: true},
+		"price": {Kind: common.FieldNumber},
+	},
+})
+
+func NewProtocol(cache *dcache.Manager, schedule *common.Schedule) *Protocol {
 	return &Protocol{
-		common: common.NewProtocol(cache),
+		common: common.NewProtocol(cache, schedule),
 		cache:  cache,
 		ticks:  &sync.Map{},
 	}
@@ -62,6 +73,18 @@ func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction,
 	return p.common.Parse(block, tx, md)
 }
 
+func (p *Protocol) NormalizeMetaData(tx *xycommon.RpcTransaction, md *devents.MetaData) error {
+	return p.common.NormalizeMetaData(tx, md)
+}
+
+func (p *Protocol) NormalizeTick(height uint64, tick string) string {
+	return p.common.NormalizeTick(height, tick)
+}
+
+func (p *Protocol) VerifyStart(startBlock uint64) error {
+	return p.common.VerifyStart(startBlock)
+}
+
 func ParseMetaDataByEventLogs(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
 	for _, event := range tx.Events {
 		if len(event.Topics) < 1 {
@@ -86,4 +109,18 @@ func init() {
 	if err != nil {
 		xylog.Logger.Fatalf("asc20 abi decode err:%v", err)
 	}
+
+	// exchange events are parsed from logs, deploy / mint / transfer from the tx input
+	registry.MustRegister(registry.Entry{
+		ChainGroup: model.EvmChainGroup,
+		Protocol:   types.ASC20Protocol,
+		Parser:     ParseMetaDataByEventLogs,
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			schedule, err := common.NewSchedule(common.DefaultRules.WithJSONProfile(jsonProfile), types.ASC20Protocol, cfg.Chain.ProtocolRules)
+			if err != nil {
+				return nil, err
+			}
+			return NewProtocol(cache, schedule), nil
+		},
+	})
 }
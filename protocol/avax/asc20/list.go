@@ -28,13 +28,18 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/common"
 	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
 )
 
 type List struct {
 	Amount decimal.Decimal `json:"amt"`
+	Price  decimal.Decimal `json:"price"` // optional asking price in the native coin
 }
 
+// List opens the order of the market tx.To identified by the list tx hash, the amount is escrowed
+// from the available balance of the seller until the order is filled or canceled
 func (p *Protocol) List(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
 	list, err := p.verifyList(tx, md)
 	if err != nil {
@@ -44,14 +49,12 @@ func (p *Protocol) List(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, m
 		MD:    md,
 		Block: block,
 		Tx:    tx,
-		Transfer: &devents.Transfer{
-			Sender: tx.From,
-			Receives: []*devents.Receive{
-				{
-					Address: tx.To,
-					Amount:  list.Amount,
-				},
-			},
+		List: &devents.List{
+			ListID: strings.ToLower(tx.Hash),
+			Seller: tx.From,
+			Market: tx.To,
+			Amount: list.Amount,
+			Price:  list.Price,
 		},
 	}
 	return []*devents.TxResult{result}, nil
@@ -77,15 +80,23 @@ func (p *Protocol) verifyList(tx *xycommon.RpcTransaction, md *devents.MetaData)
 		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", protocol, tick))
 	}
 
+	if inscription.StrictDecimals && !common.ValidDecimals(tf.Amount, inscription.Decimals) {
+		return nil, xyerrors.NewInsError(-35, fmt.Sprintf("list amount[%v] exceeds decimals[%d]", tf.Amount, inscription.Decimals))
+	}
+
 	// sender balance checking
 	ok, balance := p.cache.Balance.Get(protocol, tick, tx.From)
 	if !ok {
 		return nil, xyerrors.NewInsError(-16, fmt.Sprintf("sender balance record not exist, tick[%s-%s], address[%s]", protocol, tick, tx.From))
 	}
 
-	// balance available checking
-	if balance.Overall.LessThan(tf.Amount) {
-		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("sender total balance[%v] < transfer amount[%v]", balance.Overall, tf.Amount))
+	// balance available checking, the escrowed balance of the open orders is not available
+	if balance.Available.LessThan(tf.Amount) {
+		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("sender available balance[%v] < list amount[%v]", balance.Available, tf.Amount))
+	}
+
+	if tf.Price.LessThan(decimal.Zero) {
+		return nil, xyerrors.NewInsError(-18, "list price < 0")
 	}
 	return tf, nil
 }
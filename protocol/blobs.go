@@ -0,0 +1,115 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/protocol/common"
+)
+
+const (
+	// blobSize 4096 field elements of 32 bytes
+	blobSize = 131072
+
+	// fieldElementSize the high byte of the field element is kept zero,
+	// so the element value is below the BLS modulus
+	fieldElementSize = 32
+
+	// payloadTerminator marks the end of the payload packed into the blobs
+	payloadTerminator = 0x80
+
+	// blobHashMark marks the tx resolved from the blobs
+	blobHashMark = "blob"
+)
+
+// DecodeBlobs
+/***************************************
+ * unpacks the payload of the blobs in order: each field element carries 31 bytes
+ * after the zero high byte, the payload ends with the terminator followed by zeros
+ ***************************************/
+func DecodeBlobs(blobs []string) ([]byte, error) {
+	payload := make([]byte, 0, len(blobs)*blobSize)
+	for i, blob := range blobs {
+		data, err := hexutil.Decode(blob)
+		if err != nil || len(data) != blobSize {
+			return nil, fmt.Errorf("blob[%d] invalid, size[%d], err[%v]", i, len(data), err)
+		}
+
+		for j := 0; j < blobSize; j += fieldElementSize {
+			if data[j] != 0 {
+				return nil, fmt.Errorf("blob[%d] field element[%d] out of range", i, j/fieldElementSize)
+			}
+			payload = append(payload, data[j+1:j+fieldElementSize]...)
+		}
+	}
+
+	payload = bytes.TrimRight(payload, "\x00")
+	if len(payload) < 1 || payload[len(payload)-1] != payloadTerminator {
+		return nil, errors.New("blobs payload terminator not found")
+	}
+	return payload[:len(payload)-1], nil
+}
+
+// EncodeBlobs packs the payload into blobs as DecodeBlobs unpacks
+func EncodeBlobs(payload []byte) []string {
+	data := append(append([]byte{}, payload...), payloadTerminator)
+	blobs := make([]string, 0, 1)
+	for len(data) > 0 {
+		blob := make([]byte, blobSize)
+		for j := 0; j < blobSize && len(data) > 0; j += fieldElementSize {
+			n := copy(blob[j+1:j+fieldElementSize], data)
+			data = data[n:]
+		}
+		blobs = append(blobs, hexutil.Encode(blob))
+	}
+	return blobs
+}
+
+// ResolveBlobInscription
+/***************************************
+ * type-3 txs inscribe by the "data:" payload of their blobs, resolved as the tx of the same
+ * sender & receiver with the payload as the input. the hash is marked apart from the tx,
+ * which is indexed by its calldata as usual. returns nil if no blob payload inscribed
+ ***************************************/
+func ResolveBlobInscription(tx *xycommon.RpcTransaction) *xycommon.RpcTransaction {
+	if len(tx.Blobs) < 1 {
+		return nil
+	}
+
+	payload, err := DecodeBlobs(tx.Blobs)
+	if err != nil || !common.IsDataURI(payload) {
+		return nil
+	}
+
+	resolved := *tx
+	resolved.Hash = tx.Hash + xycommon.DerivedHashSeparator + blobHashMark
+	resolved.Input = hexutil.Encode(payload)
+	resolved.Events = nil
+	resolved.Trace = nil
+	resolved.Blobs = nil
+	return &resolved
+}
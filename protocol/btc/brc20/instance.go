@@ -23,16 +23,149 @@
 package brc20
 
 import (
+	"errors"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
+	"strings"
 )
 
+// Protocol of btc brc-20, transfers take two steps: inscribing the transfer inscription
+// reserves the transferable balance, sending the inscription settles it to the receiver
 type Protocol struct {
-	*common.Protocol
+	common *common.Protocol
+	cache  *dcache.Manager
 }
 
-func NewProtocol(cache *dcache.Manager) *Protocol {
+func NewProtocol(cache *dcache.Manager, schedule *common.Schedule) *Protocol {
 	return &Protocol{
-		Protocol: common.NewProtocol(cache),
+		common: common.NewProtocol(cache, schedule),
+		cache:  cache,
 	}
 }
+
+// Parse settles the pending transfer inscriptions spent by the tx first, then the inscription revealed by it,
+// which is verified against the balances before the tx
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	results, err := p.Send(block, tx, md)
+	if err != nil {
+		return nil, err
+	}
+
+	// the sending txs without envelope carry no data
+	if md.Data == "" {
+		if len(results) < 1 {
+			err := xyerrors.NewInsError(-18, fmt.Sprintf("transfer inscription not pending or already sent, tx[%s]", tx.Hash))
+			return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+		}
+		return results, nil
+	}
+
+	revealed, err := p.parseRevealed(block, tx, md)
+	if err != nil {
+		if len(results) < 1 || errors.Is(err, xyerrors.ErrInternal) {
+			return nil, err
+		}
+		xylog.Logger.Infof("revealed inscription parsed failed, sent inscriptions settled only, tx[%s], err[%v]", tx.Hash, err)
+	}
+	return append(results, revealed...), nil
+}
+
+func (p *Protocol) parseRevealed(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	switch md.Operate {
+	case devents.OperateDeploy:
+		return p.Deploy(block, tx, md)
+	case devents.OperateTransfer:
+		return p.InscribeTransfer(block, tx, md)
+	}
+	return p.common.Parse(block, tx, md)
+}
+
+// Deploy ignores the hash transfer type, btc brc-20 always transfers by the transfer inscriptions
+func (p *Protocol) Deploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	results, err := p.common.Deploy(block, tx, md)
+	for _, result := range results {
+		result.Deploy.TransferType = model.TransferTypeBalance
+		if result.Mint != nil {
+			result.Mint.SN = ""
+		}
+	}
+	return results, err
+}
+
+func (p *Protocol) NormalizeMetaData(tx *xycommon.RpcTransaction, md *devents.MetaData) error {
+	return p.common.NormalizeMetaData(tx, md)
+}
+
+func (p *Protocol) NormalizeTick(height uint64, tick string) string {
+	return p.common.NormalizeTick(height, tick)
+}
+
+func (p *Protocol) VerifyStart(startBlock uint64) error {
+	return p.common.VerifyStart(startBlock)
+}
+
+// MatchTx matches the txs sending pending transfer inscriptions, the reveals are matched by the group envelope
+func (p *Protocol) MatchTx(tx *xycommon.RpcTransaction) bool {
+	return len(p.sentInscriptions(tx)) > 0
+}
+
+// ParseMetaData recognizes the txs sending pending transfer inscriptions. The brc-20 inscription
+// revealed by the same tx is returned instead, Parse settles the sent inscriptions along with it
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	sent := p.sentInscriptions(tx)
+	if len(sent) < 1 {
+		return nil, nil
+	}
+
+	if md := p.revealedMetaData(chain, tx); md != nil {
+		return md, nil
+	}
+
+	return &devents.MetaData{
+		Chain:    chain,
+		Protocol: sent[0].item.Protocol,
+		Operate:  devents.OperateTransfer,
+		Tick:     sent[0].item.Tick,
+	}, nil
+}
+
+// revealedMetaData parses the brc-20 envelope of the tx by the group parser, nil if not found or invalid
+func (p *Protocol) revealedMetaData(chain string, tx *xycommon.RpcTransaction) *devents.MetaData {
+	parse := registry.GroupParser(model.BtcChainGroup)
+	if parse == nil {
+		return nil
+	}
+
+	md, err := parse(chain, tx)
+	if err != nil || md == nil || !strings.EqualFold(strings.TrimSpace(md.Protocol), types.BRC20Protocol) {
+		return nil
+	}
+
+	if err = p.NormalizeMetaData(tx, md); err != nil {
+		return nil
+	}
+	return md
+}
+
+func init() {
+	registry.MustRegister(registry.Entry{
+		ChainGroup: model.BtcChainGroup,
+		Protocol:   types.BRC20Protocol,
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			schedule, err := common.NewSchedule(common.BRC20Rules, types.BRC20Protocol, cfg.Chain.ProtocolRules)
+			if err != nil {
+				return nil, err
+			}
+			return NewProtocol(cache, schedule), nil
+		},
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package brc20
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+type Transfer struct {
+	Amount decimal.Decimal `json:"amt"`
+}
+
+// InscribeTransfer moves the amount from the available balance to the transferable one,
+// the inscription stays pending until it is sent
+func (p *Protocol) InscribeTransfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	tf, err := p.verifyInscribeTransfer(tx, md)
+	if err != nil {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+	result := &devents.TxResult{
+		MD:    md,
+		Block: block,
+		Tx:    tx,
+		InscribeTransfer: &devents.InscribeTransfer{
+			Address: tx.To,
+			Amount:  tf.Amount,
+			SN:      fmt.Sprintf("%si0", tx.Hash),
+		},
+	}
+	return []*devents.TxResult{result}, nil
+}
+
+func (p *Protocol) verifyInscribeTransfer(tx *xycommon.RpcTransaction, md *devents.MetaData) (*Transfer, *xyerrors.InsError) {
+	tf := &Transfer{}
+	err := json.Unmarshal([]byte(md.Data), tf)
+	if err != nil {
+		return nil, xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
+	}
+
+	if tf.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, xyerrors.NewInsError(-14, "transfer amount <= 0")
+	}
+
+	var (
+		protocol = md.Protocol
+		tick     = md.Tick
+	)
+	ok, inscription := p.cache.Inscription.Get(protocol, tick)
+	if !ok || inscription == nil {
+		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", protocol, tick))
+	}
+
+	// inscriber balance checking
+	ok, balance := p.cache.Balance.Get(protocol, tick, tx.To)
+	if !ok {
+		return nil, xyerrors.NewInsError(-16, fmt.Sprintf("inscriber balance record not exist, tick[%s-%s], address[%s]", protocol, tick, tx.To))
+	}
+
+	// balance available checking, the transferable balance can not be inscribed twice
+	if balance.Available.LessThan(tf.Amount) {
+		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("inscriber available balance[%v] < transfer amount[%v]", balance.Available, tf.Amount))
+	}
+	return tf, nil
+}
+
+// Send settles each pending transfer inscription spent by the tx to its receiver
+func (p *Protocol) Send(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	sent := p.sentInscriptions(tx)
+	results := make([]*devents.TxResult, 0, len(sent))
+	for _, s := range sent {
+		receiver, err := satReceiver(tx, s.idx)
+		if err != nil {
+			return nil, xyerrors.ErrInternal.WrapCause(xyerrors.NewInsError(-38, err.Error()))
+		}
+
+		// inscriptions lost to the fee or sent to outputs without address return to the sender
+		if receiver == "" {
+			receiver = s.item.Owner
+		}
+
+		smd := md.Copy()
+		smd.Protocol = s.item.Protocol
+		smd.Operate = devents.OperateTransfer
+		smd.Tick = s.item.Tick
+		smd.Data = ""
+		results = append(results, &devents.TxResult{
+			MD:    smd,
+			Block: block,
+			Tx:    tx,
+			Transfer: &devents.Transfer{
+				Sender: s.item.Owner,
+				Receives: []*devents.Receive{
+					{
+						Address: receiver,
+						Amount:  s.item.Amount,
+					},
+				},
+				RootHash:     tx.Vin[s.idx].Txid,
+				SN:           s.item.SN,
+				Transferable: true,
+			},
+		})
+	}
+	return results, nil
+}
+
+type sentInscription struct {
+	idx  int // input spending the inscription
+	item *dcache.UTXOItem
+}
+
+// sentInscriptions returns the inputs spending pending transfer inscriptions in the input order,
+// transfer inscriptions are revealed on the first sat of the first output
+func (p *Protocol) sentInscriptions(tx *xycommon.RpcTransaction) []*sentInscription {
+	items := make([]*sentInscription, 0, 1)
+	for idx, vin := range tx.Vin {
+		if vin.IsCoinBase() || vin.Vout != 0 {
+			continue
+		}
+
+		ok, item := p.cache.UTXO.Get(vin.Txid)
+		if !ok || !strings.EqualFold(item.Protocol, types.BRC20Protocol) {
+			continue
+		}
+		items = append(items, &sentInscription{idx: idx, item: item})
+	}
+	return items
+}
+
+// satReceiver follows the first sat of the input through the outputs, empty if it is lost to the fee.
+// The first sat of the first input is the first one of the outputs, the others are offset by the values
+// of the former inputs, which are unknown without the prevouts
+func satReceiver(tx *xycommon.RpcTransaction, idx int) (string, error) {
+	if idx > 0 && len(tx.Prevouts) != len(tx.Vin) {
+		return "", fmt.Errorf("prevouts of the tx[%s] missing, input[%d] sat offset unknown", tx.Hash, idx)
+	}
+
+	offset := int64(0)
+	for _, prevout := range tx.Prevouts[:idx] {
+		offset += sats(prevout.Value)
+	}
+
+	for _, vout := range tx.Vout {
+		value := sats(vout.Value)
+		if offset < value {
+			return voutAddress(&vout), nil
+		}
+		offset -= value
+	}
+	return "", nil
+}
+
+func sats(value float64) int64 {
+	amount, err := btcutil.NewAmount(value)
+	if err != nil {
+		return 0
+	}
+	return int64(amount)
+}
+
+func voutAddress(vout *btcjson.Vout) string {
+	if vout.ScriptPubKey.Address != "" {
+		return vout.ScriptPubKey.Address
+	}
+
+	if len(vout.ScriptPubKey.Addresses) > 0 {
+		return vout.ScriptPubKey.Addresses[0]
+	}
+	return ""
+}
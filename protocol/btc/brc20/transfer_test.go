@@ -0,0 +1,192 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package brc20
+
+import (
+	"errors"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"testing"
+)
+
+const (
+	testAddrA = "bc1qaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	testAddrB = "bc1qbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func newTestProtocol() (*Protocol, *dcache.Manager) {
+	cache := &dcache.Manager{
+		Inscription:      dcache.NewInscription(),
+		InscriptionStats: dcache.NewInscriptionStats(),
+		Balance:          dcache.NewBalance(),
+		AddressMinted:    dcache.NewAddressMinted(),
+		UTXO:             dcache.NewUTXO(),
+	}
+	return NewProtocol(cache, common.BaseSchedule(common.BRC20Rules, types.BRC20Protocol)), cache
+}
+
+func vout(address string, value float64) btcjson.Vout {
+	return btcjson.Vout{Value: value, ScriptPubKey: btcjson.ScriptPubKeyResult{Address: address}}
+}
+
+func TestVerifyInscribeTransfer(t *testing.T) {
+	p, cache := newTestProtocol()
+	cache.Inscription.Create(types.BRC20Protocol, "ordi", &dcache.Tick{})
+
+	// the transferable balance is not available
+	cache.Balance.Create(types.BRC20Protocol, "ordi", testAddrA, &dcache.BalanceItem{
+		Available: decimal.NewFromInt(100),
+		Overall:   decimal.NewFromInt(150),
+	})
+
+	tests := []struct {
+		name string
+		tick string
+		to   string
+		data string
+		code int // 0 valid
+	}{
+		{"inscribe", "ordi", testAddrA, `{"amt":"100"}`, 0},
+		{"json invalid", "ordi", testAddrA, `{"amt":100`, -13},
+		{"amount zero", "ordi", testAddrA, `{"amt":"0"}`, -14},
+		{"not deployed", "none", testAddrA, `{"amt":"100"}`, -15},
+		{"balance not exist", "ordi", testAddrB, `{"amt":"100"}`, -16},
+		{"exceeds available balance", "ordi", testAddrA, `{"amt":"120"}`, -17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the inscription is revealed to the receiver of the first output
+			tx := &xycommon.RpcTransaction{Hash: "ab", To: tt.to}
+			md := &devents.MetaData{Protocol: types.BRC20Protocol, Tick: tt.tick, Data: tt.data}
+			tf, err := p.verifyInscribeTransfer(tx, md)
+			if tt.code != 0 {
+				if assert.NotNil(t, err) {
+					assert.Equal(t, tt.code, err.Code(), err.Error())
+				}
+				return
+			}
+
+			if assert.Nil(t, err) {
+				assert.Equal(t, "100", tf.Amount.String())
+			}
+		})
+	}
+}
+
+func TestSatReceiver(t *testing.T) {
+	prevouts := []btcjson.Vout{{Value: 0.0001}, {Value: 0.00000546}}
+	tests := []struct {
+		name     string
+		prevouts []btcjson.Vout
+		vout     []btcjson.Vout
+		idx      int
+		want     string
+		err      bool
+	}{
+		{"first input", prevouts, []btcjson.Vout{vout(testAddrA, 0.0001), vout(testAddrB, 0.00000546)}, 0, testAddrA, false},
+		{"offset input", prevouts, []btcjson.Vout{vout(testAddrA, 0.0001), vout(testAddrB, 0.00000546)}, 1, testAddrB, false},
+		{"merged outputs", prevouts, []btcjson.Vout{vout(testAddrA, 0.00010546)}, 1, testAddrA, false},
+		{"lost to the fee", prevouts, []btcjson.Vout{vout(testAddrA, 0.00009)}, 1, "", false},
+		{"first input prevouts missing", nil, []btcjson.Vout{vout(testAddrA, 0.0001)}, 0, testAddrA, false},
+		{"zero value output skipped", nil, []btcjson.Vout{vout(testAddrB, 0), vout(testAddrA, 0.0001)}, 0, testAddrA, false},
+		{"offset input prevouts missing", nil, []btcjson.Vout{vout(testAddrA, 0.0001)}, 1, "", true},
+		{"legacy addresses", prevouts, []btcjson.Vout{{Value: 0.0002, ScriptPubKey: btcjson.ScriptPubKeyResult{Addresses: []string{testAddrB}}}}, 0, testAddrB, false},
+		{"no address", prevouts, []btcjson.Vout{{Value: 0.0002}}, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &xycommon.RpcTransaction{
+				Vin:      []btcjson.Vin{{Txid: "aa"}, {Txid: "bb"}},
+				Vout:     tt.vout,
+				Prevouts: tt.prevouts,
+			}
+			receiver, err := satReceiver(tx, tt.idx)
+			assert.Equal(t, tt.err, err != nil)
+			assert.Equal(t, tt.want, receiver)
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	p, cache := newTestProtocol()
+	cache.UTXO.Add(types.BRC20Protocol, "ordi", "aa", testAddrA, decimal.NewFromInt(10), "aai0")
+	cache.UTXO.Add(types.BRC20Protocol, "sats", "bb", testAddrA, decimal.NewFromInt(20), "bbi0")
+	cache.UTXO.Add("runes", "rune", "cc", testAddrA, decimal.NewFromInt(30), "cc:0")
+
+	// the second inscription is lost to the fee & returns to its owner,
+	// the non brc-20 utxo & the output other than the inscribed one are skipped
+	tx := &xycommon.RpcTransaction{
+		Hash: "dd",
+		Vin: []btcjson.Vin{
+			{Txid: "aa", Vout: 0},
+			{Txid: "cc", Vout: 0},
+			{Txid: "aa", Vout: 1},
+			{Txid: "bb", Vout: 0},
+		},
+		Prevouts: []btcjson.Vout{{Value: 0.00000546}, {Value: 0.00000546}, {Value: 0.00000546}, {Value: 0.00000546}},
+		Vout:     []btcjson.Vout{vout(testAddrB, 0.00000546)},
+	}
+	assert.True(t, p.MatchTx(tx))
+
+	results, err := p.Send(&xycommon.RpcBlock{}, tx, &devents.MetaData{Protocol: types.BRC20Protocol})
+	assert.Nil(t, err)
+	if !assert.Len(t, results, 2) {
+		return
+	}
+
+	for i, expected := range []struct {
+		tick     string
+		sn       string
+		amount   string
+		receiver string
+	}{
+		{"ordi", "aai0", "10", testAddrB},
+		{"sats", "bbi0", "20", testAddrA},
+	} {
+		assert.Equal(t, devents.OperateTransfer, results[i].MD.Operate)
+		assert.Equal(t, expected.tick, results[i].MD.Tick)
+
+		tf := results[i].Transfer
+		assert.Equal(t, testAddrA, tf.Sender)
+		assert.Equal(t, expected.sn, tf.SN)
+		assert.True(t, tf.Transferable)
+		if assert.Len(t, tf.Receives, 1) {
+			assert.Equal(t, expected.receiver, tf.Receives[0].Address)
+			assert.Equal(t, expected.amount, tf.Receives[0].Amount.String())
+		}
+	}
+
+	assert.False(t, p.MatchTx(&xycommon.RpcTransaction{Vin: []btcjson.Vin{{Txid: "cc"}}}))
+
+	// the block is retried if the receivers are unknown without the prevouts
+	tx.Prevouts = nil
+	_, err = p.Parse(&xycommon.RpcBlock{}, tx, &devents.MetaData{Protocol: types.BRC20Protocol})
+	assert.True(t, errors.Is(err, xyerrors.ErrInternal))
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ordinals
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"strings"
+)
+
+// envelope fields tags
+const (
+	TagContentType     byte = 1
+	TagPointer         byte = 2
+	TagParent          byte = 3
+	TagMetadata        byte = 5
+	TagMetaprotocol    byte = 7
+	TagContentEncoding byte = 9
+	TagDelegate        byte = 11
+)
+
+// annexTag first byte of the taproot annex witness element, BIP-341
+const annexTag = 0x50
+
+var (
+	protocolID = []byte("ord")
+
+	// envelopeHex hex of OP_FALSE OP_IF OP_PUSHBYTES_3 "ord", the envelope header
+	envelopeHex = "0063036f7264"
+)
+
+// Inscription parsed from the ordinals envelope:
+// OP_FALSE OP_IF "ord" [tag value]... [OP_0 body...] OP_ENDIF
+type Inscription struct {
+	Input           int    // index of the input carrying the envelope
+	Offset          int    // envelope index within the input
+	ContentType     string // tag 1
+	ContentEncoding string // tag 9
+	Metaprotocol    string // tag 7
+	Body            []byte
+	Fields          map[byte][]byte
+
+	HasBody           bool
+	DuplicateField    bool // duplicated tags, the first value wins
+	IncompleteField   bool // tag without value
+	UnrecognizedField bool // unknown even tag, the inscription must not be interpreted
+}
+
+// HasEnvelope fast checking the tx witness containing the envelope header
+func HasEnvelope(tx *xycommon.RpcTransaction) bool {
+	for _, vin := range tx.Vin {
+		for _, item := range vin.Witness {
+			if strings.Contains(item, envelopeHex) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseTx parses the inscriptions of all inputs in order
+func ParseTx(tx *xycommon.RpcTransaction) []*Inscription {
+	inscriptions := make([]*Inscription, 0, 1)
+	for idx, vin := range tx.Vin {
+		if len(vin.Witness) < 1 {
+			continue
+		}
+
+		witness := make([][]byte, 0, len(vin.Witness))
+		for _, item := range vin.Witness {
+			data, err := hex.DecodeString(item)
+			if err != nil {
+				witness = nil
+				break
+			}
+			witness = append(witness, data)
+		}
+
+		for _, inscription := range ParseWitness(witness) {
+			inscription.Input = idx
+			inscriptions = append(inscriptions, inscription)
+		}
+	}
+	return inscriptions
+}
+
+// ParseWitness parses the envelopes in the tapscript of the witness
+func ParseWitness(witness [][]byte) []*Inscription {
+	script := tapscript(witness)
+	if script == nil {
+		return nil
+	}
+	return ParseScript(script)
+}
+
+// tapscript returns the leaf script of a taproot script path spend, nil for key path spends
+func tapscript(witness [][]byte) []byte {
+	if len(witness) >= 2 {
+		if last := witness[len(witness)-1]; len(last) > 0 && last[0] == annexTag {
+			witness = witness[:len(witness)-1]
+		}
+	}
+
+	if len(witness) < 2 {
+		return nil
+	}
+	return witness[len(witness)-2]
+}
+
+// ParseScript parses all envelopes in the script, malformed envelopes are skipped
+func ParseScript(script []byte) []*Inscription {
+	inscriptions := make([]*Inscription, 0, 1)
+
+	tokenizer := txscript.MakeScriptTokenizer(0, script)
+	for {
+		// looking for envelope header
+		if !nextEnvelope(&tokenizer) {
+			break
+		}
+
+		pushes, ok := readPushes(&tokenizer)
+		if !ok {
+			continue
+		}
+
+		inscription := buildInscription(pushes)
+		inscription.Offset = len(inscriptions)
+		inscriptions = append(inscriptions, inscription)
+	}
+	return inscriptions
+}
+
+// nextEnvelope advances the tokenizer after the next envelope header
+func nextEnvelope(tokenizer *txscript.ScriptTokenizer) bool {
+	// sliding window of the last 3 opcodes: OP_FALSE OP_IF push("ord")
+	var prev2, prev1 byte = 0xff, 0xff
+	for tokenizer.Next() {
+		op := tokenizer.Opcode()
+		if prev2 == txscript.OP_FALSE && prev1 == txscript.OP_IF && isPush(op) && bytes.Equal(tokenizer.Data(), protocolID) {
+			return true
+		}
+		prev2, prev1 = prev1, op
+	}
+	return false
+}
+
+// readPushes reads the envelope payload till OP_ENDIF, false returned if any non push opcode found
+func readPushes(tokenizer *txscript.ScriptTokenizer) ([][]byte, bool) {
+	pushes := make([][]byte, 0, 4)
+	for tokenizer.Next() {
+		op := tokenizer.Opcode()
+		switch {
+		case op == txscript.OP_ENDIF:
+			return pushes, true
+		case op == txscript.OP_1NEGATE:
+			pushes = append(pushes, []byte{0x81})
+		case op >= txscript.OP_1 && op <= txscript.OP_16:
+			pushes = append(pushes, []byte{op - txscript.OP_1 + 1})
+		case isPush(op):
+			pushes = append(pushes, tokenizer.Data())
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func isPush(op byte) bool {
+	return op <= txscript.OP_PUSHDATA4
+}
+
+func buildInscription(pushes [][]byte) *Inscription {
+	inscription := &Inscription{
+		Fields: make(map[byte][]byte),
+	}
+
+	// body starts with an empty push at tag position
+	fields := pushes
+	for i := 0; i < len(pushes); i += 2 {
+		if len(pushes[i]) == 0 {
+			fields = pushes[:i]
+			inscription.HasBody = true
+			inscription.Body = bytes.Join(pushes[i+1:], nil)
+			break
+		}
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		if i+1 >= len(fields) {
+			inscription.IncompleteField = true
+			break
+		}
+
+		tag, value := fields[i], fields[i+1]
+		if len(tag) != 1 {
+			// multi bytes tags are unknown, even tags must be recognized
+			if tag[0]%2 == 0 {
+				inscription.UnrecognizedField = true
+			}
+			continue
+		}
+
+		if _, ok := inscription.Fields[tag[0]]; ok {
+			inscription.DuplicateField = true
+			continue
+		}
+		inscription.Fields[tag[0]] = value
+	}
+
+	for tag := range inscription.Fields {
+		if tag%2 == 0 && !knownEvenTag(tag) {
+			inscription.UnrecognizedField = true
+		}
+	}
+
+	inscription.ContentType = string(inscription.Fields[TagContentType])
+	inscription.ContentEncoding = string(inscription.Fields[TagContentEncoding])
+	inscription.Metaprotocol = string(inscription.Fields[TagMetaprotocol])
+	return inscription
+}
+
+func knownEvenTag(tag byte) bool {
+	return tag == TagPointer
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ordinals
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"testing"
+)
+
+var (
+	testPubKey       = bytes.Repeat([]byte{0x02}, 32)
+	testSignature    = bytes.Repeat([]byte{0x03}, 64)
+	testControlBlock = append([]byte{0xc0}, bytes.Repeat([]byte{0x04}, 32)...)
+)
+
+// envelopeScript builds the reveal tapscript: <pubkey> OP_CHECKSIG OP_FALSE OP_IF "ord" pushes... OP_ENDIF
+func envelopeScript(t *testing.T, pushes ...[]byte) []byte {
+	builder := txscript.NewScriptBuilder().
+		AddData(testPubKey).
+		AddOp(txscript.OP_CHECKSIG)
+	builder = addEnvelope(builder, pushes...)
+
+	script, err := builder.Script()
+	assert.NoError(t, err)
+	return script
+}
+
+func addEnvelope(builder *txscript.ScriptBuilder, pushes ...[]byte) *txscript.ScriptBuilder {
+	builder.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData(protocolID)
+	for _, push := range pushes {
+		builder.AddFullData(push)
+	}
+	return builder.AddOp(txscript.OP_ENDIF)
+}
+
+func revealTx(witness ...[]byte) *xycommon.RpcTransaction {
+	items := make([]string, 0, len(witness))
+	for _, item := range witness {
+		items = append(items, hex.EncodeToString(item))
+	}
+	return &xycommon.RpcTransaction{
+		Vin: []btcjson.Vin{{Txid: "00", Witness: items}},
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	body := []byte(`{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000","lim":"1000"}`)
+	script := envelopeScript(t,
+		[]byte{TagContentType}, []byte("text/plain;charset=utf-8"),
+		[]byte{TagMetaprotocol}, []byte("brc-20"),
+		[]byte{}, body[:10], body[10:],
+	)
+
+	inscriptions := ParseScript(script)
+	assert.Len(t, inscriptions, 1)
+
+	ins := inscriptions[0]
+	assert.Equal(t, "text/plain;charset=utf-8", ins.ContentType)
+	assert.Equal(t, "brc-20", ins.Metaprotocol)
+	assert.True(t, ins.HasBody)
+	assert.Equal(t, body, ins.Body)
+	assert.False(t, ins.DuplicateField || ins.IncompleteField || ins.UnrecognizedField)
+}
+
+func TestParseScriptFields(t *testing.T) {
+	// tag pushed by OP_1, duplicated field
+	builder := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData(protocolID).
+		AddOp(txscript.OP_1).AddData([]byte("text/plain")).
+		AddData([]byte{TagContentType}).AddData([]byte("application/json")).
+		AddOp(txscript.OP_0).AddData([]byte("hello")).
+		AddOp(txscript.OP_ENDIF)
+	script, err := builder.Script()
+	assert.NoError(t, err)
+
+	inscriptions := ParseScript(script)
+	assert.Len(t, inscriptions, 1)
+	assert.Equal(t, "text/plain", inscriptions[0].ContentType)
+	assert.True(t, inscriptions[0].DuplicateField)
+	assert.Equal(t, []byte("hello"), inscriptions[0].Body)
+
+	// unrecognized even tag & incomplete field
+	inscriptions = ParseScript(envelopeScript(t, []byte{4}, []byte("x"), []byte{TagContentType}))
+	assert.Len(t, inscriptions, 1)
+	assert.True(t, inscriptions[0].UnrecognizedField)
+	assert.True(t, inscriptions[0].IncompleteField)
+	assert.False(t, inscriptions[0].HasBody)
+}
+
+func TestParseScriptMultiple(t *testing.T) {
+	builder := txscript.NewScriptBuilder()
+	builder = addEnvelope(builder, []byte{TagContentType}, []byte("text/plain"), []byte{}, []byte("first"))
+
+	// envelope with non push opcode is invalid & skipped
+	builder.AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData(protocolID).AddOp(txscript.OP_CHECKSIG).AddOp(txscript.OP_ENDIF)
+	builder = addEnvelope(builder, []byte{TagContentType}, []byte("text/plain"), []byte{}, []byte("second"))
+
+	script, err := builder.Script()
+	assert.NoError(t, err)
+
+	inscriptions := ParseScript(script)
+	assert.Len(t, inscriptions, 2)
+	assert.Equal(t, []byte("first"), inscriptions[0].Body)
+	assert.Equal(t, 0, inscriptions[0].Offset)
+	assert.Equal(t, []byte("second"), inscriptions[1].Body)
+	assert.Equal(t, 1, inscriptions[1].Offset)
+}
+
+func TestParseTx(t *testing.T) {
+	script := envelopeScript(t, []byte{TagContentType}, []byte("text/plain"), []byte{}, []byte("body"))
+
+	tx := revealTx(testSignature, script, testControlBlock)
+	assert.True(t, HasEnvelope(tx))
+
+	inscriptions := ParseTx(tx)
+	assert.Len(t, inscriptions, 1)
+	assert.Equal(t, 0, inscriptions[0].Input)
+	assert.Equal(t, []byte("body"), inscriptions[0].Body)
+
+	// annex is skipped
+	inscriptions = ParseTx(revealTx(testSignature, script, testControlBlock, []byte{annexTag, 0x01}))
+	assert.Len(t, inscriptions, 1)
+
+	// key path spend carries no script
+	tx = revealTx(testSignature)
+	assert.False(t, HasEnvelope(tx))
+	assert.Len(t, ParseTx(tx), 0)
+
+	// envelope in the second input
+	tx = revealTx(testSignature)
+	tx.Vin = append(tx.Vin, revealTx(testSignature, script, testControlBlock).Vin...)
+	inscriptions = ParseTx(tx)
+	assert.Len(t, inscriptions, 1)
+	assert.Equal(t, 1, inscriptions[0].Input)
+}
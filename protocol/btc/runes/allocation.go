@@ -0,0 +1,245 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package runes
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// runeBalance of one rune moved by the tx, amounts are the raw integers
+type runeBalance struct {
+	item        *dcache.RuneItem
+	etching     *devents.Etching // set for the rune etched by the tx
+	mint        bool
+	spends      []*devents.UTXOBalance
+	unallocated *big.Int
+	allocated   map[int]*big.Int // output -> amount
+	minted      *big.Int
+	burned      *big.Int
+}
+
+// allocation of the runes moved by the tx, in order of the inputs, the mint & the etching
+type allocation struct {
+	tx       *xycommon.RpcTransaction
+	ids      []string
+	balances map[string]*runeBalance
+}
+
+func newAllocation(tx *xycommon.RpcTransaction) *allocation {
+	return &allocation{
+		tx:       tx,
+		ids:      make([]string, 0, 1),
+		balances: make(map[string]*runeBalance, 1),
+	}
+}
+
+func (a *allocation) balance(item *dcache.RuneItem) *runeBalance {
+	if b, ok := a.balances[item.ID]; ok {
+		return b
+	}
+
+	b := &runeBalance{
+		item:        item,
+		unallocated: new(big.Int),
+		allocated:   make(map[int]*big.Int, 1),
+		minted:      new(big.Int),
+		burned:      new(big.Int),
+	}
+	a.ids = append(a.ids, item.ID)
+	a.balances[item.ID] = b
+	return b
+}
+
+func (a *allocation) allocate(b *runeBalance, amount *big.Int, output int) {
+	if amount.Sign() <= 0 {
+		return
+	}
+
+	b.unallocated.Sub(b.unallocated, amount)
+	if _, ok := b.allocated[output]; !ok {
+		b.allocated[output] = new(big.Int)
+	}
+	b.allocated[output].Add(b.allocated[output], amount)
+}
+
+// applyEdict allocates the unallocated balance to the output,
+// the output of the outputs count splits it among all the non OP_RETURN outputs
+func (a *allocation) applyEdict(b *runeBalance, amount *big.Int, output int) {
+	if output < len(a.tx.Vout) {
+		if amount.Sign() == 0 || amount.Cmp(b.unallocated) > 0 {
+			amount = new(big.Int).Set(b.unallocated)
+		}
+		a.allocate(b, amount, output)
+		return
+	}
+
+	destinations := a.destinations()
+	if len(destinations) < 1 {
+		return
+	}
+
+	// zero amount divides the balance evenly, the remainder goes to the first outputs
+	if amount.Sign() == 0 {
+		share, remainder := new(big.Int).DivMod(b.unallocated, big.NewInt(int64(len(destinations))), new(big.Int))
+		for i, out := range destinations {
+			value := new(big.Int).Set(share)
+			if int64(i) < remainder.Int64() {
+				value.Add(value, big.NewInt(1))
+			}
+			a.allocate(b, value, out)
+		}
+		return
+	}
+
+	for _, out := range destinations {
+		value := new(big.Int).Set(amount)
+		if value.Cmp(b.unallocated) > 0 {
+			value.Set(b.unallocated)
+		}
+		a.allocate(b, value, out)
+	}
+}
+
+// settle allocates the unallocated runes to the pointer or the first non OP_RETURN output,
+// the cenotaph burns them. Runes allocated to OP_RETURN or outputs without address are burned
+func (a *allocation) settle(stone *Runestone) {
+	output := -1
+	if stone == nil || !stone.Cenotaph {
+		if stone != nil && stone.Pointer != nil {
+			output = int(*stone.Pointer)
+		} else if destinations := a.destinations(); len(destinations) > 0 {
+			output = destinations[0]
+		}
+	}
+
+	for _, id := range a.ids {
+		b := a.balances[id]
+		if output >= 0 {
+			a.allocate(b, new(big.Int).Set(b.unallocated), output)
+		}
+		b.burned.Add(b.burned, b.unallocated)
+		b.unallocated.SetInt64(0)
+
+		for out, amount := range b.allocated {
+			if isOpReturn(&a.tx.Vout[out]) || voutAddress(&a.tx.Vout[out]) == "" {
+				b.burned.Add(b.burned, amount)
+				delete(b.allocated, out)
+			}
+		}
+	}
+}
+
+// destinations the non OP_RETURN outputs
+func (a *allocation) destinations() []int {
+	outputs := make([]int, 0, len(a.tx.Vout))
+	for idx := range a.tx.Vout {
+		if !isOpReturn(&a.tx.Vout[idx]) {
+			outputs = append(outputs, idx)
+		}
+	}
+	return outputs
+}
+
+// utxoTransfer builds the moves of the rune in display amounts
+func (a *allocation) utxoTransfer(b *runeBalance) *devents.UTXOTransfer {
+	div := b.item.Divisibility
+	ut := &devents.UTXOTransfer{
+		Spends:  b.spends,
+		Creates: make([]*devents.UTXOBalance, 0, len(b.allocated)),
+		Minted:  displayAmount(b.minted, div),
+		Burned:  displayAmount(b.burned, div),
+	}
+
+	outputs := make([]int, 0, len(b.allocated))
+	for out := range b.allocated {
+		outputs = append(outputs, out)
+	}
+	sort.Ints(outputs)
+
+	for _, out := range outputs {
+		sn := Outpoint(a.tx.Hash, uint32(out))
+		ut.Creates = append(ut.Creates, &devents.UTXOBalance{
+			RootHash: sn + ":" + b.item.ID,
+			SN:       sn,
+			Address:  voutAddress(&a.tx.Vout[out]),
+			Amount:   displayAmount(b.allocated[out], div),
+		})
+	}
+
+	// net balance changes of the addresses, in order of appearance
+	changes := make(map[string]*devents.Receive)
+	ut.Changes = make([]*devents.Receive, 0, len(ut.Spends)+len(ut.Creates))
+	move := func(address string, amount decimal.Decimal) {
+		if c, ok := changes[address]; ok {
+			c.Amount = c.Amount.Add(amount)
+			return
+		}
+		changes[address] = &devents.Receive{Address: address, Amount: amount}
+		ut.Changes = append(ut.Changes, changes[address])
+	}
+	for _, item := range ut.Spends {
+		move(item.Address, item.Amount.Neg())
+	}
+	for _, item := range ut.Creates {
+		move(item.Address, item.Amount)
+	}
+
+	items := ut.Changes[:0]
+	for _, item := range ut.Changes {
+		if !item.Amount.IsZero() {
+			items = append(items, item)
+		}
+	}
+	ut.Changes = items
+	return ut
+}
+
+func displayAmount(amount *big.Int, divisibility int8) decimal.Decimal {
+	return decimal.NewFromBigInt(amount, -int32(divisibility))
+}
+
+func rawAmount(amount decimal.Decimal, divisibility int8) *big.Int {
+	return amount.Shift(int32(divisibility)).BigInt()
+}
+
+func isOpReturn(vout *btcjson.Vout) bool {
+	return strings.HasPrefix(vout.ScriptPubKey.Hex, "6a")
+}
+
+func voutAddress(vout *btcjson.Vout) string {
+	if vout.ScriptPubKey.Address != "" {
+		return vout.ScriptPubKey.Address
+	}
+
+	if len(vout.ScriptPubKey.Addresses) > 0 {
+		return vout.ScriptPubKey.Addresses[0]
+	}
+	return ""
+}
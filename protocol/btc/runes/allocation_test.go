@@ -0,0 +1,190 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package runes
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/protocol/types"
+	"math/big"
+	"testing"
+)
+
+var (
+	addressOut  = btcjson.Vout{ScriptPubKey: btcjson.ScriptPubKeyResult{Address: "bc1qaddress"}}
+	opReturnOut = btcjson.Vout{ScriptPubKey: btcjson.ScriptPubKeyResult{Hex: "6a5d00"}}
+	scriptOut   = btcjson.Vout{ScriptPubKey: btcjson.ScriptPubKeyResult{Hex: "51"}}
+)
+
+// testBalance builds the balance of the unallocated & the allocated amounts
+func testBalance(a *allocation, unallocated int64, allocated map[int]int64) *runeBalance {
+	b := a.balance(&dcache.RuneItem{ID: "1:0"})
+	b.unallocated.SetInt64(unallocated)
+	for out, amount := range allocated {
+		b.allocated[out] = big.NewInt(amount)
+	}
+	return b
+}
+
+func allocatedAmounts(b *runeBalance) map[int]int64 {
+	amounts := make(map[int]int64, len(b.allocated))
+	for out, amount := range b.allocated {
+		amounts[out] = amount.Int64()
+	}
+	return amounts
+}
+
+func TestApplyEdict(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		output    int
+		allocated map[int]int64
+		left      int64
+	}{
+		{"output amount", 30, 0, map[int]int64{0: 30}, 71},
+		{"output zero amount", 0, 2, map[int]int64{2: 101}, 0},
+		{"output amount exceeded", 150, 0, map[int]int64{0: 101}, 0},
+		{"split evenly", 0, 3, map[int]int64{0: 51, 2: 50}, 0},
+		{"split amount", 40, 3, map[int]int64{0: 40, 2: 40}, 21},
+		{"split amount exceeded", 70, 3, map[int]int64{0: 70, 2: 31}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the OP_RETURN output is not a destination of the split
+			a := newAllocation(&xycommon.RpcTransaction{Vout: []btcjson.Vout{addressOut, opReturnOut, addressOut}})
+			b := testBalance(a, 101, nil)
+
+			a.applyEdict(b, big.NewInt(tt.amount), tt.output)
+			assert.Equal(t, tt.allocated, allocatedAmounts(b))
+			assert.Equal(t, tt.left, b.unallocated.Int64())
+		})
+	}
+}
+
+func TestSettle(t *testing.T) {
+	pointer := func(output uint32) *uint32 {
+		return &output
+	}
+
+	tests := []struct {
+		name      string
+		vout      []btcjson.Vout
+		stone     *Runestone
+		allocated map[int]int64 // allocated by the edicts
+		want      map[int]int64
+		burned    int64
+	}{
+		{"first destination", []btcjson.Vout{opReturnOut, addressOut}, nil, nil, map[int]int64{1: 100}, 0},
+		{"pointer", []btcjson.Vout{addressOut, opReturnOut, addressOut}, &Runestone{Pointer: pointer(2)}, nil, map[int]int64{2: 100}, 0},
+		{"pointer to op_return", []btcjson.Vout{addressOut, opReturnOut}, &Runestone{Pointer: pointer(1)}, nil, map[int]int64{}, 100},
+		{"cenotaph", []btcjson.Vout{addressOut}, &Runestone{Cenotaph: true}, nil, map[int]int64{}, 100},
+		{"no destination", []btcjson.Vout{opReturnOut}, nil, nil, map[int]int64{}, 100},
+		{"output without address", []btcjson.Vout{scriptOut, addressOut}, nil, nil, map[int]int64{}, 100},
+		{"edicts", []btcjson.Vout{addressOut, opReturnOut, addressOut}, &Runestone{}, map[int]int64{1: 30, 2: 20}, map[int]int64{0: 50, 2: 20}, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAllocation(&xycommon.RpcTransaction{Vout: tt.vout})
+			unallocated := int64(100)
+			for _, amount := range tt.allocated {
+				unallocated -= amount
+			}
+			b := testBalance(a, unallocated, tt.allocated)
+
+			a.settle(tt.stone)
+			assert.Equal(t, tt.want, allocatedAmounts(b))
+			assert.Equal(t, tt.burned, b.burned.Int64())
+			assert.Equal(t, int64(0), b.unallocated.Int64())
+		})
+	}
+}
+
+func TestMintable(t *testing.T) {
+	height := func(h uint64) *uint64 {
+		return &h
+	}
+
+	tests := []struct {
+		name   string
+		edit   func(item *dcache.RuneItem)
+		minted string // display amount, premine included
+		height int64
+		want   int64 // 0 not mintable
+	}{
+		{"open", nil, "0", 100, 10},
+		{"cap zero", func(item *dcache.RuneItem) { item.Cap = decimal.Zero }, "0", 100, 0},
+		{"before height start", func(item *dcache.RuneItem) { item.HeightStart = height(110) }, "0", 109, 0},
+		{"at height start", func(item *dcache.RuneItem) { item.HeightStart = height(110) }, "0", 110, 10},
+		{"before offset start", func(item *dcache.RuneItem) { item.OffsetStart = height(5) }, "0", 104, 0},
+		{"later offset start", func(item *dcache.RuneItem) { item.HeightStart, item.OffsetStart = height(103), height(5) }, "0", 104, 0},
+		{"at offset start", func(item *dcache.RuneItem) { item.OffsetStart = height(5) }, "0", 105, 10},
+		{"before height end", func(item *dcache.RuneItem) { item.HeightEnd = height(120) }, "0", 119, 10},
+		{"at height end", func(item *dcache.RuneItem) { item.HeightEnd = height(120) }, "0", 120, 0},
+		{"at offset end", func(item *dcache.RuneItem) { item.OffsetEnd = height(10) }, "0", 110, 0},
+		{"last mint", nil, "40", 100, 10},
+		{"cap reached", nil, "50", 100, 0},
+		{"premine excluded", func(item *dcache.RuneItem) { item.Premine = decimal.NewFromInt(30) }, "70", 100, 10},
+		{"premine excluded cap reached", func(item *dcache.RuneItem) { item.Premine = decimal.NewFromInt(30) }, "80", 100, 0},
+		{"divisibility", func(item *dcache.RuneItem) { item.Divisibility = 1 }, "4", 100, 10},
+		{"divisibility cap reached", func(item *dcache.RuneItem) { item.Divisibility = 1 }, "5", 100, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 5 mints of 10 raw amounts, etched at the block 100
+			id := RuneID{Block: 100, Tx: 1}
+			item := &dcache.RuneItem{
+				ID:     id.String(),
+				Tick:   "test",
+				Amount: decimal.NewFromInt(10),
+				Cap:    decimal.NewFromInt(5),
+				Block:  100,
+			}
+			if tt.edit != nil {
+				tt.edit(item)
+			}
+
+			cache := &dcache.Manager{Rune: dcache.NewRune(), InscriptionStats: dcache.NewInscriptionStats()}
+			cache.Rune.Create(item)
+			cache.InscriptionStats.Create(types.RunesProtocol, item.Tick, &dcache.InsStats{Minted: decimal.RequireFromString(tt.minted)})
+
+			p := NewProtocol(cache)
+			got, amount := p.mintable(&xycommon.RpcBlock{Number: big.NewInt(tt.height)}, id)
+			if tt.want == 0 {
+				assert.Nil(t, got)
+				assert.Nil(t, amount)
+				return
+			}
+			if assert.NotNil(t, got) && assert.NotNil(t, amount) {
+				assert.Equal(t, tt.want, amount.Int64())
+			}
+		})
+	}
+
+	p := NewProtocol(&dcache.Manager{Rune: dcache.NewRune(), InscriptionStats: dcache.NewInscriptionStats()})
+	got, _ := p.mintable(&xycommon.RpcBlock{Number: big.NewInt(100)}, RuneID{Block: 100, Tx: 1})
+	assert.Nil(t, got, "not etched")
+}
@@ -0,0 +1,328 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package runes
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"math/big"
+)
+
+// Protocol of the bitcoin runes, the rune balances are bound to the utxos & follow the runestone edicts,
+// the ticks are the lower case rune names. Unlike ord, the etching commitment & the minimum name length
+// of the height are not checked, runes allocated to outputs without address are burned
+type Protocol struct {
+	cache *dcache.Manager
+}
+
+func NewProtocol(cache *dcache.Manager) *Protocol {
+	return &Protocol{
+		cache: cache,
+	}
+}
+
+// ParseMetaData recognizes the txs carrying a runestone or spending the rune utxos
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	md := &devents.MetaData{
+		Chain:    chain,
+		Protocol: types.RunesProtocol,
+		Operate:  devents.OperateTransfer,
+	}
+
+	if stone, ok := Decipher(tx); ok {
+		if stone.Etching != nil {
+			md.Operate = devents.OperateDeploy
+		} else if stone.Mint != nil {
+			md.Operate = devents.OperateMint
+		}
+		return md, nil
+	}
+
+	if p.spendsRunes(tx) {
+		return md, nil
+	}
+	return nil, nil
+}
+
+// Parse returns one result for each rune moved by the tx
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	a := newAllocation(tx)
+	p.collectInputs(a)
+
+	stone, _ := Decipher(tx)
+	if stone != nil {
+		if stone.Mint != nil {
+			if item, amount := p.mintable(block, *stone.Mint); item != nil {
+				b := a.balance(item)
+				b.mint = true
+				b.unallocated.Add(b.unallocated, amount)
+				b.minted.Add(b.minted, amount)
+			}
+		}
+
+		etched := p.etch(a, block, tx, stone)
+		for _, edict := range stone.Edicts {
+			b := etched
+			if !edict.ID.IsZero() {
+				b = a.balances[edict.ID.String()]
+			}
+
+			if b == nil {
+				continue
+			}
+			a.applyEdict(b, edict.Amount, int(edict.Output))
+		}
+	}
+	a.settle(stone)
+
+	results := make([]*devents.TxResult, 0, len(a.ids))
+	for _, id := range a.ids {
+		b := a.balances[id]
+		rmd := md.Copy()
+		rmd.Tick = b.item.Tick
+		rmd.Operate = devents.OperateTransfer
+
+		result := &devents.TxResult{
+			MD:           rmd,
+			Block:        block,
+			Tx:           tx,
+			UTXOTransfer: a.utxoTransfer(b),
+		}
+
+		if b.etching != nil {
+			rmd.Operate = devents.OperateDeploy
+			result.Etching = b.etching
+			result.Deploy = deploy(b.item, b.etching)
+		} else if b.mint {
+			rmd.Operate = devents.OperateMint
+		}
+		results = append(results, result)
+	}
+
+	if len(results) < 1 {
+		err := xyerrors.NewInsError(-11, fmt.Sprintf("no rune moved by the tx[%s]", tx.Hash))
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+	return results, nil
+}
+
+// collectInputs adds the runes of the spent utxos to the unallocated balances
+func (p *Protocol) collectInputs(a *allocation) {
+	for _, vin := range a.tx.Vin {
+		if vin.IsCoinBase() {
+			continue
+		}
+
+		for _, utxo := range p.cache.UTXO.GetBySN(Outpoint(vin.Txid, vin.Vout)) {
+			if utxo.Protocol != types.RunesProtocol {
+				continue
+			}
+
+			ok, item := p.cache.Rune.GetByTick(utxo.Tick)
+			if !ok {
+				continue
+			}
+
+			b := a.balance(item)
+			b.spends = append(b.spends, &devents.UTXOBalance{
+				RootHash: utxo.RootHash,
+				SN:       utxo.SN,
+				Address:  utxo.Owner,
+				Amount:   utxo.Amount,
+			})
+			b.unallocated.Add(b.unallocated, rawAmount(utxo.Amount, item.Divisibility))
+		}
+	}
+}
+
+// MatchTx matches the txs carrying a runestone output or spending the rune utxos
+func (p *Protocol) MatchTx(tx *xycommon.RpcTransaction) bool {
+	return HasRunestone(tx) || p.spendsRunes(tx)
+}
+
+func (p *Protocol) spendsRunes(tx *xycommon.RpcTransaction) bool {
+	for _, vin := range tx.Vin {
+		if vin.IsCoinBase() {
+			continue
+		}
+
+		for _, utxo := range p.cache.UTXO.GetBySN(Outpoint(vin.Txid, vin.Vout)) {
+			if utxo.Protocol == types.RunesProtocol {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mintable returns the rune & the amount if the mint is open at the block height & under the cap
+func (p *Protocol) mintable(block *xycommon.RpcBlock, id RuneID) (*dcache.RuneItem, *big.Int) {
+	ok, item := p.cache.Rune.Get(id.String())
+	if !ok || item.Cap.Sign() <= 0 || item.Amount.Sign() <= 0 {
+		return nil, nil
+	}
+
+	height := block.Number.Uint64()
+	start := uint64(0)
+	if item.HeightStart != nil {
+		start = *item.HeightStart
+	}
+	if item.OffsetStart != nil && item.Block+*item.OffsetStart > start {
+		start = item.Block + *item.OffsetStart
+	}
+
+	if height < start {
+		return nil, nil
+	}
+
+	if item.HeightEnd != nil && height >= *item.HeightEnd {
+		return nil, nil
+	}
+
+	if item.OffsetEnd != nil && height >= item.Block+*item.OffsetEnd {
+		return nil, nil
+	}
+
+	ok, stats := p.cache.InscriptionStats.Get(types.RunesProtocol, item.Tick)
+	if !ok {
+		return nil, nil
+	}
+
+	// minted amount includes the premine
+	minted := stats.Minted.Shift(int32(item.Divisibility)).Sub(item.Premine)
+	if minted.Add(item.Amount).GreaterThan(item.Cap.Mul(item.Amount)) {
+		return nil, nil
+	}
+	return item, item.Amount.BigInt()
+}
+
+// etch registers the rune etched by the tx, the premine is added to the unallocated balance.
+// Explicit reserved names & the names etched already are rejected
+func (p *Protocol) etch(a *allocation, block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, stone *Runestone) *runeBalance {
+	e := stone.Etching
+	if e == nil {
+		return nil
+	}
+
+	id := RuneID{Block: block.Number.Uint64(), Tx: uint32(tx.TxIndex.Uint64())}
+	name := e.Rune
+	if name == nil {
+		name = ReservedName(id)
+	} else if IsReserved(name) {
+		return nil
+	}
+
+	runeName := Name(name)
+	tick := Tick(runeName)
+	if ok, _ := p.cache.Rune.GetByTick(tick); ok {
+		return nil
+	}
+
+	if ok, _ := p.cache.Inscription.Get(types.RunesProtocol, tick); ok {
+		return nil
+	}
+
+	item := &dcache.RuneItem{
+		ID:           id.String(),
+		Tick:         tick,
+		Divisibility: int8(e.Divisibility),
+		Premine:      rawDecimal(e.Premine),
+		Block:        id.Block,
+	}
+
+	if e.Terms != nil {
+		item.Amount = rawDecimal(e.Terms.Amount)
+		item.Cap = rawDecimal(e.Terms.Cap)
+		item.HeightStart = e.Terms.HeightStart
+		item.HeightEnd = e.Terms.HeightEnd
+		item.OffsetStart = e.Terms.OffsetStart
+		item.OffsetEnd = e.Terms.OffsetEnd
+	}
+
+	symbol := ""
+	if e.Symbol != 0 {
+		symbol = string(e.Symbol)
+	}
+
+	b := a.balance(item)
+	b.etching = &devents.Etching{
+		RuneID:       item.ID,
+		SpacedName:   SpacedName(runeName, e.Spacers),
+		Symbol:       symbol,
+		Divisibility: item.Divisibility,
+		Premine:      item.Premine,
+		Amount:       item.Amount,
+		Cap:          item.Cap,
+		HeightStart:  item.HeightStart,
+		HeightEnd:    item.HeightEnd,
+		OffsetStart:  item.OffsetStart,
+		OffsetEnd:    item.OffsetEnd,
+		Turbo:        e.Turbo,
+		Cenotaph:     stone.Cenotaph,
+	}
+
+	premine := item.Premine.BigInt()
+	b.unallocated.Add(b.unallocated, premine)
+	b.minted.Add(b.minted, premine)
+	return b
+}
+
+// deploy the tick of the etched rune, the supply is the premine & all the capped mints
+func deploy(item *dcache.RuneItem, e *devents.Etching) *devents.Deploy {
+	supply := item.Premine.Add(item.Cap.Mul(item.Amount))
+	return &devents.Deploy{
+		Name:           e.SpacedName,
+		MaxSupply:      supply.Shift(-int32(item.Divisibility)),
+		MintLimit:      item.Amount.Shift(-int32(item.Divisibility)),
+		Decimal:        item.Divisibility,
+		TransferType:   model.TransferTypeHash,
+		StrictDecimals: true,
+	}
+}
+
+func rawDecimal(value *big.Int) decimal.Decimal {
+	if value == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(value, 0)
+}
+
+func init() {
+	// runes index every btc tx alongside its inscription protocol
+	registry.MustRegister(registry.Entry{
+		ChainGroup: model.BtcChainGroup,
+		Protocol:   types.RunesProtocol,
+		Shared:     true,
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return NewProtocol(cache), nil
+		},
+	})
+}
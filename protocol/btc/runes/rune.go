@@ -0,0 +1,155 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package runes
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const nameAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// reservedName the first reserved rune name, etchings without a name get the reserved one of their rune id
+var reservedName, _ = new(big.Int).SetString("6402364363415443603228541259936211926", 10)
+
+// RuneID block height & tx index of the etching
+type RuneID struct {
+	Block uint64
+	Tx    uint32
+}
+
+func newRuneID(block, tx *big.Int) (RuneID, bool) {
+	if !block.IsUint64() {
+		return RuneID{}, false
+	}
+
+	idx, ok := toUint32(tx)
+	if !ok || (block.Sign() == 0 && idx > 0) {
+		return RuneID{}, false
+	}
+	return RuneID{Block: block.Uint64(), Tx: idx}, true
+}
+
+// next applies the edict delta, the tx is relative to the last id in the same block
+func (id RuneID) next(blockDelta, txDelta *big.Int) (RuneID, bool) {
+	block := new(big.Int).Add(new(big.Int).SetUint64(id.Block), blockDelta)
+	tx := new(big.Int).Set(txDelta)
+	if blockDelta.Sign() == 0 {
+		tx.Add(tx, big.NewInt(int64(id.Tx)))
+	}
+	return newRuneID(block, tx)
+}
+
+func (id RuneID) IsZero() bool {
+	return id.Block == 0 && id.Tx == 0
+}
+
+func (id RuneID) String() string {
+	return fmt.Sprintf("%d:%d", id.Block, id.Tx)
+}
+
+// ReservedName the name of the rune etched without one
+func ReservedName(id RuneID) *big.Int {
+	n := new(big.Int).Lsh(new(big.Int).SetUint64(id.Block), 32)
+	n.Or(n, big.NewInt(int64(id.Tx)))
+	return n.Add(n, reservedName)
+}
+
+// IsReserved checks the name within the reserved ones, which can not be etched explicitly
+func IsReserved(name *big.Int) bool {
+	return name.Cmp(reservedName) >= 0
+}
+
+// Name modified base-26 of the rune name: A = 0, Z = 25, AA = 26...
+func Name(n *big.Int) string {
+	if n.Cmp(maxU128) == 0 {
+		return "BCGDENLQRQWDSLRUGSNLBTMFIJAV"
+	}
+
+	base := big.NewInt(26)
+	v := new(big.Int).Add(n, big.NewInt(1))
+	mod := new(big.Int)
+	symbols := make([]byte, 0, 28)
+	for v.Sign() > 0 {
+		v.Sub(v, big.NewInt(1))
+		v.DivMod(v, base, mod)
+		symbols = append(symbols, nameAlphabet[mod.Int64()])
+	}
+
+	for i, j := 0, len(symbols)-1; i < j; i, j = i+1, j-1 {
+		symbols[i], symbols[j] = symbols[j], symbols[i]
+	}
+	return string(symbols)
+}
+
+// ParseName parses the rune name, spacers are ignored
+func ParseName(name string) (*big.Int, error) {
+	n := new(big.Int)
+	base := big.NewInt(26)
+	letters := 0
+	for _, c := range strings.ToUpper(name) {
+		if c == '•' || c == '.' {
+			continue
+		}
+
+		if c < 'A' || c > 'Z' {
+			return nil, fmt.Errorf("invalid rune name character[%c]", c)
+		}
+
+		if letters > 0 {
+			n.Add(n, big.NewInt(1))
+		}
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(c-'A')))
+		letters++
+	}
+
+	if letters < 1 || n.Cmp(maxU128) > 0 {
+		return nil, fmt.Errorf("invalid rune name[%s]", name)
+	}
+	return n, nil
+}
+
+// SpacedName inserts the spacers after the letters of the set bits
+func SpacedName(name string, spacers uint32) string {
+	var b strings.Builder
+	for i, c := range name {
+		b.WriteRune(c)
+		if i < len(name)-1 && spacers&(1<<uint(i)) != 0 {
+			b.WriteRune('•')
+		}
+	}
+	return b.String()
+}
+
+// Tick of the rune is the lower case name without spacers
+func Tick(name string) string {
+	return strings.ToLower(name)
+}
+
+// Outpoint sn of the utxo
+func Outpoint(txid string, vout uint32) string {
+	return strings.ToLower(txid) + ":" + strconv.FormatUint(uint64(vout), 10)
+}
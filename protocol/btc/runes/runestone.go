@@ -0,0 +1,541 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package runes
+
+import (
+	"encoding/hex"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// runestone fields tags, the unknown even tags turn the runestone into a cenotaph
+const (
+	tagBody         = 0
+	tagDivisibility = 1
+	tagFlags        = 2
+	tagSpacers      = 3
+	tagRune         = 4
+	tagSymbol       = 5
+	tagPremine      = 6
+	tagCap          = 8
+	tagAmount       = 10
+	tagHeightStart  = 12
+	tagHeightEnd    = 14
+	tagOffsetStart  = 16
+	tagOffsetEnd    = 18
+	tagMint         = 20
+	tagPointer      = 22
+)
+
+// runestone flags bits
+const (
+	flagEtching = 0
+	flagTerms   = 1
+	flagTurbo   = 2
+)
+
+const (
+	maxDivisibility = 38
+	maxSpacers      = 0x07ffffff
+
+	// runestoneHex hex of OP_RETURN OP_13, the runestone output script header
+	runestoneHex = "6a5d"
+)
+
+var maxU128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+type Edict struct {
+	ID     RuneID
+	Amount *big.Int
+	Output uint32
+}
+
+// Terms of the open mint, nil amounts & heights are unset
+type Terms struct {
+	Amount      *big.Int
+	Cap         *big.Int
+	HeightStart *uint64
+	HeightEnd   *uint64
+	OffsetStart *uint64
+	OffsetEnd   *uint64
+}
+
+type Etching struct {
+	Divisibility uint8
+	Premine      *big.Int
+	Rune         *big.Int // nil for the reserved name
+	Spacers      uint32
+	Symbol       rune // 0 for unset
+	Terms        *Terms
+	Turbo        bool
+}
+
+// Runestone deciphered from the first OP_RETURN OP_13 output:
+// [tag value]... [0 edicts...], the edicts are delta encoded groups of id block, id tx, amount & output.
+// A malformed runestone is a cenotaph, only the etched rune & the mint are kept & all input runes are burned
+type Runestone struct {
+	Edicts   []*Edict
+	Etching  *Etching
+	Mint     *RuneID
+	Pointer  *uint32
+	Cenotaph bool
+}
+
+// HasRunestone fast checking the tx outputs containing the runestone header
+func HasRunestone(tx *xycommon.RpcTransaction) bool {
+	for _, vout := range tx.Vout {
+		if strings.HasPrefix(vout.ScriptPubKey.Hex, runestoneHex) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decipher parses the runestone of the tx, false returned if the tx carries none
+func Decipher(tx *xycommon.RpcTransaction) (*Runestone, bool) {
+	payload, valid, ok := runestonePayload(tx)
+	if !ok {
+		return nil, false
+	}
+
+	if !valid {
+		return &Runestone{Cenotaph: true}, true
+	}
+
+	integers, valid := decodeIntegers(payload)
+	stone := &Runestone{Cenotaph: !valid}
+
+	fields := make(tagFields)
+	for i := 0; i < len(integers); i += 2 {
+		tag := integers[i]
+		if tag.Sign() == 0 {
+			stone.Edicts, valid = decodeEdicts(integers[i+1:], len(tx.Vout))
+			if !valid {
+				stone.Cenotaph = true
+			}
+			break
+		}
+
+		// tag without value
+		if i+1 >= len(integers) {
+			stone.Cenotaph = true
+			break
+		}
+		fields.add(tag, integers[i+1])
+	}
+
+	flags := new(big.Int)
+	fields.take(tagFlags, 1, func(values []*big.Int) bool {
+		flags.Set(values[0])
+		return true
+	})
+
+	if takeFlag(flags, flagEtching) {
+		stone.Etching = decodeEtching(fields, flags)
+		if stone.Etching.supply() == nil {
+			stone.Cenotaph = true
+		}
+	}
+
+	fields.take(tagMint, 2, func(values []*big.Int) bool {
+		id, ok := newRuneID(values[0], values[1])
+		if ok {
+			stone.Mint = &id
+		}
+		return ok
+	})
+
+	fields.take(tagPointer, 1, func(values []*big.Int) bool {
+		pointer, ok := toUint32(values[0])
+		if ok && int(pointer) < len(tx.Vout) {
+			stone.Pointer = &pointer
+			return true
+		}
+		return false
+	})
+
+	if flags.Sign() != 0 || fields.hasEvenTag() {
+		stone.Cenotaph = true
+	}
+
+	// only the etched rune & the mint of the cenotaph take effect
+	if stone.Cenotaph {
+		stone.Edicts = nil
+		stone.Pointer = nil
+		if stone.Etching != nil {
+			stone.Etching = &Etching{Rune: stone.Etching.Rune}
+		}
+	}
+	return stone, true
+}
+
+// runestonePayload concatenates the data pushes of the runestone output,
+// valid is false if any non push opcode found
+func runestonePayload(tx *xycommon.RpcTransaction) (payload []byte, valid bool, ok bool) {
+	for _, vout := range tx.Vout {
+		if !strings.HasPrefix(vout.ScriptPubKey.Hex, runestoneHex) {
+			continue
+		}
+
+		script, err := hex.DecodeString(vout.ScriptPubKey.Hex)
+		if err != nil {
+			continue
+		}
+
+		payload = make([]byte, 0, len(script))
+		tokenizer := txscript.MakeScriptTokenizer(0, script[2:])
+		for tokenizer.Next() {
+			if tokenizer.Opcode() > txscript.OP_PUSHDATA4 {
+				return nil, false, true
+			}
+			payload = append(payload, tokenizer.Data()...)
+		}
+		return payload, tokenizer.Err() == nil, true
+	}
+	return nil, false, false
+}
+
+// decodeIntegers decodes the payload as LEB128 u128 varints, valid is false if any varint malformed
+func decodeIntegers(payload []byte) ([]*big.Int, bool) {
+	integers := make([]*big.Int, 0, len(payload))
+	for len(payload) > 0 {
+		value, size := decodeVarint(payload)
+		if size <= 0 {
+			return integers, false
+		}
+		integers = append(integers, value)
+		payload = payload[size:]
+	}
+	return integers, true
+}
+
+// decodeVarint returns the u128 value & the bytes read, size <= 0 for the overlong, overflow or unterminated varint
+func decodeVarint(buf []byte) (*big.Int, int) {
+	value := new(big.Int)
+	for i, b := range buf {
+		if i > 18 {
+			return nil, -1
+		}
+
+		bits := uint(b & 0x7f)
+		if i == 18 && bits&0x7c != 0 {
+			return nil, -1
+		}
+		value.Or(value, new(big.Int).Lsh(new(big.Int).SetUint64(uint64(bits)), uint(7*i)))
+
+		if b&0x80 == 0 {
+			return value, i + 1
+		}
+	}
+	return nil, 0
+}
+
+func decodeEdicts(integers []*big.Int, outputs int) ([]*Edict, bool) {
+	edicts := make([]*Edict, 0, len(integers)/4)
+	id := RuneID{}
+	for i := 0; i < len(integers); i += 4 {
+		// trailing integers
+		if i+4 > len(integers) {
+			return edicts, false
+		}
+
+		next, ok := id.next(integers[i], integers[i+1])
+		if !ok {
+			return edicts, false
+		}
+
+		output, ok := toUint32(integers[i+3])
+		if !ok || int(output) > outputs {
+			return edicts, false
+		}
+
+		id = next
+		edicts = append(edicts, &Edict{
+			ID:     id,
+			Amount: integers[i+2],
+			Output: output,
+		})
+	}
+	return edicts, true
+}
+
+func decodeEtching(fields tagFields, flags *big.Int) *Etching {
+	etching := &Etching{}
+	fields.take(tagDivisibility, 1, func(values []*big.Int) bool {
+		value, ok := toUint32(values[0])
+		if ok && value <= maxDivisibility {
+			etching.Divisibility = uint8(value)
+			return true
+		}
+		return false
+	})
+	fields.take(tagPremine, 1, func(values []*big.Int) bool {
+		etching.Premine = values[0]
+		return true
+	})
+	fields.take(tagRune, 1, func(values []*big.Int) bool {
+		etching.Rune = values[0]
+		return true
+	})
+	fields.take(tagSpacers, 1, func(values []*big.Int) bool {
+		value, ok := toUint32(values[0])
+		if ok && value <= maxSpacers {
+			etching.Spacers = value
+			return true
+		}
+		return false
+	})
+	fields.take(tagSymbol, 1, func(values []*big.Int) bool {
+		value, ok := toUint32(values[0])
+		if ok && utf8.ValidRune(rune(value)) {
+			etching.Symbol = rune(value)
+			return true
+		}
+		return false
+	})
+
+	if takeFlag(flags, flagTerms) {
+		terms := &Terms{}
+		fields.take(tagCap, 1, func(values []*big.Int) bool {
+			terms.Cap = values[0]
+			return true
+		})
+		fields.take(tagAmount, 1, func(values []*big.Int) bool {
+			terms.Amount = values[0]
+			return true
+		})
+		terms.HeightStart = fields.takeUint64(tagHeightStart)
+		terms.HeightEnd = fields.takeUint64(tagHeightEnd)
+		terms.OffsetStart = fields.takeUint64(tagOffsetStart)
+		terms.OffsetEnd = fields.takeUint64(tagOffsetEnd)
+		etching.Terms = terms
+	}
+	etching.Turbo = takeFlag(flags, flagTurbo)
+	return etching
+}
+
+// supply premine + cap * amount, nil if it overflows u128
+func (e *Etching) supply() *big.Int {
+	supply := new(big.Int)
+	if e.Premine != nil {
+		supply.Set(e.Premine)
+	}
+
+	if e.Terms != nil && e.Terms.Cap != nil && e.Terms.Amount != nil {
+		supply.Add(supply, new(big.Int).Mul(e.Terms.Cap, e.Terms.Amount))
+	}
+
+	if supply.Cmp(maxU128) > 0 {
+		return nil
+	}
+	return supply
+}
+
+// takeFlag clears the flag bit, true if it was set
+func takeFlag(flags *big.Int, flag int) bool {
+	if flags.Bit(flag) == 0 {
+		return false
+	}
+	flags.SetBit(flags, flag, 0)
+	return true
+}
+
+// tagFields values of the tags in order, a tag may appear multiple times
+type tagFields map[string][]*big.Int
+
+func (f tagFields) add(tag, value *big.Int) {
+	key := tag.String()
+	f[key] = append(f[key], value)
+}
+
+// take consumes the first n values of the tag if accepted, the rejected values stay in the fields
+func (f tagFields) take(tag uint64, n int, accept func(values []*big.Int) bool) {
+	key := strconv.FormatUint(tag, 10)
+	values := f[key]
+	if len(values) < n || !accept(values[:n]) {
+		return
+	}
+
+	if len(values) == n {
+		delete(f, key)
+		return
+	}
+	f[key] = values[n:]
+}
+
+func (f tagFields) takeUint64(tag uint64) *uint64 {
+	var ret *uint64
+	f.take(tag, 1, func(values []*big.Int) bool {
+		if !values[0].IsUint64() {
+			return false
+		}
+		value := values[0].Uint64()
+		ret = &value
+		return true
+	})
+	return ret
+}
+
+func (f tagFields) hasEvenTag() bool {
+	for key := range f {
+		tag, ok := new(big.Int).SetString(key, 10)
+		if ok && tag.Bit(0) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func toUint32(value *big.Int) (uint32, bool) {
+	if !value.IsUint64() || value.Uint64() > 0xffffffff {
+		return 0, false
+	}
+	return uint32(value.Uint64()), true
+}
+
+// Encipher builds the runestone output script, the edicts are sorted by rune id & delta encoded
+func (r *Runestone) Encipher() []byte {
+	payload := make([]byte, 0, 64)
+	field := func(tag uint64, values ...*big.Int) {
+		for _, value := range values {
+			payload = append(payload, encodeVarint(new(big.Int).SetUint64(tag))...)
+			payload = append(payload, encodeVarint(value)...)
+		}
+	}
+	optional := func(tag uint64, value *uint64) {
+		if value != nil {
+			field(tag, new(big.Int).SetUint64(*value))
+		}
+	}
+
+	if e := r.Etching; e != nil {
+		flags := new(big.Int).SetBit(new(big.Int), flagEtching, 1)
+		if e.Terms != nil {
+			flags.SetBit(flags, flagTerms, 1)
+		}
+		if e.Turbo {
+			flags.SetBit(flags, flagTurbo, 1)
+		}
+		field(tagFlags, flags)
+
+		if e.Rune != nil {
+			field(tagRune, e.Rune)
+		}
+		if e.Divisibility > 0 {
+			field(tagDivisibility, big.NewInt(int64(e.Divisibility)))
+		}
+		if e.Spacers > 0 {
+			field(tagSpacers, big.NewInt(int64(e.Spacers)))
+		}
+		if e.Symbol != 0 {
+			field(tagSymbol, big.NewInt(int64(e.Symbol)))
+		}
+		if e.Premine != nil {
+			field(tagPremine, e.Premine)
+		}
+
+		if t := e.Terms; t != nil {
+			if t.Amount != nil {
+				field(tagAmount, t.Amount)
+			}
+			if t.Cap != nil {
+				field(tagCap, t.Cap)
+			}
+			optional(tagHeightStart, t.HeightStart)
+			optional(tagHeightEnd, t.HeightEnd)
+			optional(tagOffsetStart, t.OffsetStart)
+			optional(tagOffsetEnd, t.OffsetEnd)
+		}
+	}
+
+	if r.Mint != nil {
+		field(tagMint, new(big.Int).SetUint64(r.Mint.Block), big.NewInt(int64(r.Mint.Tx)))
+	}
+
+	if r.Pointer != nil {
+		field(tagPointer, big.NewInt(int64(*r.Pointer)))
+	}
+
+	if len(r.Edicts) > 0 {
+		edicts := make([]*Edict, len(r.Edicts))
+		copy(edicts, r.Edicts)
+		sort.SliceStable(edicts, func(i, j int) bool {
+			if edicts[i].ID.Block != edicts[j].ID.Block {
+				return edicts[i].ID.Block < edicts[j].ID.Block
+			}
+			return edicts[i].ID.Tx < edicts[j].ID.Tx
+		})
+
+		payload = append(payload, encodeVarint(big.NewInt(tagBody))...)
+		previous := RuneID{}
+		for _, edict := range edicts {
+			block, tx := edict.ID.Block-previous.Block, uint64(edict.ID.Tx)
+			if block == 0 {
+				tx -= uint64(previous.Tx)
+			}
+			payload = append(payload, encodeVarint(new(big.Int).SetUint64(block))...)
+			payload = append(payload, encodeVarint(new(big.Int).SetUint64(tx))...)
+			payload = append(payload, encodeVarint(edict.Amount)...)
+			payload = append(payload, encodeVarint(big.NewInt(int64(edict.Output)))...)
+			previous = edict.ID
+		}
+	}
+
+	// data pushes of the max script element size
+	script := []byte{txscript.OP_RETURN, txscript.OP_13}
+	for len(payload) > 0 {
+		size := len(payload)
+		if size > txscript.MaxScriptElementSize {
+			size = txscript.MaxScriptElementSize
+		}
+
+		switch {
+		case size <= txscript.OP_DATA_75:
+			script = append(script, byte(size))
+		case size <= 0xff:
+			script = append(script, txscript.OP_PUSHDATA1, byte(size))
+		default:
+			script = append(script, txscript.OP_PUSHDATA2, byte(size), byte(size>>8))
+		}
+		script = append(script, payload[:size]...)
+		payload = payload[size:]
+	}
+	return script
+}
+
+// encodeVarint encodes the u128 value as LEB128 varint
+func encodeVarint(value *big.Int) []byte {
+	n := new(big.Int).Set(value)
+	buf := make([]byte, 0, 19)
+	mask := big.NewInt(0x7f)
+	for n.Cmp(mask) > 0 {
+		buf = append(buf, byte(new(big.Int).And(n, mask).Uint64())|0x80)
+		n.Rsh(n, 7)
+	}
+	return append(buf, byte(n.Uint64()))
+}
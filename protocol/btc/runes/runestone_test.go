@@ -0,0 +1,191 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package runes
+
+import (
+	"encoding/hex"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"math/big"
+	"testing"
+)
+
+// runestoneTx builds the tx of the runestone script in the first output & the outputs to addresses
+func runestoneTx(script []byte, outputs int) *xycommon.RpcTransaction {
+	tx := &xycommon.RpcTransaction{
+		Vin:  []btcjson.Vin{{Txid: "00"}},
+		Vout: []btcjson.Vout{{ScriptPubKey: btcjson.ScriptPubKeyResult{Hex: hex.EncodeToString(script)}}},
+	}
+	for i := 0; i < outputs; i++ {
+		tx.Vout = append(tx.Vout, btcjson.Vout{ScriptPubKey: btcjson.ScriptPubKeyResult{Address: "bcrt1q"}})
+	}
+	return tx
+}
+
+// payloadScript builds the runestone script of the integers in one push
+func payloadScript(integers ...int64) []byte {
+	payload := make([]byte, 0, len(integers))
+	for _, v := range integers {
+		payload = append(payload, encodeVarint(big.NewInt(v))...)
+	}
+	return append([]byte{0x6a, 0x5d, byte(len(payload))}, payload...)
+}
+
+func TestName(t *testing.T) {
+	for n, name := range map[int64]string{0: "A", 1: "B", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, name, Name(big.NewInt(n)))
+
+		parsed, err := ParseName(name)
+		assert.NoError(t, err)
+		assert.Equal(t, n, parsed.Int64())
+	}
+
+	assert.Equal(t, "BCGDENLQRQWDSLRUGSNLBTMFIJAV", Name(maxU128))
+	parsed, err := ParseName("BCGDENLQRQWDSLRUGSNLBTMFIJAV")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, parsed.Cmp(maxU128))
+
+	_, err = ParseName("BCGDENLQRQWDSLRUGSNLBTMFIJAW")
+	assert.Error(t, err)
+	_, err = ParseName("ab1")
+	assert.Error(t, err)
+
+	parsed, err = ParseName("uncommon•goods")
+	assert.NoError(t, err)
+	assert.Equal(t, "UNCOMMONGOODS", Name(parsed))
+	assert.Equal(t, "UNCOMMON•GOODS", SpacedName("UNCOMMONGOODS", 1<<7))
+	assert.Equal(t, "A•B•C", SpacedName("ABC", 0b111))
+
+	// the reserved names start from 27 letters
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAAAAAAA", Name(ReservedName(RuneID{})))
+	assert.True(t, IsReserved(ReservedName(RuneID{Block: 840000, Tx: 1})))
+	assert.False(t, IsReserved(parsed))
+}
+
+func TestVarint(t *testing.T) {
+	for _, v := range []*big.Int{big.NewInt(0), big.NewInt(127), big.NewInt(128), big.NewInt(300), maxU128} {
+		value, size := decodeVarint(encodeVarint(v))
+		assert.Equal(t, len(encodeVarint(v)), size)
+		assert.Equal(t, 0, v.Cmp(value))
+	}
+
+	// unterminated, overlong & overflow
+	_, size := decodeVarint([]byte{0x80})
+	assert.Equal(t, 0, size)
+
+	overlong := append(make([]byte, 0, 20), encodeVarint(maxU128)[:18]...)
+	_, size = decodeVarint(append(overlong, 0x83, 0x00))
+	assert.True(t, size < 0)
+
+	_, size = decodeVarint(append(encodeVarint(maxU128)[:18], 0x04))
+	assert.True(t, size < 0)
+}
+
+func TestEncipher(t *testing.T) {
+	start, end := uint64(840000), uint64(850000)
+	pointer := uint32(2)
+	stone := &Runestone{
+		Etching: &Etching{
+			Divisibility: 2,
+			Premine:      big.NewInt(1000),
+			Rune:         big.NewInt(2055900680524219742),
+			Spacers:      1 << 7,
+			Symbol:       '⧉',
+			Terms: &Terms{
+				Amount:      big.NewInt(500),
+				Cap:         big.NewInt(2),
+				HeightStart: &start,
+				HeightEnd:   &end,
+			},
+			Turbo: true,
+		},
+		Mint:    &RuneID{Block: 1, Tx: 2},
+		Pointer: &pointer,
+		Edicts: []*Edict{
+			{ID: RuneID{Block: 840000, Tx: 3}, Amount: big.NewInt(7), Output: 3},
+			{ID: RuneID{Block: 2, Tx: 1}, Amount: big.NewInt(5), Output: 1},
+			{ID: RuneID{Block: 840000, Tx: 9}, Amount: big.NewInt(0), Output: 2},
+		},
+	}
+
+	parsed, ok := Decipher(runestoneTx(stone.Encipher(), 3))
+	assert.True(t, ok)
+	assert.False(t, parsed.Cenotaph)
+	assert.Equal(t, stone.Etching, parsed.Etching)
+	assert.Equal(t, stone.Mint, parsed.Mint)
+	assert.Equal(t, stone.Pointer, parsed.Pointer)
+	assert.Equal(t, "UNCOMMONGOODS", Name(parsed.Etching.Rune))
+
+	// edicts are sorted by rune id
+	if assert.Len(t, parsed.Edicts, 3) {
+		assert.Equal(t, stone.Edicts[1], parsed.Edicts[0])
+		assert.Equal(t, stone.Edicts[0], parsed.Edicts[1])
+		assert.Equal(t, stone.Edicts[2], parsed.Edicts[2])
+	}
+}
+
+func TestDecipher(t *testing.T) {
+	_, ok := Decipher(&xycommon.RpcTransaction{Vout: []btcjson.Vout{{ScriptPubKey: btcjson.ScriptPubKeyResult{Hex: "6a0401020304"}}}})
+	assert.False(t, ok)
+	assert.False(t, HasRunestone(&xycommon.RpcTransaction{}))
+
+	// edict of the etched rune to the second output, 0:0 is the rune etched by the tx
+	stone, ok := Decipher(runestoneTx(payloadScript(tagFlags, 1, 0, 0, 0, 100, 1), 1))
+	assert.True(t, ok)
+	assert.False(t, stone.Cenotaph)
+	assert.NotNil(t, stone.Etching)
+	assert.Nil(t, stone.Etching.Rune)
+	if assert.Len(t, stone.Edicts, 1) {
+		assert.True(t, stone.Edicts[0].ID.IsZero())
+		assert.Equal(t, uint32(1), stone.Edicts[0].Output)
+	}
+
+	// unknown odd tags are ignored
+	stone, _ = Decipher(runestoneTx(payloadScript(tagMint, 1, tagMint, 1, 127, 5), 1))
+	assert.False(t, stone.Cenotaph)
+	assert.Equal(t, &RuneID{Block: 1, Tx: 1}, stone.Mint)
+
+	for name, script := range map[string][]byte{
+		"unknown even tag":  payloadScript(tagMint, 1, tagMint, 1, 126, 1),
+		"unknown flag":      payloadScript(tagFlags, 1<<5),
+		"tag without value": payloadScript(tagFlags, 1, tagRune),
+		"trailing integers": payloadScript(0, 1, 1, 5),
+		"edict output":      payloadScript(0, 1, 1, 5, 3),
+		"edict rune id":     payloadScript(0, 0, 1, 5, 0),
+		"mint rune id":      payloadScript(tagMint, 0, tagMint, 1),
+		"non push opcode":   {0x6a, 0x5d, 0x51},
+		"invalid varint":    {0x6a, 0x5d, 0x01, 0x80},
+	} {
+		stone, ok = Decipher(runestoneTx(script, 1))
+		assert.True(t, ok, name)
+		assert.True(t, stone.Cenotaph, name)
+		assert.Empty(t, stone.Edicts, name)
+	}
+
+	// the cenotaph keeps the etched rune & the mint only
+	stone, _ = Decipher(runestoneTx(payloadScript(tagFlags, 3, tagRune, 1000, tagPremine, 100, tagCap, 10, tagMint, 1, tagMint, 1, 126, 1), 1))
+	assert.True(t, stone.Cenotaph)
+	assert.Equal(t, &Etching{Rune: big.NewInt(1000)}, stone.Etching)
+	assert.Equal(t, &RuneID{Block: 1, Tx: 1}, stone.Mint)
+}
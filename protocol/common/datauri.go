@@ -0,0 +1,276 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	dataURIScheme = "data:"
+
+	// maxDecompressedSize bounds the gzip payloads against the compression bombs
+	maxDecompressedSize = 1 << 20
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipMediaTypes media types of the gzip compressed data
+var gzipMediaTypes = map[string]struct{}{
+	"application/gzip":   {},
+	"application/x-gzip": {},
+}
+
+// DataURIOptions decoding rules of the inscribing chain or protocol
+type DataURIOptions struct {
+	// GzipInput decompresses the input compressed as a whole (ESIP-7 calldata) before parsing
+	GzipInput bool
+
+	// Legacy matches the lower case scheme only & takes the data verbatim as the history was indexed
+	// before RFC 2397, the escapes are not percent decoded
+	Legacy bool
+}
+
+// DataURI
+/***************************************
+ * RFC 2397 data uri, data:[<mediatype>][;<attribute>=<value>]*[;base64],<data>
+ * the scheme is case insensitive, the data is percent decoded, the malformed escapes are kept verbatim,
+ * then base64 decoded. The legacy options match the lower case scheme only & take the data verbatim.
+
+ * the data is decompressed if the gzip media type or the content-encoding=gzip parameter says so,
+ * the input compressed as a whole is only decompressed if the options enable it
+ ***************************************/
+type DataURI struct {
+	URI        string   // the uri as inscribed, decompressed if compressed as a whole
+	Header     string   // the part between the scheme & the data separator
+	MediaType  string   // lower cased, empty if omitted
+	Params     []string // attribute=value pairs in order, the base64 extension excluded
+	Base64     bool
+	Compressed bool // the input or the data was gzip compressed
+	Data       []byte
+
+	FoldedScheme bool // the scheme was not in lower case
+	Escaped      bool // the data was percent decoded
+}
+
+// MimeType returns the media type, defaults to text/plain
+func (u *DataURI) MimeType() string {
+	if u.MediaType == "" {
+		return "text/plain"
+	}
+	return u.MediaType
+}
+
+// Param returns the value of the attribute, case insensitive
+func (u *DataURI) Param(attribute string) (string, bool) {
+	for _, v := range u.Params {
+		kv := strings.SplitN(v, "=", 2)
+		if strings.EqualFold(strings.TrimSpace(kv[0]), attribute) {
+			return strings.TrimSpace(kv[1]), true
+		}
+	}
+	return "", false
+}
+
+// HasParam checks the attribute=value pair, case insensitive
+func (u *DataURI) HasParam(param string) bool {
+	for _, v := range u.Params {
+		if strings.EqualFold(strings.TrimSpace(v), param) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compression returns the declared compression of the data, empty if none
+func (u *DataURI) Compression() string {
+	if _, ok := gzipMediaTypes[u.MediaType]; ok {
+		return "gzip"
+	}
+
+	encoding, _ := u.Param("content-encoding")
+	return strings.ToLower(encoding)
+}
+
+// ParseDataURI parses the data uri, the compressed input is decompressed first if the options enable it
+func ParseDataURI(input []byte, opts DataURIOptions) (*DataURI, error) {
+	compressed := false
+	if opts.GzipInput && bytes.HasPrefix(input, gzipMagic) {
+		decompressed, err := gunzip(input)
+		if err != nil {
+			return nil, fmt.Errorf("data uri decompress err:%v", err)
+		}
+		input, compressed = decompressed, true
+	}
+
+	if !hasSchemePrefix(input) || (opts.Legacy && !bytes.HasPrefix(input, []byte(dataURIScheme))) {
+		return nil, fmt.Errorf("data uri scheme prefix checking failed")
+	}
+
+	uri := string(input)
+	sepIdx := strings.Index(uri, ",")
+	if sepIdx == -1 {
+		return nil, fmt.Errorf("data uri separator not found")
+	}
+
+	u := &DataURI{
+		URI:          uri,
+		Header:       uri[len(dataURIScheme):sepIdx],
+		Compressed:   compressed,
+		Params:       make([]string, 0),
+		FoldedScheme: !strings.HasPrefix(uri, dataURIScheme),
+	}
+
+	items := strings.Split(u.Header, ";")
+	u.MediaType = strings.ToLower(strings.TrimSpace(items[0]))
+	if u.MediaType != "" && !validMediaType(u.MediaType) {
+		return nil, fmt.Errorf("data uri media type[%s] invalid", items[0])
+	}
+
+	for i, item := range items[1:] {
+		if strings.EqualFold(strings.TrimSpace(item), "base64") && i == len(items)-2 {
+			u.Base64 = true
+			continue
+		}
+
+		if !strings.Contains(item, "=") {
+			return nil, fmt.Errorf("data uri parameter[%s] invalid", item)
+		}
+		u.Params = append(u.Params, item)
+	}
+
+	u.Data = []byte(uri[sepIdx+1:])
+	if !opts.Legacy {
+		decoded := percentDecode(uri[sepIdx+1:])
+		u.Data, u.Escaped = decoded, len(decoded) != len(u.Data)
+	}
+	if u.Base64 {
+		data, err := base64.StdEncoding.DecodeString(string(u.Data))
+		if err != nil {
+			return nil, fmt.Errorf("data uri base64 decode err:%v", err)
+		}
+		u.Data = data
+	}
+
+	switch compression := u.Compression(); compression {
+	case "":
+	case "gzip":
+		data, err := gunzip(u.Data)
+		if err != nil {
+			return nil, fmt.Errorf("data uri data decompress err:%v", err)
+		}
+		u.Data, u.Compressed = data, true
+	default:
+		return nil, fmt.Errorf("data uri content encoding[%s] not supported", compression)
+	}
+	return u, nil
+}
+
+// percentDecode decodes the %XX escapes of the data, the malformed ones are kept verbatim as browsers do
+func percentDecode(data string) []byte {
+	if !strings.Contains(data, "%") {
+		return []byte(data)
+	}
+
+	decoded := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == '%' && i+2 < len(data) && isHex(data[i+1]) && isHex(data[i+2]) {
+			b, _ := hex.DecodeString(data[i+1 : i+3])
+			decoded = append(decoded, b[0])
+			i += 2
+			continue
+		}
+		decoded = append(decoded, data[i])
+	}
+	return decoded
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// IsDataURI checks the data uri prefix or the gzip magic of the compressed uri
+func IsDataURI(input []byte) bool {
+	return hasSchemePrefix(input) || bytes.HasPrefix(input, gzipMagic)
+}
+
+// IsDataURIHex checks the 0x prefixed hex input by IsDataURI, only the prefix is decoded
+func IsDataURIHex(input string) bool {
+	if !strings.HasPrefix(input, "0x") {
+		return false
+	}
+
+	prefix := input[2:]
+	if len(prefix) > 2*len(dataURIScheme) {
+		prefix = prefix[:2*len(dataURIScheme)]
+	}
+
+	data, err := hex.DecodeString(prefix)
+	if err != nil {
+		return false
+	}
+	return hasSchemePrefix(data) || bytes.HasPrefix(data, gzipMagic)
+}
+
+func hasSchemePrefix(input []byte) bool {
+	return len(input) >= len(dataURIScheme) && strings.EqualFold(string(input[:len(dataURIScheme)]), dataURIScheme)
+}
+
+// validMediaType checks the type/subtype form
+func validMediaType(mediaType string) bool {
+	parts := strings.Split(mediaType, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return false
+	}
+
+	for _, c := range mediaType {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`()<>@,;:\"[]?=`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decompressed) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed size > %d", maxDecompressedSize)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func gzipData(data string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write([]byte(data))
+	_ = w.Close()
+	return buf.String()
+}
+
+func TestParseDataURI(t *testing.T) {
+	gzipInput := DataURIOptions{GzipInput: true}
+	tests := []struct {
+		name       string
+		input      string
+		opts       DataURIOptions
+		data       string // expected data, empty if the uri is invalid
+		compressed bool
+	}{
+		{"plain", `data:,{"a":"b"}`, DataURIOptions{}, `{"a":"b"}`, false},
+		{"percent encoded", `data:,%7B%22a%22:%22b%22%7d`, DataURIOptions{}, `{"a":"b"}`, false},
+		{"malformed escapes kept", `data:,100%;50%zz;%4`, DataURIOptions{}, `100%;50%zz;%4`, false},
+		{"percent encoded base64", "data:;base64,e30%3D", DataURIOptions{}, `{}`, false},
+		{"declared gzip", "data:application/json;content-encoding=gzip;base64," + base64.StdEncoding.EncodeToString([]byte(gzipData("{}"))), DataURIOptions{}, `{}`, true},
+		{"gzip media type", "data:application/gzip;base64," + base64.StdEncoding.EncodeToString([]byte(gzipData("{}"))), DataURIOptions{}, `{}`, true},
+		{"undeclared gzip kept", "data:;base64," + base64.StdEncoding.EncodeToString([]byte(gzipData("{}"))), DataURIOptions{}, gzipData("{}"), false},
+		{"gzip input", gzipData("data:,{}"), gzipInput, `{}`, true},
+		{"gzip input disabled", gzipData("data:,{}"), DataURIOptions{}, "", false},
+		{"declared gzip invalid", "data:;content-encoding=gzip,{}", DataURIOptions{}, "", false},
+		{"unknown encoding", "data:;content-encoding=br,{}", DataURIOptions{}, "", false},
+		{"scheme case", `DATA:,{}`, DataURIOptions{}, `{}`, false},
+		{"legacy scheme case", `DATA:,{}`, DataURIOptions{Legacy: true}, "", false},
+		{"legacy verbatim", `data:,%7B%7D`, DataURIOptions{Legacy: true}, `%7B%7D`, false},
+	}
+
+	for _, tt := range tests {
+		u, err := ParseDataURI([]byte(tt.input), tt.opts)
+		if tt.data == "" {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, tt.data, string(u.Data), tt.name)
+			assert.Equal(t, tt.compressed, u.Compressed, tt.name)
+		}
+	}
+
+	// the decoding unknown to the legacy rules is reported
+	u, err := ParseDataURI([]byte(`DATA:,%7B%7D`), DataURIOptions{})
+	if assert.NoError(t, err) {
+		assert.True(t, u.FoldedScheme)
+		assert.True(t, u.Escaped)
+	}
+
+	u, err = ParseDataURI([]byte(`data:,100%zz`), DataURIOptions{})
+	if assert.NoError(t, err) {
+		assert.False(t, u.FoldedScheme)
+		assert.False(t, u.Escaped)
+	}
+}
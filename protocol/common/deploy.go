@@ -28,16 +28,39 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/xyerrors"
 	"math"
 	"math/big"
+	"strings"
+)
+
+const (
+	TransferTypeHash    = "hash"
+	TransferTypeBalance = "balance"
+
+	// DefaultDecimals decimals of the tick deployed without dec by the brc-20 rules
+	DefaultDecimals = 18
+
+	// LegacyDefaultDecimals decimals recorded for the tick deployed without dec before the strict decimals rules
+	LegacyDefaultDecimals = 0
 )
 
 type Deploy struct {
-	Tick      string          `json:"tick"`
-	MaxSupply decimal.Decimal `json:"max"`
-	MintLimit decimal.Decimal `json:"lim"`
-	Decimal   decimal.Decimal `json:"dec"`
+	Tick         string           `json:"tick"`
+	MaxSupply    decimal.Decimal  `json:"max"`
+	MintLimit    decimal.Decimal  `json:"lim"`
+	Decimal      *decimal.Decimal `json:"dec"` // optional, the default decimals of the rules if omitted
+	TransferType string           `json:"tt"`  // transfer type, hash or balance(default)
+
+	// extended deploy parameters, all optional
+	AddressMintLimit decimal.Decimal `json:"addr_lim"`  // total mint amount limit per address, 0 unlimited
+	StartBlock       decimal.Decimal `json:"start"`     // first mintable block, 0 unlimited
+	EndBlock         decimal.Decimal `json:"end"`       // last mintable block, 0 unlimited
+	Premine          decimal.Decimal `json:"premine"`   // minted to the deployer on deploy
+	SelfMint         string          `json:"self_mint"` // true if mintable by the deployer only
+
+	StrictDecimals bool `json:"-"` // strict decimals rules in force at the deploy height
 }
 
 func (base *Protocol) Deploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
@@ -51,12 +74,36 @@ func (base *Protocol) Deploy(block *xycommon.RpcBlock, tx *xycommon.RpcTransacti
 		Block: block,
 		Tx:    tx,
 		Deploy: &devents.Deploy{
-			Name:      d.Tick,
-			MaxSupply: d.MaxSupply,
-			MintLimit: d.MintLimit,
-			Decimal:   int8(d.Decimal.IntPart()),
+			Name:         d.Tick,
+			MaxSupply:    d.MaxSupply,
+			MintLimit:    d.MintLimit,
+			Decimal:      int8(d.Decimal.IntPart()),
+			TransferType: model.TransferTypeBalance,
+
+			StrictDecimals: d.StrictDecimals,
+
+			AddressMintLimit: d.AddressMintLimit,
+			MintStartBlock:   uint64(d.StartBlock.IntPart()),
+			MintEndBlock:     uint64(d.EndBlock.IntPart()),
+			Premine:          d.Premine,
+			SelfMint:         d.SelfMint == "true",
 		},
 	}
+	if d.TransferType == TransferTypeHash {
+		result.Deploy.TransferType = model.TransferTypeHash
+	}
+
+	// premine is minted to the deployer by the deploy tx
+	if d.Premine.GreaterThan(decimal.Zero) {
+		result.Mint = &devents.Mint{
+			Minter:  tx.From,
+			Amount:  d.Premine,
+			Premine: true,
+		}
+		if result.Deploy.TransferType == model.TransferTypeHash {
+			result.Mint.SN = tx.Hash
+		}
+	}
 	return []*devents.TxResult{result}, nil
 }
 
@@ -66,6 +113,12 @@ func (base *Protocol) verifyDeploy(tx *xycommon.RpcTransaction, md *devents.Meta
 		return nil, xyerrors.NewInsError(-12, fmt.Sprintf("protocol[%s] / tick[%s] nil", md.Protocol, md.Tick))
 	}
 
+	// tick naming rules checking
+	rules := base.Rules(tx)
+	if err := rules.TickRule.Verify(md.Tick); err != nil {
+		return nil, err
+	}
+
 	// exists checking
 	if ok, _ := base.cache.Inscription.Get(md.Protocol, md.Tick); ok {
 		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription deployed & abort, protocol[%s], tick[%s]", md.Protocol, md.Tick))
@@ -92,14 +145,25 @@ func (base *Protocol) verifyDeploy(tx *xycommon.RpcTransaction, md *devents.Meta
 		return nil, xyerrors.NewInsError(-16, "max < limit")
 	}
 
+	if deploy.Decimal == nil {
+		dec := decimal.NewFromInt(int64(rules.DefaultDecimals))
+		deploy.Decimal = &dec
+	}
+
 	// decimal value only int type is valid
 	if !deploy.Decimal.IsInteger() {
 		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("invalid decimal:%s", deploy.Decimal.String()))
 	}
 
-	// maximum decimals is 18
-	if deploy.Decimal.IntPart() > 18 {
-		return nil, xyerrors.NewInsError(-18, fmt.Sprintf("decimal[%d] > 18", deploy.Decimal.IntPart()))
+	// maximum decimals
+	if deploy.Decimal.IntPart() > int64(rules.MaxDecimals) {
+		return nil, xyerrors.NewInsError(-18, fmt.Sprintf("decimal[%d] > %d", deploy.Decimal.IntPart(), rules.MaxDecimals))
+	}
+
+	// max & limit must fit the decimals
+	decimals := int8(deploy.Decimal.IntPart())
+	if rules.StrictDecimals && (!ValidDecimals(deploy.MaxSupply, decimals) || !ValidDecimals(deploy.MintLimit, decimals)) {
+		return nil, xyerrors.NewInsError(-21, fmt.Sprintf("max[%s] / limit[%s] exceeds decimals[%d]", deploy.MaxSupply, deploy.MintLimit, decimals))
 	}
 
 	// MaxSupply must <= uint64
@@ -107,5 +171,64 @@ func (base *Protocol) verifyDeploy(tx *xycommon.RpcTransaction, md *devents.Meta
 	if deploy.MaxSupply.GreaterThan(maxUint64Decimal) {
 		return nil, xyerrors.NewInsError(-19, fmt.Sprintf("max[%s] > max_uint64", deploy.MaxSupply.String()))
 	}
+
+	// transfer type checking
+	deploy.TransferType = strings.ToLower(deploy.TransferType)
+	if deploy.TransferType != "" && deploy.TransferType != TransferTypeHash && deploy.TransferType != TransferTypeBalance {
+		return nil, xyerrors.NewInsError(-36, fmt.Sprintf("invalid transfer type:%s", deploy.TransferType))
+	}
+
+	// tt is ignored before the hash transfer activated
+	if !rules.HashTransfer {
+		deploy.TransferType = TransferTypeBalance
+	}
+	deploy.StrictDecimals = rules.StrictDecimals
+
+	if err := verifyDeployParams(deploy, decimals, rules.StrictDecimals); err != nil {
+		return nil, err
+	}
 	return deploy, nil
 }
+
+// verifyDeployParams checks the extended deploy parameters
+func verifyDeployParams(deploy *Deploy, decimals int8, strictDecimals bool) *xyerrors.InsError {
+	// 0 <= address mint limit <= max
+	if deploy.AddressMintLimit.IsNegative() || deploy.AddressMintLimit.GreaterThan(deploy.MaxSupply) {
+		return xyerrors.NewInsError(-26, fmt.Sprintf("invalid address mint limit:%s", deploy.AddressMintLimit))
+	}
+
+	if strictDecimals && !ValidDecimals(deploy.AddressMintLimit, decimals) {
+		return xyerrors.NewInsError(-26, fmt.Sprintf("address mint limit[%s] exceeds decimals[%d]", deploy.AddressMintLimit, decimals))
+	}
+
+	// mint window of the block numbers, both ends included
+	maxHeight := decimal.NewFromInt(math.MaxInt64)
+	for _, height := range []decimal.Decimal{deploy.StartBlock, deploy.EndBlock} {
+		if !height.IsInteger() || height.IsNegative() || height.GreaterThan(maxHeight) {
+			return xyerrors.NewInsError(-27, fmt.Sprintf("invalid mint window, start[%s], end[%s]", deploy.StartBlock, deploy.EndBlock))
+		}
+	}
+	if deploy.EndBlock.IsPositive() && deploy.EndBlock.LessThan(deploy.StartBlock) {
+		return xyerrors.NewInsError(-27, fmt.Sprintf("invalid mint window, start[%s] > end[%s]", deploy.StartBlock, deploy.EndBlock))
+	}
+
+	// 0 <= premine <= max
+	if deploy.Premine.IsNegative() || deploy.Premine.GreaterThan(deploy.MaxSupply) {
+		return xyerrors.NewInsError(-28, fmt.Sprintf("invalid premine:%s", deploy.Premine))
+	}
+
+	if strictDecimals && !ValidDecimals(deploy.Premine, decimals) {
+		return xyerrors.NewInsError(-28, fmt.Sprintf("premine[%s] exceeds decimals[%d]", deploy.Premine, decimals))
+	}
+
+	deploy.SelfMint = strings.ToLower(deploy.SelfMint)
+	if deploy.SelfMint != "" && deploy.SelfMint != "true" && deploy.SelfMint != "false" {
+		return xyerrors.NewInsError(-29, fmt.Sprintf("invalid self mint:%s", deploy.SelfMint))
+	}
+	return nil
+}
+
+// ValidDecimals checks the fractional digits of the amount fit the decimals of the tick
+func ValidDecimals(amount decimal.Decimal, decimals int8) bool {
+	return amount.Equal(amount.Truncate(int32(decimals)))
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"math/big"
+	"testing"
+)
+
+const (
+	testAddrA = "0x00000000000000000000000000000000000000a1"
+	testAddrB = "0x00000000000000000000000000000000000000b2"
+)
+
+func newTestProtocol(rules *Rules) (*Protocol, *dcache.Manager) {
+	cache := &dcache.Manager{
+		Inscription:      dcache.NewInscription(),
+		InscriptionStats: dcache.NewInscriptionStats(),
+		Balance:          dcache.NewBalance(),
+		AddressMinted:    dcache.NewAddressMinted(),
+		UTXO:             dcache.NewUTXO(),
+	}
+	return NewProtocol(cache, BaseSchedule(rules, "brc-20")), cache
+}
+
+func TestVerifyDeploy(t *testing.T) {
+	tests := []struct {
+		name         string
+		rules        *Rules
+		tick         string
+		data         string
+		code         int // 0 valid
+		decimals     int64
+		transferType string // empty if omitted, the balance transfer type
+		selfMint     string
+	}{
+		{"legacy default decimals", DefaultRules, "ordi", `{"max":"1000","lim":"100"}`, 0, LegacyDefaultDecimals, "", ""},
+		{"default decimals", BRC20Rules, "ordi", `{"max":"1000","lim":"100"}`, 0, DefaultDecimals, TransferTypeBalance, ""},
+		{"decimals", BRC20Rules, "ordi", `{"max":"1000","lim":"100","dec":"2"}`, 0, 2, TransferTypeBalance, ""},
+		{"tick nil", DefaultRules, "", `{"max":"1000","lim":"100"}`, -12, 0, "", ""},
+		{"tick invalid", BRC20Rules, "o di", `{"max":"1000","lim":"100"}`, -23, 0, "", ""},
+		{"tick legacy", DefaultRules, "or di", `{"max":"1000","lim":"100"}`, 0, 0, "", ""},
+		{"json invalid", DefaultRules, "ordi", `{"max":1000`, -13, 0, "", ""},
+		{"max zero", DefaultRules, "ordi", `{"max":"0","lim":"100"}`, -14, 0, "", ""},
+		{"limit zero", DefaultRules, "ordi", `{"max":"1000","lim":"0"}`, -15, 0, "", ""},
+		{"max < limit", DefaultRules, "ordi", `{"max":"100","lim":"1000"}`, -16, 0, "", ""},
+		{"decimals fraction", DefaultRules, "ordi", `{"max":"1000","lim":"100","dec":"1.5"}`, -17, 0, "", ""},
+		{"decimals too many", DefaultRules, "ordi", `{"max":"1000","lim":"100","dec":"19"}`, -18, 0, "", ""},
+		{"max exceeds decimals", BRC20Rules, "ordi", `{"max":"1000.5","lim":"100","dec":"0"}`, -21, 0, "", ""},
+		{"limit exceeds decimals", BRC20Rules, "ordi", `{"max":"1000","lim":"0.125","dec":"2"}`, -21, 0, "", ""},
+		{"max exceeds decimals legacy", DefaultRules, "ordi", `{"max":"1000.5","lim":"100","dec":"0"}`, 0, 0, "", ""},
+		{"max > max_uint64", DefaultRules, "ordi", `{"max":"18446744073709551616","lim":"100"}`, -19, 0, "", ""},
+		{"transfer type invalid", DefaultRules, "ordi", `{"max":"1000","lim":"100","tt":"utxo"}`, -36, 0, "", ""},
+		{"transfer type balance", DefaultRules, "ordi", `{"max":"1000","lim":"100","tt":"Balance"}`, 0, 0, TransferTypeBalance, ""},
+		{"transfer type hash", DefaultRules, "ordi", `{"max":"1000","lim":"100","tt":"HASH"}`, 0, 0, TransferTypeHash, ""},
+		{"transfer type hash inactive", BRC20Rules, "ordi", `{"max":"1000","lim":"100","tt":"hash"}`, 0, DefaultDecimals, TransferTypeBalance, ""},
+		{"address limit", DefaultRules, "ordi", `{"max":"1000","lim":"100","addr_lim":"1000"}`, 0, 0, "", ""},
+		{"address limit negative", DefaultRules, "ordi", `{"max":"1000","lim":"100","addr_lim":"-1"}`, -26, 0, "", ""},
+		{"address limit > max", DefaultRules, "ordi", `{"max":"1000","lim":"100","addr_lim":"1001"}`, -26, 0, "", ""},
+		{"address limit exceeds decimals", BRC20Rules, "ordi", `{"max":"1000","lim":"100","addr_lim":"0.5","dec":"0"}`, -26, 0, "", ""},
+		{"mint window", DefaultRules, "ordi", `{"max":"1000","lim":"100","start":"10","end":"10"}`, 0, 0, "", ""},
+		{"mint window open end", DefaultRules, "ordi", `{"max":"1000","lim":"100","start":"10"}`, 0, 0, "", ""},
+		{"mint window fraction", DefaultRules, "ordi", `{"max":"1000","lim":"100","start":"1.5"}`, -27, 0, "", ""},
+		{"mint window negative", DefaultRules, "ordi", `{"max":"1000","lim":"100","end":"-1"}`, -27, 0, "", ""},
+		{"mint window overflow", DefaultRules, "ordi", `{"max":"1000","lim":"100","end":"9223372036854775808"}`, -27, 0, "", ""},
+		{"mint window reversed", DefaultRules, "ordi", `{"max":"1000","lim":"100","start":"20","end":"10"}`, -27, 0, "", ""},
+		{"premine", DefaultRules, "ordi", `{"max":"1000","lim":"100","premine":"1000"}`, 0, 0, "", ""},
+		{"premine negative", DefaultRules, "ordi", `{"max":"1000","lim":"100","premine":"-1"}`, -28, 0, "", ""},
+		{"premine > max", DefaultRules, "ordi", `{"max":"1000","lim":"100","premine":"1001"}`, -28, 0, "", ""},
+		{"premine exceeds decimals", BRC20Rules, "ordi", `{"max":"1000","lim":"100","premine":"0.005","dec":"2"}`, -28, 0, "", ""},
+		{"self mint", DefaultRules, "ordi", `{"max":"1000","lim":"100","self_mint":"TRUE"}`, 0, 0, "", "true"},
+		{"self mint disabled", DefaultRules, "ordi", `{"max":"1000","lim":"100","self_mint":"false"}`, 0, 0, "", "false"},
+		{"self mint invalid", DefaultRules, "ordi", `{"max":"1000","lim":"100","self_mint":"yes"}`, -29, 0, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestProtocol(tt.rules)
+			md := &devents.MetaData{Protocol: "brc-20", Tick: tt.tick, Data: tt.data}
+			deploy, err := p.verifyDeploy(&xycommon.RpcTransaction{From: testAddrA}, md)
+			if tt.code != 0 {
+				if assert.NotNil(t, err) {
+					assert.Equal(t, tt.code, err.Code(), err.Error())
+				}
+				return
+			}
+
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.decimals, deploy.Decimal.IntPart())
+				assert.Equal(t, tt.transferType, deploy.TransferType)
+				assert.Equal(t, tt.selfMint, deploy.SelfMint)
+				assert.Equal(t, tt.rules.StrictDecimals, deploy.StrictDecimals)
+			}
+		})
+	}
+}
+
+func TestVerifyDeployExists(t *testing.T) {
+	p, cache := newTestProtocol(DefaultRules)
+	cache.Inscription.Create("brc-20", "ordi", &dcache.Tick{})
+
+	md := &devents.MetaData{Protocol: "brc-20", Tick: "ordi", Data: `{"max":"1000","lim":"100"}`}
+	_, err := p.verifyDeploy(&xycommon.RpcTransaction{From: testAddrA}, md)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, -15, err.Code())
+	}
+}
+
+func TestDeployPremine(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		mint  bool
+		sn    string
+		ttype int8
+	}{
+		{"no premine", `{"max":"1000","lim":"100"}`, false, "", model.TransferTypeBalance},
+		{"premine", `{"max":"1000","lim":"100","premine":"200"}`, true, "", model.TransferTypeBalance},
+		{"premine hash", `{"max":"1000","lim":"100","premine":"200","tt":"hash"}`, true, "0x01", model.TransferTypeHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestProtocol(DefaultRules)
+			tx := &xycommon.RpcTransaction{Hash: "0x01", From: testAddrA, To: testAddrB, BlockNumber: big.NewInt(1)}
+			md := &devents.MetaData{Protocol: "brc-20", Tick: "ordi", Data: tt.data}
+
+			results, err := p.Deploy(&xycommon.RpcBlock{}, tx, md)
+			assert.Nil(t, err)
+			if !assert.Len(t, results, 1) {
+				return
+			}
+			assert.Equal(t, tt.ttype, results[0].Deploy.TransferType)
+
+			// the premine is minted to the deployer
+			mint := results[0].Mint
+			if !tt.mint {
+				assert.Nil(t, mint)
+				return
+			}
+			if assert.NotNil(t, mint) {
+				assert.Equal(t, testAddrA, mint.Minter)
+				assert.Equal(t, "200", mint.Amount.String())
+				assert.True(t, mint.Premine)
+				assert.Equal(t, tt.sn, mint.SN)
+				assert.True(t, results[0].Deploy.Premine.Equal(decimal.NewFromInt(200)))
+			}
+		})
+	}
+}
@@ -29,15 +29,15 @@ import (
 	"github.com/uxuycom/indexer/xyerrors"
 )
 
-const DataPrefix = "0x646174613a"
-
 type Protocol struct {
-	cache *dcache.Manager
+	cache    *dcache.Manager
+	schedule *Schedule
 }
 
-func NewProtocol(cache *dcache.Manager) *Protocol {
+func NewProtocol(cache *dcache.Manager, schedule *Schedule) *Protocol {
 	return &Protocol{
-		cache: cache,
+		cache:    cache,
+		schedule: schedule,
 	}
 }
 
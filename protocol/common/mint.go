@@ -28,7 +28,9 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
 )
 
 type Mint struct {
@@ -49,6 +51,11 @@ func (base *Protocol) Mint(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction
 			Amount: m.Amount,
 		},
 	}
+
+	// minted amount of the hash transfer type tick is kept as utxo keyed by the mint hash
+	if _, inscription := base.cache.Inscription.Get(md.Protocol, md.Tick); inscription.TransferType == model.TransferTypeHash {
+		result.Mint.SN = tx.Hash
+	}
 	return []*devents.TxResult{result}, nil
 }
 
@@ -72,11 +79,29 @@ func (base *Protocol) verifyMint(tx *xycommon.RpcTransaction, md *devents.MetaDa
 		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s], tick[%s]", protocol, tick))
 	}
 
+	if inscription.StrictDecimals && !ValidDecimals(mint.Amount, inscription.Decimals) {
+		return nil, xyerrors.NewInsError(-33, fmt.Sprintf("mint amount[%v] exceeds decimals[%d]", mint.Amount, inscription.Decimals))
+	}
+
 	// mint amount maximum checking
 	if mint.Amount.GreaterThan(inscription.LimitPerMint) {
 		return nil, xyerrors.NewInsError(-17, "mint amount exceeds limit per mint")
 	}
 
+	// mint window checking
+	height := TxHeight(tx)
+	if inscription.MintStartBlock > 0 && height < inscription.MintStartBlock {
+		return nil, xyerrors.NewInsError(-30, fmt.Sprintf("mint not started, block[%d] < start[%d]", height, inscription.MintStartBlock))
+	}
+	if inscription.MintEndBlock > 0 && height > inscription.MintEndBlock {
+		return nil, xyerrors.NewInsError(-30, fmt.Sprintf("mint ended, block[%d] > end[%d]", height, inscription.MintEndBlock))
+	}
+
+	// self mint tick is only minted by the deployer
+	if inscription.SelfMint && !strings.EqualFold(tx.From, inscription.DeployBy) {
+		return nil, xyerrors.NewInsError(-31, fmt.Sprintf("self mint tick, minter[%s] is not the deployer", tx.From))
+	}
+
 	// mint finished checking
 	ok, stats := base.cache.InscriptionStats.Get(protocol, tick)
 	if !ok {
@@ -92,5 +117,16 @@ func (base *Protocol) verifyMint(tx *xycommon.RpcTransaction, md *devents.MetaDa
 	if mint.Amount.GreaterThan(mintLeft) {
 		mint.Amount = mintLeft
 	}
+
+	// final mint of the address = math.Min(Address Mint Limit - Address Minted)
+	if inscription.AddressMintLimit.GreaterThan(decimal.Zero) {
+		addressLeft := inscription.AddressMintLimit.Sub(base.cache.AddressMinted.Get(protocol, tick, tx.To))
+		if addressLeft.LessThanOrEqual(decimal.Zero) {
+			return nil, xyerrors.NewInsError(-32, fmt.Sprintf("address[%s] reached the mint limit per address", tx.To))
+		}
+		if mint.Amount.GreaterThan(addressLeft) {
+			mint.Amount = addressLeft
+		}
+	}
 	return mint, nil
 }
@@ -0,0 +1,135 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestVerifyMint(t *testing.T) {
+	tick := func(edit func(tick *dcache.Tick)) *dcache.Tick {
+		nt := &dcache.Tick{
+			LimitPerMint: decimal.NewFromInt(100),
+			TotalSupply:  decimal.NewFromInt(1000),
+			Decimals:     2,
+			DeployBy:     testAddrA,
+		}
+		if edit != nil {
+			edit(nt)
+		}
+		return nt
+	}
+
+	tests := []struct {
+		name          string
+		tick          *dcache.Tick // nil not deployed
+		minted        int64
+		addressMinted int64
+		from          string
+		height        int64
+		data          string
+		code          int // 0 valid
+		amount        string
+	}{
+		{"mint", tick(nil), 0, 0, testAddrB, 1, `{"amt":"100"}`, 0, "100"},
+		{"json invalid", tick(nil), 0, 0, testAddrB, 1, `{"amt":100`, -13, ""},
+		{"amount zero", tick(nil), 0, 0, testAddrB, 1, `{"amt":"0"}`, -14, ""},
+		{"not deployed", nil, 0, 0, testAddrB, 1, `{"amt":"100"}`, -15, ""},
+		{"exceeds decimals", tick(func(nt *dcache.Tick) { nt.StrictDecimals = true }), 0, 0, testAddrB, 1, `{"amt":"1.255"}`, -33, ""},
+		{"fits decimals", tick(func(nt *dcache.Tick) { nt.StrictDecimals = true }), 0, 0, testAddrB, 1, `{"amt":"1.25"}`, 0, "1.25"},
+		{"exceeds decimals legacy", tick(nil), 0, 0, testAddrB, 1, `{"amt":"1.255"}`, 0, "1.255"},
+		{"exceeds limit", tick(nil), 0, 0, testAddrB, 1, `{"amt":"100.01"}`, -17, ""},
+		{"before start", tick(func(nt *dcache.Tick) { nt.MintStartBlock = 10 }), 0, 0, testAddrB, 9, `{"amt":"100"}`, -30, ""},
+		{"at start", tick(func(nt *dcache.Tick) { nt.MintStartBlock = 10 }), 0, 0, testAddrB, 10, `{"amt":"100"}`, 0, "100"},
+		{"at end", tick(func(nt *dcache.Tick) { nt.MintEndBlock = 20 }), 0, 0, testAddrB, 20, `{"amt":"100"}`, 0, "100"},
+		{"after end", tick(func(nt *dcache.Tick) { nt.MintEndBlock = 20 }), 0, 0, testAddrB, 21, `{"amt":"100"}`, -30, ""},
+		{"self mint by deployer", tick(func(nt *dcache.Tick) { nt.SelfMint = true }), 0, 0, strings.ToUpper(testAddrA), 1, `{"amt":"100"}`, 0, "100"},
+		{"self mint by others", tick(func(nt *dcache.Tick) { nt.SelfMint = true }), 0, 0, testAddrB, 1, `{"amt":"100"}`, -31, ""},
+		{"completed", tick(nil), 1000, 0, testAddrB, 1, `{"amt":"100"}`, -20, ""},
+		{"cut to supply left", tick(nil), 950, 0, testAddrB, 1, `{"amt":"100"}`, 0, "50"},
+		{"address limit", tick(func(nt *dcache.Tick) { nt.AddressMintLimit = decimal.NewFromInt(150) }), 0, 0, testAddrB, 1, `{"amt":"100"}`, 0, "100"},
+		{"cut to address left", tick(func(nt *dcache.Tick) { nt.AddressMintLimit = decimal.NewFromInt(150) }), 0, 100, testAddrB, 1, `{"amt":"100"}`, 0, "50"},
+		{"address limit reached", tick(func(nt *dcache.Tick) { nt.AddressMintLimit = decimal.NewFromInt(150) }), 0, 150, testAddrB, 1, `{"amt":"100"}`, -32, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, cache := newTestProtocol(DefaultRules)
+			if tt.tick != nil {
+				cache.Inscription.Create("brc-20", "ordi", tt.tick)
+				cache.InscriptionStats.Create("brc-20", "ordi", &dcache.InsStats{Minted: decimal.NewFromInt(tt.minted)})
+			}
+			cache.AddressMinted.Add("brc-20", "ordi", testAddrA, decimal.NewFromInt(tt.addressMinted))
+
+			// the minted amount goes to the receiver of the tx
+			tx := &xycommon.RpcTransaction{From: tt.from, To: testAddrA, BlockNumber: big.NewInt(tt.height)}
+			md := &devents.MetaData{Protocol: "brc-20", Tick: "ordi", Data: tt.data}
+			mint, err := p.verifyMint(tx, md)
+			if tt.code != 0 {
+				if assert.NotNil(t, err) {
+					assert.Equal(t, tt.code, err.Code(), err.Error())
+				}
+				return
+			}
+
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.amount, mint.Amount.String())
+			}
+		})
+	}
+}
+
+func TestMintHashTransferType(t *testing.T) {
+	for _, ttype := range []int8{model.TransferTypeBalance, model.TransferTypeHash} {
+		p, cache := newTestProtocol(DefaultRules)
+		cache.Inscription.Create("brc-20", "ordi", &dcache.Tick{
+			TransferType: ttype,
+			LimitPerMint: decimal.NewFromInt(100),
+			TotalSupply:  decimal.NewFromInt(1000),
+		})
+		cache.InscriptionStats.Create("brc-20", "ordi", &dcache.InsStats{})
+
+		tx := &xycommon.RpcTransaction{Hash: "0x01", From: testAddrA, To: testAddrB, BlockNumber: big.NewInt(1)}
+		md := &devents.MetaData{Protocol: "brc-20", Tick: "ordi", Data: `{"amt":"100"}`}
+		results, err := p.Mint(&xycommon.RpcBlock{}, tx, md)
+		assert.Nil(t, err)
+		if !assert.Len(t, results, 1) {
+			continue
+		}
+		assert.Equal(t, testAddrB, results[0].Mint.Minter)
+
+		// the minted amount of the hash transfer type tick is kept as utxo keyed by the mint hash
+		sn := ""
+		if ttype == model.TransferTypeHash {
+			sn = tx.Hash
+		}
+		assert.Equal(t, sn, results[0].Mint.SN)
+	}
+}
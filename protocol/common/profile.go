@@ -0,0 +1,207 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/uxuycom/indexer/devents"
+	"io"
+	"regexp"
+)
+
+type FieldKind int8
+
+const (
+	FieldString  FieldKind = iota // json string
+	FieldNumber                   // json string of the plain decimal number
+	FieldInteger                  // json string of the plain integer number
+	FieldBool                     // json string of true / false
+)
+
+var (
+	numberPattern  = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+	integerPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+)
+
+type FieldRule struct {
+	Kind     FieldKind
+	Required bool
+}
+
+// JSONProfile strictness profile fixing the valid json shapes of the protocol inscriptions:
+// the content is one json object without duplicate keys, the keys are case-sensitive & must be
+// the p / op / tick or the fields of the op, all values are strings, numbers are written as plain
+// decimals without sign, exponent, leading zeros & spaces. The ops out of the profile are rejected.
+type JSONProfile struct {
+	Ops map[string]map[string]FieldRule // op => fields besides p / op / tick
+}
+
+var headerFields = map[string]FieldRule{
+	"p":    {Kind: FieldString, Required: true},
+	"op":   {Kind: FieldString, Required: true},
+	"tick": {Kind: FieldString, Required: true},
+}
+
+// BRC20JSONProfile canonical brc-20 inscription shapes
+var BRC20JSONProfile = &JSONProfile{
+	Ops: map[string]map[string]FieldRule{
+		devents.OperateDeploy: {
+			"max":       {Kind: FieldNumber, Required: true},
+			"lim":       {Kind: FieldNumber, Required: true},
+			"dec":       {Kind: FieldInteger},
+			"self_mint": {Kind: FieldBool},
+		},
+		devents.OperateMint: {
+			"amt": {Kind: FieldNumber, Required: true},
+		},
+		devents.OperateTransfer: {
+			"amt": {Kind: FieldNumber, Required: true},
+		},
+	},
+}
+
+// HashTransferJSONProfile brc-20 shapes extended with the hash transfer type & the extended
+// deploy parameters, the amount of the transfer by hash is optional
+var HashTransferJSONProfile = BRC20JSONProfile.Extend(map[string]map[string]FieldRule{
+	devents.OperateDeploy: {
+		"tt":       {Kind: FieldString},
+		"addr_lim": {Kind: FieldNumber},
+		"start":    {Kind: FieldInteger},
+		"end":      {Kind: FieldInteger},
+		"premine":  {Kind: FieldNumber},
+	},
+	devents.OperateTransfer: {
+		"amt":  {Kind: FieldNumber},
+		"hash": {Kind: FieldString},
+	},
+})
+
+// Extend returns a copy of the profile with the ops & fields added or overridden
+func (p *JSONProfile) Extend(ops map[string]map[string]FieldRule) *JSONProfile {
+	extended := &JSONProfile{Ops: make(map[string]map[string]FieldRule, len(p.Ops)+len(ops))}
+	for _, items := range []map[string]map[string]FieldRule{p.Ops, ops} {
+		for op, fields := range items {
+			if extended.Ops[op] == nil {
+				extended.Ops[op] = make(map[string]FieldRule, len(fields))
+			}
+			for name, rule := range fields {
+				extended.Ops[op][name] = rule
+			}
+		}
+	}
+	return extended
+}
+
+// Validate checks the json content of the metadata matches the profile
+func (p *JSONProfile) Validate(md *devents.MetaData) error {
+	fields, ok := p.Ops[md.Operate]
+	if !ok {
+		return fmt.Errorf("op[%s] not supported", md.Operate)
+	}
+
+	values, err := decodeObject([]byte(md.Data))
+	if err != nil {
+		return err
+	}
+
+	for name, raw := range values {
+		rule, ok := headerFields[name]
+		if !ok {
+			if rule, ok = fields[name]; !ok {
+				return fmt.Errorf("field[%s] unknown", name)
+			}
+		}
+		if err := rule.check(name, raw); err != nil {
+			return err
+		}
+	}
+
+	for _, items := range []map[string]FieldRule{headerFields, fields} {
+		for name, rule := range items {
+			if _, ok := values[name]; rule.Required && !ok {
+				return fmt.Errorf("field[%s] missing", name)
+			}
+		}
+	}
+	return nil
+}
+
+func (r FieldRule) check(name string, raw json.RawMessage) error {
+	var value string
+	if len(raw) < 1 || raw[0] != '"' || json.Unmarshal(raw, &value) != nil {
+		return fmt.Errorf("field[%s] value[%s] not string", name, raw)
+	}
+
+	switch r.Kind {
+	case FieldNumber:
+		if !numberPattern.MatchString(value) {
+			return fmt.Errorf("field[%s] value[%s] not plain decimal", name, value)
+		}
+	case FieldInteger:
+		if !integerPattern.MatchString(value) {
+			return fmt.Errorf("field[%s] value[%s] not plain integer", name, value)
+		}
+	case FieldBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("field[%s] value[%s] not true / false", name, value)
+		}
+	}
+	return nil
+}
+
+// decodeObject decodes the single json object keyed by the raw values, duplicate keys are rejected
+func decodeObject(data []byte) (map[string]json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("json object expected")
+	}
+
+	values := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("json decode err:%v", err)
+		}
+
+		key := token.(string)
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("duplicate key[%s]", key)
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("json decode err:%v", err)
+		}
+		values[key] = raw
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("json decode err:%v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("trailing data after json object")
+	}
+	return values, nil
+}
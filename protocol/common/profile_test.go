@@ -0,0 +1,96 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/devents"
+	"testing"
+)
+
+func TestJSONProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *JSONProfile
+		op      string
+		data    string
+		wantErr bool
+	}{
+		{"deploy", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000","lim":"1000"}`, false},
+		{"deploy with decimals", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000.5","lim":"0.5","dec":"1"}`, false},
+		{"deploy lim missing", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"21000000"}`, true},
+		{"deploy dec fraction", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","dec":"1.0"}`, true},
+		{"deploy tt unknown", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","tt":"hash"}`, true},
+		{"deploy tt extended", HashTransferJSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","tt":"hash"}`, false},
+		{"deploy self mint", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","self_mint":"true"}`, false},
+		{"deploy self mint not bool", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","self_mint":"yes"}`, true},
+		{"deploy premine not extended", BRC20JSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","premine":"10"}`, true},
+		{"deploy extended params", HashTransferJSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","addr_lim":"5","start":"100","end":"200","premine":"10"}`, false},
+		{"deploy start fraction", HashTransferJSONProfile, "deploy", `{"p":"brc-20","op":"deploy","tick":"ordi","max":"100","lim":"1","start":"1.5"}`, true},
+		{"mint", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1000"}`, false},
+		{"mint zero fraction", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"0.001"}`, false},
+		{"mint spaces between tokens", BRC20JSONProfile, "mint", "{ \"p\" : \"brc-20\",\n \"op\":\"mint\", \"tick\":\"ordi\", \"amt\":\"1\" }", false},
+		{"mint number value", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":1000}`, true},
+		{"mint leading zeros", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"01000"}`, true},
+		{"mint exponent", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1e3"}`, true},
+		{"mint sign", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"+1000"}`, true},
+		{"mint value spaces", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":" 1000"}`, true},
+		{"mint dot only", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1."}`, true},
+		{"mint dot prefix", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":".5"}`, true},
+		{"mint duplicate key", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1","amt":"1000"}`, true},
+		{"mint unknown field", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1","to":"0x01"}`, true},
+		{"mint key case", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","AMT":"1"}`, true},
+		{"mint amt missing", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi"}`, true},
+		{"mint tick not string", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":1234,"amt":"1"}`, true},
+		{"mint nested object", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":{"v":"1"}}`, true},
+		{"mint trailing data", BRC20JSONProfile, "mint", `{"p":"brc-20","op":"mint","tick":"ordi","amt":"1"}{}`, true},
+		{"mint array", BRC20JSONProfile, "mint", `[{"p":"brc-20","op":"mint","tick":"ordi","amt":"1"}]`, true},
+		{"transfer", BRC20JSONProfile, "transfer", `{"p":"brc-20","op":"transfer","tick":"ordi","amt":"100"}`, false},
+		{"transfer hash", HashTransferJSONProfile, "transfer", `{"p":"brc-20","op":"transfer","tick":"ordi","hash":"0x01"}`, false},
+		{"transfer hash not extended", BRC20JSONProfile, "transfer", `{"p":"brc-20","op":"transfer","tick":"ordi","hash":"0x01"}`, true},
+		{"op unsupported", BRC20JSONProfile, "list", `{"p":"brc-20","op":"list","tick":"ordi","amt":"1"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.Validate(&devents.MetaData{Operate: tt.op, Data: tt.data})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestJSONProfileExtend(t *testing.T) {
+	profile := BRC20JSONProfile.Extend(map[string]map[string]FieldRule{
+		devents.OperateList: {"amt": {Kind: FieldNumber, Required: true}},
+	})
+	assert.Contains(t, profile.Ops, devents.OperateList)
+	assert.Equal(t, FieldRule{Kind: FieldNumber}, HashTransferJSONProfile.Ops[devents.OperateTransfer]["amt"])
+
+	// the extended profile is a copy
+	assert.NotContains(t, BRC20JSONProfile.Ops, devents.OperateList)
+	assert.NotContains(t, BRC20JSONProfile.Ops[devents.OperateTransfer], "hash")
+	assert.True(t, BRC20JSONProfile.Ops[devents.OperateTransfer]["amt"].Required)
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/devents"
+	"sort"
+	"strings"
+)
+
+// Rules validation rules of the protocol in force from the activation height
+type Rules struct {
+	Height          uint64
+	MaxPayloadSize  int // max bytes of the inscription envelope as inscribed, before decoding, 0 unlimited
+	DefaultDecimals int8
+	MaxDecimals     int8
+	StrictDecimals  bool
+	HashTransfer    bool
+	TickRule        *TickRule
+	StrictJSON      bool         // the inscriptions must match the json profile
+	JSONProfile     *JSONProfile // json shapes of the protocol inscriptions
+	RFC2397         bool         // the data uri schemes are case insensitive & the data percent decoded
+}
+
+// BRC20Rules canonical brc-20 rules
+var BRC20Rules = &Rules{
+	DefaultDecimals: DefaultDecimals,
+	MaxDecimals:     18,
+	StrictDecimals:  true,
+	TickRule:        BRC20TickRule,
+	StrictJSON:      true,
+	JSONProfile:     BRC20JSONProfile,
+}
+
+// DefaultRules rules of the brc-20 compatible protocols as the indexed history was built: the payload
+// is limited to 256 bytes, the decimals stay unchecked & the ticks deployed without dec record 0 decimals,
+// the ticks are trimmed & folded to lower case only, the json is decoded leniently & the data uris are
+// neither percent decoded nor matched case insensitively. The strict decimals, the default decimals of 18,
+// the unicode tick rule, the strict json & the RFC 2397 data uris are activated by the protocol_rules
+// of the chain config
+var DefaultRules = &Rules{
+	MaxPayloadSize:  256,
+	DefaultDecimals: LegacyDefaultDecimals,
+	MaxDecimals:     18,
+	StrictDecimals:  false,
+	HashTransfer:    true,
+	TickRule:        LegacyTickRule,
+	StrictJSON:      false,
+	JSONProfile:     HashTransferJSONProfile,
+}
+
+// WithJSONProfile returns a copy of the rules checking the json shapes of the profile
+func (r *Rules) WithJSONProfile(profile *JSONProfile) *Rules {
+	rules := *r
+	rules.JSONProfile = profile
+	return &rules
+}
+
+// Schedule rules of the protocol ordered by the activation heights, re-indexing the history
+// applies the rules in force at the heights of the txs
+type Schedule struct {
+	protocol string
+	rules    []*Rules
+}
+
+// NewSchedule activates the configured rule changes of the protocol on top of the base rules
+func NewSchedule(base *Rules, protocol string, changes []*config.ProtocolRules) (*Schedule, error) {
+	matched := make([]*config.ProtocolRules, 0, len(changes))
+	for _, change := range changes {
+		if normalizeProtocol(change.Protocol) == normalizeProtocol(protocol) {
+			matched = append(matched, change)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Height < matched[j].Height
+	})
+
+	s := BaseSchedule(base, protocol)
+	for _, change := range matched {
+		rules, err := s.rules[len(s.rules)-1].apply(change)
+		if err != nil {
+			return nil, fmt.Errorf("protocol[%s] rules of height[%d] invalid: %v", protocol, change.Height, err)
+		}
+		if rules.Height == s.rules[len(s.rules)-1].Height {
+			s.rules[len(s.rules)-1] = rules
+			continue
+		}
+		s.rules = append(s.rules, rules)
+	}
+	return s, nil
+}
+
+// BaseSchedule schedule of the base rules of the protocol in force from the height 0 without changes
+func BaseSchedule(base *Rules, protocol string) *Schedule {
+	first := *base
+	first.Height = 0
+	return &Schedule{protocol: normalizeProtocol(protocol), rules: []*Rules{&first}}
+}
+
+func normalizeProtocol(protocol string) string {
+	return strings.ToLower(strings.TrimSpace(protocol))
+}
+
+// At returns the rules in force at the height
+func (s *Schedule) At(height uint64) *Rules {
+	idx := sort.Search(len(s.rules), func(i int) bool {
+		return s.rules[i].Height > height
+	})
+	return s.rules[idx-1]
+}
+
+// apply returns the rules changed from the activation height
+func (r *Rules) apply(change *config.ProtocolRules) (*Rules, error) {
+	rules := *r
+	rules.Height = change.Height
+	if change.MaxPayloadSize != nil {
+		rules.MaxPayloadSize = *change.MaxPayloadSize
+	}
+	if change.DefaultDecimals != nil {
+		rules.DefaultDecimals = *change.DefaultDecimals
+	}
+	if change.MaxDecimals != nil {
+		rules.MaxDecimals = *change.MaxDecimals
+	}
+	if change.StrictDecimals != nil {
+		rules.StrictDecimals = *change.StrictDecimals
+	}
+	if change.HashTransfer != nil {
+		rules.HashTransfer = *change.HashTransfer
+	}
+	if change.StrictJSON != nil {
+		rules.StrictJSON = *change.StrictJSON
+	}
+	if change.RFC2397 != nil {
+		rules.RFC2397 = *change.RFC2397
+	}
+	if change.TickRule != nil {
+		tickRule, ok := TickRules[strings.ToLower(strings.TrimSpace(*change.TickRule))]
+		if !ok {
+			return nil, fmt.Errorf("tick rule[%s] unknown", *change.TickRule)
+		}
+		rules.TickRule = tickRule
+	}
+
+	if change.TickMinBytes != nil || change.TickMaxBytes != nil {
+		tickRule := *rules.TickRule
+		if change.TickMinBytes != nil {
+			tickRule.MinBytes = *change.TickMinBytes
+		}
+		if change.TickMaxBytes != nil {
+			tickRule.MaxBytes = *change.TickMaxBytes
+		}
+		rules.TickRule = &tickRule
+	}
+	return &rules, nil
+}
+
+// TxHeight block height of the tx, 0 if unknown
+func TxHeight(tx *xycommon.RpcTransaction) uint64 {
+	if tx.BlockNumber == nil {
+		return 0
+	}
+	return tx.BlockNumber.Uint64()
+}
+
+// NormalizeMetaData checks the envelope size & the json shapes, then normalizes the tick by the rules
+// in force at the tx height
+func (base *Protocol) NormalizeMetaData(tx *xycommon.RpcTransaction, md *devents.MetaData) error {
+	rules := base.schedule.At(TxHeight(tx))
+	if err := pickDataURIMetaData(rules, md); err != nil {
+		return err
+	}
+
+	if rules.MaxPayloadSize > 0 && md.Size > rules.MaxPayloadSize {
+		return fmt.Errorf("data character size[%d] > %d", md.Size, rules.MaxPayloadSize)
+	}
+
+	if rules.StrictJSON && rules.JSONProfile != nil {
+		if err := rules.JSONProfile.Validate(md); err != nil {
+			return fmt.Errorf("json profile checking failed: %v", err)
+		}
+	}
+	md.Tick = rules.TickRule.Normalize(md.Tick)
+	return nil
+}
+
+// pickDataURIMetaData keeps the metadata decoded by the data uri rules in force
+func pickDataURIMetaData(rules *Rules, md *devents.MetaData) error {
+	legacy := md.Legacy
+	if legacy == nil {
+		return nil
+	}
+	md.Legacy = nil
+
+	if rules.RFC2397 {
+		if md.Data == "" {
+			return fmt.Errorf("data uri rejected by RFC 2397")
+		}
+		return nil
+	}
+
+	if legacy.Protocol != md.Protocol {
+		return fmt.Errorf("data uri rejected by the legacy rules")
+	}
+	md.Operate, md.Tick, md.Data, md.Size = legacy.Operate, legacy.Tick, legacy.Data, legacy.Size
+	return nil
+}
+
+// NormalizeTick returns the tick identity of the tick by the tick rule in force at the height
+func (base *Protocol) NormalizeTick(height uint64, tick string) string {
+	return base.schedule.At(height).TickRule.Normalize(tick)
+}
+
+// VerifyStart checks the indexed ticks keep their identities under the tick rules in force from the
+// start block, the ticks renamed by a later tick rule would fork from their indexed history
+func (base *Protocol) VerifyStart(startBlock uint64) error {
+	if base.cache == nil || base.cache.Inscription == nil {
+		return nil
+	}
+
+	ticks := base.cache.Inscription.Ticks(base.schedule.protocol)
+	for i, rules := range base.schedule.rules {
+		// the rules replaced before the start block are not applied anymore
+		if i+1 < len(base.schedule.rules) && base.schedule.rules[i+1].Height <= startBlock {
+			continue
+		}
+
+		for _, tick := range ticks {
+			if normalized := rules.TickRule.Normalize(tick); normalized != tick {
+				return fmt.Errorf("indexed tick[%s] renamed to [%s] by the tick rule from height[%d]", tick, normalized, rules.Height)
+			}
+		}
+	}
+	return nil
+}
+
+// Rules returns the rules in force at the tx height
+func (base *Protocol) Rules(tx *xycommon.RpcTransaction) *Rules {
+	return base.schedule.At(TxHeight(tx))
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"math/big"
+	"testing"
+)
+
+func TestScheduleAt(t *testing.T) {
+	var (
+		size    = 512
+		strict  = true
+		hash    = false
+		maxLen  = 5
+		unicode = "Unicode"
+	)
+	s, err := NewSchedule(DefaultRules, "brc-20", []*config.ProtocolRules{
+		{Protocol: "brc-20", Height: 200, StrictDecimals: &strict},
+		{Protocol: "BRC-20", Height: 100, MaxPayloadSize: &size},
+		{Protocol: "brc-20", Height: 200, HashTransfer: &hash, TickRule: &unicode, TickMaxBytes: &maxLen},
+		{Protocol: "bsc-20", Height: 50, MaxPayloadSize: &maxLen},
+	})
+	assert.NoError(t, err)
+
+	// the legacy rules are in force before the first change
+	rules := s.At(0)
+	assert.Equal(t, 256, rules.MaxPayloadSize)
+	assert.Equal(t, LegacyTickRule, rules.TickRule)
+	assert.False(t, rules.StrictJSON)
+
+	rules = s.At(99)
+	assert.Equal(t, 256, rules.MaxPayloadSize)
+
+	rules = s.At(100)
+	assert.Equal(t, uint64(100), rules.Height)
+	assert.Equal(t, 512, rules.MaxPayloadSize)
+	assert.False(t, rules.StrictDecimals)
+
+	// the changes of the same height are merged & the earlier changes kept
+	for _, height := range []uint64{200, 1000} {
+		rules = s.At(height)
+		assert.Equal(t, uint64(200), rules.Height)
+		assert.Equal(t, 512, rules.MaxPayloadSize)
+		assert.True(t, rules.StrictDecimals)
+		assert.False(t, rules.HashTransfer)
+		assert.Equal(t, 5, rules.TickRule.MaxBytes)
+		assert.Equal(t, UnicodeTickRule.Normalization, rules.TickRule.Normalization)
+	}
+
+	// the base rules are not changed
+	assert.Equal(t, 256, DefaultRules.MaxPayloadSize)
+	assert.Equal(t, 32, UnicodeTickRule.MaxBytes)
+	assert.Len(t, BaseSchedule(DefaultRules, "asc-20").rules, 1)
+
+	unknown := "nfd"
+	_, err = NewSchedule(DefaultRules, "brc-20", []*config.ProtocolRules{{Protocol: "brc-20", Height: 1, TickRule: &unknown}})
+	assert.Error(t, err)
+}
+
+func TestNormalizeMetaData(t *testing.T) {
+	var (
+		size     = 0
+		strict   = true
+		tickRule = "unicode"
+	)
+	s, err := NewSchedule(DefaultRules, "brc-20", []*config.ProtocolRules{
+		{Protocol: "brc-20", Height: 100, MaxPayloadSize: &size},
+		{Protocol: "brc-20", Height: 200, StrictJSON: &strict, TickRule: &tickRule},
+	})
+	assert.NoError(t, err)
+	p := NewProtocol(nil, s)
+	at := func(height int64) *xycommon.RpcTransaction {
+		return &xycommon.RpcTransaction{BlockNumber: big.NewInt(height)}
+	}
+
+	md := &devents.MetaData{Operate: devents.OperateMint, Tick: " ＯＲＤＩ ", Size: 300, Data: `{"p":"brc-20","op":"mint","tick":"ＯＲＤＩ","amt":1}`}
+	assert.Error(t, p.NormalizeMetaData(at(99), md))
+
+	// unlimited from the height 100, the legacy ticks are trimmed & folded to lower case only
+	assert.NoError(t, p.NormalizeMetaData(at(100), md))
+	assert.Equal(t, "ｏｒｄｉ", md.Tick)
+
+	// the strict json & the unicode ticks from the height 200
+	md.Tick = " ＯＲＤＩ "
+	assert.Error(t, p.NormalizeMetaData(at(200), md))
+
+	md.Data = `{"p":"brc-20","op":"mint","tick":"ＯＲＤＩ","amt":"1"}`
+	assert.NoError(t, p.NormalizeMetaData(at(200), md))
+	assert.Equal(t, "ordi", md.Tick)
+}
+
+func TestVerifyStart(t *testing.T) {
+	tickRule := "unicode"
+	s, err := NewSchedule(DefaultRules, "brc-20", []*config.ProtocolRules{
+		{Protocol: "brc-20", Height: 100, TickRule: &tickRule},
+		{Protocol: "brc-20", Height: 200, TickRule: &tickRule},
+	})
+	assert.NoError(t, err)
+
+	cache := &dcache.Manager{Inscription: dcache.NewInscription()}
+	cache.Inscription.Create("brc-20", "ordi", &dcache.Tick{})
+	cache.Inscription.Create("asc-20", "ＡＶＡＸ", &dcache.Tick{})
+	p := NewProtocol(cache, s)
+	assert.NoError(t, p.VerifyStart(0))
+	assert.Equal(t, "ordi", p.NormalizeTick(0, " ORDI "))
+
+	// the legacy full width ticks fork from their history under the unicode rule
+	cache.Inscription.Create("brc-20", "ｏｒｄｉ", &dcache.Tick{})
+	assert.Equal(t, []string{"ordi", "ｏｒｄｉ"}, cache.Inscription.Ticks("BRC-20"))
+	assert.Equal(t, "ｏｒｄｉ", p.NormalizeTick(99, "ＯＲＤＩ"))
+	assert.Equal(t, "ordi", p.NormalizeTick(100, "ＯＲＤＩ"))
+	assert.Error(t, p.VerifyStart(0))
+	assert.Error(t, p.VerifyStart(150))
+
+	// the full width ticks keep their identities under the legacy rule
+	assert.NoError(t, NewProtocol(cache, BaseSchedule(DefaultRules, "brc-20")).VerifyStart(0))
+}
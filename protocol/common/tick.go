@@ -0,0 +1,218 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"fmt"
+	"github.com/uxuycom/indexer/xyerrors"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TickNormalization int8
+
+const (
+	TickNormNone TickNormalization = iota
+	TickNormNFC
+	TickNormNFKC // compatibility forms like the fullwidth letters fold to the plain ones
+)
+
+// TickClass character classes allowed in the ticks, spaces, control & private use characters are never allowed
+type TickClass uint8
+
+const (
+	TickClassLetter TickClass = 1 << iota
+	TickClassDigit
+	TickClassMark
+	TickClassPunct
+	TickClassSymbol // symbols & the emoji, the emoji joiner & tag characters included
+
+	TickClassAll = TickClassLetter | TickClassDigit | TickClassMark | TickClassPunct | TickClassSymbol
+)
+
+// TickRule naming rules of the protocol ticks, the ticks are normalized before any lookup & the deploys
+// of the ticks breaking the rules are rejected
+type TickRule struct {
+	MinBytes      int // utf-8 bytes of the normalized tick, 0 unlimited
+	MaxBytes      int
+	Classes       TickClass
+	Normalization TickNormalization
+
+	// CaseSensitive ticks are not folded to lower case, as ticks are indexed in lower case
+	// the ticks with upper case letters are rejected instead of aliasing the lower case ones
+	CaseSensitive bool
+
+	// SingleScript rejects the ticks mixing the letters of the scripts, like the latin & cyrillic
+	// confusables, the common han / kana / hangul combinations are allowed
+	SingleScript bool
+
+	// AnyCharacters only the tick size is checked, the classes & scripts are not
+	AnyCharacters bool
+}
+
+// LegacyTickRule ticks as indexed before the tick rules, trimmed & folded to lower case only
+var LegacyTickRule = &TickRule{
+	MinBytes:      1,
+	AnyCharacters: true,
+}
+
+// BRC20TickRule canonical brc-20 ticks of 4 bytes, compared case-insensitively without normalization
+var BRC20TickRule = &TickRule{
+	MinBytes: 4,
+	MaxBytes: 4,
+	Classes:  TickClassAll,
+}
+
+// UnicodeTickRule unicode ticks of the brc-20 compatible protocols, compatibility forms are folded
+// & confusable script mixes rejected, activated by the tick_rule of the protocol_rules
+var UnicodeTickRule = &TickRule{
+	MinBytes:      1,
+	MaxBytes:      32,
+	Classes:       TickClassAll,
+	Normalization: TickNormNFKC,
+	SingleScript:  true,
+}
+
+// TickRules tick rules by the tick_rule names of the protocol_rules
+var TickRules = map[string]*TickRule{
+	"legacy":  LegacyTickRule,
+	"brc-20":  BRC20TickRule,
+	"unicode": UnicodeTickRule,
+}
+
+// compatible script sets of the mixed script ticks, UTS #39 highly restrictive level
+var compatibleScripts = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Hangul"},
+	{"Latin", "Han", "Bopomofo"},
+}
+
+// Normalize returns the tick identity of the tick written in the inscription
+func (r *TickRule) Normalize(tick string) string {
+	tick = r.normalize(strings.TrimSpace(tick))
+	if r.CaseSensitive {
+		return tick
+	}
+	return r.normalize(strings.ToLower(tick))
+}
+
+func (r *TickRule) normalize(tick string) string {
+	switch r.Normalization {
+	case TickNormNFC:
+		return norm.NFC.String(tick)
+	case TickNormNFKC:
+		return norm.NFKC.String(tick)
+	}
+	return tick
+}
+
+// Verify checks the normalized tick against the rule
+func (r *TickRule) Verify(tick string) *xyerrors.InsError {
+	if !utf8.ValidString(tick) {
+		return xyerrors.NewInsError(-23, fmt.Sprintf("tick[%q] invalid utf-8", tick))
+	}
+
+	if size := len(tick); size < r.MinBytes || (r.MaxBytes > 0 && size > r.MaxBytes) {
+		return xyerrors.NewInsError(-22, fmt.Sprintf("tick[%s] bytes[%d] out of range[%d, %d]", tick, size, r.MinBytes, r.MaxBytes))
+	}
+
+	if r.AnyCharacters {
+		return nil
+	}
+
+	for _, c := range tick {
+		if class := tickClass(c); class == 0 || r.Classes&class == 0 {
+			return xyerrors.NewInsError(-23, fmt.Sprintf("tick[%s] character[%U] not allowed", tick, c))
+		}
+	}
+
+	if r.SingleScript {
+		if scripts := tickScripts(tick); !singleScript(scripts) {
+			return xyerrors.NewInsError(-24, fmt.Sprintf("tick[%s] mixes scripts%v", tick, scripts))
+		}
+	}
+
+	if r.CaseSensitive && tick != strings.ToLower(tick) {
+		return xyerrors.NewInsError(-25, fmt.Sprintf("tick[%s] upper case letters not allowed", tick))
+	}
+	return nil
+}
+
+func tickClass(c rune) TickClass {
+	switch {
+	case unicode.IsLetter(c):
+		return TickClassLetter
+	case unicode.IsNumber(c):
+		return TickClassDigit
+	case unicode.IsMark(c):
+		return TickClassMark
+	case unicode.IsPunct(c):
+		return TickClassPunct
+	case unicode.IsSymbol(c), c == '\u200d', c >= '\U000e0020' && c <= '\U000e007f':
+		return TickClassSymbol
+	}
+	return 0
+}
+
+// tickScripts returns the scripts of the tick letters, the common & inherited characters are skipped
+func tickScripts(tick string) []string {
+	scripts := make([]string, 0, 1)
+	seen := make(map[string]struct{})
+	for _, c := range tick {
+		for name, table := range unicode.Scripts {
+			if name == "Common" || name == "Inherited" || !unicode.Is(table, c) {
+				continue
+			}
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				scripts = append(scripts, name)
+			}
+			break
+		}
+	}
+	return scripts
+}
+
+func singleScript(scripts []string) bool {
+	if len(scripts) <= 1 {
+		return true
+	}
+
+	for _, set := range compatibleScripts {
+		matched := 0
+		for _, script := range scripts {
+			for _, name := range set {
+				if script == name {
+					matched++
+					break
+				}
+			}
+		}
+		if matched == len(scripts) {
+			return true
+		}
+	}
+	return false
+}
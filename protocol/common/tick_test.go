@@ -0,0 +1,101 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTickRuleNormalize(t *testing.T) {
+	tests := []struct {
+		rule *TickRule
+		tick string
+		want string
+	}{
+		{UnicodeTickRule, " ORDI ", "ordi"},
+		{UnicodeTickRule, "ｏｒｄｉ", "ordi"},
+		{UnicodeTickRule, "ＯＲＤＩ", "ordi"},
+		{UnicodeTickRule, "ﬁre", "fire"},
+		{UnicodeTickRule, "café", "café"},
+		{BRC20TickRule, "ORDI", "ordi"},
+		{BRC20TickRule, "ｏｒｄｉ", "ｏｒｄｉ"},
+		{LegacyTickRule, " ＯＲＤＩ ", "ｏｒｄｉ"},
+		{&TickRule{Normalization: TickNormNFC}, "ﬁre", "ﬁre"},
+		{&TickRule{Normalization: TickNormNFC, CaseSensitive: true}, "Café", "Café"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.rule.Normalize(tt.tick), tt.tick)
+	}
+}
+
+func TestTickRuleVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *TickRule
+		tick string
+		code int // 0 valid
+	}{
+		{"legacy", LegacyTickRule, "or di", 0},
+		{"legacy long", LegacyTickRule, "abcdefghijklmnopqrstuvwxyz0123456", 0},
+		{"legacy empty", LegacyTickRule, "", -22},
+		{"brc-20 4 bytes", BRC20TickRule, "ordi", 0},
+		{"brc-20 emoji", BRC20TickRule, "🔥", 0},
+		{"brc-20 3 bytes", BRC20TickRule, "ord", -22},
+		{"brc-20 5 bytes", BRC20TickRule, "ordis", -22},
+		{"brc-20 latin cyrillic", BRC20TickRule, "оrd", 0},
+		{"default", UnicodeTickRule, "dino", 0},
+		{"default empty", UnicodeTickRule, "", -22},
+		{"default too long", UnicodeTickRule, "abcdefghijklmnopqrstuvwxyz0123456", -22},
+		{"default digits", UnicodeTickRule, "1234", 0},
+		{"default punct", UnicodeTickRule, "a-b.c", 0},
+		{"default emoji sequence", UnicodeTickRule, "👨\u200d👩\u200d👧", 0},
+		{"default flag tags", UnicodeTickRule, "🏴\U000e0067\U000e0062\U000e007f", 0},
+		{"default space", UnicodeTickRule, "or di", -23},
+		{"default control", UnicodeTickRule, "or\tdi", -23},
+		{"default zero width", UnicodeTickRule, "or\u200bdi", -23},
+		{"default private use", UnicodeTickRule, "ord\ue000", -23},
+		{"default invalid utf-8", UnicodeTickRule, "ord\xff", -23},
+		{"default cyrillic", UnicodeTickRule, "орди", 0},
+		{"default latin cyrillic", UnicodeTickRule, "оrdi", -24},
+		{"default latin greek", UnicodeTickRule, "οrdi", -24},
+		{"default japanese", UnicodeTickRule, "漢字かなカナ", 0},
+		{"default korean latin", UnicodeTickRule, "한국abc", 0},
+		{"default hangul kana", UnicodeTickRule, "한かな", -24},
+		{"letters only", &TickRule{Classes: TickClassLetter}, "ord1", -23},
+		{"case sensitive lower", &TickRule{Classes: TickClassAll, CaseSensitive: true}, "ordi", 0},
+		{"case sensitive upper", &TickRule{Classes: TickClassAll, CaseSensitive: true}, "Ordi", -25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Verify(tt.tick)
+			if tt.code == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.NotNil(t, err) {
+				assert.Equal(t, tt.code, err.Code())
+			}
+		})
+	}
+}
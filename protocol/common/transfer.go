@@ -27,16 +27,20 @@ import (
 	"fmt"
 	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
 )
 
 type Transfer struct {
 	Amount decimal.Decimal `json:"amt"`
+	Hash   string          `json:"hash"` // utxo root hash transferred, hash transfer type only
 }
 
 func (base *Protocol) Transfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
-	tf, err := base.verifyTransfer(tx, md)
+	tf, utxo, err := base.verifyTransfer(tx, md)
 	if err != nil {
 		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
 	}
@@ -54,18 +58,21 @@ func (base *Protocol) Transfer(block *xycommon.RpcBlock, tx *xycommon.RpcTransac
 			},
 		},
 	}
+
+	// hash transfer spends the utxo & moves its ownership to the receiver
+	if utxo != nil {
+		result.Transfer.RootHash = tf.Hash
+		result.Transfer.SN = utxo.SN
+		result.Transfer.Receives[0].SN = tx.Hash
+	}
 	return []*devents.TxResult{result}, nil
 }
 
-func (base *Protocol) verifyTransfer(tx *xycommon.RpcTransaction, md *devents.MetaData) (*Transfer, *xyerrors.InsError) {
+func (base *Protocol) verifyTransfer(tx *xycommon.RpcTransaction, md *devents.MetaData) (*Transfer, *dcache.UTXOItem, *xyerrors.InsError) {
 	tf := &Transfer{}
 	err := json.Unmarshal([]byte(md.Data), tf)
 	if err != nil {
-		return nil, xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
-	}
-
-	if tf.Amount.LessThanOrEqual(decimal.Zero) {
-		return nil, xyerrors.NewInsError(-14, "transfer amount <= 0")
+		return nil, nil, xyerrors.NewInsError(-13, fmt.Sprintf("data json deocde err:%v, data[%s]", err, md.Data))
 	}
 
 	var (
@@ -74,18 +81,54 @@ func (base *Protocol) verifyTransfer(tx *xycommon.RpcTransaction, md *devents.Me
 	)
 	ok, inscription := base.cache.Inscription.Get(protocol, tick)
 	if !ok || inscription == nil {
-		return nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", protocol, tick))
+		return nil, nil, xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", protocol, tick))
+	}
+
+	if inscription.TransferType == model.TransferTypeHash {
+		return base.verifyHashTransfer(tx, md, tf)
+	}
+
+	if tf.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, nil, xyerrors.NewInsError(-14, "transfer amount <= 0")
+	}
+
+	if inscription.StrictDecimals && !ValidDecimals(tf.Amount, inscription.Decimals) {
+		return nil, nil, xyerrors.NewInsError(-34, fmt.Sprintf("transfer amount[%v] exceeds decimals[%d]", tf.Amount, inscription.Decimals))
 	}
 
 	// sender balance checking
 	ok, balance := base.cache.Balance.Get(protocol, tick, tx.From)
 	if !ok {
-		return nil, xyerrors.NewInsError(-16, fmt.Sprintf("sender balance record not exist, tick[%s-%s], address[%s]", protocol, tick, tx.From))
+		return nil, nil, xyerrors.NewInsError(-16, fmt.Sprintf("sender balance record not exist, tick[%s-%s], address[%s]", protocol, tick, tx.From))
+	}
+
+	// balance available checking, the escrowed balance of the open orders is not available
+	if balance.Available.LessThan(tf.Amount) {
+		return nil, nil, xyerrors.NewInsError(-17, fmt.Sprintf("sender available balance[%v] < transfer amount[%v]", balance.Available, tf.Amount))
+	}
+	return tf, nil, nil
+}
+
+// verifyHashTransfer checks the referenced utxo is unspent & owned by the sender, the whole utxo amount is transferred
+func (base *Protocol) verifyHashTransfer(tx *xycommon.RpcTransaction, md *devents.MetaData, tf *Transfer) (*Transfer, *dcache.UTXOItem, *xyerrors.InsError) {
+	tf.Hash = strings.ToLower(tf.Hash)
+	if tf.Hash == "" {
+		return nil, nil, xyerrors.NewInsError(-18, fmt.Sprintf("transfer hash nil, tick[%s-%s] transfers by hash", md.Protocol, md.Tick))
+	}
+
+	ok, utxo := base.cache.UTXO.Get(tf.Hash)
+	if !ok || !strings.EqualFold(utxo.Protocol, md.Protocol) || !strings.EqualFold(utxo.Tick, md.Tick) {
+		return nil, nil, xyerrors.NewInsError(-19, fmt.Sprintf("utxo not exist or spent, tick[%s-%s], hash[%s]", md.Protocol, md.Tick, tf.Hash))
+	}
+
+	if !strings.EqualFold(utxo.Owner, tx.From) {
+		return nil, nil, xyerrors.NewInsError(-37, fmt.Sprintf("utxo owner[%s] <> sender[%s], hash[%s]", utxo.Owner, tx.From, tf.Hash))
 	}
 
-	// balance available checking
-	if balance.Overall.LessThan(tf.Amount) {
-		return nil, xyerrors.NewInsError(-17, fmt.Sprintf("sender total balance[%v] < transfer amount[%v]", balance.Overall, tf.Amount))
+	// amount is optional, it must match the utxo if set
+	if tf.Amount.GreaterThan(decimal.Zero) && !tf.Amount.Equal(utxo.Amount) {
+		return nil, nil, xyerrors.NewInsError(-14, fmt.Sprintf("transfer amount[%v] <> utxo amount[%v]", tf.Amount, utxo.Amount))
 	}
-	return tf, nil
+	tf.Amount = utxo.Amount
+	return tf, utxo, nil
 }
@@ -0,0 +1,141 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package common
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"testing"
+)
+
+func TestVerifyTransfer(t *testing.T) {
+	p, cache := newTestProtocol(DefaultRules)
+	cache.Inscription.Create("brc-20", "ordi", &dcache.Tick{Decimals: 2, StrictDecimals: true})
+	cache.Inscription.Create("brc-20", "legacy", &dcache.Tick{Decimals: 2})
+
+	// the escrowed balance of the open orders is not available
+	for _, tick := range []string{"ordi", "legacy"} {
+		cache.Balance.Create("brc-20", tick, testAddrA, &dcache.BalanceItem{
+			Available: decimal.NewFromInt(100),
+			Overall:   decimal.NewFromInt(150),
+		})
+	}
+
+	tests := []struct {
+		name   string
+		tick   string
+		from   string
+		data   string
+		code   int // 0 valid
+		amount string
+	}{
+		{"transfer", "ordi", testAddrA, `{"amt":"100"}`, 0, "100"},
+		{"json invalid", "ordi", testAddrA, `{"amt":100`, -13, ""},
+		{"not deployed", "none", testAddrA, `{"amt":"100"}`, -15, ""},
+		{"amount zero", "ordi", testAddrA, `{"amt":"0"}`, -14, ""},
+		{"exceeds decimals", "ordi", testAddrA, `{"amt":"0.005"}`, -34, ""},
+		{"exceeds decimals legacy", "legacy", testAddrA, `{"amt":"0.005"}`, 0, "0.005"},
+		{"balance not exist", "ordi", testAddrB, `{"amt":"100"}`, -16, ""},
+		{"exceeds available balance", "ordi", testAddrA, `{"amt":"120"}`, -17, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &xycommon.RpcTransaction{From: tt.from, To: testAddrB}
+			md := &devents.MetaData{Protocol: "brc-20", Tick: tt.tick, Data: tt.data}
+			tf, utxo, err := p.verifyTransfer(tx, md)
+			if tt.code != 0 {
+				if assert.NotNil(t, err) {
+					assert.Equal(t, tt.code, err.Code(), err.Error())
+				}
+				return
+			}
+
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.amount, tf.Amount.String())
+				assert.Nil(t, utxo)
+			}
+		})
+	}
+}
+
+func TestVerifyHashTransfer(t *testing.T) {
+	p, cache := newTestProtocol(DefaultRules)
+	cache.Inscription.Create("brc-20", "hash", &dcache.Tick{TransferType: model.TransferTypeHash})
+	cache.Inscription.Create("brc-20", "ordi", &dcache.Tick{TransferType: model.TransferTypeHash})
+	cache.UTXO.Add("brc-20", "hash", "0xaa", testAddrA, decimal.NewFromInt(100), "0x0a")
+	cache.UTXO.Add("brc-20", "ordi", "0xbb", testAddrA, decimal.NewFromInt(100), "0x0b")
+
+	tests := []struct {
+		name string
+		from string
+		data string
+		code int // 0 valid
+	}{
+		{"transfer", testAddrA, `{"hash":"0xaa"}`, 0},
+		{"transfer hash case", testAddrA, `{"hash":"0xAA"}`, 0},
+		{"transfer with amount", testAddrA, `{"hash":"0xaa","amt":"100"}`, 0},
+		{"hash nil", testAddrA, `{"amt":"100"}`, -18},
+		{"utxo not exist", testAddrA, `{"hash":"0xcc"}`, -19},
+		{"utxo of other tick", testAddrA, `{"hash":"0xbb"}`, -19},
+		{"utxo not owned", testAddrB, `{"hash":"0xaa"}`, -37},
+		{"amount mismatch", testAddrA, `{"hash":"0xaa","amt":"50"}`, -14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &xycommon.RpcTransaction{Hash: "0x02", From: tt.from, To: testAddrB}
+			md := &devents.MetaData{Protocol: "brc-20", Tick: "hash", Data: tt.data}
+			tf, utxo, err := p.verifyTransfer(tx, md)
+			if tt.code != 0 {
+				if assert.NotNil(t, err) {
+					assert.Equal(t, tt.code, err.Code(), err.Error())
+				}
+				return
+			}
+
+			// the whole utxo amount is transferred
+			if assert.Nil(t, err) && assert.NotNil(t, utxo) {
+				assert.Equal(t, "0xaa", tf.Hash)
+				assert.Equal(t, "100", tf.Amount.String())
+				assert.Equal(t, "0x0a", utxo.SN)
+			}
+		})
+	}
+
+	results, err := p.Transfer(&xycommon.RpcBlock{}, &xycommon.RpcTransaction{Hash: "0x02", From: testAddrA, To: testAddrB}, &devents.MetaData{
+		Protocol: "brc-20",
+		Tick:     "hash",
+		Data:     `{"hash":"0xaa"}`,
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, results, 1) {
+		tf := results[0].Transfer
+		assert.Equal(t, "0xaa", tf.RootHash)
+		assert.Equal(t, "0x0a", tf.SN)
+		assert.Equal(t, "0x02", tf.Receives[0].SN)
+		assert.Equal(t, testAddrB, tf.Receives[0].Address)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package cia20
+
+import (
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/protocol/types"
+)
+
+// Protocol of the memo inscriptions on cosmos chains, sharing the brc-20 rules with the bech32 addresses,
+// the sender & the receiver are the ones of the bank send carrying the memo
+type Protocol struct {
+	*common.Protocol
+}
+
+func NewProtocol(cache *dcache.Manager, schedule *common.Schedule) *Protocol {
+	return &Protocol{
+		Protocol: common.NewProtocol(cache, schedule),
+	}
+}
+
+func init() {
+	registry.MustRegister(registry.Entry{
+		ChainGroup: model.CosmosChainGroup,
+		Protocol:   types.CIA20Protocol,
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			schedule, err := common.NewSchedule(common.DefaultRules, types.CIA20Protocol, cfg.Chain.ProtocolRules)
+			if err != nil {
+				return nil, err
+			}
+			return NewProtocol(cache, schedule), nil
+		},
+	})
+}
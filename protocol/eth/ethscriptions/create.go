@@ -0,0 +1,58 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+// ruleESIP6 content uri parameter allowing duplicated contents
+const ruleESIP6 = "rule=esip6"
+
+// create verifies the ethscription created by the tx input, the content uri must be unique unless esip-6 is applied
+func (p *Protocol) create(tx *xycommon.RpcTransaction) (*devents.Ethscription, *xyerrors.InsError) {
+	uri, ok := contentURI(tx)
+	if !ok {
+		return nil, xyerrors.NewInsError(-12, fmt.Sprintf("tx input is not a data uri, tx[%s]", tx.Hash))
+	}
+
+	contentSha := fmt.Sprintf("0x%x", sha256.Sum256([]byte(uri.URI)))
+	if !uri.HasParam(ruleESIP6) && p.cache.Ethscription.ContentExists(contentSha) {
+		return nil, xyerrors.NewInsError(-13, fmt.Sprintf("ethscription content duplicated, sha[%s]", contentSha))
+	}
+
+	return &devents.Ethscription{
+		ID:         strings.ToLower(tx.Hash),
+		From:       strings.ToLower(tx.From),
+		To:         strings.ToLower(tx.To),
+		Create:     true,
+		ContentSha: contentSha,
+		MimeType:   uri.MimeType(),
+		LogIndex:   -1,
+	}, nil
+}
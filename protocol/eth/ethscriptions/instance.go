@@ -0,0 +1,142 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+)
+
+type Protocol struct {
+	cache *dcache.Manager
+}
+
+func NewProtocol(cache *dcache.Manager) *Protocol {
+	return &Protocol{
+		cache: cache,
+	}
+}
+
+// MatchTx matches the calldata transfers of 32 bytes ids, the creations are matched by the group data uri
+// & the esip transfers by their events
+func (p *Protocol) MatchTx(tx *xycommon.RpcTransaction) bool {
+	return !xycommon.IsDerivedTx(tx.Hash) && len(calldataIds(tx)) > 0
+}
+
+// ParseMetaData recognizes the ethscription creations, calldata transfers & esip transfer events of the tx
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	// internal calls do not create or transfer ethscriptions
+	if xycommon.IsDerivedTx(tx.Hash) {
+		return nil, nil
+	}
+
+	md := &devents.MetaData{
+		Chain:    chain,
+		Protocol: types.EthscriptionsProtocol,
+	}
+
+	if _, ok := contentURI(tx); ok {
+		md.Operate = devents.OperateCreate
+		return md, nil
+	}
+
+	if len(calldataIds(tx)) > 0 || len(transferEvents(tx)) > 0 {
+		md.Operate = devents.OperateTransfer
+		return md, nil
+	}
+	return nil, nil
+}
+
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	changes := make([]*devents.Ethscription, 0, 1)
+	owners := newOwners(p.cache)
+
+	item, err := p.create(tx)
+	if err == nil {
+		owners.apply(item)
+		changes = append(changes, item)
+	}
+
+	// transfers of the tx are applied after its creation
+	changes = append(changes, p.transfers(tx, owners)...)
+	if len(changes) < 1 {
+		if md.Operate != devents.OperateCreate {
+			err = xyerrors.NewInsError(-11, fmt.Sprintf("no valid ethscription transfer, tx[%s]", tx.Hash))
+		}
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+
+	results := make([]*devents.TxResult, 0, len(changes))
+	for _, item := range changes {
+		results = append(results, &devents.TxResult{
+			MD:           md,
+			Block:        block,
+			Tx:           tx,
+			Ethscription: item,
+		})
+	}
+	return results, nil
+}
+
+// contentURI returns the data uri sent to an address by the tx input
+func contentURI(tx *xycommon.RpcTransaction) (*common.DataURI, bool) {
+	if tx.To == "" || !strings.HasPrefix(tx.Input, "0x") {
+		return nil, false
+	}
+
+	data, err := hex.DecodeString(tx.Input[2:])
+	if err != nil {
+		return nil, false
+	}
+
+	// ESIP-7 creations may gzip the whole calldata
+	uri, err := common.ParseDataURI(data, common.DataURIOptions{GzipInput: true})
+	if err != nil {
+		return nil, false
+	}
+	return uri, true
+}
+
+func init() {
+	// ethscriptions index every tx alongside its inscription protocol, esip transfer events are scanned from logs
+	registry.MustRegister(registry.Entry{
+		ChainGroup:  model.EvmChainGroup,
+		Chain:       model.ChainETH,
+		Protocol:    types.EthscriptionsProtocol,
+		Shared:      true,
+		EventTopics: []string{EventTopicHashTransfer, EventTopicHashTransferForPreviousOwner},
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return NewProtocol(cache), nil
+		},
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"encoding/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/xylog"
+	"sort"
+	"strings"
+)
+
+const (
+	// EventTopicHashTransfer ESIP-1 ethscriptions_protocol_TransferEthscription(index_topic_1 address recipient, index_topic_2 bytes32 ethscriptionId)
+	EventTopicHashTransfer = "0xf30861289185032f511ff94a8127e470f3d0e6230be4925cb6fad33f3436dffb"
+
+	// EventTopicHashTransferForPreviousOwner ESIP-2 ethscriptions_protocol_TransferEthscriptionForPreviousOwner(index_topic_1 address previousOwner, index_topic_2 address recipient, index_topic_3 bytes32 ethscriptionId)
+	EventTopicHashTransferForPreviousOwner = "0xf1d95ed4d1680e6f665104f19c296ae52c1f64cd8114e84d55dc6349dbdafea3"
+)
+
+// owners tracks the ownership changed by the tx on top of the cache
+type owners struct {
+	cache *dcache.Manager
+	items map[string]*dcache.EthscriptionItem
+}
+
+func newOwners(cache *dcache.Manager) *owners {
+	return &owners{
+		cache: cache,
+		items: make(map[string]*dcache.EthscriptionItem),
+	}
+}
+
+func (o *owners) get(id string) (*dcache.EthscriptionItem, bool) {
+	if item, ok := o.items[id]; ok {
+		return item, true
+	}
+
+	ok, item := o.cache.Ethscription.Get(id)
+	return item, ok
+}
+
+func (o *owners) apply(e *devents.Ethscription) {
+	item := &dcache.EthscriptionItem{
+		Creator:       e.From,
+		Owner:         e.To,
+		PreviousOwner: e.From,
+	}
+	if last, ok := o.get(e.ID); ok && !e.Create {
+		item.Creator = last.Creator
+	}
+	o.items[e.ID] = item
+}
+
+// transfers verifies the ESIP-5 calldata transfers & the ESIP-1 / ESIP-2 event transfers of the tx in order
+func (p *Protocol) transfers(tx *xycommon.RpcTransaction, owners *owners) []*devents.Ethscription {
+	items := make([]*devents.Ethscription, 0, 1)
+	sender := strings.ToLower(tx.From)
+	for _, id := range calldataIds(tx) {
+		item, ok := owners.get(id)
+		if !ok || !strings.EqualFold(item.Owner, sender) {
+			xylog.Logger.Infof("calldata transfer ignored, ethscription[%s] not owned by sender[%s]", id, sender)
+			continue
+		}
+
+		e := &devents.Ethscription{
+			ID:       id,
+			From:     sender,
+			To:       strings.ToLower(tx.To),
+			LogIndex: -1,
+		}
+		owners.apply(e)
+		items = append(items, e)
+	}
+
+	for _, event := range transferEvents(tx) {
+		var (
+			topic    = event.Topics[0].String()
+			from     = strings.ToLower(event.Address.String())
+			previous string
+			to       string
+			id       string
+		)
+
+		switch topic {
+		case EventTopicHashTransfer:
+			to, id = topicAddress(event.Topics[1]), strings.ToLower(event.Topics[2].String())
+		case EventTopicHashTransferForPreviousOwner:
+			previous, to, id = topicAddress(event.Topics[1]), topicAddress(event.Topics[2]), strings.ToLower(event.Topics[3].String())
+		}
+
+		item, ok := owners.get(id)
+		if !ok || !strings.EqualFold(item.Owner, from) {
+			xylog.Logger.Infof("event transfer ignored, ethscription[%s] not owned by contract[%s]", id, from)
+			continue
+		}
+
+		if previous != "" && !strings.EqualFold(item.PreviousOwner, previous) {
+			xylog.Logger.Infof("event transfer ignored, ethscription[%s] previous owner[%s] mismatched", id, previous)
+			continue
+		}
+
+		e := &devents.Ethscription{
+			ID:       id,
+			From:     from,
+			To:       to,
+			LogIndex: logIndex(event),
+		}
+		owners.apply(e)
+		items = append(items, e)
+	}
+	return items
+}
+
+// calldataIds returns the ethscription ids sent by the tx input, one 32 bytes id each
+func calldataIds(tx *xycommon.RpcTransaction) []string {
+	if tx.To == "" || !strings.HasPrefix(tx.Input, "0x") {
+		return nil
+	}
+
+	data := strings.ToLower(tx.Input[2:])
+	if len(data) < 64 || len(data)%64 != 0 {
+		return nil
+	}
+
+	if _, err := hex.DecodeString(data); err != nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(data)/64)
+	for i := 0; i < len(data); i += 64 {
+		ids = append(ids, "0x"+data[i:i+64])
+	}
+	return ids
+}
+
+// transferEvents returns the esip transfer events of the tx ordered by log index
+func transferEvents(tx *xycommon.RpcTransaction) []xycommon.RpcLog {
+	events := make([]xycommon.RpcLog, 0, len(tx.Events))
+	for _, event := range tx.Events {
+		if event.Removed || len(event.Topics) < 1 {
+			continue
+		}
+
+		switch event.Topics[0].String() {
+		case EventTopicHashTransfer:
+			if len(event.Topics) == 3 {
+				events = append(events, event)
+			}
+		case EventTopicHashTransferForPreviousOwner:
+			if len(event.Topics) == 4 {
+				events = append(events, event)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return logIndex(events[i]) < logIndex(events[j])
+	})
+	return events
+}
+
+func topicAddress(topic common.Hash) string {
+	return strings.ToLower(common.BytesToAddress(topic.Bytes()).String())
+}
+
+func logIndex(event xycommon.RpcLog) int64 {
+	if event.Index == nil {
+		return 0
+	}
+	return event.Index.ToInt().Int64()
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package ethscriptions
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+const (
+	testAddrA     = "0x00000000000000000000000000000000000000a1"
+	testAddrB     = "0x00000000000000000000000000000000000000b2"
+	testContractC = "0x00000000000000000000000000000000000000c3"
+	testContractD = "0x00000000000000000000000000000000000000d4"
+)
+
+var (
+	testID1 = common.BigToHash(big.NewInt(1)).Hex()
+	testID2 = common.BigToHash(big.NewInt(2)).Hex()
+)
+
+func esip1(contract, to, id string, index int64) xycommon.RpcLog {
+	return xycommon.RpcLog{
+		Address: common.HexToAddress(contract),
+		Topics:  []common.Hash{common.HexToHash(EventTopicHashTransfer), common.HexToHash(to), common.HexToHash(id)},
+		Index:   (*hexutil.Big)(big.NewInt(index)),
+	}
+}
+
+func esip2(contract, previous, to, id string, index int64) xycommon.RpcLog {
+	return xycommon.RpcLog{
+		Address: common.HexToAddress(contract),
+		Topics: []common.Hash{
+			common.HexToHash(EventTopicHashTransferForPreviousOwner),
+			common.HexToHash(previous),
+			common.HexToHash(to),
+			common.HexToHash(id),
+		},
+		Index: (*hexutil.Big)(big.NewInt(index)),
+	}
+}
+
+func TestCalldataIds(t *testing.T) {
+	tests := []struct {
+		name  string
+		to    string
+		input string
+		want  []string
+	}{
+		{"single id", testAddrB, testID1, []string{testID1}},
+		{"multiple ids", testAddrB, testID1 + testID2[2:], []string{testID1, testID2}},
+		{"upper case", testAddrB, "0x" + strings.ToUpper(testID1[2:]), []string{testID1}},
+		{"empty", testAddrB, "0x", nil},
+		{"partial id", testAddrB, testID1 + "00", nil},
+		{"not hex", testAddrB, "0x" + strings.Repeat("z", 64), nil},
+		{"prefix missing", testAddrB, testID1[2:], nil},
+		{"contract creation", "", testID1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calldataIds(&xycommon.RpcTransaction{To: tt.to, Input: tt.input}))
+		})
+	}
+}
+
+func TestTransferEvents(t *testing.T) {
+	removed := esip1(testContractC, testAddrB, testID1, 0)
+	removed.Removed = true
+	malformed := esip2(testContractC, testAddrA, testAddrB, testID1, 1)
+	malformed.Topics = malformed.Topics[:3]
+
+	tx := &xycommon.RpcTransaction{Events: []xycommon.RpcLog{
+		esip2(testContractC, testAddrA, testAddrB, testID2, 5),
+		removed,
+		malformed,
+		{Address: common.HexToAddress(testContractC), Topics: []common.Hash{common.HexToHash("0x01")}},
+		esip1(testContractC, testAddrB, testID1, 3),
+	}}
+
+	// the events are ordered by log index
+	events := transferEvents(tx)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, int64(3), logIndex(events[0]))
+		assert.Equal(t, int64(5), logIndex(events[1]))
+	}
+}
+
+func TestTransfers(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		input  string
+		events []xycommon.RpcLog
+		want   []string // id:from>to
+	}{
+		{"calldata", testAddrA, testAddrB, testID1, nil, []string{testID1 + ":" + testAddrA + ">" + testAddrB}},
+		{"calldata not owned", testAddrB, testAddrA, testID1, nil, nil},
+		{"calldata unknown id", testAddrA, testAddrB, common.BigToHash(big.NewInt(3)).Hex(), nil, nil},
+		{"calldata sent twice", testAddrA, testAddrB, testID1 + testID1[2:], nil, []string{testID1 + ":" + testAddrA + ">" + testAddrB}},
+		{"esip1", testAddrA, testContractC, "0x", []xycommon.RpcLog{esip1(testContractC, testAddrA, testID2, 0)}, []string{testID2 + ":" + testContractC + ">" + testAddrA}},
+		{"esip1 not owned", testAddrA, testContractD, "0x", []xycommon.RpcLog{esip1(testContractD, testAddrA, testID2, 0)}, nil},
+		{"esip2", testAddrA, testContractC, "0x", []xycommon.RpcLog{esip2(testContractC, testAddrB, testAddrA, testID2, 0)}, []string{testID2 + ":" + testContractC + ">" + testAddrA}},
+		{"esip2 previous owner mismatched", testAddrA, testContractC, "0x", []xycommon.RpcLog{esip2(testContractC, testAddrA, testAddrA, testID2, 0)}, nil},
+		{
+			// the contract escrows the ethscription deposited by the calldata transfer of the tx
+			"calldata then esip2", testAddrA, testContractC, testID1,
+			[]xycommon.RpcLog{
+				esip2(testContractC, testAddrA, testAddrB, testID1, 2),
+				esip1(testContractC, testAddrA, testID2, 1),
+			},
+			[]string{
+				testID1 + ":" + testAddrA + ">" + testContractC,
+				testID2 + ":" + testContractC + ">" + testAddrA,
+				testID1 + ":" + testContractC + ">" + testAddrB,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &dcache.Manager{Ethscription: dcache.NewEthscription()}
+			cache.Ethscription.Create(testID1, "", &dcache.EthscriptionItem{Creator: testAddrA, Owner: testAddrA, PreviousOwner: testAddrA})
+			cache.Ethscription.Create(testID2, "", &dcache.EthscriptionItem{Creator: testAddrB, Owner: testContractC, PreviousOwner: testAddrB})
+			p := NewProtocol(cache)
+
+			tx := &xycommon.RpcTransaction{From: tt.from, To: tt.to, Input: tt.input, Events: tt.events}
+			got := make([]string, 0)
+			for _, e := range p.transfers(tx, newOwners(cache)) {
+				got = append(got, fmt.Sprintf("%s:%s>%s", e.ID, e.From, e.To))
+			}
+
+			want := tt.want
+			if want == nil {
+				want = []string{}
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package protocol
+
+import (
+	"encoding/hex"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/model"
+	protocommon "github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/xylog"
+	"strings"
+)
+
+// EventTopicHashCreate ESIP-3 ethscriptions_protocol_CreateEthscription(index_topic_1 address initialOwner, string contentURI)
+const EventTopicHashCreate = "0x665fba0baf3dc33e9943340197893ac16f56482c2defb8de60f944987fee451c"
+
+var createABIJSON = `
+[
+  {
+    "anonymous": false,
+    "inputs": [
+      {
+        "indexed": true,
+        "internalType": "address",
+        "name": "initialOwner",
+        "type": "address"
+      },
+      {
+        "indexed": false,
+        "internalType": "string",
+        "name": "contentURI",
+        "type": "string"
+      }
+    ],
+    "name": "ethscriptions_protocol_CreateEthscription",
+    "type": "event"
+  }
+]
+`
+
+var createABI abi.ABI
+
+// ResolveEventCreation
+/***************************************
+ * contracts inscribe by emitting the creation event, the emitting contract is the sender,
+ * the initial owner the receiver & the content uri the input of the resolved tx.
+ * only the first creation event is accepted, txs inscribing by the input are returned as is
+ ***************************************/
+func ResolveEventCreation(tx *xycommon.RpcTransaction) *xycommon.RpcTransaction {
+	if len(tx.Events) < 1 || hasDataInput(tx.Input) {
+		return tx
+	}
+
+	for _, event := range tx.Events {
+		if event.Removed || len(event.Topics) != 2 || event.Topics[0].String() != EventTopicHashCreate {
+			continue
+		}
+
+		values, err := createABI.Unpack("ethscriptions_protocol_CreateEthscription", event.Data)
+		if err != nil || len(values) < 1 {
+			xylog.Logger.Infof("creation event data decode failed, tx[%s], err[%v]", tx.Hash, err)
+			continue
+		}
+
+		uri, _ := values[0].(string)
+		if !protocommon.IsDataURI([]byte(uri)) {
+			continue
+		}
+
+		resolved := *tx
+		resolved.From = strings.ToLower(event.Address.String())
+		resolved.To = strings.ToLower(common.BytesToAddress(event.Topics[1].Bytes()).String())
+		resolved.Input = "0x" + hex.EncodeToString([]byte(uri))
+		return &resolved
+	}
+	return tx
+}
+
+func hasDataInput(input string) bool {
+	return protocommon.IsDataURIHex(input)
+}
+
+func init() {
+	var err error
+	createABI, err = abi.JSON(strings.NewReader(createABIJSON))
+	if err != nil {
+		xylog.Logger.Fatalf("creation event abi decode err:%v", err)
+	}
+
+	registry.RegisterGroupEventTopics(model.EvmChainGroup, EventTopicHashCreate)
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package adapter
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	protocommon "github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/utils"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+)
+
+// binding of the event mapping to its adapter
+type binding struct {
+	adapter   *config.EventAdapter
+	mapping   *config.EventMapping
+	abi       abi.ABI
+	contracts map[string]struct{}
+}
+
+// Protocol indexes the events of the configured adapters as the tick transfers of the adapter protocols,
+// e.g. the marketplace or bridge contracts moving the inscriptions. The transfers are verified against
+// the indexed balances of the senders, the ticks shall be deployed
+type Protocol struct {
+	cache    *dcache.Manager
+	bindings map[common.Hash][]*binding
+
+	// normalizeTick normalizes the ticks by the tick rules of the target protocols
+	normalizeTick func(protocol string, height uint64, tick string) string
+}
+
+func NewProtocol(cache *dcache.Manager, adapters []*config.EventAdapter) (*Protocol, error) {
+	p := &Protocol{
+		cache:    cache,
+		bindings: make(map[common.Hash][]*binding),
+		normalizeTick: func(protocol string, height uint64, tick string) string {
+			return strings.ToLower(strings.TrimSpace(tick))
+		},
+	}
+
+	for _, adapter := range adapters {
+		if adapter.Protocol == "" || len(adapter.Contracts) < 1 {
+			return nil, fmt.Errorf("adapter[%s] protocol / contracts empty", adapter.Name)
+		}
+
+		content, err := os.ReadFile(adapter.Abi)
+		if err != nil {
+			return nil, fmt.Errorf("adapter[%s] read abi err:%v", adapter.Name, err)
+		}
+
+		parsed, err := abi.JSON(strings.NewReader(string(content)))
+		if err != nil {
+			return nil, fmt.Errorf("adapter[%s] abi decode err:%v", adapter.Name, err)
+		}
+
+		contracts := make(map[string]struct{}, len(adapter.Contracts))
+		for _, contract := range adapter.Contracts {
+			contracts[strings.ToLower(common.HexToAddress(contract).Hex())] = struct{}{}
+		}
+
+		for _, mapping := range adapter.Events {
+			event, ok := parsed.Events[mapping.Event]
+			if !ok {
+				return nil, fmt.Errorf("adapter[%s] event[%s] not found in abi", adapter.Name, mapping.Event)
+			}
+
+			if err = verifyMapping(event, mapping); err != nil {
+				return nil, fmt.Errorf("adapter[%s] event[%s] %v", adapter.Name, mapping.Event, err)
+			}
+
+			p.bindings[event.ID] = append(p.bindings[event.ID], &binding{
+				adapter:   adapter,
+				mapping:   mapping,
+				abi:       parsed,
+				contracts: contracts,
+			})
+		}
+	}
+	return p, nil
+}
+
+func verifyMapping(event abi.Event, mapping *config.EventMapping) error {
+	switch mapping.Operate {
+	case "":
+		mapping.Operate = devents.OperateTransfer
+	case devents.OperateTransfer, devents.OperateExchange:
+	default:
+		// the mapped events only move balances, recorded as the sales by exchange,
+		// the list orders are escrowed & settled by the market protocols
+		return fmt.Errorf("op[%s] unsupported, only transfer / exchange mapped", mapping.Operate)
+	}
+
+	if (mapping.Tick == "") == (mapping.TickField == "") {
+		return fmt.Errorf("either tick or tick field required")
+	}
+
+	fields := make(map[string]struct{}, len(event.Inputs))
+	for _, input := range event.Inputs {
+		fields[input.Name] = struct{}{}
+	}
+
+	for _, field := range []string{mapping.TickField, mapping.From, mapping.To, mapping.Amount} {
+		if _, ok := fields[field]; !ok && field != "" {
+			return fmt.Errorf("field[%s] not found", field)
+		}
+	}
+
+	if mapping.To == "" || mapping.Amount == "" {
+		return fmt.Errorf("to / amount field empty")
+	}
+	return nil
+}
+
+// SetTickNormalizer normalizes the ticks by the tick rules of the target protocols enabled on the chain
+func (p *Protocol) SetTickNormalizer(normalize func(protocol string, height uint64, tick string) string) {
+	p.normalizeTick = normalize
+}
+
+// EventTopics scans the events of the mappings
+func (p *Protocol) EventTopics() []string {
+	topics := make([]string, 0, len(p.bindings))
+	for topic := range p.bindings {
+		topics = append(topics, topic.Hex())
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+type transferEvent struct {
+	binding *binding
+	tick    string
+	from    string
+	to      string
+	amount  decimal.Decimal
+}
+
+// transfers decodes the mapped events emitted by the allowlisted contracts in the log order
+func (p *Protocol) transfers(tx *xycommon.RpcTransaction) []*transferEvent {
+	events := make([]*transferEvent, 0, len(tx.Events))
+	for _, e := range tx.Events {
+		if e.Removed || len(e.Topics) < 1 {
+			continue
+		}
+
+		contract := strings.ToLower(e.Address.Hex())
+		for _, b := range p.bindings[e.Topics[0]] {
+			if _, ok := b.contracts[contract]; !ok {
+				continue
+			}
+
+			if item := p.decode(b, e, protocommon.TxHeight(tx)); item != nil {
+				events = append(events, item)
+				break
+			}
+		}
+	}
+	return events
+}
+
+func (p *Protocol) decode(b *binding, e xycommon.RpcLog, height uint64) *transferEvent {
+	fields := make(map[string]interface{})
+	if _, err := utils.ParseEventToMap(b.abi, utils.EventLog{Address: e.Address, Topics: e.Topics, Data: e.Data}, fields); err != nil {
+		return nil
+	}
+
+	item := &transferEvent{
+		binding: b,
+		tick:    b.mapping.Tick,
+		from:    strings.ToLower(e.Address.Hex()),
+	}
+
+	if b.mapping.TickField != "" {
+		item.tick = p.tickValue(fields[b.mapping.TickField])
+	}
+	item.tick = p.normalizeTick(b.adapter.Protocol, height, item.tick)
+
+	if b.mapping.From != "" {
+		item.from = addressValue(fields[b.mapping.From])
+	}
+	item.to = addressValue(fields[b.mapping.To])
+
+	amount, ok := fields[b.mapping.Amount].(*big.Int)
+	if !ok || amount.Sign() <= 0 {
+		return nil
+	}
+	item.amount = decimal.NewFromBigInt(amount, -b.mapping.Decimals)
+
+	if item.tick == "" || item.from == "" || item.to == "" {
+		return nil
+	}
+	return item
+}
+
+// tickValue reads the string tick or resolves the bytes32 tick hash
+func (p *Protocol) tickValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case common.Hash:
+		_, tick := p.cache.Inscription.GetNameByIdx(v.Hex())
+		return tick
+	case [32]byte:
+		_, tick := p.cache.Inscription.GetNameByIdx(common.Hash(v).Hex())
+		return tick
+	}
+	return ""
+}
+
+func addressValue(value interface{}) string {
+	address, ok := value.(common.Address)
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(address.Hex())
+}
+
+// ParseMetaData recognizes the txs emitting the mapped events
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	events := p.transfers(tx)
+	if len(events) < 1 {
+		return nil, nil
+	}
+
+	return &devents.MetaData{
+		Chain:    chain,
+		Protocol: types.AdapterProtocol,
+		Operate:  events[0].binding.mapping.Operate,
+		Tick:     events[0].tick,
+	}, nil
+}
+
+// Parse returns one transfer result of the adapter protocol for each mapped event, the balances
+// moved by the former events of the tx are counted in as the cache is updated after parsing
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	events := p.transfers(tx)
+	results := make([]*devents.TxResult, 0, len(events))
+	moved := make(map[string]decimal.Decimal, len(events))
+	for _, e := range events {
+		rmd := md.Copy()
+		rmd.Protocol = e.binding.adapter.Protocol
+		rmd.Operate = e.binding.mapping.Operate
+		rmd.Tick = e.tick
+
+		if err := p.verifyTransfer(rmd, e, moved); err != nil {
+			xylog.Logger.Infof("adapter[%s] transfer verified failed, tx[%s], err:%v", e.binding.adapter.Name, tx.Hash, err)
+			continue
+		}
+
+		fromKey, toKey := balanceKey(rmd, e.from), balanceKey(rmd, e.to)
+		moved[fromKey] = moved[fromKey].Sub(e.amount)
+		moved[toKey] = moved[toKey].Add(e.amount)
+		results = append(results, &devents.TxResult{
+			MD:    rmd,
+			Block: block,
+			Tx:    tx,
+			Transfer: &devents.Transfer{
+				Sender:   e.from,
+				Receives: []*devents.Receive{{Address: e.to, Amount: e.amount}},
+			},
+		})
+	}
+
+	if len(results) < 1 {
+		err := xyerrors.NewInsError(-12, fmt.Sprintf("no adapter transfer indexed by the tx[%s]", tx.Hash))
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+	return results, nil
+}
+
+func (p *Protocol) verifyTransfer(md *devents.MetaData, e *transferEvent, moved map[string]decimal.Decimal) *xyerrors.InsError {
+	ok, inscription := p.cache.Inscription.Get(md.Protocol, md.Tick)
+	if !ok || inscription == nil {
+		return xyerrors.NewInsError(-15, fmt.Sprintf("inscription not exist, protocol[%s]-tick[%s]", md.Protocol, md.Tick))
+	}
+
+	if inscription.TransferType == model.TransferTypeHash {
+		return xyerrors.NewInsError(-18, fmt.Sprintf("tick[%s-%s] transfers by hash", md.Protocol, md.Tick))
+	}
+
+	if inscription.StrictDecimals && !protocommon.ValidDecimals(e.amount, inscription.Decimals) {
+		return xyerrors.NewInsError(-34, fmt.Sprintf("transfer amount[%v] exceeds decimals[%d]", e.amount, inscription.Decimals))
+	}
+
+	// sender balance checking, the escrowed balance of the open orders is not available
+	balance := moved[balanceKey(md, e.from)]
+	if ok, item := p.cache.Balance.Get(md.Protocol, md.Tick, e.from); ok {
+		balance = balance.Add(item.Available)
+	} else if balance.IsZero() {
+		return xyerrors.NewInsError(-16, fmt.Sprintf("sender balance record not exist, tick[%s-%s], address[%s]", md.Protocol, md.Tick, e.from))
+	}
+
+	if balance.LessThan(e.amount) {
+		return xyerrors.NewInsError(-17, fmt.Sprintf("sender available balance[%v] < transfer amount[%v]", balance, e.amount))
+	}
+	return nil
+}
+
+func balanceKey(md *devents.MetaData, address string) string {
+	return fmt.Sprintf("%s/%s/%s", md.Protocol, md.Tick, address)
+}
+
+func init() {
+	// adapters index every evm tx alongside its inscription protocol
+	registry.MustRegister(registry.Entry{
+		ChainGroup: model.EvmChainGroup,
+		Protocol:   types.AdapterProtocol,
+		Shared:     true,
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			p, err := NewProtocol(cache, cfg.Chain.EventAdapters)
+			if err != nil {
+				return nil, fmt.Errorf("event adapters config err:%v", err)
+			}
+			return p, nil
+		},
+	})
+}
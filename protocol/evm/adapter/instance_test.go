@@ -0,0 +1,239 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package adapter
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	protocommon "github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const soldABI = `[{"type":"event","name":"Sold","anonymous":false,"inputs":[
+	{"name":"seller","type":"address","indexed":true},
+	{"name":"buyer","type":"address","indexed":true},
+	{"name":"tick","type":"string","indexed":false},
+	{"name":"amount","type":"uint256","indexed":false}
+]}]`
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+func TestNewProtocol(t *testing.T) {
+	abiFile := filepath.Join(t.TempDir(), "market.json")
+	assert.NoError(t, os.WriteFile(abiFile, []byte(soldABI), 0644))
+
+	adapter := func(mapping *config.EventMapping) []*config.EventAdapter {
+		return []*config.EventAdapter{{
+			Name:      "market",
+			Protocol:  "asc-20",
+			Abi:       abiFile,
+			Contracts: []string{"0x00000000000000000000000000000000000000cc"},
+			Events:    []*config.EventMapping{mapping},
+		}}
+	}
+
+	mapping := &config.EventMapping{Event: "Sold", TickField: "tick", From: "seller", To: "buyer", Amount: "amount"}
+	p, err := NewProtocol(nil, adapter(mapping))
+	assert.NoError(t, err)
+	assert.Equal(t, devents.OperateTransfer, mapping.Operate)
+	assert.Equal(t, []string{crypto.Keccak256Hash([]byte("Sold(address,address,string,uint256)")).Hex()}, p.EventTopics())
+
+	for _, mapping := range []*config.EventMapping{
+		{Event: "Bought", TickField: "tick", To: "buyer", Amount: "amount"},
+		{Event: "Sold", Tick: "test", TickField: "tick", To: "buyer", Amount: "amount"},
+		{Event: "Sold", TickField: "tick", To: "receiver", Amount: "amount"},
+		{Event: "Sold", TickField: "tick", Amount: "amount"},
+		{Event: "Sold", Operate: "mint", TickField: "tick", To: "buyer", Amount: "amount"},
+		{Event: "Sold", Operate: "list", TickField: "tick", From: "seller", To: "buyer", Amount: "amount"},
+		{Event: "Sold", Operate: "delist", TickField: "tick", From: "seller", To: "buyer", Amount: "amount"},
+	} {
+		_, err = NewProtocol(nil, adapter(mapping))
+		assert.Error(t, err, mapping.Event)
+	}
+
+	_, err = NewProtocol(nil, []*config.EventAdapter{{Name: "market", Protocol: "asc-20", Abi: abiFile}})
+	assert.Error(t, err)
+
+	_, err = NewProtocol(nil, []*config.EventAdapter{{Name: "market", Protocol: "asc-20", Abi: abiFile + ".missing", Contracts: []string{"0xcc"}}})
+	assert.Error(t, err)
+}
+
+func TestParseTickRule(t *testing.T) {
+	abiFile := filepath.Join(t.TempDir(), "market.json")
+	assert.NoError(t, os.WriteFile(abiFile, []byte(soldABI), 0644))
+
+	market := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	seller := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	buyer := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	cache := &dcache.Manager{
+		Inscription: dcache.NewInscription(),
+		Balance:     dcache.NewBalance(),
+	}
+	p, err := NewProtocol(cache, []*config.EventAdapter{{
+		Name:      "market",
+		Protocol:  "asc-20",
+		Abi:       abiFile,
+		Contracts: []string{market.Hex()},
+		Events:    []*config.EventMapping{{Event: "Sold", TickField: "tick", From: "seller", To: "buyer", Amount: "amount"}},
+	}})
+	assert.NoError(t, err)
+
+	cache.Inscription.Create("asc-20", "duck", &dcache.Tick{})
+	cache.Balance.Create("asc-20", "duck", strings.ToLower(seller.Hex()), &dcache.BalanceItem{
+		Available: decimal.NewFromInt(10),
+		Overall:   decimal.NewFromInt(10),
+	})
+
+	parsed, err := abi.JSON(strings.NewReader(soldABI))
+	assert.NoError(t, err)
+	data, err := parsed.Events["Sold"].Inputs.NonIndexed().Pack(" ＤＵＣＫ ", big.NewInt(5))
+	assert.NoError(t, err)
+	tx := &xycommon.RpcTransaction{
+		Hash:        "0x01",
+		BlockNumber: big.NewInt(100),
+		Events: []xycommon.RpcLog{{
+			Address: market,
+			Topics:  []common.Hash{parsed.Events["Sold"].ID, common.BytesToHash(seller.Bytes()), common.BytesToHash(buyer.Bytes())},
+			Data:    data,
+		}},
+	}
+
+	// the ticks are folded to lower case by default
+	md, err := p.ParseMetaData("avalanche", tx)
+	assert.NoError(t, err)
+	assert.Equal(t, "ｄｕｃｋ", md.Tick)
+
+	// the ticks follow the tick rule of the target protocol in force at the tx height
+	var heights []uint64
+	p.SetTickNormalizer(func(protocol string, height uint64, tick string) string {
+		assert.Equal(t, "asc-20", protocol)
+		heights = append(heights, height)
+		return protocommon.UnicodeTickRule.Normalize(tick)
+	})
+	results, insErr := p.Parse(&xycommon.RpcBlock{}, tx, md)
+	assert.Nil(t, insErr)
+	if !assert.Len(t, results, 1) {
+		return
+	}
+	assert.Equal(t, "asc-20", results[0].MD.Protocol)
+	assert.Equal(t, "duck", results[0].MD.Tick)
+	assert.Equal(t, []uint64{100}, heights)
+}
+
+func TestParse(t *testing.T) {
+	abiFile := filepath.Join(t.TempDir(), "market.json")
+	assert.NoError(t, os.WriteFile(abiFile, []byte(soldABI), 0644))
+
+	market := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	foreign := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	seller := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	buyer := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	cache := &dcache.Manager{
+		Inscription: dcache.NewInscription(),
+		Balance:     dcache.NewBalance(),
+	}
+	p, err := NewProtocol(cache, []*config.EventAdapter{{
+		Name:      "market",
+		Protocol:  "brc-20",
+		Abi:       abiFile,
+		Contracts: []string{market.Hex()},
+		Events:    []*config.EventMapping{{Event: "Sold", Operate: devents.OperateExchange, TickField: "tick", From: "seller", To: "buyer", Amount: "amount"}},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cache.Inscription.Create("brc-20", "test", &dcache.Tick{TransferType: model.TransferTypeBalance})
+	cache.Inscription.Create("brc-20", "hash", &dcache.Tick{TransferType: model.TransferTypeHash})
+	cache.Balance.Create("brc-20", "test", strings.ToLower(seller.Hex()), &dcache.BalanceItem{
+		Available: decimal.NewFromInt(50),
+		Overall:   decimal.NewFromInt(80),
+	})
+
+	parsed, err := abi.JSON(strings.NewReader(soldABI))
+	assert.NoError(t, err)
+	sold := func(contract, from, to common.Address, tick string, amount int64) xycommon.RpcLog {
+		data, err := parsed.Events["Sold"].Inputs.NonIndexed().Pack(tick, big.NewInt(amount))
+		assert.NoError(t, err)
+		return xycommon.RpcLog{
+			Address: contract,
+			Topics:  []common.Hash{parsed.Events["Sold"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:    data,
+		}
+	}
+
+	// the foreign contract is not on the allowlist
+	tx := &xycommon.RpcTransaction{Hash: "0x01", Events: []xycommon.RpcLog{sold(foreign, seller, buyer, "test", 30)}}
+	md, err := p.ParseMetaData("ethereum", tx)
+	assert.NoError(t, err)
+	assert.Nil(t, md)
+
+	// the escrowed balance is not available, the tokens bought in the tx are spendable by the later events,
+	// the ticks transferred by hash are refused
+	tx = &xycommon.RpcTransaction{Hash: "0x02", Events: []xycommon.RpcLog{
+		sold(market, seller, buyer, "test", 60),
+		sold(market, seller, buyer, "test", 20),
+		sold(market, buyer, seller, "test", 5),
+		sold(foreign, seller, buyer, "test", 20),
+		sold(market, seller, buyer, "hash", 1),
+	}}
+	md, err = p.ParseMetaData("ethereum", tx)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, md) {
+		return
+	}
+	assert.Equal(t, devents.OperateExchange, md.Operate)
+
+	results, insErr := p.Parse(&xycommon.RpcBlock{}, tx, md)
+	assert.Nil(t, insErr)
+	if !assert.Len(t, results, 2) {
+		return
+	}
+	for i, sender := range []common.Address{seller, buyer} {
+		assert.Equal(t, "brc-20", results[i].MD.Protocol)
+		assert.Equal(t, devents.OperateExchange, results[i].MD.Operate)
+		assert.Equal(t, strings.ToLower(sender.Hex()), results[i].Transfer.Sender)
+	}
+	assert.Equal(t, "20", results[0].Transfer.Receives[0].Amount.String())
+	assert.Equal(t, "5", results[1].Transfer.Receives[0].Amount.String())
+
+	tx = &xycommon.RpcTransaction{Hash: "0x03", Events: []xycommon.RpcLog{sold(market, buyer, seller, "test", 1)}}
+	_, insErr = p.Parse(&xycommon.RpcBlock{}, tx, md)
+	assert.NotNil(t, insErr)
+}
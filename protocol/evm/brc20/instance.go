@@ -23,16 +23,38 @@
 package brc20
 
 import (
+	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/model"
 	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/protocol/types"
 )
 
 type Protocol struct {
 	*common.Protocol
 }
 
-func NewProtocol(cache *dcache.Manager) *Protocol {
+func NewProtocol(cache *dcache.Manager, schedule *common.Schedule) *Protocol {
 	return &Protocol{
-		Protocol: common.NewProtocol(cache),
+		Protocol: common.NewProtocol(cache, schedule),
+	}
+}
+
+func init() {
+	// brc-20 compatible protocols on evm chains
+	for _, protocol := range []string{types.BRC20Protocol, types.BSC20Protocol, types.PRC20Protocol} {
+		protocol := protocol
+		registry.MustRegister(registry.Entry{
+			ChainGroup: model.EvmChainGroup,
+			Protocol:   protocol,
+			Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+				schedule, err := common.NewSchedule(common.DefaultRules, protocol, cfg.Chain.ProtocolRules)
+				if err != nil {
+					return nil, err
+				}
+				return NewProtocol(cache, schedule), nil
+			},
+		})
 	}
 }
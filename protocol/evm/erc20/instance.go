@@ -0,0 +1,233 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package erc20
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/registry"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"strings"
+)
+
+// EventTopicTransfer Transfer(address indexed from, address indexed to, uint256 value)
+const EventTopicTransfer = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+var zeroAddress = strings.ToLower(common.Address{}.Hex())
+
+// Protocol of the erc-20 tokens configured on the chain, the balances follow the Transfer events of
+// the token contracts. The tick of the token is deployed uncapped by its first indexed event, the indexer
+// refuses to start after the deploy block of a token not indexed yet, so the indexed balances are complete
+// & the transfers exceeding them are rejected as inconsistent history.
+// Mints are sent from the zero address, burns are transferred to it
+type Protocol struct {
+	cache  *dcache.Manager
+	tokens map[string]*config.ERC20Token
+}
+
+func NewProtocol(cache *dcache.Manager, tokens []*config.ERC20Token) *Protocol {
+	p := &Protocol{
+		cache:  cache,
+		tokens: make(map[string]*config.ERC20Token, len(tokens)),
+	}
+	for _, token := range tokens {
+		p.tokens[strings.ToLower(common.HexToAddress(token.Address).Hex())] = &config.ERC20Token{
+			Address:     token.Address,
+			Tick:        strings.ToLower(strings.TrimSpace(token.Tick)),
+			Decimals:    token.Decimals,
+			DeployBlock: token.DeployBlock,
+		}
+	}
+	return p
+}
+
+// VerifyStart checks the scan covers the whole history of the tokens not indexed yet,
+// the balances built from a later block would miss the earlier transfers
+func (p *Protocol) VerifyStart(startBlock uint64) error {
+	for _, token := range p.tokens {
+		if token.DeployBlock == 0 {
+			return fmt.Errorf("token[%s] deploy block not configured", token.Address)
+		}
+
+		if ok, _ := p.cache.Inscription.Get(types.ERC20Protocol, token.Tick); ok {
+			continue
+		}
+
+		if startBlock > token.DeployBlock {
+			return fmt.Errorf("scan start block[%d] > token[%s] deploy block[%d]", startBlock, token.Address, token.DeployBlock)
+		}
+	}
+	return nil
+}
+
+// EventTopics scans the Transfer events only with the tokens configured
+func (p *Protocol) EventTopics() []string {
+	if len(p.tokens) < 1 {
+		return nil
+	}
+	return []string{EventTopicTransfer}
+}
+
+type transferEvent struct {
+	token  *config.ERC20Token
+	from   string
+	to     string
+	amount decimal.Decimal
+}
+
+// transfers decodes the non-zero Transfer events emitted by the configured tokens in the log order
+func (p *Protocol) transfers(tx *xycommon.RpcTransaction) []*transferEvent {
+	events := make([]*transferEvent, 0, len(tx.Events))
+	for _, e := range tx.Events {
+		if e.Removed || len(e.Topics) != 3 || len(e.Data) != 32 || e.Topics[0] != common.HexToHash(EventTopicTransfer) {
+			continue
+		}
+
+		token, ok := p.tokens[strings.ToLower(e.Address.Hex())]
+		if !ok {
+			continue
+		}
+
+		value := new(big.Int).SetBytes(e.Data)
+		if value.Sign() == 0 {
+			continue
+		}
+
+		events = append(events, &transferEvent{
+			token:  token,
+			from:   strings.ToLower(common.BytesToAddress(e.Topics[1].Bytes()).Hex()),
+			to:     strings.ToLower(common.BytesToAddress(e.Topics[2].Bytes()).Hex()),
+			amount: decimal.NewFromBigInt(value, -int32(token.Decimals)),
+		})
+	}
+	return events
+}
+
+// ParseMetaData recognizes the txs emitting the Transfer events of the configured tokens
+func (p *Protocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	events := p.transfers(tx)
+	if len(events) < 1 {
+		return nil, nil
+	}
+
+	return &devents.MetaData{
+		Chain:    chain,
+		Protocol: types.ERC20Protocol,
+		Operate:  devents.OperateTransfer,
+		Tick:     events[0].token.Tick,
+	}, nil
+}
+
+// Parse returns one result for each Transfer event, the balances moved by the former events
+// of the tx are counted in as the cache is updated after parsing
+func (p *Protocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	events := p.transfers(tx)
+	results := make([]*devents.TxResult, 0, len(events))
+	deployed := make(map[string]bool, 1)
+	moved := make(map[string]decimal.Decimal, len(events))
+	err := xyerrors.NewInsError(-12, fmt.Sprintf("no token transfer indexed by the tx[%s]", tx.Hash))
+	for _, e := range events {
+		rmd := md.Copy()
+		rmd.Tick = e.token.Tick
+
+		result := &devents.TxResult{
+			MD:    rmd,
+			Block: block,
+			Tx:    tx,
+		}
+
+		if e.from == zeroAddress {
+			rmd.Operate = devents.OperateMint
+			result.Mint = &devents.Mint{
+				Minter: e.to,
+				Amount: e.amount,
+			}
+		} else {
+			key := balanceKey(e.token, e.from)
+			balance := p.indexedBalance(e.token, e.from).Add(moved[key])
+			if balance.LessThan(e.amount) {
+				err = xyerrors.NewInsError(-17, fmt.Sprintf("sender total balance[%v] < transfer amount[%v], tick[%s], address[%s]", balance, e.amount, e.token.Tick, e.from))
+				xylog.Logger.Errorf("token transfer exceeds the indexed balance & skip, tx[%s], err:%v", tx.Hash, err)
+				continue
+			}
+
+			rmd.Operate = devents.OperateTransfer
+			moved[key] = moved[key].Sub(e.amount)
+			result.Transfer = &devents.Transfer{
+				Sender:   e.from,
+				Receives: []*devents.Receive{{Address: e.to, Amount: e.amount}},
+			}
+		}
+		moved[balanceKey(e.token, e.to)] = moved[balanceKey(e.token, e.to)].Add(e.amount)
+
+		// the first indexed event deploys the tick
+		if ok, _ := p.cache.Inscription.Get(types.ERC20Protocol, e.token.Tick); !ok && !deployed[e.token.Tick] {
+			deployed[e.token.Tick] = true
+			result.Deploy = &devents.Deploy{
+				Name:           e.token.Tick,
+				Decimal:        e.token.Decimals,
+				TransferType:   model.TransferTypeBalance,
+				StrictDecimals: true,
+			}
+		}
+		results = append(results, result)
+	}
+
+	if len(results) < 1 {
+		return nil, xyerrors.ErrDataVerifiedFailed.WrapCause(err)
+	}
+	return results, nil
+}
+
+func (p *Protocol) indexedBalance(token *config.ERC20Token, address string) decimal.Decimal {
+	ok, balance := p.cache.Balance.Get(types.ERC20Protocol, token.Tick, address)
+	if !ok {
+		return decimal.Zero
+	}
+	return balance.Overall
+}
+
+func balanceKey(token *config.ERC20Token, address string) string {
+	return fmt.Sprintf("%s/%s", token.Tick, address)
+}
+
+func init() {
+	// erc-20 tokens index every evm tx alongside its inscription protocol
+	registry.MustRegister(registry.Entry{
+		ChainGroup: model.EvmChainGroup,
+		Protocol:   types.ERC20Protocol,
+		Shared:     true,
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return NewProtocol(cache, cfg.Chain.ERC20Tokens), nil
+		},
+	})
+}
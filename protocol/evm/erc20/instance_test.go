@@ -0,0 +1,150 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package erc20
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xylog"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func init() {
+	xylog.InitLog(logrus.DebugLevel, "")
+}
+
+func TestVerifyStart(t *testing.T) {
+	cache := &dcache.Manager{Inscription: dcache.NewInscription()}
+	cache.Inscription.Create(types.ERC20Protocol, "usdi", &dcache.Tick{})
+
+	tests := []struct {
+		name  string
+		token *config.ERC20Token
+		start uint64
+		valid bool
+	}{
+		{"start before deploy", &config.ERC20Token{Address: "0x01", Tick: "USDX", DeployBlock: 100}, 90, true},
+		{"start at deploy", &config.ERC20Token{Address: "0x01", Tick: "USDX", DeployBlock: 100}, 100, true},
+		{"start after deploy", &config.ERC20Token{Address: "0x01", Tick: "USDX", DeployBlock: 100}, 101, false},
+		{"deploy block missing", &config.ERC20Token{Address: "0x01", Tick: "USDX"}, 1, false},
+		// the scan of the indexed token resumes from the indexed blocks
+		{"indexed token", &config.ERC20Token{Address: "0x02", Tick: "USDI", DeployBlock: 100}, 200, true},
+	}
+
+	for _, tt := range tests {
+		err := NewProtocol(cache, []*config.ERC20Token{tt.token}).VerifyStart(tt.start)
+		if tt.valid {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.Error(t, err, tt.name)
+		}
+	}
+}
+
+func transferLog(token, from, to string, value int64) xycommon.RpcLog {
+	return xycommon.RpcLog{
+		Address: common.HexToAddress(token),
+		Topics:  []common.Hash{common.HexToHash(EventTopicTransfer), common.HexToHash(from), common.HexToHash(to)},
+		Data:    common.BigToHash(big.NewInt(value)).Bytes(),
+	}
+}
+
+func TestParse(t *testing.T) {
+	const (
+		token = "0x00000000000000000000000000000000000000cc"
+		addrA = "0x00000000000000000000000000000000000000aa"
+		addrB = "0x00000000000000000000000000000000000000bb"
+		addrD = "0x00000000000000000000000000000000000000dd"
+	)
+
+	cache := &dcache.Manager{
+		Inscription: dcache.NewInscription(),
+		Balance:     dcache.NewBalance(),
+	}
+	p := NewProtocol(cache, []*config.ERC20Token{{Address: strings.ToUpper(token), Tick: " USDX ", Decimals: 2, DeployBlock: 1}})
+
+	// the mint deploys the tick, the tokens received in the tx are spendable by the later events
+	tx := &xycommon.RpcTransaction{
+		Hash: "0x01",
+		Events: []xycommon.RpcLog{
+			transferLog(token, zeroAddress, addrA, 10000),
+			transferLog(token, addrA, addrB, 2500),
+			transferLog(token, addrB, addrD, 1000),
+			// zero transfers & foreign tokens are ignored
+			transferLog(token, addrA, addrD, 0),
+			transferLog("0x00000000000000000000000000000000000000ee", addrA, addrD, 1),
+		},
+	}
+	md, err := p.ParseMetaData("avalanche", tx)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, md) {
+		return
+	}
+	assert.Equal(t, types.ERC20Protocol, md.Protocol)
+	assert.Equal(t, "usdx", md.Tick)
+
+	results, insErr := p.Parse(&xycommon.RpcBlock{}, tx, md)
+	assert.Nil(t, insErr)
+	if !assert.Len(t, results, 3) {
+		return
+	}
+	assert.Equal(t, devents.OperateMint, results[0].MD.Operate)
+	assert.Equal(t, addrA, results[0].Mint.Minter)
+	assert.Equal(t, "100", results[0].Mint.Amount.String())
+	if assert.NotNil(t, results[0].Deploy) {
+		assert.Equal(t, int8(2), results[0].Deploy.Decimal)
+		assert.True(t, results[0].Deploy.StrictDecimals)
+	}
+	assert.Equal(t, devents.OperateTransfer, results[2].MD.Operate)
+	assert.Equal(t, addrB, results[2].Transfer.Sender)
+	assert.Equal(t, "10", results[2].Transfer.Receives[0].Amount.String())
+	assert.Nil(t, results[1].Deploy)
+	assert.Nil(t, results[2].Deploy)
+
+	// transfers exceeding the indexed balance are skipped
+	cache.Inscription.Create(types.ERC20Protocol, "usdx", &dcache.Tick{})
+	cache.Balance.Create(types.ERC20Protocol, "usdx", addrA, &dcache.BalanceItem{Overall: decimal.NewFromInt(5)})
+	tx = &xycommon.RpcTransaction{
+		Hash:   "0x02",
+		Events: []xycommon.RpcLog{transferLog(token, addrA, addrB, 1000), transferLog(token, addrA, zeroAddress, 500)},
+	}
+	results, insErr = p.Parse(&xycommon.RpcBlock{}, tx, md)
+	assert.Nil(t, insErr)
+	if assert.Len(t, results, 1) {
+		assert.Nil(t, results[0].Deploy)
+		assert.Equal(t, zeroAddress, results[0].Transfer.Receives[0].Address)
+	}
+
+	tx = &xycommon.RpcTransaction{Hash: "0x03", Events: []xycommon.RpcLog{transferLog(token, addrD, addrB, 1)}}
+	_, insErr = p.Parse(&xycommon.RpcBlock{}, tx, md)
+	assert.NotNil(t, insErr)
+}
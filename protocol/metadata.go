@@ -23,13 +23,16 @@
 package protocol
 
 import (
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/devents"
 	"github.com/uxuycom/indexer/model"
-	"github.com/uxuycom/indexer/protocol/avax/asc20"
+	"github.com/uxuycom/indexer/protocol/btc/ordinals"
+	"github.com/uxuycom/indexer/protocol/common"
+	"github.com/uxuycom/indexer/protocol/registry"
 	"strings"
 )
 
@@ -39,17 +42,34 @@ var EVMValidContentTypes = map[string]struct{}{
 	"application/json": {},
 }
 
-func ParseMetaData(chainName string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
-	switch chainName {
-	case model.ChainBTC:
-		return ParseBTCMetaData(chainName, tx)
-	}
+var CosmosValidContentTypes = map[string]struct{}{
+	"":                 {},
+	"text/plain":       {},
+	"application/json": {},
+}
 
-	// MethodID: 0xd9b3d6d0
-	if chainName == model.ChainAVAX && len(tx.Events) > 0 {
-		return asc20.ParseMetaDataByEventLogs(chainName, tx)
-	}
-	return ParseEVMMetaData(chainName, tx.Input)
+var BTCValidContentTypes = map[string]struct{}{
+	"text/plain":       {},
+	"application/json": {},
+}
+
+func init() {
+	registry.RegisterGroupParser(model.EvmChainGroup, func(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+		return ParseEVMMetaData(chain, tx.Input)
+	})
+	registry.RegisterGroupParser(model.BtcChainGroup, ParseBTCMetaData)
+	registry.RegisterGroupParser(model.CosmosChainGroup, ParseCosmosMetaData)
+
+	// data uris of the calldata, case insensitive & gzip compressed uris included
+	registry.RegisterGroupMatcher(model.EvmChainGroup, func(tx *xycommon.RpcTransaction) bool {
+		return common.IsDataURIHex(tx.Input)
+	})
+	// btc inscriptions are revealed in the taproot witness
+	registry.RegisterGroupMatcher(model.BtcChainGroup, ordinals.HasEnvelope)
+	// cosmos inscriptions are carried by the memo of the bank sends
+	registry.RegisterGroupMatcher(model.CosmosChainGroup, func(tx *xycommon.RpcTransaction) bool {
+		return tx.Memo != "" && tx.From != ""
+	})
 }
 
 func ParseEVMMetaData(chain string, inputData string) (*devents.MetaData, error) {
@@ -63,38 +83,70 @@ func ParseEVMMetaData(chain string, inputData string) (*devents.MetaData, error)
 		return nil, fmt.Errorf("input hex data decode err:%v", err)
 	}
 
-	// try json format data
-	input := string(bytes)
-	dataPrefixIdx := strings.Index(input, ",")
-	if dataPrefixIdx == -1 {
-		return nil, fmt.Errorf("data seprator index failed")
+	// the calldata may be gzip compressed as a whole (ESIP-7)
+	return parseDataURIMetaData(chain, bytes, EVMValidContentTypes, common.DataURIOptions{GzipInput: true})
+}
+
+// parseDataURIMetaData parses the "data:" uri with the json inscription content,
+// the base64 encoded & gzip compressed contents are decoded
+func parseDataURIMetaData(chain string, input []byte, contentTypes map[string]struct{}, opts common.DataURIOptions) (*devents.MetaData, error) {
+	md, uri, err := decodeDataURIMetaData(chain, input, contentTypes, opts)
+	if err == nil && !uri.FoldedScheme && !uri.Escaped {
+		return md, nil
+	}
+
+	// the upper case schemes & the percent escapes were not decoded before RFC 2397, the legacy metadata
+	// is indexed until the protocol rules activate the decoding
+	opts.Legacy = true
+	legacy, _, legacyErr := decodeDataURIMetaData(chain, input, contentTypes, opts)
+	switch {
+	case err != nil && legacyErr != nil:
+		return nil, err
+	case err != nil:
+		return &devents.MetaData{Chain: chain, Protocol: legacy.Protocol, Size: legacy.Size, Legacy: legacy}, nil
+	case legacyErr != nil:
+		legacy = &devents.MetaData{}
+	}
+	md.Legacy = legacy
+	return md, nil
+}
+
+func decodeDataURIMetaData(chain string, input []byte, contentTypes map[string]struct{}, opts common.DataURIOptions) (*devents.MetaData, *common.DataURI, error) {
+	uri, err := common.ParseDataURI(input, opts)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// max length limit
-	if len(input) > 256 {
-		return nil, fmt.Errorf("data character size[%d] > 256", len(input))
+	//set parse content types, the content is utf-8 json
+	if _, ok := contentTypes[uri.MediaType]; !ok {
+		return nil, nil, fmt.Errorf("tx content-type invalid & filtered, ct:%s", uri.MediaType)
 	}
 
-	//set parse content types
-	contentType := ""
-	if dataPrefixIdx > 5 {
-		contentType = input[5:dataPrefixIdx]
+	if charset, ok := uri.Param("charset"); ok && !strings.EqualFold(charset, "utf-8") && !strings.EqualFold(charset, "us-ascii") {
+		return nil, nil, fmt.Errorf("tx content charset[%s] not supported", charset)
 	}
-	contentType = strings.ToLower(contentType)
-	if _, ok := EVMValidContentTypes[contentType]; !ok {
-		return nil, fmt.Errorf("tx content-type invalid & filtered, ct:%s", contentType)
+
+	md, err := parseJSONMetaData(chain, string(uri.Data))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	data := input[dataPrefixIdx+1:]
+	// length of the input as inscribed, encoded or compressed, limited by the protocol rules
+	md.Size = len(input)
+	return md, uri, nil
+}
+
+// parseJSONMetaData parses the json inscription content
+func parseJSONMetaData(chain string, data string) (*devents.MetaData, error) {
 	proto := &devents.MetaData{}
 	if err := json.Unmarshal([]byte(data), proto); err != nil {
 		return nil, fmt.Errorf("tx input data parsed failed, data[%s], err[%v]", data, err)
 	}
 
-	// trim prefix / suffix spaces & case insensitive
+	// trim prefix / suffix spaces & case insensitive, the tick is normalized by the tick rule of the protocol
 	proto.Protocol = strings.ToLower(strings.TrimSpace(proto.Protocol))
 	proto.Operate = strings.ToLower(strings.TrimSpace(proto.Operate))
-	proto.Tick = strings.ToLower(strings.TrimSpace(proto.Tick))
+	proto.Tick = strings.TrimSpace(proto.Tick)
 
 	// data checking
 	if proto.Protocol == "" || proto.Tick == "" {
@@ -105,6 +157,47 @@ func ParseEVMMetaData(chain string, inputData string) (*devents.MetaData, error)
 	return proto, nil
 }
 
+// ParseCosmosMetaData parses the "data:" uri in the memo, base64 encoded memos are accepted too
+func ParseCosmosMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	memo := []byte(strings.TrimSpace(tx.Memo))
+	if !common.IsDataURI(memo) {
+		decoded, err := base64.StdEncoding.DecodeString(string(memo))
+		if err != nil || !common.IsDataURI(decoded) {
+			return nil, fmt.Errorf("memo data prefix checking failed")
+		}
+		memo = decoded
+	}
+	return parseDataURIMetaData(chain, memo, CosmosValidContentTypes, common.DataURIOptions{})
+}
+
+// ParseBTCMetaData parses the first inscription revealed by the tx, which must be in the first input
 func ParseBTCMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
-	return nil, nil
+	inscriptions := ordinals.ParseTx(tx)
+	if len(inscriptions) < 1 {
+		return nil, fmt.Errorf("inscription envelope not found")
+	}
+
+	ins := inscriptions[0]
+	if ins.Input != 0 {
+		return nil, fmt.Errorf("inscription revealed in input[%d] & ignored", ins.Input)
+	}
+
+	if ins.UnrecognizedField || ins.DuplicateField || ins.IncompleteField {
+		return nil, fmt.Errorf("inscription envelope fields invalid")
+	}
+
+	if ins.ContentEncoding != "" {
+		return nil, fmt.Errorf("inscription content encoding[%s] not supported", ins.ContentEncoding)
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(ins.ContentType, ";")[0]))
+	if _, ok := BTCValidContentTypes[contentType]; !ok {
+		return nil, fmt.Errorf("inscription content-type invalid & filtered, ct:%s", ins.ContentType)
+	}
+	md, err := parseJSONMetaData(chain, string(ins.Body))
+	if err != nil {
+		return nil, err
+	}
+	md.Size = len(ins.Body)
+	return md, nil
 }
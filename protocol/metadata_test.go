@@ -23,10 +23,24 @@
 package protocol
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"encoding/hex"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/devents"
 	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/btc/ordinals"
+	protocommon "github.com/uxuycom/indexer/protocol/common"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -54,25 +68,20 @@ func TestParseEVMMetaData(t *testing.T) {
 				Chain:    model.ChainAVAX,
 				Operate:  "deploy",
 				Protocol: "asc-20",
-				Tick:     "tduck",
+				Tick:     "Tduck",
 				Data:     "{\"p\":\"asc-20\",\"op\":\"deploy\",\"tick\":\"Tduck\",\"max\":\"210000000\",\"lim\":\"1000\"}",
+				Size:     80,
 			},
 			wantErr: false,
 		},
 		{
-			name: "Can be passed",
+			name: "Data scheme missing",
 			args: args{
 				chain:     model.ChainAVAX,
 				inputData: "0x" + inputData,
 			},
-			want: &devents.MetaData{
-				Chain:    model.ChainAVAX,
-				Operate:  "deploy",
-				Protocol: "asc-20",
-				Tick:     "tduck",
-				Data:     "{\"p\":\"asc-20\",\"op\":\"deploy\",\"tick\":\"Tduck\",\"max\":\"210000000\",\"lim\":\"1000\"}",
-			},
-			wantErr: false,
+			want:    nil,
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -88,3 +97,238 @@ func TestParseEVMMetaData(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEVMMetaDataURIVariants(t *testing.T) {
+	body := `{"p":"asc-20","op":"mint","tick":"Duck","amt":"1"}`
+	compress := func(data []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(data)
+		_ = w.Close()
+		return buf.Bytes()
+	}
+	input := func(data []byte) string {
+		return "0x" + hex.EncodeToString(data)
+	}
+
+	for name, data := range map[string][]byte{
+		"plain":          []byte("data:," + body),
+		"scheme case":    []byte("DATA:application/json," + body),
+		"charset":        []byte("data:application/json;charset=UTF-8," + body),
+		"base64":         []byte("data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(body))),
+		"gzip base64":    []byte("data:application/json;content-encoding=gzip;base64," + base64.StdEncoding.EncodeToString(compress([]byte(body)))),
+		"gzip calldata":  compress([]byte("data:text/plain;charset=utf-8," + body)),
+		"esip6 rule":     []byte("data:;rule=esip6," + body),
+		"mixed params":   []byte("data:application/json;rule=esip6;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(body))),
+		"gzip whole b64": compress([]byte("data:;content-encoding=gzip;base64," + base64.StdEncoding.EncodeToString(compress([]byte(body))))),
+		"percent":        []byte("data:," + strings.NewReplacer("{", "%7B", "}", "%7d", `"`, "%22").Replace(body)),
+	} {
+		md, err := ParseEVMMetaData(model.ChainAVAX, input(data))
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, "Duck", md.Tick, name)
+			assert.Equal(t, body, md.Data, name)
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"image":          []byte("data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(body))),
+		"charset":        []byte("data:application/json;charset=utf-16," + body),
+		"bad media type": []byte("data:json," + body),
+		"bad param":      []byte("data:application/json;gzip," + body),
+		"base64 not last": []byte("data:application/json;base64;charset=utf-8," +
+			base64.StdEncoding.EncodeToString([]byte(body))),
+		"bad base64":      []byte("data:;base64,{}"),
+		"bad gzip":        append([]byte{0x1f, 0x8b}, []byte("data:,"+body)...),
+		"undeclared gzip": []byte("data:application/json;base64," + base64.StdEncoding.EncodeToString(compress([]byte(body)))),
+		"unknown encoding": []byte("data:application/json;content-encoding=br;base64," +
+			base64.StdEncoding.EncodeToString([]byte(body))),
+		"no separator": []byte("data:" + body[:10]),
+	} {
+		_, err := ParseEVMMetaData(model.ChainAVAX, input(data))
+		assert.Error(t, err, name)
+	}
+
+	// the size limited by the protocol rules is the length of the input as inscribed, not the decoded one
+	long := `{"p":"asc-20","op":"mint","tick":"duck","amt":"1","memo":"` + strings.Repeat("x", 150) + `"}`
+	encoded := "data:;base64," + base64.StdEncoding.EncodeToString([]byte(long))
+	md, err := ParseEVMMetaData(model.ChainAVAX, input([]byte(encoded)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, len(encoded), md.Size)
+		p := protocommon.NewProtocol(nil, protocommon.BaseSchedule(protocommon.DefaultRules, "asc-20"))
+		assert.Error(t, p.NormalizeMetaData(&xycommon.RpcTransaction{}, md))
+	}
+
+	md, err = ParseEVMMetaData(model.ChainAVAX, input([]byte("data:,"+long)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, len("data:,")+len(long), md.Size)
+		p := protocommon.NewProtocol(nil, protocommon.BaseSchedule(protocommon.DefaultRules, "asc-20"))
+		assert.NoError(t, p.NormalizeMetaData(&xycommon.RpcTransaction{}, md))
+	}
+
+	compressed := compress([]byte("data:," + long))
+	md, err = ParseEVMMetaData(model.ChainAVAX, input(compressed))
+	if assert.NoError(t, err) {
+		assert.Equal(t, len(compressed), md.Size)
+	}
+}
+
+func TestParseBTCMetaData(t *testing.T) {
+	reveal := func(contentType, encoding, body string) *xycommon.RpcTransaction {
+		builder := txscript.NewScriptBuilder().
+			AddData(bytes.Repeat([]byte{0x02}, 32)).AddOp(txscript.OP_CHECKSIG).
+			AddOp(txscript.OP_FALSE).AddOp(txscript.OP_IF).AddData([]byte("ord")).
+			AddData([]byte{ordinals.TagContentType}).AddData([]byte(contentType))
+		if encoding != "" {
+			builder.AddData([]byte{ordinals.TagContentEncoding}).AddData([]byte(encoding))
+		}
+		script, _ := builder.AddOp(txscript.OP_0).AddData([]byte(body)).AddOp(txscript.OP_ENDIF).Script()
+
+		witness := []string{
+			hex.EncodeToString(bytes.Repeat([]byte{0x03}, 64)),
+			hex.EncodeToString(script),
+			hex.EncodeToString(append([]byte{0xc0}, bytes.Repeat([]byte{0x04}, 32)...)),
+		}
+		return &xycommon.RpcTransaction{Vin: []btcjson.Vin{{Txid: "00", Witness: witness}}}
+	}
+
+	body := `{"p":"BRC-20","op":"mint","tick":"ORDI","amt":"1000"}`
+	md, err := ParseBTCMetaData(model.ChainBTC, reveal("text/plain;charset=utf-8", "", body))
+	if err != nil {
+		t.Fatalf("ParseBTCMetaData() error = %v", err)
+	}
+
+	want := &devents.MetaData{
+		Chain:    model.ChainBTC,
+		Protocol: "brc-20",
+		Operate:  "mint",
+		Tick:     "ORDI",
+		Data:     body,
+		Size:     len(body),
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("ParseBTCMetaData() got = %v, want %v", md, want)
+	}
+
+	for _, tx := range []*xycommon.RpcTransaction{
+		reveal("image/png", "", body),
+		reveal("text/plain", "br", body),
+		reveal("text/plain", "", "not json"),
+		{Vin: []btcjson.Vin{{Txid: "00"}}},
+	} {
+		if _, err = ParseBTCMetaData(model.ChainBTC, tx); err == nil {
+			t.Errorf("ParseBTCMetaData() should fail")
+		}
+	}
+}
+
+func TestResolveEventCreation(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	data, err := abi.Arguments{{Type: stringType}}.Pack(`data:,{"p":"asc-20","op":"mint","tick":"duck","amt":"1"}`)
+	assert.NoError(t, err)
+
+	contract := "0x00000000000000000000000000000000000000cc"
+	owner := "0x00000000000000000000000000000000000000bb"
+	tx := &xycommon.RpcTransaction{
+		Hash:  "0x01",
+		From:  "0x00000000000000000000000000000000000000aa",
+		To:    contract,
+		Input: "0x",
+		Events: []xycommon.RpcLog{{
+			Address: common.HexToAddress(contract),
+			Topics:  []common.Hash{common.HexToHash(EventTopicHashCreate), common.HexToHash(owner)},
+			Data:    data,
+		}},
+	}
+
+	resolved := ResolveEventCreation(tx)
+	assert.Equal(t, contract, resolved.From)
+	assert.Equal(t, owner, resolved.To)
+	assert.Equal(t, tx.Hash, resolved.Hash)
+
+	md, err := ParseEVMMetaData(model.ChainETH, resolved.Input)
+	assert.NoError(t, err)
+	assert.Equal(t, devents.OperateMint, md.Operate)
+
+	// the input inscription takes precedence over the emitted ones
+	tx.Input = "0x" + hex.EncodeToString([]byte("data:,hello"))
+	assert.Equal(t, tx, ResolveEventCreation(tx))
+}
+
+func TestResolveBlobInscription(t *testing.T) {
+	// the payload spans two blobs
+	payload := []byte(strings.Repeat("blob", 40000))
+	blobs := EncodeBlobs(payload)
+	assert.Len(t, blobs, 2)
+
+	decoded, err := DecodeBlobs(blobs)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decoded)
+
+	// blobs without the terminator are invalid
+	_, err = DecodeBlobs(blobs[:1])
+	assert.Error(t, err)
+
+	tx := &xycommon.RpcTransaction{
+		Hash:       "0x01",
+		From:       "0x00000000000000000000000000000000000000aa",
+		To:         "0x00000000000000000000000000000000000000aa",
+		Input:      "0x",
+		BlobHashes: []string{"0x01aa"},
+		Blobs:      EncodeBlobs([]byte(`data:,{"p":"asc-20","op":"mint","tick":"duck","amt":"1"}`)),
+	}
+
+	resolved := ResolveBlobInscription(tx)
+	if assert.NotNil(t, resolved) {
+		assert.Equal(t, "0x01#blob", resolved.Hash)
+		assert.Equal(t, tx.From, resolved.From)
+		assert.Nil(t, resolved.Blobs)
+
+		md, err := ParseEVMMetaData(model.ChainETH, resolved.Input)
+		assert.NoError(t, err)
+		assert.Equal(t, devents.OperateMint, md.Operate)
+	}
+
+	// blobs without the inscription are not resolved
+	tx.Blobs = EncodeBlobs([]byte("rollup batch"))
+	assert.Nil(t, ResolveBlobInscription(tx))
+}
+
+func TestParseEVMMetaDataLegacyURI(t *testing.T) {
+	rfc2397 := true
+	schedule, err := protocommon.NewSchedule(protocommon.DefaultRules, "asc-20", []*config.ProtocolRules{
+		{Protocol: "asc-20", Height: 100, RFC2397: &rfc2397},
+	})
+	assert.NoError(t, err)
+	p := protocommon.NewProtocol(nil, schedule)
+	at := func(height int64) *xycommon.RpcTransaction {
+		return &xycommon.RpcTransaction{BlockNumber: big.NewInt(height)}
+	}
+	parse := func(uri string) *devents.MetaData {
+		md, err := ParseEVMMetaData(model.ChainAVAX, "0x"+hex.EncodeToString([]byte(uri)))
+		assert.NoError(t, err, uri)
+		return md
+	}
+
+	// the upper case schemes are matched from the activation height
+	uri := `DATA:,{"p":"asc-20","op":"mint","tick":"duck","amt":"1"}`
+	assert.Error(t, p.NormalizeMetaData(at(99), parse(uri)))
+	assert.NoError(t, p.NormalizeMetaData(at(100), parse(uri)))
+
+	// the escapes are taken verbatim before the activation height
+	uri = `data:,{"p":"asc-20","op":"mint","tick":"duck","amt":"1","memo":"100%25"}`
+	md := parse(uri)
+	assert.NoError(t, p.NormalizeMetaData(at(99), md))
+	assert.Equal(t, uri[len("data:,"):], md.Data)
+	assert.Nil(t, md.Legacy)
+
+	md = parse(uri)
+	assert.NoError(t, p.NormalizeMetaData(at(100), md))
+	assert.Equal(t, `{"p":"asc-20","op":"mint","tick":"duck","amt":"1","memo":"100%"}`, md.Data)
+
+	// the escapes breaking the json are valid before the activation height only
+	uri = `data:,{"p":"asc-20","op":"mint","tick":"du%22ck","amt":"1"}`
+	md = parse(uri)
+	assert.NoError(t, p.NormalizeMetaData(at(99), md))
+	assert.Equal(t, "du%22ck", md.Tick)
+	assert.Error(t, p.NormalizeMetaData(at(100), parse(uri)))
+}
@@ -23,57 +23,129 @@
 package protocol
 
 import (
+	"fmt"
 	"github.com/uxuycom/indexer/client/xycommon"
 	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/dcache"
 	"github.com/uxuycom/indexer/devents"
-	"github.com/uxuycom/indexer/model"
-	"github.com/uxuycom/indexer/protocol/avax/asc20"
-	btcBrc20 "github.com/uxuycom/indexer/protocol/btc/brc20"
-	"github.com/uxuycom/indexer/protocol/evm/brc20"
+	_ "github.com/uxuycom/indexer/protocol/avax/asc20"
+	_ "github.com/uxuycom/indexer/protocol/btc/brc20"
+	_ "github.com/uxuycom/indexer/protocol/btc/runes"
+	_ "github.com/uxuycom/indexer/protocol/cosmos/cia20"
+	_ "github.com/uxuycom/indexer/protocol/eth/ethscriptions"
+	_ "github.com/uxuycom/indexer/protocol/evm/adapter"
+	_ "github.com/uxuycom/indexer/protocol/evm/brc20"
+	_ "github.com/uxuycom/indexer/protocol/evm/erc20"
+	"github.com/uxuycom/indexer/protocol/registry"
 	"github.com/uxuycom/indexer/protocol/types"
 	"github.com/uxuycom/indexer/storage"
 	"github.com/uxuycom/indexer/xylog"
+	"math"
+	"strings"
 )
 
-var (
-	BTCBrc20Protocol *btcBrc20.Protocol
-	EvmAsc20Protocol *asc20.Protocol
-	EvmBrc20Protocol *brc20.Protocol
-)
+// protocols enabled on the indexed chain, protocol packages register themselves in registry
+var protocols *registry.Registry
+
+// chainProtocols protocols by the chain names, the ticks looked up out of the inscription envelope
+// are normalized by the tick rules of their chain protocols
+var chainProtocols = make(map[string]*registry.Registry)
+
+// LatestHeight height of the rules in force now, for the ticks not bound to a tx
+const LatestHeight = math.MaxUint64
+
+func InitProtocols(cfg *config.Config, cache *dcache.Manager) error {
+	r, err := registry.New(cfg, cache)
+	if err != nil {
+		return err
+	}
+
+	protocols = r
+	chainProtocols[strings.ToLower(cfg.Chain.ChainName)] = r
+	xylog.Logger.Infof("protocols enabled on chain[%s]: %v", cfg.Chain.ChainName, protocols.Protocols())
+	return nil
+}
 
-func InitProtocols(cache *dcache.Manager) {
-	BTCBrc20Protocol = btcBrc20.NewProtocol(cache)
-	EvmBrc20Protocol = brc20.NewProtocol(cache)
-	EvmAsc20Protocol = asc20.NewProtocol(cache)
+// InitChainProtocols creates the protocols of the chains served by the api, no tx is indexed by them
+func InitChainProtocols(chains []*config.ChainConfig) error {
+	for _, chain := range chains {
+		r, err := registry.New(&config.Config{Chain: *chain}, nil)
+		if err != nil {
+			return fmt.Errorf("chain[%s] protocols init err:%v", chain.ChainName, err)
+		}
+		chainProtocols[strings.ToLower(chain.ChainName)] = r
+	}
+	return nil
+}
+
+// NormalizeTick returns the tick identity of the tick by the tick rule of the chain protocol in force at the height,
+// the ticks of the unknown chains are folded to lower case
+func NormalizeTick(chain, protocol string, height uint64, tick string) string {
+	r, ok := chainProtocols[strings.ToLower(chain)]
+	if !ok {
+		return strings.ToLower(strings.TrimSpace(tick))
+	}
+	return r.NormalizeTick(protocol, height, tick)
 }
 
 func GetProtocol(cfg *config.Config, tx *xycommon.RpcTransaction) (types.IProtocol, *devents.MetaData) {
-	md, err := ParseMetaData(cfg.Chain.ChainName, tx)
+	md, err := protocols.ParseMetaData(tx)
 	if md == nil {
 		xylog.Logger.Infof("metadata parsed failed, block:%d-tx:%s, err:%v", tx.BlockNumber, tx.Hash, err)
 		return nil, nil
 	}
 
-	// btc types protocols
-	if cfg.Chain.ChainGroup == model.BtcChainGroup {
-		switch md.Protocol {
-		case types.BRC20Protocol:
-			return BTCBrc20Protocol, md
-		}
+	pt := protocols.Get(md.Protocol)
+	if pt == nil {
+		xylog.Logger.Infof("protocol[%s] not registered & ignore, block:%d-tx:%s", md.Protocol, tx.BlockNumber, tx.Hash)
 		return nil, nil
 	}
+	return pt, md
+}
+
+// Matched protocol instance & metadata of the tx
+type Matched struct {
+	Protocol types.IProtocol
+	MD       *devents.MetaData
+}
+
+// GetProtocols returns the primary protocol of the tx followed by the shared protocols indexing it too
+func GetProtocols(cfg *config.Config, tx *xycommon.RpcTransaction) []*Matched {
+	items := make([]*Matched, 0, 2)
+	if pt, md := GetProtocol(cfg, tx); pt != nil {
+		items = append(items, &Matched{Protocol: pt, MD: md})
+	}
 
-	// default protocols: evm
-	switch md.Protocol {
-	case types.ASC20Protocol:
-		return EvmAsc20Protocol, md
-	default:
-		return EvmBrc20Protocol, md
+	for _, md := range protocols.ParseSharedMetaData(tx) {
+		if pt := protocols.Get(md.Protocol); pt != nil {
+			items = append(items, &Matched{Protocol: pt, MD: md})
+		}
 	}
+	return items
+}
+
+// VerifyStart checks the enabled protocols can be indexed from the scan start block
+func VerifyStart(startBlock uint64) error {
+	return protocols.VerifyStart(startBlock)
+}
+
+// MatchTx pre-filters the txs possibly carrying the data of the enabled protocols
+func MatchTx(tx *xycommon.RpcTransaction) bool {
+	return protocols.MatchTx(tx)
+}
+
+// EventTopics returns the event topics scanned for the enabled protocols
+func EventTopics() []string {
+	return protocols.EventTopics()
 }
 
 func GetOperateByTxInput(chain, inputData string, db *storage.DBClient) *devents.MetaData {
-	md, _ := ParseMetaData(chain, &xycommon.RpcTransaction{Input: inputData})
+	md, _ := ParseEVMMetaData(chain, inputData)
+	if md == nil {
+		return nil
+	}
+
+	// the input is not bound to a tx, its tick is looked up by the rules in force now
+	md.Tick = NormalizeTick(chain, md.Protocol, LatestHeight, md.Tick)
 	return md
 }
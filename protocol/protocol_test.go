@@ -0,0 +1,57 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package protocol
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/model"
+	"testing"
+)
+
+func TestNormalizeTick(t *testing.T) {
+	tickRule := "unicode"
+	err := InitChainProtocols([]*config.ChainConfig{
+		{
+			ChainName:     model.ChainAVAX,
+			ChainGroup:    model.EvmChainGroup,
+			ProtocolRules: []*config.ProtocolRules{{Protocol: "asc-20", Height: 100, TickRule: &tickRule}},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "ｄｕｃｋ", NormalizeTick("Avalanche", "asc-20", 99, " ＤＵＣＫ "))
+	assert.Equal(t, "duck", NormalizeTick("avalanche", "ASC-20", 100, " ＤＵＣＫ "))
+	assert.Equal(t, "duck", NormalizeTick("avalanche", "asc-20", LatestHeight, "ＤＵＣＫ"))
+
+	// the ticks of the unknown chains are folded to lower case
+	assert.Equal(t, "ｄｕｃｋ", NormalizeTick("unknown", "asc-20", LatestHeight, " ＤＵＣＫ "))
+
+	input := hex.EncodeToString([]byte(`data:,{"p":"asc-20","op":"mint","tick":"ＤＵＣＫ","amt":"1"}`))
+	md := GetOperateByTxInput(model.ChainAVAX, "0x"+input, nil)
+	if !assert.NotNil(t, md) {
+		return
+	}
+	assert.Equal(t, "duck", md.Tick)
+}
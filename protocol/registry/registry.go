@@ -0,0 +1,344 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package registry
+
+import (
+	"fmt"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MetaDataParser parses the inscription metadata of the tx, nil returned if the tx is not recognized
+type MetaDataParser func(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error)
+
+// TxMatcher tells cheaply whether the tx may carry the protocol data
+type TxMatcher func(tx *xycommon.RpcTransaction) bool
+
+// Factory creates the protocol instance of the indexed chain, the invalid protocol config is returned as error
+type Factory func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error)
+
+// Entry of one protocol
+type Entry struct {
+	ChainGroup model.ChainGroup
+	Chain      string         // chain name, empty for all chains of the group
+	Protocol   string         // protocol name, case insensitive
+	Parser     MetaDataParser // optional, protocol own metadata format tried before the group envelope parser, defaults to the instance types.IMetaDataParser
+	Factory    Factory
+
+	// Shared protocols index the txs alongside the primary protocol of the tx, their parser is required
+	Shared bool
+
+	// EventTopics event logs to be scanned for the protocol, merged with the configured filter topics
+	EventTopics []string
+}
+
+var (
+	registerLock  sync.RWMutex
+	entries       = make(map[string]*Entry)
+	groupParsers  = make(map[model.ChainGroup]MetaDataParser)
+	groupTopics   = make(map[model.ChainGroup][]string)
+	groupMatchers = make(map[model.ChainGroup]TxMatcher)
+)
+
+func entryKey(group model.ChainGroup, chain, protocol string) string {
+	return fmt.Sprintf("%s/%s/%s", group, strings.ToLower(chain), normalize(protocol))
+}
+
+func normalize(protocol string) string {
+	return strings.ToLower(strings.TrimSpace(protocol))
+}
+
+// Register adds the protocol entry, the same protocol can not be registered twice on one chain
+func Register(entry Entry) error {
+	if entry.ChainGroup == "" || normalize(entry.Protocol) == "" {
+		return fmt.Errorf("chain group[%s] / protocol[%s] empty", entry.ChainGroup, entry.Protocol)
+	}
+
+	if entry.Factory == nil {
+		return fmt.Errorf("protocol[%s] factory nil", entry.Protocol)
+	}
+
+	registerLock.Lock()
+	defer registerLock.Unlock()
+
+	key := entryKey(entry.ChainGroup, entry.Chain, entry.Protocol)
+	if _, ok := entries[key]; ok {
+		return fmt.Errorf("protocol[%s] already registered", key)
+	}
+	entries[key] = &entry
+	return nil
+}
+
+// MustRegister performs the same function as Register except it panics
+// if there is an error.  This should only be called from package init
+// functions.
+func MustRegister(entry Entry) {
+	if err := Register(entry); err != nil {
+		panic(fmt.Sprintf("failed to register protocol: %v", err))
+	}
+}
+
+// RegisterGroupParser sets the envelope parser shared by all protocols of the chain group
+func RegisterGroupParser(group model.ChainGroup, parser MetaDataParser) {
+	registerLock.Lock()
+	defer registerLock.Unlock()
+
+	groupParsers[group] = parser
+}
+
+// GroupParser returns the envelope parser of the chain group, nil if not registered
+func GroupParser(group model.ChainGroup) MetaDataParser {
+	registerLock.RLock()
+	defer registerLock.RUnlock()
+
+	return groupParsers[group]
+}
+
+// RegisterGroupMatcher sets the pre-filter of the txs carrying the envelope of the group parser
+func RegisterGroupMatcher(group model.ChainGroup, matcher TxMatcher) {
+	registerLock.Lock()
+	defer registerLock.Unlock()
+
+	groupMatchers[group] = matcher
+}
+
+// RegisterGroupEventTopics adds the event logs scanned for all protocols of the chain group
+func RegisterGroupEventTopics(group model.ChainGroup, topics ...string) {
+	registerLock.Lock()
+	defer registerLock.Unlock()
+
+	groupTopics[group] = append(groupTopics[group], topics...)
+}
+
+type protocolParser struct {
+	protocol string
+	parser   MetaDataParser
+}
+
+// Registry
+/*****************************************************
+ * protocol instances enabled on the indexed chain,
+ * unregistered protocols are rejected
+ ****************************************************/
+type Registry struct {
+	chain       string
+	protocols   map[string]types.IProtocol
+	parsers     []*protocolParser
+	shared      []*protocolParser
+	groupParser MetaDataParser
+	matchers    []TxMatcher
+	topics      []string
+}
+
+// New creates the protocol instances registered for the configured chain,
+// chain specific entries take precedence over the group wide ones
+func New(cfg *config.Config, cache *dcache.Manager) (*Registry, error) {
+	group := cfg.Chain.ChainGroup
+	if group == "" {
+		group = model.EvmChainGroup
+	}
+
+	registerLock.RLock()
+	defer registerLock.RUnlock()
+
+	matched := make(map[string]*Entry, len(entries))
+	for _, entry := range entries {
+		if entry.ChainGroup != group {
+			continue
+		}
+
+		protocol := normalize(entry.Protocol)
+		switch {
+		case strings.EqualFold(entry.Chain, cfg.Chain.ChainName):
+			matched[protocol] = entry
+		case entry.Chain == "":
+			if _, ok := matched[protocol]; !ok {
+				matched[protocol] = entry
+			}
+		}
+	}
+
+	r := &Registry{
+		chain:       cfg.Chain.ChainName,
+		protocols:   make(map[string]types.IProtocol, len(matched)),
+		parsers:     make([]*protocolParser, 0, len(matched)),
+		groupParser: groupParsers[group],
+		topics:      append([]string{}, groupTopics[group]...),
+	}
+	if matcher, ok := groupMatchers[group]; ok {
+		r.matchers = append(r.matchers, matcher)
+	}
+	for protocol, entry := range matched {
+		pt, err := entry.Factory(cfg, cache)
+		if err != nil {
+			return nil, fmt.Errorf("protocol[%s] init err:%v", protocol, err)
+		}
+		r.protocols[protocol] = pt
+
+		parser := entry.Parser
+		if mp, ok := pt.(types.IMetaDataParser); ok && parser == nil {
+			parser = mp.ParseMetaData
+		}
+
+		if tm, ok := pt.(types.ITxMatcher); ok {
+			r.matchers = append(r.matchers, tm.MatchTx)
+		}
+
+		r.topics = append(r.topics, entry.EventTopics...)
+		if es, ok := pt.(types.IEventSubscriber); ok {
+			r.topics = append(r.topics, es.EventTopics()...)
+		}
+		if parser == nil {
+			continue
+		}
+
+		if entry.Shared {
+			r.shared = append(r.shared, &protocolParser{protocol: protocol, parser: parser})
+			continue
+		}
+		r.parsers = append(r.parsers, &protocolParser{protocol: protocol, parser: parser})
+	}
+
+	for _, pt := range r.protocols {
+		if tr, ok := pt.(types.ITickResolver); ok {
+			tr.SetTickNormalizer(r.NormalizeTick)
+		}
+	}
+
+	sort.Slice(r.parsers, func(i, j int) bool {
+		return r.parsers[i].protocol < r.parsers[j].protocol
+	})
+	sort.Slice(r.shared, func(i, j int) bool {
+		return r.shared[i].protocol < r.shared[j].protocol
+	})
+	sort.Strings(r.topics)
+	return r, nil
+}
+
+// ParseMetaData tries the protocol own parsers first, then the group envelope parser
+func (r *Registry) ParseMetaData(tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	for _, p := range r.parsers {
+		md, err := p.parser(r.chain, tx)
+		if err == nil && md != nil && normalize(md.Protocol) == p.protocol {
+			return md, nil
+		}
+	}
+
+	if r.groupParser == nil {
+		return nil, fmt.Errorf("no metadata parser registered for chain[%s]", r.chain)
+	}
+
+	md, err := r.groupParser(r.chain, tx)
+	if err != nil || md == nil {
+		return md, err
+	}
+
+	// the ticks are folded to lower case & the data uris decoded by RFC 2397 by default
+	mn, ok := r.protocols[normalize(md.Protocol)].(types.IMetaDataNormalizer)
+	if !ok {
+		if md.Legacy != nil && md.Data == "" {
+			return nil, fmt.Errorf("protocol[%s] data uri rejected by RFC 2397", md.Protocol)
+		}
+		md.Legacy = nil
+		md.Tick = strings.ToLower(strings.TrimSpace(md.Tick))
+		return md, nil
+	}
+
+	if err := mn.NormalizeMetaData(tx, md); err != nil {
+		return nil, fmt.Errorf("protocol[%s] metadata checking failed: %v", md.Protocol, err)
+	}
+	return md, nil
+}
+
+// ParseSharedMetaData returns the metadata of all shared protocols recognizing the tx
+func (r *Registry) ParseSharedMetaData(tx *xycommon.RpcTransaction) []*devents.MetaData {
+	mds := make([]*devents.MetaData, 0, len(r.shared))
+	for _, p := range r.shared {
+		md, err := p.parser(r.chain, tx)
+		if err == nil && md != nil && normalize(md.Protocol) == p.protocol {
+			mds = append(mds, md)
+		}
+	}
+	return mds
+}
+
+// MatchTx checks whether the group envelope or any enabled protocol may be carried by the tx
+func (r *Registry) MatchTx(tx *xycommon.RpcTransaction) bool {
+	for _, match := range r.matchers {
+		if match(tx) {
+			return true
+		}
+	}
+	return false
+}
+
+// EventTopics returns the sorted event topics of the enabled protocols
+func (r *Registry) EventTopics() []string {
+	return r.topics
+}
+
+// NormalizeTick returns the tick identity of the tick by the tick rule of the protocol in force at the height,
+// the ticks are folded to lower case by default
+func (r *Registry) NormalizeTick(protocol string, height uint64, tick string) string {
+	if tn, ok := r.protocols[normalize(protocol)].(types.ITickNormalizer); ok {
+		return tn.NormalizeTick(height, tick)
+	}
+	return strings.ToLower(strings.TrimSpace(tick))
+}
+
+// VerifyStart checks the enabled protocols can be indexed from the scan start block
+func (r *Registry) VerifyStart(startBlock uint64) error {
+	for _, protocol := range r.Protocols() {
+		sv, ok := r.protocols[protocol].(types.IStartVerifier)
+		if !ok {
+			continue
+		}
+
+		if err := sv.VerifyStart(startBlock); err != nil {
+			return fmt.Errorf("protocol[%s] start checking failed: %v", protocol, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the protocol instance, nil if the protocol is not registered
+func (r *Registry) Get(protocol string) types.IProtocol {
+	return r.protocols[normalize(protocol)]
+}
+
+// Protocols returns the sorted names of the enabled protocols
+func (r *Registry) Protocols() []string {
+	protocols := make([]string, 0, len(r.protocols))
+	for protocol := range r.protocols {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+	return protocols
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package registry
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/client/xycommon"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/dcache"
+	"github.com/uxuycom/indexer/devents"
+	"github.com/uxuycom/indexer/model"
+	"github.com/uxuycom/indexer/protocol/types"
+	"github.com/uxuycom/indexer/xyerrors"
+	"strings"
+	"testing"
+)
+
+const testChainGroup model.ChainGroup = "test"
+
+type fakeProtocol struct {
+	name string
+}
+
+func (p *fakeProtocol) Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError) {
+	return nil, nil
+}
+
+// stateProtocol recognizes the txs sent to its own address
+type stateProtocol struct {
+	fakeProtocol
+}
+
+func (p *stateProtocol) ParseMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+	if tx.To != p.name {
+		return nil, nil
+	}
+	return &devents.MetaData{Chain: chain, Protocol: "state-20"}, nil
+}
+
+// tickProtocol folds the ticks to upper case
+type tickProtocol struct {
+	fakeProtocol
+}
+
+func (p *tickProtocol) NormalizeMetaData(tx *xycommon.RpcTransaction, md *devents.MetaData) error {
+	if len(md.Tick) > 4 {
+		return fmt.Errorf("tick too long")
+	}
+	md.Tick = p.NormalizeTick(0, md.Tick)
+	return nil
+}
+
+func (p *tickProtocol) NormalizeTick(height uint64, tick string) string {
+	return strings.ToUpper(tick)
+}
+
+// resolverProtocol looks up the ticks of the other protocols
+type resolverProtocol struct {
+	fakeProtocol
+	normalize func(protocol string, height uint64, tick string) string
+}
+
+func (p *resolverProtocol) SetTickNormalizer(normalize func(protocol string, height uint64, tick string) string) {
+	p.normalize = normalize
+}
+
+// subscriberProtocol scans the topics configured by the chain name
+type subscriberProtocol struct {
+	fakeProtocol
+}
+
+func (p *subscriberProtocol) EventTopics() []string {
+	return []string{p.name}
+}
+
+// matcherProtocol matches the txs sent to its own address
+type matcherProtocol struct {
+	fakeProtocol
+}
+
+func (p *matcherProtocol) MatchTx(tx *xycommon.RpcTransaction) bool {
+	return tx.To == p.name
+}
+
+// VerifyStart the history of the protocol starts at the block 100
+func (p *matcherProtocol) VerifyStart(startBlock uint64) error {
+	if startBlock > 100 {
+		return fmt.Errorf("start block[%d] > 100", startBlock)
+	}
+	return nil
+}
+
+func fakeFactory(name string) Factory {
+	return func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+		return &fakeProtocol{name: name}, nil
+	}
+}
+
+func init() {
+	RegisterGroupParser(testChainGroup, func(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+		return &devents.MetaData{Chain: chain, Protocol: tx.Input, Operate: devents.OperateMint, Tick: tx.Hash, Data: tx.Memo}, nil
+	})
+
+	RegisterGroupEventTopics(testChainGroup, "0xgroup")
+	RegisterGroupMatcher(testChainGroup, func(tx *xycommon.RpcTransaction) bool {
+		return tx.Input != ""
+	})
+
+	MustRegister(Entry{ChainGroup: testChainGroup, Protocol: "xyz-20", Factory: fakeFactory("group")})
+	MustRegister(Entry{ChainGroup: testChainGroup, Chain: "alpha", Protocol: "XYZ-20", Factory: fakeFactory("alpha")})
+	MustRegister(Entry{
+		ChainGroup: testChainGroup,
+		Protocol:   "evt-20",
+		Factory:    fakeFactory("evt"),
+		Parser: func(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+			if len(tx.Events) < 1 {
+				return nil, nil
+			}
+			return &devents.MetaData{Chain: chain, Protocol: "evt-20"}, nil
+		},
+	})
+	MustRegister(Entry{
+		ChainGroup: testChainGroup,
+		Chain:      "gamma",
+		Protocol:   "state-20",
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return &stateProtocol{fakeProtocol{name: "0xstate"}}, nil
+		},
+	})
+	MustRegister(Entry{
+		ChainGroup:  testChainGroup,
+		Chain:       "gamma",
+		Protocol:    "shared-20",
+		Shared:      true,
+		EventTopics: []string{"0xshared"},
+		Factory:     fakeFactory("shared"),
+		Parser: func(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error) {
+			if tx.From != "0xsharer" {
+				return nil, nil
+			}
+			return &devents.MetaData{Chain: chain, Protocol: "shared-20"}, nil
+		},
+	})
+	MustRegister(Entry{
+		ChainGroup: testChainGroup,
+		Chain:      "epsilon",
+		Protocol:   "tick-20",
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return &tickProtocol{fakeProtocol{name: "tick"}}, nil
+		},
+	})
+	MustRegister(Entry{
+		ChainGroup: testChainGroup,
+		Chain:      "epsilon",
+		Protocol:   "resolver-20",
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return &resolverProtocol{fakeProtocol: fakeProtocol{name: "resolver"}}, nil
+		},
+	})
+	MustRegister(Entry{
+		ChainGroup: testChainGroup,
+		Chain:      "delta",
+		Protocol:   "sub-20",
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return &subscriberProtocol{fakeProtocol{name: "0x" + cfg.Chain.ChainName}}, nil
+		},
+	})
+	MustRegister(Entry{
+		ChainGroup: testChainGroup,
+		Chain:      "zeta",
+		Protocol:   "match-20",
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return &matcherProtocol{fakeProtocol{name: "0xmatch"}}, nil
+		},
+	})
+	MustRegister(Entry{
+		ChainGroup: testChainGroup,
+		Chain:      "eta",
+		Protocol:   "broken-20",
+		Factory: func(cfg *config.Config, cache *dcache.Manager) (types.IProtocol, error) {
+			return nil, fmt.Errorf("config invalid")
+		},
+	})
+}
+
+func newTestRegistry(t *testing.T, chain string) *Registry {
+	cfg := &config.Config{
+		Chain: config.ChainConfig{
+			ChainName:  chain,
+			ChainGroup: testChainGroup,
+		},
+	}
+	r, err := New(cfg, nil)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestRegistryFactoryError(t *testing.T) {
+	r, err := New(&config.Config{Chain: config.ChainConfig{ChainName: "eta", ChainGroup: testChainGroup}}, nil)
+	assert.Error(t, err)
+	assert.Nil(t, r)
+}
+
+func TestRegisterDuplicated(t *testing.T) {
+	err := Register(Entry{ChainGroup: testChainGroup, Protocol: " Xyz-20 ", Factory: fakeFactory("dup")})
+	assert.Error(t, err)
+
+	err = Register(Entry{ChainGroup: testChainGroup, Protocol: "nil-20"})
+	assert.Error(t, err)
+}
+
+func TestRegistryGet(t *testing.T) {
+	r := newTestRegistry(t, "beta")
+	assert.Equal(t, []string{"evt-20", "xyz-20"}, r.Protocols())
+	assert.Equal(t, "group", r.Get("XYZ-20").(*fakeProtocol).name)
+	assert.Nil(t, r.Get("brc-20"))
+
+	// chain specific entry overrides the group wide one
+	r = newTestRegistry(t, "alpha")
+	assert.Equal(t, "alpha", r.Get("xyz-20").(*fakeProtocol).name)
+}
+
+func TestRegistryParseMetaData(t *testing.T) {
+	r := newTestRegistry(t, "beta")
+
+	md, err := r.ParseMetaData(&xycommon.RpcTransaction{Input: "xyz-20", Events: []xycommon.RpcLog{{}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "evt-20", md.Protocol)
+
+	md, err = r.ParseMetaData(&xycommon.RpcTransaction{Input: "unknown-20"})
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown-20", md.Protocol)
+	assert.Nil(t, r.Get(md.Protocol))
+}
+
+func TestRegistryInstanceParser(t *testing.T) {
+	r := newTestRegistry(t, "gamma")
+
+	md, err := r.ParseMetaData(&xycommon.RpcTransaction{Input: "xyz-20", To: "0xstate"})
+	assert.NoError(t, err)
+	assert.Equal(t, "state-20", md.Protocol)
+
+	md, err = r.ParseMetaData(&xycommon.RpcTransaction{Input: "xyz-20", To: "0xother"})
+	assert.NoError(t, err)
+	assert.Equal(t, "xyz-20", md.Protocol)
+}
+
+func TestRegistrySharedParser(t *testing.T) {
+	r := newTestRegistry(t, "gamma")
+	assert.Equal(t, []string{"0xgroup", "0xshared"}, r.EventTopics())
+	assert.Equal(t, "shared", r.Get("shared-20").(*fakeProtocol).name)
+
+	// shared protocols never take the primary metadata of the tx
+	tx := &xycommon.RpcTransaction{Input: "xyz-20", From: "0xsharer"}
+	md, err := r.ParseMetaData(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, "xyz-20", md.Protocol)
+
+	mds := r.ParseSharedMetaData(tx)
+	assert.Len(t, mds, 1)
+	assert.Equal(t, "shared-20", mds[0].Protocol)
+
+	assert.Len(t, r.ParseSharedMetaData(&xycommon.RpcTransaction{Input: "xyz-20"}), 0)
+	assert.Equal(t, []string{"0xgroup"}, newTestRegistry(t, "beta").EventTopics())
+}
+
+func TestRegistrySubscriberTopics(t *testing.T) {
+	assert.Equal(t, []string{"0xdelta", "0xgroup"}, newTestRegistry(t, "delta").EventTopics())
+}
+
+func TestRegistryNormalizeTick(t *testing.T) {
+	r := newTestRegistry(t, "epsilon")
+
+	// ticks are folded to lower case by default
+	md, err := r.ParseMetaData(&xycommon.RpcTransaction{Input: "xyz-20", Hash: " Ordi "})
+	assert.NoError(t, err)
+	assert.Equal(t, "ordi", md.Tick)
+
+	md, err = r.ParseMetaData(&xycommon.RpcTransaction{Input: "tick-20", Hash: "Ordi"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDI", md.Tick)
+
+	md, err = r.ParseMetaData(&xycommon.RpcTransaction{Input: "tick-20", Hash: "Ordis"})
+	assert.Error(t, err)
+	assert.Nil(t, md)
+
+	// the tick lookups follow the tick rule of the protocol
+	assert.Equal(t, "ORDI", r.NormalizeTick("TICK-20", 100, "Ordi"))
+	assert.Equal(t, "ordi", r.NormalizeTick("xyz-20", 100, " Ordi "))
+
+	rp := r.Get("resolver-20").(*resolverProtocol)
+	assert.Equal(t, "ORDI", rp.normalize("tick-20", 100, "Ordi"))
+}
+
+func TestRegistryMatchTx(t *testing.T) {
+	r := newTestRegistry(t, "beta")
+	assert.True(t, r.MatchTx(&xycommon.RpcTransaction{Input: "xyz-20"}))
+	assert.False(t, r.MatchTx(&xycommon.RpcTransaction{To: "0xmatch"}))
+
+	// protocol matchers are tried after the group envelope one
+	r = newTestRegistry(t, "zeta")
+	assert.True(t, r.MatchTx(&xycommon.RpcTransaction{Input: "xyz-20"}))
+	assert.True(t, r.MatchTx(&xycommon.RpcTransaction{To: "0xmatch"}))
+	assert.False(t, r.MatchTx(&xycommon.RpcTransaction{To: "0xother"}))
+}
+
+func TestRegistryVerifyStart(t *testing.T) {
+	assert.NoError(t, newTestRegistry(t, "beta").VerifyStart(1000))
+
+	r := newTestRegistry(t, "zeta")
+	assert.NoError(t, r.VerifyStart(100))
+	assert.Error(t, r.VerifyStart(101))
+}
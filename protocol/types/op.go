@@ -32,9 +32,60 @@ type IProtocol interface {
 	Parse(block *xycommon.RpcBlock, tx *xycommon.RpcTransaction, md *devents.MetaData) ([]*devents.TxResult, *xyerrors.InsError)
 }
 
+// IMetaDataParser is implemented by protocols recognizing txs from the indexed state,
+// e.g. sending the btc transfer inscriptions which carry no envelope
+type IMetaDataParser interface {
+	ParseMetaData(chain string, tx *xycommon.RpcTransaction) (*devents.MetaData, error)
+}
+
+// IEventSubscriber is implemented by protocols scanning the event logs depending on the config,
+// the topics are merged with the static topics of the registry entry
+type IEventSubscriber interface {
+	EventTopics() []string
+}
+
+// IMetaDataNormalizer checks the inscription envelope & normalizes the tick written in the inscription
+// into the tick identity by the rules in force at the tx height
+type IMetaDataNormalizer interface {
+	NormalizeMetaData(tx *xycommon.RpcTransaction, md *devents.MetaData) error
+}
+
+// ITxMatcher is implemented by protocols able to tell cheaply whether the tx may concern them,
+// the block txs matched by no protocol are dropped before the metadata parsing
+type ITxMatcher interface {
+	MatchTx(tx *xycommon.RpcTransaction) bool
+}
+
+// ITickNormalizer is implemented by protocols normalizing the ticks by their tick rules, the ticks
+// looked up out of the inscription envelope are normalized into the tick identities as well
+type ITickNormalizer interface {
+	NormalizeTick(height uint64, tick string) string
+}
+
+// ITickResolver is implemented by protocols indexing the ticks of the other protocols, e.g. the adapters,
+// the ticks are normalized by the tick rules of the target protocols enabled on the chain
+type ITickResolver interface {
+	SetTickNormalizer(normalize func(protocol string, height uint64, tick string) string)
+}
+
+// IStartVerifier is implemented by protocols depending on the indexed history, the indexer refuses
+// to start from the block the protocol can not be indexed from
+type IStartVerifier interface {
+	VerifyStart(startBlock uint64) error
+}
+
 const (
 	BRC20Protocol = "brc-20"
 	ASC20Protocol = "asc-20"
 	BSC20Protocol = "bsc-20"
 	PRC20Protocol = "prc-20"
+	CIA20Protocol = "cia-20"
+
+	ERC20Protocol = "erc-20"
+
+	// AdapterProtocol indexes the configured contract events as the transfers of the other protocols
+	AdapterProtocol = "abi-adapter"
+
+	EthscriptionsProtocol = "ethscriptions"
+	RunesProtocol         = "runes"
 )
@@ -25,6 +25,7 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"github.com/shopspring/decimal"
 	"github.com/uxuycom/indexer/config"
 	"github.com/uxuycom/indexer/model"
 	"gorm.io/gorm"
@@ -119,7 +120,16 @@ func (conn *DBClient) QueryLastBlock(chain string) (*big.Int, error) {
 	return blockNumber, nil
 }
 
+func (conn *DBClient) isSqlite() bool {
+	return conn.SqlDB.Dialector.Name() == "sqlite"
+}
+
 func (conn *DBClient) GetLock() (ok bool, err error) {
+	// sqlite is an embedded single process db, no session lock required
+	if conn.isSqlite() {
+		return true, nil
+	}
+
 	locked := int64(0)
 	err = conn.SqlDB.Table(model.BlockStatus{}.TableName()).Raw("SELECT GET_LOCK(?, 0)", DBSessionLockKey).Scan(&locked).Error
 	if err != nil {
@@ -133,6 +143,10 @@ type CountResult struct {
 }
 
 func (conn *DBClient) ReleaseLock() (cnt int64, err error) {
+	if conn.isSqlite() {
+		return 1, nil
+	}
+
 	ret := &CountResult{}
 	err = conn.SqlDB.Table(model.BlockStatus{}.TableName()).Raw("SELECT RELEASE_LOCK(?) AS cnt", DBSessionLockKey).Take(ret).Error
 	if err != nil {
@@ -261,6 +275,100 @@ func (conn *DBClient) BatchAddBalances(dbTx *gorm.DB, items []*model.Balances) e
 	return conn.CreateInBatches(dbTx, items, 1000)
 }
 
+func (conn *DBClient) BatchAddUTXOs(dbTx *gorm.DB, items []*model.UTXO) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 1000)
+}
+
+// BatchSpendUTXOs marks the utxos spent by the spent_hash txs
+func (conn *DBClient) BatchSpendUTXOs(dbTx *gorm.DB, chain string, items []*model.UTXO) error {
+	for _, item := range items {
+		err := dbTx.Model(&model.UTXO{}).
+			Where("chain = ? and sn = ? and status = ?", chain, item.Sn, model.UTXOStatusUnspent).
+			Updates(map[string]interface{}{
+				"status":     model.UTXOStatusSpent,
+				"spent_hash": item.SpentHash,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conn *DBClient) BatchAddEthscriptions(dbTx *gorm.DB, items []*model.Ethscription) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 1000)
+}
+
+// BatchUpdateEthscriptionOwners moves the ethscriptions to the current owners in order
+func (conn *DBClient) BatchUpdateEthscriptionOwners(dbTx *gorm.DB, chain string, items []*model.Ethscription) error {
+	for _, item := range items {
+		err := dbTx.Model(&model.Ethscription{}).
+			Where("chain = ? and ethscription_id = ?", chain, item.EthscriptionID).
+			Updates(map[string]interface{}{
+				"current_owner":  item.CurrentOwner,
+				"previous_owner": item.PreviousOwner,
+				"updated_at":     item.UpdatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conn *DBClient) BatchAddEthscriptionTransfers(dbTx *gorm.DB, items []*model.EthscriptionTransfer) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 1000)
+}
+
+func (conn *DBClient) BatchAddRunes(dbTx *gorm.DB, items []*model.Rune) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 1000)
+}
+
+func (conn *DBClient) BatchAddListOrders(dbTx *gorm.DB, items []*model.ListOrder) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 1000)
+}
+
+// BatchSettleListOrders closes the open orders filled or canceled by the settle_hash txs
+func (conn *DBClient) BatchSettleListOrders(dbTx *gorm.DB, chain string, items []*model.ListOrder) error {
+	for _, item := range items {
+		err := dbTx.Model(&model.ListOrder{}).
+			Where("chain = ? and list_id = ? and status = ?", chain, item.ListID, model.OrderStatusOpen).
+			Updates(map[string]interface{}{
+				"status":      item.Status,
+				"buyer":       item.Buyer,
+				"price":       item.Price,
+				"settle_hash": item.SettleHash,
+				"updated_at":  item.UpdatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conn *DBClient) BatchAddTrades(dbTx *gorm.DB, items []*model.Trade) error {
+	if len(items) < 1 {
+		return nil
+	}
+	return conn.CreateInBatches(dbTx, items, 1000)
+}
+
 func (conn *DBClient) BatchUpdateBalances(dbTx *gorm.DB, chain string, items []*model.Balances) error {
 	if len(items) < 1 {
 		return nil
@@ -585,6 +693,23 @@ func (conn *DBClient) GetUTXOCount(address, chain, protocol, tick string) (int64
 	return count, nil
 }
 
+// BackfillAvailableBalances restores the available balances of the rows written before the available column was kept,
+// the available balance is the overall one less the open list escrows and the pending btc brc-20 transfer inscriptions,
+// so recomputing the rows with no available balance left is idempotent
+func (conn *DBClient) BackfillAvailableBalances(chain string) (int64, error) {
+	sql := `UPDATE balances SET available = balance
+	- COALESCE((SELECT SUM(o.amount) FROM list_orders AS o WHERE o.chain = balances.chain AND o.protocol = balances.protocol
+		AND o.tick = balances.tick AND o.seller = balances.address AND o.status = ?), 0)
+	- COALESCE((SELECT SUM(u.amount) FROM utxos AS u WHERE u.chain = balances.chain AND u.protocol = balances.protocol
+		AND u.tick = balances.tick AND u.address = balances.address AND u.status = ? AND u.chain = ? AND u.protocol = ?), 0)
+	WHERE chain = ? AND available = 0 AND balance > 0`
+	ret := conn.SqlDB.Exec(sql, model.OrderStatusOpen, model.UTXOStatusUnspent, model.ChainBTC, "brc-20", chain)
+	if ret.Error != nil {
+		return 0, ret.Error
+	}
+	return ret.RowsAffected, nil
+}
+
 func (conn *DBClient) GetBalancesByIdLimit(chain string, start uint64, limit int) ([]model.Balances, error) {
 	balances := make([]model.Balances, 0)
 	err := conn.SqlDB.Where("chain = ?", chain).Where("id > ?", start).Order("id asc").Limit(limit).Find(&balances).Error
@@ -594,15 +719,85 @@ func (conn *DBClient) GetBalancesByIdLimit(chain string, start uint64, limit int
 	return balances, nil
 }
 
-func (conn *DBClient) GetUTXOsByIdLimit(start uint64, limit int) ([]model.UTXO, error) {
+// AddressMinted total amount minted by the address
+type AddressMinted struct {
+	Protocol string          `gorm:"column:protocol"`
+	Tick     string          `gorm:"column:tick"`
+	Address  string          `gorm:"column:address"`
+	Minted   decimal.Decimal `gorm:"column:minted"`
+}
+
+// GetAddressMinted sums up the mints of the addresses on the ticks limiting the mint amount per address,
+// the premine of the deployer is not counted
+func (conn *DBClient) GetAddressMinted(chain string) ([]*AddressMinted, error) {
+	items := make([]*AddressMinted, 0)
+	err := conn.SqlDB.Table("balance_txn as b").
+		Select("b.protocol, b.tick, b.address, sum(b.amount) as minted").
+		Joins("inner join inscriptions as i on i.chain = b.chain and i.protocol = b.protocol and i.tick = b.tick").
+		Where("b.chain = ? and b.event = ? and i.address_mint_limit > 0", chain, model.TransactionEventMint).
+		Group("b.protocol, b.tick, b.address").Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) GetUTXOsByIdLimit(chain string, start uint64, limit int) ([]model.UTXO, error) {
 	utxos := make([]model.UTXO, 0, limit)
-	err := conn.SqlDB.Where("id > ? ", start).Where("status = ? ", model.UTXOStatusUnspent).Order("id asc").Limit(limit).Find(&utxos).Error
+	err := conn.SqlDB.Where("chain = ?", chain).Where("id > ? ", start).Where("status = ? ", model.UTXOStatusUnspent).Order("id asc").Limit(limit).Find(&utxos).Error
 	if err != nil {
 		return nil, err
 	}
 	return utxos, nil
 }
 
+func (conn *DBClient) GetEthscriptionsByIdLimit(chain string, start uint64, limit int) ([]model.Ethscription, error) {
+	items := make([]model.Ethscription, 0, limit)
+	err := conn.SqlDB.Where("chain = ?", chain).Where("id > ? ", start).Order("id asc").Limit(limit).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) GetRunesByIdLimit(chain string, start uint64, limit int) ([]model.Rune, error) {
+	items := make([]model.Rune, 0, limit)
+	err := conn.SqlDB.Where("chain = ?", chain).Where("id > ? ", start).Order("id asc").Limit(limit).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (conn *DBClient) GetOpenListOrdersByIdLimit(chain string, start uint64, limit int) ([]model.ListOrder, error) {
+	items := make([]model.ListOrder, 0, limit)
+	err := conn.SqlDB.Where("chain = ?", chain).Where("id > ? ", start).Where("status = ? ", model.OrderStatusOpen).Order("id asc").Limit(limit).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetOpenListOrders returns the open orders of the tick, the seller is optional
+func (conn *DBClient) GetOpenListOrders(chain, protocol, tick, seller string, limit, offset int) ([]*model.ListOrder, int64, error) {
+	query := conn.SqlDB.Model(&model.ListOrder{}).
+		Where("chain = ? and protocol = ? and tick = ? and status = ?", chain, protocol, tick, model.OrderStatusOpen)
+	if seller != "" {
+		query = query.Where("seller = ?", seller)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	orders := make([]*model.ListOrder, 0, limit)
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
 func (conn *DBClient) GetUtxosByAddress(address, chain, protocol, tick string) ([]*model.UTXO, error) {
 	var utxos []*model.UTXO
 	query := conn.SqlDB.Model(&model.UTXO{}).
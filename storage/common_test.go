@@ -0,0 +1,98 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/model"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackfillAvailableBalances(t *testing.T) {
+	db, err := NewDbClient(&config.DatabaseConfig{
+		Type: DatabaseTypeSqlite3,
+		Dsn:  filepath.Join(t.TempDir(), "indexer.db"),
+	})
+	assert.NoError(t, err)
+
+	balance := func(chain, protocol, address string, available, overall int64) *model.Balances {
+		return &model.Balances{
+			Chain:     chain,
+			Protocol:  protocol,
+			Address:   address,
+			Tick:      "ordi",
+			Available: decimal.NewFromInt(available),
+			Balance:   decimal.NewFromInt(overall),
+		}
+	}
+	balances := []*model.Balances{
+		balance("avax", "asc-20", "legacy", 0, 100),
+		balance("avax", "asc-20", "seller", 0, 100),
+		balance("avax", "asc-20", "escrowed", 0, 50),
+		balance("avax", "asc-20", "kept", 10, 100),
+		balance("avax", "asc-20", "empty", 0, 0),
+		balance("eth", "asc-20", "legacy", 0, 100),
+		balance(model.ChainBTC, "brc-20", "inscriber", 0, 100),
+	}
+	assert.NoError(t, db.SqlDB.Create(balances).Error)
+
+	orders := []*model.ListOrder{
+		{Chain: "avax", Protocol: "asc-20", Tick: "ordi", ListID: "0x1", Seller: "seller", Amount: decimal.NewFromInt(40), Status: model.OrderStatusOpen},
+		{Chain: "avax", Protocol: "asc-20", Tick: "ordi", ListID: "0x2", Seller: "seller", Amount: decimal.NewFromInt(20), Status: model.OrderStatusFilled},
+		{Chain: "avax", Protocol: "asc-20", Tick: "ordi", ListID: "0x3", Seller: "escrowed", Amount: decimal.NewFromInt(50), Status: model.OrderStatusOpen},
+	}
+	assert.NoError(t, db.SqlDB.Create(orders).Error)
+
+	utxos := []*model.UTXO{
+		{Chain: model.ChainBTC, Protocol: "brc-20", Tick: "ordi", Address: "inscriber", Amount: decimal.NewFromInt(30), Sn: "0xai0", Status: model.UTXOStatusUnspent},
+		{Chain: model.ChainBTC, Protocol: "brc-20", Tick: "ordi", Address: "inscriber", Amount: decimal.NewFromInt(5), Sn: "0xbi0", Status: model.UTXOStatusSpent},
+	}
+	assert.NoError(t, db.SqlDB.Create(utxos).Error)
+
+	expected := map[uint64]int64{
+		balances[0].ID: 100,
+		balances[1].ID: 60,
+		balances[2].ID: 0,
+		balances[3].ID: 10,
+		balances[4].ID: 0,
+		balances[5].ID: 0,
+		balances[6].ID: 70,
+	}
+
+	// the backfill recomputes the same available balances on each start
+	for i := 0; i < 2; i++ {
+		_, err = db.BackfillAvailableBalances("avax")
+		assert.NoError(t, err)
+		_, err = db.BackfillAvailableBalances(model.ChainBTC)
+		assert.NoError(t, err)
+
+		for id, available := range expected {
+			item := &model.Balances{}
+			assert.NoError(t, db.SqlDB.First(item, id).Error)
+			assert.True(t, item.Available.Equal(decimal.NewFromInt(available)), "id[%d] available[%s]", id, item.Available)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package storage
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Candle OHLCV of the trades in the interval starting at the time, prices are unit prices,
+// the volume is the traded amount of the tick & the value is paid in the native coin
+type Candle struct {
+	Time   int64
+	Open   decimal.Decimal
+	High   decimal.Decimal
+	Low    decimal.Decimal
+	Close  decimal.Decimal
+	Volume decimal.Decimal
+	Value  decimal.Decimal
+	Trades int
+}
+
+// GetTrades returns the trades of the tick, latest first, the address matching the seller or the buyer is optional
+func (conn *DBClient) GetTrades(chain, protocol, tick, address string, limit, offset int) ([]*model.Trade, int64, error) {
+	query := conn.SqlDB.Model(&model.Trade{}).Where("chain = ? and protocol = ? and tick = ?", chain, protocol, tick)
+	if address != "" {
+		query = query.Where("seller = ? or buyer = ?", address, address)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	trades := make([]*model.Trade, 0, limit)
+	if err := query.Order("block_number desc, id desc").Limit(limit).Offset(offset).Find(&trades).Error; err != nil {
+		return nil, 0, err
+	}
+	return trades, total, nil
+}
+
+// GetTickCandles returns the candles of the last limit intervals ending with the latest trade of the tick,
+// intervals without trades are skipped
+func (conn *DBClient) GetTickCandles(chain, protocol, tick string, interval int64, limit int) ([]*Candle, error) {
+	if interval <= 0 || limit <= 0 {
+		return nil, errors.New("interval & limit must be positive")
+	}
+
+	latest := &model.Trade{}
+	err := conn.SqlDB.Where("chain = ? and protocol = ? and tick = ?", chain, protocol, tick).Order("block_time desc, id desc").First(latest).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return []*Candle{}, nil
+		}
+		return nil, err
+	}
+
+	since := latest.BlockTime.Unix()/interval*interval - int64(limit-1)*interval
+	trades := make([]*model.Trade, 0)
+	err = conn.SqlDB.Where("chain = ? and protocol = ? and tick = ? and block_time >= ?", chain, protocol, tick, time.Unix(since, 0)).
+		Order("block_time asc, id asc").Find(&trades).Error
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]*Candle, 0, limit)
+	for _, trade := range trades {
+		start := trade.BlockTime.Unix() / interval * interval
+		if len(candles) < 1 || candles[len(candles)-1].Time != start {
+			candles = append(candles, &Candle{
+				Time:   start,
+				Open:   trade.UnitPrice,
+				High:   trade.UnitPrice,
+				Low:    trade.UnitPrice,
+				Volume: decimal.Zero,
+				Value:  decimal.Zero,
+			})
+		}
+
+		c := candles[len(candles)-1]
+		c.High = decimal.Max(c.High, trade.UnitPrice)
+		c.Low = decimal.Min(c.Low, trade.UnitPrice)
+		c.Close = trade.UnitPrice
+		c.Volume = c.Volume.Add(trade.Amount)
+		c.Value = c.Value.Add(trade.Price)
+		c.Trades++
+	}
+	return candles, nil
+}
+
+// GetFloorPrice returns the lowest unit price of the open priced orders of the tick, nil if no order is priced
+func (conn *DBClient) GetFloorPrice(chain, protocol, tick string) (*decimal.Decimal, error) {
+	orders := make([]*model.ListOrder, 0, 1)
+	err := conn.SqlDB.Where("chain = ? and protocol = ? and tick = ? and status = ? and price > 0 and amount > 0", chain, protocol, tick, model.OrderStatusOpen).
+		Order("price / amount asc, id asc").Limit(1).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) < 1 {
+		return nil, nil
+	}
+
+	floor := orders[0].Price.DivRound(orders[0].Amount, 18)
+	return &floor, nil
+}
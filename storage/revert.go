@@ -0,0 +1,328 @@
+// Copyright (c) 2023-2024 The UXUY Developer Team
+// License:
+// MIT License
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+//SOFTWARE
+
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/uxuycom/indexer/model"
+	"gorm.io/gorm"
+)
+
+const revertBatchSize = 500
+
+type balanceKey struct {
+	Protocol string
+	Tick     string
+	Address  string
+}
+
+type tickKey struct {
+	Protocol string
+	Tick     string
+}
+
+// RevertBlocks
+/***************************************
+ * remove all records indexed above the block height,
+ * balances, inscription stats, utxos, list orders & ethscription owners are restored to the state at that height
+ ***************************************/
+func (conn *DBClient) RevertBlocks(dbTx *gorm.DB, chain string, block uint64) error {
+	if err := conn.revertEthscriptions(dbTx, chain, block); err != nil {
+		return err
+	}
+
+	// ticks of the reverted etchings are removed with the deployed inscriptions
+	if err := dbTx.Where("chain = ? AND block_number > ?", chain, block).Delete(&model.Rune{}).Error; err != nil {
+		return err
+	}
+
+	if err := dbTx.Where("chain = ? AND block_number > ?", chain, block).Delete(&model.Trade{}).Error; err != nil {
+		return err
+	}
+
+	hashes := make([]string, 0)
+	err := dbTx.Model(&model.Transaction{}).Where("chain = ? AND block_height > ?", chain, block).Distinct().Pluck("tx_hash", &hashes).Error
+	if err != nil {
+		return err
+	}
+
+	if len(hashes) < 1 {
+		return nil
+	}
+
+	txns, err := conn.getBalanceTxnsByHashes(dbTx, chain, hashes)
+	if err != nil {
+		return err
+	}
+
+	if err = conn.revertBalances(dbTx, chain, txns); err != nil {
+		return err
+	}
+
+	if err = conn.revertInscriptions(dbTx, chain, block, hashes, txns); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(hashes); start += revertBatchSize {
+		end := start + revertBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		batch := hashes[start:end]
+		if err = dbTx.Where("chain = ? AND tx_hash IN ?", chain, batch).Delete(&model.AddressTxs{}).Error; err != nil {
+			return err
+		}
+
+		if err = dbTx.Where("chain = ? AND tx_hash IN ?", chain, batch).Delete(&model.BalanceTxn{}).Error; err != nil {
+			return err
+		}
+
+		if err = dbTx.Where("chain = ? AND tx_hash IN ?", chain, batch).Delete(&model.Transaction{}).Error; err != nil {
+			return err
+		}
+
+		// utxos spent by the reverted txs are unspent again, the ones created by them are removed
+		err = dbTx.Model(&model.UTXO{}).Where("chain = ? AND spent_hash IN ?", chain, batch).Updates(map[string]interface{}{
+			"status":     model.UTXOStatusUnspent,
+			"spent_hash": "",
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err = dbTx.Where("chain = ? AND tx_hash IN ?", chain, batch).Delete(&model.UTXO{}).Error; err != nil {
+			return err
+		}
+
+		// orders settled by the reverted txs are open again, the ones listed by them are removed
+		err = dbTx.Model(&model.ListOrder{}).Where("chain = ? AND settle_hash IN ?", chain, batch).Updates(map[string]interface{}{
+			"status":      model.OrderStatusOpen,
+			"buyer":       "",
+			"settle_hash": "",
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err = dbTx.Where("chain = ? AND list_id IN ?", chain, batch).Delete(&model.ListOrder{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (conn *DBClient) getBalanceTxnsByHashes(dbTx *gorm.DB, chain string, hashes []string) ([]*model.BalanceTxn, error) {
+	txns := make([]*model.BalanceTxn, 0, len(hashes))
+	for start := 0; start < len(hashes); start += revertBatchSize {
+		end := start + revertBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		items := make([]*model.BalanceTxn, 0)
+		err := dbTx.Where("chain = ? AND tx_hash IN ?", chain, hashes[start:end]).Order("id asc").Find(&items).Error
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, items...)
+	}
+	return txns, nil
+}
+
+// revertBalances restore balances to the last balance txn before the reverted ones,
+// balances without any earlier txn are created by reverted blocks & removed
+func (conn *DBClient) revertBalances(dbTx *gorm.DB, chain string, txns []*model.BalanceTxn) error {
+	firstIds := make(map[balanceKey]uint64, len(txns))
+	for _, txn := range txns {
+		key := balanceKey{Protocol: txn.Protocol, Tick: txn.Tick, Address: txn.Address}
+		if id, ok := firstIds[key]; !ok || txn.ID < id {
+			firstIds[key] = txn.ID
+		}
+	}
+
+	for key, id := range firstIds {
+		prev := make([]*model.BalanceTxn, 0, 1)
+		err := dbTx.Where("chain = ? AND protocol = ? AND tick = ? AND address = ? AND id < ?", chain, key.Protocol, key.Tick, key.Address, id).
+			Order("id desc").Limit(1).Find(&prev).Error
+		if err != nil {
+			return err
+		}
+
+		query := dbTx.Model(&model.Balances{}).Where("chain = ? AND protocol = ? AND tick = ? AND address = ?", chain, key.Protocol, key.Tick, key.Address)
+		if len(prev) < 1 {
+			if err = query.Delete(&model.Balances{}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = query.Updates(map[string]interface{}{
+			"available": prev[0].Available,
+			"balance":   prev[0].Balance,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertInscriptions remove ticks deployed in reverted blocks & roll back the stats of other touched ticks
+func (conn *DBClient) revertInscriptions(dbTx *gorm.DB, chain string, block uint64, hashes []string, txns []*model.BalanceTxn) error {
+	deployed := make(map[tickKey]struct{})
+	for start := 0; start < len(hashes); start += revertBatchSize {
+		end := start + revertBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		items := make([]*model.Inscriptions, 0)
+		if err := dbTx.Where("chain = ? AND deploy_hash IN ?", chain, hashes[start:end]).Find(&items).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			deployed[tickKey{Protocol: item.Protocol, Tick: item.Tick}] = struct{}{}
+		}
+	}
+
+	for key := range deployed {
+		if err := dbTx.Where("chain = ? AND protocol = ? AND tick = ?", chain, key.Protocol, key.Tick).Delete(&model.Inscriptions{}).Error; err != nil {
+			return err
+		}
+
+		if err := dbTx.Where("chain = ? AND protocol = ? AND tick = ?", chain, key.Protocol, key.Tick).Delete(&model.InscriptionsStats{}).Error; err != nil {
+			return err
+		}
+	}
+
+	// minted amount & tx count changes of the reverted blocks
+	minted := make(map[tickKey]decimal.Decimal)
+	txCnt := make(map[tickKey]uint64)
+	for _, txn := range txns {
+		key := tickKey{Protocol: txn.Protocol, Tick: txn.Tick}
+		if _, ok := deployed[key]; ok {
+			continue
+		}
+
+		if _, ok := minted[key]; !ok {
+			minted[key] = decimal.Zero
+			txCnt[key] = 0
+		}
+
+		// one mint txn for each mint, one negative sender txn for each transfer
+		if txn.Event == model.TransactionEventMint {
+			minted[key] = minted[key].Add(txn.Amount)
+			txCnt[key]++
+		} else if txn.Amount.IsNegative() {
+			txCnt[key]++
+		}
+	}
+
+	for key, amount := range minted {
+		stats := make([]*model.InscriptionsStats, 0, 1)
+		err := dbTx.Where("chain = ? AND protocol = ? AND tick = ?", chain, key.Protocol, key.Tick).Limit(1).Find(&stats).Error
+		if err != nil {
+			return err
+		}
+
+		if len(stats) < 1 {
+			continue
+		}
+
+		holders := int64(0)
+		err = dbTx.Model(&model.Balances{}).Where("chain = ? AND protocol = ? AND tick = ? AND balance > 0", chain, key.Protocol, key.Tick).Count(&holders).Error
+		if err != nil {
+			return err
+		}
+
+		stat := stats[0]
+		updates := map[string]interface{}{
+			"minted":  decimal.Max(stat.Minted.Sub(amount), decimal.Zero),
+			"holders": holders,
+			"tx_cnt":  uint64(0),
+		}
+		if stat.TxCnt > txCnt[key] {
+			updates["tx_cnt"] = stat.TxCnt - txCnt[key]
+		}
+
+		if stat.MintFirstBlock > block {
+			updates["mint_first_block"] = 0
+		}
+
+		if stat.MintLastBlock > block {
+			updates["mint_last_block"] = 0
+			updates["mint_completed_time"] = nil
+		}
+
+		if err = conn.UpdateInscriptionsStatsBySID(dbTx, chain, stat.SID, updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revertEthscriptions remove ethscriptions created in reverted blocks & move the other transferred ones
+// back to the owners recorded by their last remaining transfer
+func (conn *DBClient) revertEthscriptions(dbTx *gorm.DB, chain string, block uint64) error {
+	ids := make([]string, 0)
+	err := dbTx.Model(&model.EthscriptionTransfer{}).Where("chain = ? AND block_number > ?", chain, block).Distinct().Pluck("ethscription_id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	if len(ids) < 1 {
+		return nil
+	}
+
+	if err = dbTx.Where("chain = ? AND block_number > ?", chain, block).Delete(&model.EthscriptionTransfer{}).Error; err != nil {
+		return err
+	}
+
+	if err = dbTx.Where("chain = ? AND block_number > ?", chain, block).Delete(&model.Ethscription{}).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		prev := make([]*model.EthscriptionTransfer, 0, 1)
+		err = dbTx.Where("chain = ? AND ethscription_id = ?", chain, id).Order("id desc").Limit(1).Find(&prev).Error
+		if err != nil {
+			return err
+		}
+
+		// created in the reverted blocks
+		if len(prev) < 1 {
+			continue
+		}
+
+		err = dbTx.Model(&model.Ethscription{}).Where("chain = ? AND ethscription_id = ?", chain, id).Updates(map[string]interface{}{
+			"current_owner":  prev[0].To,
+			"previous_owner": prev[0].From,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
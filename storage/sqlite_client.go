@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/uxuycom/indexer/config"
+	"github.com/uxuycom/indexer/model"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -44,6 +45,33 @@ func NewSqliteClient(cfg *config.DatabaseConfig, gormCfg *gorm.Config) (*DBClien
 		return nil, err
 	}
 
+	// sqlite allows one writer only
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	err = db.AutoMigrate(
+		&model.Inscriptions{},
+		&model.InscriptionsStats{},
+		&model.Transaction{},
+		&model.AddressTxs{},
+		&model.BalanceTxn{},
+		&model.Balances{},
+		&model.UTXO{},
+		&model.Ethscription{},
+		&model.EthscriptionTransfer{},
+		&model.Rune{},
+		&model.ListOrder{},
+		&model.Trade{},
+		&model.Block{},
+	)
+	if err != nil {
+		log.Error("migrate sqlite tables failed", "err", err)
+		return nil, err
+	}
+
 	conn := &DBClient{
 		SqlDB: db,
 	}
@@ -38,8 +38,8 @@ type EventLog struct {
 }
 
 func ParseEventToMap(parsedABI abi.ABI, eventLog EventLog, output map[string]interface{}) (eventName string, err error) {
-	if output == nil || reflect.TypeOf(output).Kind() != reflect.Ptr {
-		return "", fmt.Errorf("output must be a pointer")
+	if output == nil {
+		return "", fmt.Errorf("output map nil")
 	}
 
 	if len(eventLog.Topics) < 1 {